@@ -3,6 +3,7 @@ package blockchain
 import (
 	"context"
 	"crypto/ecdsa"
+	"errors"
 	"fmt"
 	"log"
 	"math/big"
@@ -112,6 +113,13 @@ func EnsureInitialized() error {
 	return nil
 }
 
+// SignOnlyAuth 返回只签名不广播的授权副本，调用方先保存已签名交易，再通过 Client.SendTransaction 发出
+func SignOnlyAuth() *bind.TransactOpts {
+	opts := *Auth
+	opts.NoSend = true
+	return &opts
+}
+
 // GetNextNonce 获取下一个可用 Nonce，每次实时获取
 func (bm *blockchainManager) GetNextNonce(ctx context.Context) (uint64, error) {
 	bm.nonceMutex.Lock()
@@ -212,6 +220,20 @@ func (bm *blockchainManager) GetCurrentGasLimit(ctx context.Context, data []byte
 	return bm.currentGasLimit, nil
 }
 
+// nonRetryableError 标记不可重试的错误，例如交易已发出或已上链后的处理失败，重试会导致重复执行
+type nonRetryableError struct {
+	err error
+}
+
+func (e *nonRetryableError) Error() string { return e.err.Error() }
+
+func (e *nonRetryableError) Unwrap() error { return e.err }
+
+// NonRetryable 包装错误，WithBlockchain 遇到该错误时立即返回原始错误而不再重试
+func NonRetryable(err error) error {
+	return &nonRetryableError{err: err}
+}
+
 // WithBlockchain 封装区块链操作，包含错误重试机制
 func WithBlockchain(ctx context.Context, data []byte, fn func() (common.Hash, error)) (common.Hash, error) {
 	if err := EnsureInitialized(); err != nil {
@@ -241,6 +263,11 @@ func WithBlockchain(ctx context.Context, data []byte, fn func() (common.Hash, er
 		// 执行交易
 		txHash, err = fn()
 		if err != nil {
+			var stop *nonRetryableError
+			if errors.As(err, &stop) {
+				utils.Logger.Error("Transaction failed after submission, not retrying", "tx_hash", txHash.Hex(), "error", stop.err)
+				return txHash, stop.err
+			}
 			if strings.Contains(err.Error(), "nonce too low") || strings.Contains(err.Error(), "nonce too high") {
 				utils.Logger.Warn("Nonce issue, retrying immediately", "attempt", attempt+1, "nonce", nonce)
 				continue
@@ -0,0 +1,66 @@
+// main.go
+package main
+
+import (
+	"backend/config"
+	"backend/db"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+)
+
+const usage = `Usage: migrate <command> [args]
+
+Commands:
+  up          Apply all pending migrations
+  down [n]    Revert the last n applied migrations (default 1)
+  status      Show the status of every migration`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Println(usage)
+		os.Exit(1)
+	}
+
+	config.LoadConfig()
+	db.InitDB()
+
+	switch os.Args[1] {
+	case "up":
+		count, err := db.MigrateUp(db.DB)
+		if err != nil {
+			log.Fatalf("Migration failed: %v", err)
+		}
+		log.Printf("%d migrations applied", count)
+	case "down":
+		steps := 1
+		if len(os.Args) > 2 {
+			n, err := strconv.Atoi(os.Args[2])
+			if err != nil || n <= 0 {
+				log.Fatalf("Invalid number of steps: %s", os.Args[2])
+			}
+			steps = n
+		}
+		count, err := db.MigrateDown(db.DB, steps)
+		if err != nil {
+			log.Fatalf("Rollback failed: %v", err)
+		}
+		log.Printf("%d migrations reverted", count)
+	case "status":
+		statuses, err := db.GetMigrationStatus(db.DB)
+		if err != nil {
+			log.Fatalf("Failed to get migration status: %v", err)
+		}
+		for _, s := range statuses {
+			appliedAt := "pending"
+			if s.Applied {
+				appliedAt = s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%06d  %-40s %s\n", s.Version, s.Name, appliedAt)
+		}
+	default:
+		fmt.Println(usage)
+		os.Exit(1)
+	}
+}
@@ -2,10 +2,18 @@
 package main
 
 import (
+	"context"
+
 	"backend/blockchain"
 	"backend/config"
 	"backend/db"
 	"backend/routes"
+	"backend/services/authtoken"
+	"backend/services/issue"
+	"backend/services/kyc"
+	"backend/services/outbox"
+	"backend/services/screening"
+	"backend/services/webhook"
 	"backend/utils"
 
 	"github.com/gin-gonic/gin"
@@ -30,7 +38,29 @@ func main() {
 		utils.Logger.Fatal("Failed to connect to blockchain")
 	}
 
+	// JWT 签名密钥配置错误时无法登录，启动时即退出
+	if _, err := authtoken.DefaultKeySet(); err != nil {
+		utils.Logger.Fatal("Failed to load JWT keys: ", err)
+	}
+
+	// 恢复上次运行中断的链上操作（链上已执行但数据库未写入）
+	outbox.StartRecoveryWorker(context.Background(), db.DB)
+	// 按期号计划自动开期
+	issue.StartIssueScheduler(context.Background(), db.DB)
+	// 投递 Webhook，失败按指数退避重试
+	webhook.StartDispatcher(context.Background(), db.DB)
+	// 提醒并处理到期的 KYC 认证
+	kyc.StartExpiryWorker(context.Background(), db.DB)
+	// 导入制裁名单，名单变化后重新筛查所有用户
+	screening.StartListWatcher(context.Background(), db.DB)
+	// 定期清理已过期或已撤销的登录会话及其刷新令牌
+	authtoken.StartCleanupWorker(context.Background(), db.DB)
+
 	r := gin.Default()
+	// 黑白名单、国家限制和限流都依赖 c.ClientIP()，只信任配置的代理转发的客户端地址
+	if err := r.SetTrustedProxies(config.AppConfig.TrustedProxies); err != nil {
+		utils.Logger.Fatal("Invalid TRUSTED_PROXIES: ", err)
+	}
 	routes.SetupRoutes(r)
 
 	// 服务 Swagger UI
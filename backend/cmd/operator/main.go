@@ -2,10 +2,14 @@
 package main
 
 import (
+	"context"
+
 	"backend/blockchain"
 	"backend/config"
 	"backend/db"
 	"backend/routes"
+	"backend/services/authtoken"
+	"backend/services/vrf"
 	"backend/utils"
 
 	"github.com/gin-gonic/gin"
@@ -25,7 +29,19 @@ func main() {
 		utils.Logger.Fatal("Failed to connect to blockchain")
 	}
 
+	// JWT 签名密钥配置错误时无法登录，启动时即退出
+	if _, err := authtoken.DefaultKeySet(); err != nil {
+		utils.Logger.Fatal("Failed to load JWT keys: ", err)
+	}
+
+	// 开发链上代替 VRF 预言机回填随机数（DEV_VRF_ENABLED）
+	vrf.StartDevFulfiller(context.Background(), blockchain.Client)
+
 	r := gin.Default()
+	// 黑白名单、国家限制和限流都依赖 c.ClientIP()，只信任配置的代理转发的客户端地址
+	if err := r.SetTrustedProxies(config.AppConfig.TrustedProxies); err != nil {
+		utils.Logger.Fatal("Invalid TRUSTED_PROXIES: ", err)
+	}
 	routes.SetupOpRoutes(r)
 
 	// 服务 Swagger UI
@@ -5,6 +5,7 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"strings"
 
 	"github.com/joho/godotenv"
 )
@@ -22,6 +23,16 @@ type AppConfigStruct struct {
 	DB_TIMEZONE string
 	JWTSecret   string
 
+	// JWT 配置
+	JWTAlgorithm     string   // 签名算法：HS256（使用 JWTSecret）、RS256 或 EdDSA
+	JWTPrivateKeys   []string // RS256/EdDSA 的 PEM 私钥文件，第一个用于签名，其余只用于验证轮换前签发的令牌
+	JWTIssuer        string   // 令牌的 iss
+	JWTAccessTTL     int      // 访问令牌有效期（以秒为单位）
+	JWTRefreshTTL    int      // 刷新令牌有效期（以秒为单位），每次刷新都会换发新的刷新令牌
+	JWTSessionMaxAge int      // 一次登录最长可以刷新多久（以秒为单位），到期后需要重新登录
+
+	DBAutoMigrate bool // 启动时自动执行数据库迁移
+
 	//blockchain配置
 	EthereumNodeURL string // 以太坊节点 URL（例如 Infura）
 	AdminPrivateKey string // 管理员私钥（用于调用 verifyKYC）
@@ -34,6 +45,77 @@ type AppConfigStruct struct {
 
 	BlockchainSyncInterval int // 区块链同步间隔（以秒为单位）
 
+	// 开发链 VRF 配置，仅用于本地链（Anvil/Hardhat/模拟链）
+	DevVRFEnabled            bool   // 是否由运营端代替 VRF 预言机回填随机数
+	DevVRFCoordinatorAddress string // VRF 协调器合约地址
+	DevVRFSeed               string // 生成确定性随机数的种子
+	DevVRFPollInterval       int    // 轮询 RandomWordsRequested 事件的间隔（以秒为单位）
+
+	// 期号配置
+	IssueNumberPattern     string // 默认期号编号规则，例如 {yyyyMMdd}-{seq}
+	IssueSchedulerInterval int    // 期号计划检查间隔（以秒为单位）
+
+	IdempotencyKeyTTLHours  int // 幂等键保留时间（以小时为单位）
+	IdempotencyLeaseSeconds int // 处理中的幂等键租约时长，租约过期后相同请求可接管该幂等键（以秒为单位）
+
+	AccountSummaryCacheSeconds int // 用户账户汇总聚合结果的缓存时间（以秒为单位）
+
+	// Webhook 配置
+	WebhookDispatchInterval int // 投递待发送 Webhook 的间隔（以秒为单位）
+	WebhookMaxAttempts      int // 单次投递的最大尝试次数，用尽后转入死信表
+	WebhookTimeoutSeconds   int // 单次请求的超时时间（以秒为单位）
+
+	// 通知配置
+	NotificationChannel string // 通知发送通道：smtp 或 capture（仅记录在内存中，用于本地开发和测试）
+	SMTPHost            string // SMTP 服务器地址
+	SMTPPort            int    // SMTP 端口
+	SMTPUsername        string // SMTP 用户名，为空时不认证
+	SMTPPassword        string // SMTP 密码
+	SMTPFrom            string // 发件人地址
+
+	// KYC 证件存储配置
+	DocumentStorageDir    string // 未配置 S3 时加密证件的本地存储目录，不对外提供静态访问
+	DocumentEncryptionKey string // 本地证件加密密钥（32 字节的十六进制编码）
+	DocumentURLTTLSeconds int    // 证件访问链接的有效期（以秒为单位）
+	UploadScanner         string // 上传文件的恶意软件扫描：clamd（默认，无法连接时拒绝上传）或 stub（仅识别 EICAR 测试文件，需显式配置，仅用于本地开发和测试）
+	ClamdAddress          string // clamd 地址，host:port 或 unix socket 路径
+	ClamdTimeoutSeconds   int    // 单次扫描的超时时间（以秒为单位）
+
+	// KYC 复核配置
+	KYCReverifyDaysLow     int    // 低风险用户的定期复核间隔（以天为单位）
+	KYCReverifyDaysMedium  int    // 中风险及未标注风险等级用户的定期复核间隔（以天为单位）
+	KYCReverifyDaysHigh    int    // 高风险用户的定期复核间隔（以天为单位）
+	KYCExpiryReminderDays  int    // 到期前多少天提醒用户（以天为单位）
+	KYCExpiryCheckInterval int    // 检查 KYC 到期的间隔（以秒为单位）
+	KYCExpiryAction        string // 到期后的处理方式：downgrade（取消 IsVerified，无法登录和购票）或 restrict（仅禁止购票）
+
+	// 制裁名单筛查配置
+	SanctionsListDir         string  // 制裁名单目录，读取其中的 CSV 和 JSON 文件
+	SanctionsMatchThreshold  float64 // 姓名模糊匹配的相似度阈值（0-1），达到即视为命中
+	SanctionsRefreshInterval int     // 检查名单文件变化的间隔（以秒为单位），变化后重新筛查所有用户
+
+	// 黑白名单配置
+	AccessListCacheSeconds int // IP 和钱包黑白名单的缓存时间（以秒为单位），本服务的修改会立即生效，另一服务的修改在缓存过期后生效
+
+	// 反向代理配置
+	TrustedProxies []string // 信任其 X-Forwarded-For 的代理 IP 或 CIDR，为空时不信任任何代理，c.ClientIP() 取连接的对端地址
+
+	// GeoIP 配置
+	GeoIPDBPath       string // MaxMind 格式（mmdb）的 GeoIP 数据库文件，为空或无法打开时有国家限制的彩票不能购买
+	GeoIPBlockUnknown bool   // 无法解析国家的 IP（如内网地址）在有国家限制的彩票上是否拒绝
+
+	// 限流配置，速率格式为 <次数>/<时间>，如 10/1m
+	RateLimitEnabled  bool   // 是否对登录、注册和购票限流
+	RateLimitBackend  string // 令牌桶存储：memory（单机内存）或 redis（多个服务共享）
+	RedisURL          string // Redis 地址，如 redis://localhost:6379/0，兼容 Redis 协议的服务均可
+	RateLimitLogin    string // 登录接口的速率，按 IP、钱包地址分别计算
+	RateLimitRegister string // 注册接口的速率
+	RateLimitPurchase string // 购票接口的速率，每次购票都会用管理员私钥发送链上交易
+
+	// 链上操作恢复配置
+	ChainIntentRecoveryInterval int // 未完成链上操作的扫描间隔（以秒为单位）
+	ChainIntentStaleAfter       int // 链上操作超过该时间未更新视为中断（以秒为单位）
+
 	// S3 配置
 	Endpoint   string // S3 端点
 	BucketName string // S3 存储桶名称
@@ -44,6 +126,13 @@ type AppConfigStruct struct {
 
 var AppConfig AppConfigStruct
 
+func getEnvString(key string, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
 func getEnvInt(key string, defaultValue int) int {
 	valueStr := os.Getenv(key)
 	if valueStr == "" {
@@ -56,6 +145,18 @@ func getEnvInt(key string, defaultValue int) int {
 	return value
 }
 
+func getEnvBool(key string, defaultValue bool) bool {
+	valueStr := os.Getenv(key)
+	if valueStr == "" {
+		return defaultValue
+	}
+	value, err := strconv.ParseBool(valueStr)
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
 func getEnvFloat(key string, defaultValue float64) float64 {
 	valueStr := os.Getenv(key)
 	if valueStr == "" {
@@ -68,6 +169,17 @@ func getEnvFloat(key string, defaultValue float64) float64 {
 	return value
 }
 
+// getEnvList 读取逗号分隔的列表，忽略空项
+func getEnvList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
 // LoadConfig 加载配置
 func LoadConfig() {
 	// 加载 .env 文件
@@ -101,6 +213,15 @@ func LoadConfig() {
 		DB_TIMEZONE: os.Getenv("DB_TIMEZONE"),
 		JWTSecret:   os.Getenv("JWT_SECRET"),
 
+		JWTAlgorithm:     getEnvString("JWT_ALGORITHM", "HS256"),
+		JWTPrivateKeys:   getEnvList("JWT_PRIVATE_KEYS"),
+		JWTIssuer:        getEnvString("JWT_ISSUER", "lottery-backend"),
+		JWTAccessTTL:     getEnvInt("JWT_ACCESS_TTL_SECONDS", 900),
+		JWTRefreshTTL:    getEnvInt("JWT_REFRESH_TTL_SECONDS", 7*24*3600),
+		JWTSessionMaxAge: getEnvInt("JWT_SESSION_MAX_SECONDS", 30*24*3600),
+
+		DBAutoMigrate: getEnvBool("DB_AUTO_MIGRATE", false),
+
 		EthereumNodeURL: os.Getenv("ETHEREUM_NODE_URL"),
 		AdminPrivateKey: os.Getenv("ADMIN_PRIVATE_KEY"),
 
@@ -111,6 +232,65 @@ func LoadConfig() {
 		MaxBlockchainRetries:   getEnvInt("MAX_BLOCKCHAIN_RETRIES", 3),
 		GasLimitIncreaseFactor: getEnvFloat("GAS_LIMIT_INCREASE_FACTOR", 1.5),
 
+		DevVRFEnabled:            getEnvBool("DEV_VRF_ENABLED", false),
+		DevVRFCoordinatorAddress: os.Getenv("DEV_VRF_COORDINATOR_ADDRESS"),
+		DevVRFSeed:               getEnvString("DEV_VRF_SEED", "lottery-dev"),
+		DevVRFPollInterval:       getEnvInt("DEV_VRF_POLL_INTERVAL", 5),
+
+		IssueNumberPattern:     getEnvString("ISSUE_NUMBER_PATTERN", "{yyyyMMdd}-{seq}"),
+		IssueSchedulerInterval: getEnvInt("ISSUE_SCHEDULER_INTERVAL", 60),
+
+		IdempotencyKeyTTLHours:  getEnvInt("IDEMPOTENCY_KEY_TTL_HOURS", 24),
+		IdempotencyLeaseSeconds: getEnvInt("IDEMPOTENCY_LEASE_SECONDS", 300),
+
+		AccountSummaryCacheSeconds: getEnvInt("ACCOUNT_SUMMARY_CACHE_SECONDS", 30),
+
+		WebhookDispatchInterval: getEnvInt("WEBHOOK_DISPATCH_INTERVAL", 5),
+		WebhookMaxAttempts:      getEnvInt("WEBHOOK_MAX_ATTEMPTS", 8),
+		WebhookTimeoutSeconds:   getEnvInt("WEBHOOK_TIMEOUT_SECONDS", 10),
+
+		NotificationChannel: getEnvString("NOTIFICATION_CHANNEL", "capture"),
+		SMTPHost:            os.Getenv("SMTP_HOST"),
+		SMTPPort:            getEnvInt("SMTP_PORT", 587),
+		SMTPUsername:        os.Getenv("SMTP_USERNAME"),
+		SMTPPassword:        os.Getenv("SMTP_PASSWORD"),
+		SMTPFrom:            getEnvString("SMTP_FROM", "no-reply@lottery.local"),
+
+		DocumentStorageDir:    getEnvString("DOCUMENT_STORAGE_DIR", "private/kyc"),
+		DocumentEncryptionKey: os.Getenv("DOCUMENT_ENCRYPTION_KEY"),
+		DocumentURLTTLSeconds: getEnvInt("DOCUMENT_URL_TTL_SECONDS", 300),
+		UploadScanner:         getEnvString("UPLOAD_SCANNER", "clamd"),
+		ClamdAddress:          getEnvString("CLAMD_ADDRESS", "127.0.0.1:3310"),
+		ClamdTimeoutSeconds:   getEnvInt("CLAMD_TIMEOUT_SECONDS", 30),
+
+		KYCReverifyDaysLow:     getEnvInt("KYC_REVERIFY_DAYS_LOW", 730),
+		KYCReverifyDaysMedium:  getEnvInt("KYC_REVERIFY_DAYS_MEDIUM", 365),
+		KYCReverifyDaysHigh:    getEnvInt("KYC_REVERIFY_DAYS_HIGH", 180),
+		KYCExpiryReminderDays:  getEnvInt("KYC_EXPIRY_REMINDER_DAYS", 30),
+		KYCExpiryCheckInterval: getEnvInt("KYC_EXPIRY_CHECK_INTERVAL", 3600),
+		KYCExpiryAction:        getEnvString("KYC_EXPIRY_ACTION", "downgrade"),
+
+		SanctionsListDir:         getEnvString("SANCTIONS_LIST_DIR", "sanctions"),
+		SanctionsMatchThreshold:  getEnvFloat("SANCTIONS_MATCH_THRESHOLD", 0.9),
+		SanctionsRefreshInterval: getEnvInt("SANCTIONS_REFRESH_INTERVAL", 300),
+
+		AccessListCacheSeconds: getEnvInt("ACCESS_LIST_CACHE_SECONDS", 30),
+
+		TrustedProxies: getEnvList("TRUSTED_PROXIES"),
+
+		GeoIPDBPath:       os.Getenv("GEOIP_DB_PATH"),
+		GeoIPBlockUnknown: getEnvBool("GEOIP_BLOCK_UNKNOWN", false),
+
+		RateLimitEnabled:  getEnvBool("RATE_LIMIT_ENABLED", true),
+		RateLimitBackend:  getEnvString("RATE_LIMIT_BACKEND", "memory"),
+		RedisURL:          getEnvString("REDIS_URL", "redis://localhost:6379/0"),
+		RateLimitLogin:    getEnvString("RATE_LIMIT_LOGIN", "10/1m"),
+		RateLimitRegister: getEnvString("RATE_LIMIT_REGISTER", "5/1h"),
+		RateLimitPurchase: getEnvString("RATE_LIMIT_PURCHASE", "20/1m"),
+
+		ChainIntentRecoveryInterval: getEnvInt("CHAIN_INTENT_RECOVERY_INTERVAL", 60),
+		ChainIntentStaleAfter:       getEnvInt("CHAIN_INTENT_STALE_AFTER", 600),
+
 		// S3 配置
 		Endpoint:   os.Getenv("S3_ENDPOINT"),
 		BucketName: os.Getenv("S3_BUCKET_NAME"),
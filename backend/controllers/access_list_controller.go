@@ -0,0 +1,198 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"backend/db"
+	"backend/services/accesslist"
+	"backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// AccessListQuery defines the query parameters of the access list
+type AccessListQuery struct {
+	ListType string `form:"list_type" validate:"omitempty,oneof=ALLOW DENY"`
+	Kind     string `form:"kind" validate:"omitempty,oneof=IP WALLET"`
+}
+
+// AccessListAuditQuery defines the query parameters of the access list audit trail
+type AccessListAuditQuery struct {
+	EntryID uint `form:"entry_id"`
+	Limit   int  `form:"limit" validate:"omitempty,min=1,max=500"`
+}
+
+// AccessListEntryRequest defines the request structure for creating or updating an access list entry,
+// omitted fields keep their value on update
+type AccessListEntryRequest struct {
+	ListType  *string    `json:"list_type" validate:"omitempty,oneof=ALLOW DENY"`
+	Kind      *string    `json:"kind" validate:"omitempty,oneof=IP WALLET"`
+	Value     *string    `json:"value" validate:"omitempty,max=255"`
+	Reason    *string    `json:"reason" validate:"omitempty,max=500"`
+	ExpiresAt *time.Time `json:"expires_at"` // "0001-01-01T00:00:00Z" removes the expiry on update
+}
+
+// bindAccessListEntry binds and validates the request body of an entry
+func bindAccessListEntry(c *gin.Context) (accesslist.EntryParams, bool) {
+	var req AccessListEntryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Logger.Warn("Failed to bind request body", "error", err)
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(utils.NewBadRequestError("Invalid request body", err)))
+		return accesslist.EntryParams{}, false
+	}
+	if err := validator.New().Struct(&req); err != nil {
+		utils.Logger.Warn("Failed to validate request parameters", "error", err)
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(utils.NewBadRequestError("Parameter validation failed", err)))
+		return accesslist.EntryParams{}, false
+	}
+	return accesslist.EntryParams{
+		ListType:  req.ListType,
+		Kind:      req.Kind,
+		Value:     req.Value,
+		Reason:    req.Reason,
+		ExpiresAt: req.ExpiresAt,
+	}, true
+}
+
+// entryIDParam parses the :entry_id path parameter
+func entryIDParam(c *gin.Context) (uint, bool) {
+	entryID, err := strconv.ParseUint(c.Param("entry_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(utils.NewBadRequestError("Invalid entry_id", err)))
+		return 0, false
+	}
+	return uint(entryID), true
+}
+
+// ListAccessListEntries handles GET /access-lists requests
+//
+// Query parameters:
+//   - list_type: ALLOW or DENY (optional)
+//   - kind: IP or WALLET (optional)
+func ListAccessListEntries(c *gin.Context) {
+	if _, ok := currentAdmin(c); !ok {
+		return
+	}
+	var query AccessListQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.Logger.Warn("Failed to bind query parameters", "error", err)
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(utils.NewBadRequestError("Invalid query parameters", err)))
+		return
+	}
+	if err := validator.New().Struct(&query); err != nil {
+		utils.Logger.Warn("Failed to validate query parameters", "error", err)
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(utils.NewBadRequestError("Invalid query parameters", err)))
+		return
+	}
+	service := accesslist.NewAccessListService(db.DB)
+	entries, err := service.List(c.Request.Context(), accesslist.EntryFilter{ListType: query.ListType, Kind: query.Kind})
+	if err != nil {
+		utils.Logger.Error("Failed to list access list entries", "error", err)
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(err))
+		return
+	}
+	c.JSON(http.StatusOK, utils.SuccessResponse("Access list entries retrieved successfully", entries))
+}
+
+// CreateAccessListEntry handles POST /access-lists requests
+//
+// Request body:
+//   - list_type: ALLOW or DENY
+//   - kind: IP or WALLET
+//   - value: IP address, CIDR or wallet address
+//   - reason: Why the entry exists (optional)
+//   - expires_at: When the entry stops applying (optional)
+func CreateAccessListEntry(c *gin.Context) {
+	admin, ok := currentAdmin(c)
+	if !ok {
+		return
+	}
+	params, ok := bindAccessListEntry(c)
+	if !ok {
+		return
+	}
+	service := accesslist.NewAccessListService(db.DB)
+	entry, err := service.Create(c.Request.Context(), params, admin)
+	if err != nil {
+		utils.Logger.Warn("Failed to create access list entry", "admin", admin, "error", err)
+		c.JSON(serviceErrorStatus(err), utils.NewErrorResponse(err))
+		return
+	}
+	c.JSON(http.StatusCreated, utils.SuccessResponse("Access list entry created", entry))
+}
+
+// UpdateAccessListEntry handles PUT /access-lists/:entry_id requests
+func UpdateAccessListEntry(c *gin.Context) {
+	admin, ok := currentAdmin(c)
+	if !ok {
+		return
+	}
+	entryID, ok := entryIDParam(c)
+	if !ok {
+		return
+	}
+	params, ok := bindAccessListEntry(c)
+	if !ok {
+		return
+	}
+	service := accesslist.NewAccessListService(db.DB)
+	entry, err := service.Update(c.Request.Context(), entryID, params, admin)
+	if err != nil {
+		utils.Logger.Warn("Failed to update access list entry", "entry_id", entryID, "admin", admin, "error", err)
+		c.JSON(serviceErrorStatus(err), utils.NewErrorResponse(err))
+		return
+	}
+	c.JSON(http.StatusOK, utils.SuccessResponse("Access list entry updated", entry))
+}
+
+// DeleteAccessListEntry handles DELETE /access-lists/:entry_id requests
+func DeleteAccessListEntry(c *gin.Context) {
+	admin, ok := currentAdmin(c)
+	if !ok {
+		return
+	}
+	entryID, ok := entryIDParam(c)
+	if !ok {
+		return
+	}
+	service := accesslist.NewAccessListService(db.DB)
+	if err := service.Delete(c.Request.Context(), entryID, admin); err != nil {
+		utils.Logger.Warn("Failed to delete access list entry", "entry_id", entryID, "admin", admin, "error", err)
+		c.JSON(serviceErrorStatus(err), utils.NewErrorResponse(err))
+		return
+	}
+	c.JSON(http.StatusOK, utils.SuccessResponse("Access list entry deleted", nil))
+}
+
+// ListAccessListAudit handles GET /access-lists/audit requests
+//
+// Query parameters:
+//   - entry_id: Only the changes of this entry (optional)
+//   - limit: Maximum records, default 100, max 500 (optional)
+func ListAccessListAudit(c *gin.Context) {
+	if _, ok := currentAdmin(c); !ok {
+		return
+	}
+	var query AccessListAuditQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.Logger.Warn("Failed to bind query parameters", "error", err)
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(utils.NewBadRequestError("Invalid query parameters", err)))
+		return
+	}
+	if err := validator.New().Struct(&query); err != nil {
+		utils.Logger.Warn("Failed to validate query parameters", "error", err)
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(utils.NewBadRequestError("Invalid query parameters", err)))
+		return
+	}
+	service := accesslist.NewAccessListService(db.DB)
+	audits, err := service.ListAudit(c.Request.Context(), query.EntryID, query.Limit)
+	if err != nil {
+		utils.Logger.Error("Failed to list access list audit trail", "error", err)
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(err))
+		return
+	}
+	c.JSON(http.StatusOK, utils.SuccessResponse("Access list audit trail retrieved successfully", audits))
+}
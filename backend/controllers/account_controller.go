@@ -0,0 +1,39 @@
+package controllers
+
+import (
+	"net/http"
+
+	"backend/db"
+	accountService "backend/services/account"
+	"backend/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetMySummary handles GET /me/summary requests
+//
+// Returns the authenticated customer's total spent, total won (net of withholding), net position,
+// open tickets per pending issue, LOT balance and KYC status. The aggregates are cached for
+// ACCOUNT_SUMMARY_CACHE_SECONDS; lot_balance is null when the chain cannot be reached.
+//
+// Responses:
+//   - 200: Success, returns the account summary
+//   - 403: Missing or invalid token
+//   - 500: Server error
+func GetMySummary(c *gin.Context) {
+	address, _ := c.Get("customer_address")
+	customerAddress, ok := address.(string)
+	if !ok || customerAddress == "" {
+		c.JSON(http.StatusForbidden, utils.ErrorResponse(utils.ErrCodeForbidden, "Token has no customer address", nil))
+		return
+	}
+
+	service := accountService.NewAccountSummaryService(db.DB)
+	summary, err := service.GetSummary(c.Request.Context(), customerAddress)
+	if err != nil {
+		utils.Logger.Error("Failed to build account summary", "address", customerAddress, "error", err)
+		c.JSON(serviceErrorStatus(err), utils.NewErrorResponse(err))
+		return
+	}
+	c.JSON(http.StatusOK, utils.SuccessResponse("Account summary retrieved successfully", summary))
+}
@@ -2,12 +2,16 @@
 package controllers
 
 import (
+	"backend/db"
 	"backend/models"
 	"backend/services"
+	"backend/services/authtoken"
 	"backend/utils"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
 )
 
 // LoginRequest 用户登录请求结构体
@@ -20,12 +24,33 @@ type LoginResponse struct {
 	CustomerAddress string            `json:"customer_address"`
 	Role            string            `json:"role"`
 	Menus           []models.RoleMenu `json:"menus"`
-	Token           string            `json:"token"`
+	Token           string            `json:"token"`              // 访问令牌，有效期见 expires_at
+	ExpiresAt       time.Time         `json:"expires_at"`         // 访问令牌过期时间
+	RefreshToken    string            `json:"refresh_token"`      // 刷新令牌，只能使用一次
+	RefreshExpires  time.Time         `json:"refresh_expires_at"` // 刷新令牌过期时间
+}
+
+// RefreshRequest 刷新 Token 请求结构体
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required,max=100"`
 }
 
 // RefreshResponse 刷新 Token 响应结构体
 type RefreshResponse struct {
-	Token string `json:"token"`
+	Token          string    `json:"token"`
+	ExpiresAt      time.Time `json:"expires_at"`
+	RefreshToken   string    `json:"refresh_token"`
+	RefreshExpires time.Time `json:"refresh_expires_at"`
+}
+
+// LogoutRequest 退出登录请求结构体
+type LogoutRequest struct {
+	All bool `json:"all"` // 为 true 时退出该用户的所有会话
+}
+
+// RevokeSessionsRequest 撤销用户会话请求结构体
+type RevokeSessionsRequest struct {
+	CustomerAddress string `json:"customer_address" validate:"required,max=42"`
 }
 
 //TODO支持使用邮箱登录
@@ -61,6 +86,10 @@ func Login(c *gin.Context) {
 	result, err := services.Login(req.WalletAddress, c.ClientIP())
 	if err != nil {
 		utils.Logger.WithField("error", err.Error()).Error("Login failed")
+		if status := serviceErrorStatus(err); status != http.StatusInternalServerError {
+			c.JSON(status, utils.NewErrorResponse(err))
+			return
+		}
 		c.JSON(http.StatusInternalServerError, utils.ErrorResponse(utils.ErrCodeInternalServer, "Login failed", err.Error()))
 		return
 	}
@@ -69,6 +98,9 @@ func Login(c *gin.Context) {
 		Role:            result.Role.RoleName,
 		Menus:           result.Role.Menus,
 		Token:           result.Token,
+		ExpiresAt:       result.Tokens.AccessTokenExpiresAt,
+		RefreshToken:    result.Tokens.RefreshToken,
+		RefreshExpires:  result.Tokens.RefreshTokenExpiresAt,
 	}
 	utils.Logger.WithField("customer_address", result.Customer.CustomerAddress).Info("Login successful")
 	c.JSON(http.StatusOK, utils.SuccessResponse("Login successful", resp))
@@ -76,26 +108,134 @@ func Login(c *gin.Context) {
 
 // RefreshToken godoc
 // @Summary 刷新 JWT
-// @Description 使用现有 JWT 刷新新 Token
+// @Description 使用刷新令牌换发新的访问令牌和刷新令牌，旧的刷新令牌随即失效；重复使用已换发的刷新令牌会撤销整个会话
 // @Tags auth
 // @Accept json
 // @Produce json
+// @Param refresh body RefreshRequest true "刷新令牌"
 // @Success 200 {object} utils.Response{data=RefreshResponse}
-// @Failure 401 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Failure 403 {object} utils.Response
 // @Failure 500 {object} utils.Response
-// @Security BearerAuth
 // @Router /auth/refresh [post]
 func RefreshToken(c *gin.Context) {
-	customerAddress, _ := c.Get("customer_address")
-	role, _ := c.Get("role")
+	var req RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse(utils.ErrCodeBadRequest, "Invalid request body", err.Error()))
+		return
+	}
+	if err := validator.New().Struct(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse(utils.ErrCodeValidationFailed, "Validation failed", err.Error()))
+		return
+	}
 
-	newToken, err := services.RefreshToken(customerAddress.(string), role.(string))
+	tokens, err := services.RefreshToken(req.RefreshToken, c.ClientIP())
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, utils.ErrorResponse(utils.ErrCodeInternalServer, "Failed to refresh token", err.Error()))
+		utils.Logger.Warn("Failed to refresh token", "ip", c.ClientIP(), "error", err)
+		c.JSON(serviceErrorStatus(err), utils.NewErrorResponse(err))
 		return
 	}
 	resp := RefreshResponse{
-		Token: newToken,
+		Token:          tokens.AccessToken,
+		ExpiresAt:      tokens.AccessTokenExpiresAt,
+		RefreshToken:   tokens.RefreshToken,
+		RefreshExpires: tokens.RefreshTokenExpiresAt,
 	}
 	c.JSON(http.StatusOK, utils.SuccessResponse("Token refreshed successfully", resp))
 }
+
+// Logout godoc
+// @Summary 退出登录
+// @Description 撤销当前会话，其访问令牌和刷新令牌立即失效；all 为 true 时撤销该用户的所有会话
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param logout body LogoutRequest false "退出范围"
+// @Success 200 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Security BearerAuth
+// @Router /auth/logout [post]
+func Logout(c *gin.Context) {
+	var req LogoutRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, utils.ErrorResponse(utils.ErrCodeBadRequest, "Invalid request body", err.Error()))
+			return
+		}
+	}
+	customerAddress := c.GetString("customer_address")
+	sessionID := c.GetString("session_id")
+
+	service := authtoken.NewTokenService(db.DB)
+	var err error
+	if req.All {
+		_, err = service.RevokeCustomer(c.Request.Context(), customerAddress, authtoken.RevokeReasonLogout, time.Now())
+	} else {
+		err = service.RevokeSession(c.Request.Context(), sessionID, authtoken.RevokeReasonLogout, time.Now())
+	}
+	if err != nil {
+		utils.Logger.Error("Failed to log out", "customer_address", customerAddress, "session_id", sessionID, "error", err)
+		c.JSON(serviceErrorStatus(err), utils.NewErrorResponse(err))
+		return
+	}
+	utils.Logger.Info("Logged out", "customer_address", customerAddress, "session_id", sessionID, "jti", c.GetString("jti"), "all", req.All)
+	c.JSON(http.StatusOK, utils.SuccessResponse("Logged out successfully", nil))
+}
+
+// RevokeSessions godoc
+// @Summary 撤销用户的所有会话
+// @Description 管理员撤销指定用户的所有会话，其访问令牌和刷新令牌立即失效
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param revoke body RevokeSessionsRequest true "用户地址"
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Security BearerAuth
+// @Router /auth/revoke [post]
+func RevokeSessions(c *gin.Context) {
+	admin, ok := currentAdmin(c)
+	if !ok {
+		return
+	}
+	var req RevokeSessionsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse(utils.ErrCodeBadRequest, "Invalid request body", err.Error()))
+		return
+	}
+	if err := validator.New().Struct(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse(utils.ErrCodeValidationFailed, "Validation failed", err.Error()))
+		return
+	}
+
+	revoked, err := authtoken.NewTokenService(db.DB).RevokeCustomer(c.Request.Context(), req.CustomerAddress, authtoken.RevokeReasonAdmin, time.Now())
+	if err != nil {
+		utils.Logger.Error("Failed to revoke sessions", "customer_address", req.CustomerAddress, "admin", admin, "error", err)
+		c.JSON(serviceErrorStatus(err), utils.NewErrorResponse(err))
+		return
+	}
+	utils.Logger.Info("Sessions revoked", "customer_address", req.CustomerAddress, "admin", admin, "sessions", revoked)
+	c.JSON(http.StatusOK, utils.SuccessResponse("Sessions revoked successfully", gin.H{"revoked_sessions": revoked}))
+}
+
+// GetJWKS godoc
+// @Summary 获取访问令牌的验证公钥
+// @Description 以 JWKS 格式返回 RS256/EdDSA 的验证公钥，密钥轮换期间包含旧公钥；HS256 时为空
+// @Tags auth
+// @Produce json
+// @Success 200 {object} authtoken.JWKS
+// @Failure 500 {object} utils.Response
+// @Router /.well-known/jwks.json [get]
+func GetJWKS(c *gin.Context) {
+	keys, err := authtoken.DefaultKeySet()
+	if err != nil {
+		utils.Logger.Error("JWT keys are not configured", "error", err)
+		c.JSON(http.StatusInternalServerError, utils.ErrorResponse(utils.ErrCodeInternalServer, "JWT keys are not configured", nil))
+		return
+	}
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, keys.JWKS())
+}
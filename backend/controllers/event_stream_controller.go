@@ -0,0 +1,113 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"backend/services/events"
+	"backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// eventStreamHeartbeat is the interval of the keep-alive comments sent on an idle stream
+const eventStreamHeartbeat = 15 * time.Second
+
+// EventStreamQuery defines the query parameters for subscribing to lottery events
+type EventStreamQuery struct {
+	LotteryID   string `form:"lottery_id" validate:"omitempty,max=50"`
+	IssueID     string `form:"issue_id" validate:"omitempty,max=50"`
+	Types       string `form:"types" validate:"omitempty,max=200"`
+	LastEventID uint64 `form:"last_event_id"`
+}
+
+// StreamLotteryEvents handles GET /lottery/events/v2 requests as a Server-Sent Events stream
+//
+// Query parameters:
+//   - lottery_id: Only events of this lottery (optional)
+//   - issue_id: Only events of this issue (optional)
+//   - types: Comma separated event types: issue.status, ticket.sold, winner.announced (optional, defaults to all)
+//   - last_event_id: Replay the kept events after this ID, the Last-Event-ID header takes precedence (optional)
+//
+// Every message carries the event ID, the event type as the SSE event name and the event as JSON data.
+//
+// Responses:
+//   - 200: The event stream
+//   - 400: Invalid query parameters
+func StreamLotteryEvents(c *gin.Context) {
+	var query EventStreamQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.Logger.Warn("Failed to bind query parameters", "error", err)
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(utils.NewBadRequestError("Invalid query parameters", err)))
+		return
+	}
+	if err := validator.New().Struct(&query); err != nil {
+		utils.Logger.Warn("Failed to validate query parameters", "error", err)
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(utils.NewBadRequestError("Invalid query parameters", err)))
+		return
+	}
+
+	filter := events.Filter{LotteryID: query.LotteryID, IssueID: query.IssueID}
+	if query.Types != "" {
+		filter.Types = make(map[string]bool)
+		for _, eventType := range strings.Split(query.Types, ",") {
+			eventType = strings.TrimSpace(eventType)
+			switch eventType {
+			case events.TypeIssueStatus, events.TypeTicketSold, events.TypeWinnerAnnounced:
+				filter.Types[eventType] = true
+			default:
+				c.JSON(http.StatusBadRequest, utils.NewErrorResponse(utils.NewBadRequestError("Unknown event type: "+eventType, nil)))
+				return
+			}
+		}
+	}
+	lastEventID := query.LastEventID
+	if header := c.GetHeader("Last-Event-ID"); header != "" {
+		if id, err := strconv.ParseUint(header, 10, 64); err == nil {
+			lastEventID = id
+		}
+	}
+
+	stream, unsubscribe := events.Default.Subscribe(filter, lastEventID)
+	defer unsubscribe()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(eventStreamHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(c.Writer, ": ping\n\n"); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		case event, ok := <-stream:
+			if !ok {
+				// Dropped for falling behind, the client reconnects with Last-Event-ID
+				return
+			}
+			data, err := json.Marshal(event)
+			if err != nil {
+				utils.Logger.Error("Failed to encode event", "event_id", event.ID, "error", err)
+				continue
+			}
+			if _, err := fmt.Fprintf(c.Writer, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		}
+	}
+}
@@ -16,7 +16,7 @@ import (
 // CreateIssueRequest 定义创建期号的请求结构
 type CreateIssueRequest struct {
 	LotteryID      string    `json:"lottery_id" validate:"required,max=50"`
-	IssueNumber    string    `json:"issue_number" validate:"required,max=50"`
+	IssueNumber    string    `json:"issue_number" validate:"omitempty,max=50"`
 	SaleEndTime    time.Time `json:"sale_end_time" validate:"required"`
 	DrawTime       time.Time `json:"draw_time" validate:"required,gtfield=SaleEndTime"`
 	Status         string    `json:"status" validate:"required,oneof= PENDING DRAWN"`
@@ -37,7 +37,7 @@ type CreateIssueResponse struct {
 //
 // 请求体:
 //   - lottery_id: 彩票 ID（必填，最大 50 字符）
-//   - issue_number: 期号编号（必填，最大 50 字符）
+//   - issue_number: 期号编号（选填，最大 50 字符，为空时按彩票的期号规则自动生成）
 //   - sale_end_time: 销售截止时间（必填，ISO 8601 格式）
 //   - draw_time: 开奖时间（必填，晚于 sale_end_time）
 //   - status: 状态（必填，pending 或 drawn）
@@ -46,6 +46,9 @@ type CreateIssueResponse struct {
 //   - draw_tx_hash: 开奖交易哈希（选填，最大 66 字符）
 //   - random_seed: 随机种子（选填，最大 100 字符）
 //
+// 请求头:
+//   - Idempotency-Key: 幂等键（选填，最大 100 字符），重复提交返回首次创建的期号
+//
 // 响应:
 //   - 201: 成功，返回 Response{Message, Code, Data}，Data 为 CreateIssueResponse
 //   - 400: 无效参数
@@ -79,6 +82,7 @@ func NewLotteryIssue(c *gin.Context) {
 		WinningNumbers: req.WinningNumbers,
 		RandomSeed:     req.RandomSeed,
 		DrawTxHash:     req.DrawTxHash,
+		IdempotencyKey: c.GetHeader("Idempotency-Key"),
 	})
 	if err != nil {
 		utils.Logger.Error("Failed to create issue", "error", err)
@@ -89,7 +93,7 @@ func NewLotteryIssue(c *gin.Context) {
 	// 记录日志
 	utils.Logger.Info("Successfully created issue",
 		"lottery_id", req.LotteryID,
-		"issue_number", req.IssueNumber,
+		"issue_number", issue.IssueNumber,
 		"status", req.Status,
 		"issue_id", issue.IssueID,
 		"txhash", txhash.Hex())
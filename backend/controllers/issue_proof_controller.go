@@ -0,0 +1,31 @@
+package controllers
+
+import (
+	"net/http"
+
+	"backend/db"
+	drawProofService "backend/services/lottery"
+	"backend/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetIssueDrawProof handles GET /lottery/issues/v2/:issue_id/proof requests
+//
+// The proof holds the VRF request ID, the fulfilled random words, the request and fulfilment
+// block hashes and the word % 36 + 1 mapping, and is re-verified against the issue's winning numbers.
+//
+// Responses:
+//   - 200: Success, returns DrawProofResult
+//   - 400: Issue not found, not drawn yet or without a recorded proof
+//   - 500: Server error
+func GetIssueDrawProof(c *gin.Context) {
+	service := drawProofService.NewDrawProofService(db.DB)
+	proof, err := service.GetDrawProof(c.Request.Context(), c.Param("issue_id"))
+	if err != nil {
+		utils.Logger.Warn("Failed to get draw proof", "issue_id", c.Param("issue_id"), "error", err)
+		c.JSON(serviceErrorStatus(err), utils.NewErrorResponse(err))
+		return
+	}
+	c.JSON(http.StatusOK, utils.SuccessResponse("Draw proof retrieved successfully", proof))
+}
@@ -0,0 +1,108 @@
+package controllers
+
+import (
+	"net/http"
+
+	"backend/db"
+	issueScheduleService "backend/services/issue"
+	"backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// SaveIssueScheduleRequest 定义创建或更新期号计划的请求结构
+type SaveIssueScheduleRequest struct {
+	LotteryID        string `json:"lottery_id" validate:"required,max=50"`
+	Frequency        string `json:"frequency" validate:"required,oneof=DAILY WEEKLY CRON"`
+	CronExpr         string `json:"cron_expr" validate:"omitempty,max=100"`
+	DrawAt           string `json:"draw_at" validate:"omitempty,len=5"`
+	Weekday          int    `json:"weekday" validate:"gte=0,lte=6"`
+	TimeZone         string `json:"time_zone" validate:"required,max=50"`
+	SaleCloseMinutes int    `json:"sale_close_minutes" validate:"gte=0"`
+	Enabled          *bool  `json:"enabled"`
+}
+
+// SaveIssueSchedule 处理 POST /lottery/schedules/v2 请求
+//
+// 请求体:
+//   - lottery_id: 彩票 ID（必填）
+//   - frequency: 频率（必填，DAILY、WEEKLY 或 CRON）
+//   - cron_expr: 五段 cron 表达式（frequency 为 CRON 时必填）
+//   - draw_at: 开奖时刻 HH:mm（DAILY、WEEKLY 必填）
+//   - weekday: 开奖日，0 表示周日（WEEKLY 使用）
+//   - time_zone: IANA 时区（必填，如 Asia/Shanghai）
+//   - sale_close_minutes: 开奖前多少分钟停止销售（选填）
+//   - enabled: 是否启用（选填，默认 true）
+//
+// 响应:
+//   - 200: 成功，返回保存后的计划
+//   - 400: 无效参数
+//   - 403: 调用方不是管理员
+//   - 500: 服务器错误
+func SaveIssueSchedule(c *gin.Context) {
+	if _, ok := currentAdmin(c); !ok {
+		return
+	}
+	var req SaveIssueScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Logger.Warn("Failed to bind request body", "error", err)
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(utils.NewBadRequestError("Invalid request body", err)))
+		return
+	}
+	validate := validator.New()
+	if err := validate.Struct(&req); err != nil {
+		utils.Logger.Warn("Failed to validate request parameters", "error", err)
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(utils.NewBadRequestError("Parameter validation failed", err)))
+		return
+	}
+
+	params := issueScheduleService.SaveIssueScheduleParams{
+		LotteryID:        req.LotteryID,
+		Frequency:        req.Frequency,
+		CronExpr:         req.CronExpr,
+		DrawAt:           req.DrawAt,
+		Weekday:          req.Weekday,
+		TimeZone:         req.TimeZone,
+		SaleCloseMinutes: req.SaleCloseMinutes,
+		Enabled:          req.Enabled == nil || *req.Enabled,
+	}
+	service := issueScheduleService.NewIssueScheduleService(db.DB)
+	schedule, err := service.SaveSchedule(c.Request.Context(), params)
+	if err != nil {
+		utils.Logger.Error("Failed to save issue schedule", "lottery_id", req.LotteryID, "error", err)
+		c.JSON(serviceErrorStatus(err), utils.NewErrorResponse(err))
+		return
+	}
+	c.JSON(http.StatusOK, utils.SuccessResponse("Issue schedule saved", schedule))
+}
+
+// ListIssueSchedules 处理 GET /lottery/schedules/v2 请求，可按 lottery_id 过滤，仅管理员可用
+func ListIssueSchedules(c *gin.Context) {
+	if _, ok := currentAdmin(c); !ok {
+		return
+	}
+	service := issueScheduleService.NewIssueScheduleService(db.DB)
+	schedules, err := service.ListSchedules(c.Request.Context(), c.Query("lottery_id"))
+	if err != nil {
+		utils.Logger.Error("Failed to list issue schedules", "error", err)
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(err))
+		return
+	}
+	c.JSON(http.StatusOK, utils.SuccessResponse("get issue schedules success", schedules))
+}
+
+// DisableIssueSchedule 处理 DELETE /lottery/schedules/v2/:schedule_id 请求，停用计划，仅管理员可用
+func DisableIssueSchedule(c *gin.Context) {
+	if _, ok := currentAdmin(c); !ok {
+		return
+	}
+	service := issueScheduleService.NewIssueScheduleService(db.DB)
+	schedule, err := service.DisableSchedule(c.Request.Context(), c.Param("schedule_id"))
+	if err != nil {
+		utils.Logger.Error("Failed to disable issue schedule", "schedule_id", c.Param("schedule_id"), "error", err)
+		c.JSON(serviceErrorStatus(err), utils.NewErrorResponse(err))
+		return
+	}
+	c.JSON(http.StatusOK, utils.SuccessResponse("Issue schedule disabled", schedule))
+}
@@ -0,0 +1,131 @@
+package controllers
+
+import (
+	"net/http"
+
+	"backend/db"
+	documentService "backend/services/document"
+	"backend/services/kyc"
+	"backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// DocumentContentQuery defines the query parameters of a signed document URL
+type DocumentContentQuery struct {
+	Key      string `form:"key" validate:"required,max=255"`
+	Accessor string `form:"accessor" validate:"max=255"`
+	Expires  string `form:"expires" validate:"required,numeric"`
+	Sig      string `form:"sig" validate:"required,hexadecimal,len=64"`
+}
+
+// DocumentAccessLogsQuery defines the query parameters for the document access log
+type DocumentAccessLogsQuery struct {
+	CustomerAddress string `form:"customer_address" validate:"omitempty,max=255"`
+	Limit           int    `form:"limit" validate:"omitempty,min=1,max=500"`
+}
+
+// GetCustomerDocumentURL handles GET /customers/:customer_address/document-url requests
+//
+// Only the customer and verifiers get a URL; every request, granted or denied, is audited.
+//
+// Responses:
+//   - 200: Success, returns a short-lived URL to the customer's KYC document
+//   - 400: The customer has no KYC document
+//   - 403: Missing token, or not the owner nor a verifier
+//   - 500: Server error
+func GetCustomerDocumentURL(c *gin.Context) {
+	address, _ := c.Get("customer_address")
+	accessorAddress, ok := address.(string)
+	if !ok || accessorAddress == "" {
+		c.JSON(http.StatusForbidden, utils.ErrorResponse(utils.ErrCodeForbidden, "Token has no customer address", nil))
+		return
+	}
+	role, _ := c.Get("role")
+	roleName, _ := role.(string)
+
+	service, err := documentService.NewDocumentService(db.DB)
+	if err != nil {
+		utils.Logger.Error("Document storage is not configured", "error", err)
+		c.JSON(http.StatusInternalServerError, utils.ErrorResponse(utils.ErrCodeInternalServer, "Document storage is not configured", nil))
+		return
+	}
+	customerAddress := c.Param("customer_address")
+	signed, err := service.IssueURL(c.Request.Context(), customerAddress, documentService.Accessor{
+		Address:   accessorAddress,
+		Role:      roleName,
+		Verifier:  kyc.IsVerifier(roleName),
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+	if err != nil {
+		utils.Logger.Warn("Failed to issue document URL", "customer_address", customerAddress, "accessor", accessorAddress, "error", err)
+		c.JSON(serviceErrorStatus(err), utils.NewErrorResponse(err))
+		return
+	}
+	c.JSON(http.StatusOK, utils.SuccessResponse("Document URL issued", signed))
+}
+
+// GetDocumentContent handles GET /documents/v2/content requests
+//
+// Serves a document of the encrypted local store; the signature in the URL is the credential.
+func GetDocumentContent(c *gin.Context) {
+	var query DocumentContentQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.Logger.Warn("Failed to bind query parameters", "error", err)
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(utils.NewBadRequestError("Invalid query parameters", err)))
+		return
+	}
+	if err := validator.New().Struct(&query); err != nil {
+		utils.Logger.Warn("Failed to validate query parameters", "error", err)
+		c.JSON(http.StatusForbidden, utils.NewErrorResponse(utils.NewForbiddenError("Invalid document URL", err)))
+		return
+	}
+
+	service, err := documentService.NewDocumentService(db.DB)
+	if err != nil {
+		utils.Logger.Error("Document storage is not configured", "error", err)
+		c.JSON(http.StatusInternalServerError, utils.ErrorResponse(utils.ErrCodeInternalServer, "Document storage is not configured", nil))
+		return
+	}
+	content, err := service.OpenSignedContent(c.Request.Context(), query.Key, query.Accessor, query.Expires, query.Sig, documentService.Accessor{
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+	if err != nil {
+		utils.Logger.Warn("Failed to serve document", "document_key", query.Key, "accessor", query.Accessor, "error", err)
+		c.JSON(serviceErrorStatus(err), utils.NewErrorResponse(err))
+		return
+	}
+	c.Header("Cache-Control", "private, no-store")
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Data(http.StatusOK, content.ContentType, content.Data)
+}
+
+// ListDocumentAccessLogs handles GET /documents/v2/access-logs requests
+func ListDocumentAccessLogs(c *gin.Context) {
+	if _, ok := currentAdmin(c); !ok {
+		return
+	}
+	var query DocumentAccessLogsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.Logger.Warn("Failed to bind query parameters", "error", err)
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(utils.NewBadRequestError("Invalid query parameters", err)))
+		return
+	}
+	if err := validator.New().Struct(&query); err != nil {
+		utils.Logger.Warn("Failed to validate query parameters", "error", err)
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(utils.NewBadRequestError("Invalid query parameters", err)))
+		return
+	}
+
+	service := documentService.NewDocumentServiceWithStore(db.DB, nil, nil)
+	logs, err := service.ListAccessLogs(c.Request.Context(), query.CustomerAddress, query.Limit)
+	if err != nil {
+		utils.Logger.Error("Failed to list document access logs", "error", err)
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(err))
+		return
+	}
+	c.JSON(http.StatusOK, utils.SuccessResponse("Document access logs retrieved successfully", logs))
+}
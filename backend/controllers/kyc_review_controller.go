@@ -0,0 +1,317 @@
+package controllers
+
+import (
+	"net/http"
+	"time"
+
+	"backend/db"
+	"backend/models"
+	"backend/services/kyc"
+	"backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// KYCReviewQuery defines the query parameters of the review queue
+type KYCReviewQuery struct {
+	Status     string `form:"status" validate:"omitempty,oneof=PENDING IN_REVIEW INFO_REQUESTED AWAITING_SECOND_APPROVAL APPROVED REJECTED EXPIRED"`
+	AssignedTo string `form:"assigned_to" validate:"omitempty,max=255"`
+	RiskLevel  string `form:"risk_level" validate:"omitempty,max=20"`
+	Page       int    `form:"page" validate:"omitempty,min=1"`
+	PageSize   int    `form:"page_size" validate:"omitempty,min=1,max=100"`
+}
+
+// AssignKYCReviewRequest defines the request structure for assigning a review
+type AssignKYCReviewRequest struct {
+	VerifierAddress string `json:"verifier_address" validate:"omitempty,max=255"`
+}
+
+// SetKYCRiskLevelRequest defines the request structure for a verifier's risk assessment
+type SetKYCRiskLevelRequest struct {
+	RiskLevel string `json:"risk_level" validate:"required,oneof=Low Medium High"`
+	Comments  string `json:"comments" validate:"max=2000"`
+}
+
+// KYCReviewDecisionRequest defines the request structure for approving, rejecting or sending back a review
+type KYCReviewDecisionRequest struct {
+	Comments string `json:"comments" validate:"max=2000"`
+}
+
+// ResubmitKYCRequest defines the KYC data a customer changes when resubmitting, omitted fields keep their value
+type ResubmitKYCRequest struct {
+	Name               string `json:"name" validate:"omitempty,max=100"`
+	ResidentialAddress string `json:"residential_address" validate:"omitempty,max=1000"`
+	PhoneNumber        string `json:"phone_number" validate:"omitempty,max=20"`
+	Email              string `json:"email" validate:"omitempty,email,max=255"`
+	DocumentType       string `json:"document_type" validate:"omitempty,max=50"`
+	DocumentNumber     string `json:"document_number" validate:"omitempty,max=50"`
+	FilePath           string `json:"file_path" validate:"omitempty,max=255"`
+	SourceOfFunds      string `json:"source_of_funds" validate:"omitempty,max=1000"`
+	Occupation         string `json:"occupation" validate:"omitempty,max=100"`
+	TaxID              string `json:"tax_id" validate:"omitempty,max=50"`
+	DocumentExpiryDate string `json:"document_expiry_date" validate:"omitempty,datetime=2006-01-02"`
+	Comments           string `json:"comments" validate:"omitempty,max=2000"`
+}
+
+// currentVerifier returns the caller's address when the caller holds a verifier role,
+// otherwise it writes a 403 response
+func currentVerifier(c *gin.Context) (string, bool) {
+	role, _ := c.Get("role")
+	roleName, _ := role.(string)
+	address, _ := c.Get("customer_address")
+	verifier, _ := address.(string)
+	if !kyc.IsVerifier(roleName) || verifier == "" {
+		c.JSON(http.StatusForbidden, utils.ErrorResponse(utils.ErrCodeForbidden, "Insufficient permissions", nil))
+		return "", false
+	}
+	return verifier, true
+}
+
+// ListKYCReviews handles GET /kyc/reviews requests
+//
+// Query parameters:
+//   - status: PENDING, IN_REVIEW, INFO_REQUESTED, AWAITING_SECOND_APPROVAL, APPROVED, REJECTED or EXPIRED (optional)
+//   - assigned_to: Verifier address, "me" for the caller or "none" for unassigned reviews (optional)
+//   - risk_level: e.g. High (optional)
+//   - page: Page number, default 1 (optional)
+//   - page_size: Records per page, default 20, max 100 (optional)
+//
+// Responses:
+//   - 200: Success, returns the reviews, oldest submission first
+//   - 400: Invalid query parameters
+//   - 403: Not a verifier
+//   - 500: Server error
+func ListKYCReviews(c *gin.Context) {
+	verifier, ok := currentVerifier(c)
+	if !ok {
+		return
+	}
+	var query KYCReviewQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.Logger.Warn("Failed to bind query parameters", "error", err)
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(utils.NewBadRequestError("Invalid query parameters", err)))
+		return
+	}
+	if err := validator.New().Struct(&query); err != nil {
+		utils.Logger.Warn("Failed to validate query parameters", "error", err)
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(utils.NewBadRequestError("Invalid query parameters", err)))
+		return
+	}
+	if query.AssignedTo == "me" {
+		query.AssignedTo = verifier
+	}
+
+	service := kyc.NewKYCReviewService(db.DB)
+	result, err := service.List(c.Request.Context(), kyc.ReviewFilter{
+		Status:     query.Status,
+		AssignedTo: query.AssignedTo,
+		RiskLevel:  query.RiskLevel,
+		Page:       query.Page,
+		PageSize:   query.PageSize,
+	})
+	if err != nil {
+		utils.Logger.Error("Failed to list KYC reviews", "error", err)
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(err))
+		return
+	}
+	c.JSON(http.StatusOK, utils.SuccessResponse("KYC reviews retrieved successfully", result))
+}
+
+// GetKYCReview handles GET /kyc/reviews/:customer_address requests, returning the review,
+// the submitted KYC data and every step of the verification history
+func GetKYCReview(c *gin.Context) {
+	if _, ok := currentVerifier(c); !ok {
+		return
+	}
+	service := kyc.NewKYCReviewService(db.DB)
+	detail, err := service.Get(c.Request.Context(), c.Param("customer_address"))
+	if err != nil {
+		utils.Logger.Warn("Failed to fetch KYC review", "customer_address", c.Param("customer_address"), "error", err)
+		c.JSON(serviceErrorStatus(err), utils.NewErrorResponse(err))
+		return
+	}
+	c.JSON(http.StatusOK, utils.SuccessResponse("KYC review retrieved successfully", detail))
+}
+
+// AssignKYCReview handles POST /kyc/reviews/:customer_address/assign requests
+//
+// Request body:
+//   - verifier_address: Verifier to assign, defaults to the caller (optional)
+func AssignKYCReview(c *gin.Context) {
+	verifier, ok := currentVerifier(c)
+	if !ok {
+		return
+	}
+	var req AssignKYCReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil && c.Request.ContentLength > 0 {
+		utils.Logger.Warn("Failed to bind request body", "error", err)
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(utils.NewBadRequestError("Invalid request body", err)))
+		return
+	}
+	if err := validator.New().Struct(&req); err != nil {
+		utils.Logger.Warn("Failed to validate request parameters", "error", err)
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(utils.NewBadRequestError("Parameter validation failed", err)))
+		return
+	}
+
+	service := kyc.NewKYCReviewService(db.DB)
+	review, err := service.Assign(c.Request.Context(), c.Param("customer_address"), verifier, req.VerifierAddress)
+	if err != nil {
+		utils.Logger.Warn("Failed to assign KYC review", "customer_address", c.Param("customer_address"), "verifier", verifier, "error", err)
+		c.JSON(serviceErrorStatus(err), utils.NewErrorResponse(err))
+		return
+	}
+	c.JSON(http.StatusOK, utils.SuccessResponse("KYC review assigned", review))
+}
+
+// SetKYCRiskLevel handles POST /kyc/reviews/:customer_address/risk-level requests
+//
+// Submissions start as High; a verifier must set the risk level before the review can be approved.
+//
+// Request body:
+//   - risk_level: Low, Medium or High (required)
+//   - comments: Reason for the assessment (optional)
+func SetKYCRiskLevel(c *gin.Context) {
+	verifier, ok := currentVerifier(c)
+	if !ok {
+		return
+	}
+	var req SetKYCRiskLevelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Logger.Warn("Failed to bind request body", "error", err)
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(utils.NewBadRequestError("Invalid request body", err)))
+		return
+	}
+	if err := validator.New().Struct(&req); err != nil {
+		utils.Logger.Warn("Failed to validate request parameters", "error", err)
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(utils.NewBadRequestError("Parameter validation failed", err)))
+		return
+	}
+
+	service := kyc.NewKYCReviewService(db.DB)
+	review, err := service.SetRiskLevel(c.Request.Context(), c.Param("customer_address"), verifier, req.RiskLevel, req.Comments)
+	if err != nil {
+		utils.Logger.Warn("Failed to set KYC risk level", "customer_address", c.Param("customer_address"), "verifier", verifier, "error", err)
+		c.JSON(serviceErrorStatus(err), utils.NewErrorResponse(err))
+		return
+	}
+	c.JSON(http.StatusOK, utils.SuccessResponse("KYC risk level set", review))
+}
+
+// RequestKYCInfo handles POST /kyc/reviews/:customer_address/request-info requests
+//
+// Sends the review back to the customer; the comments, which are required, tell them what is missing.
+func RequestKYCInfo(c *gin.Context) {
+	decideKYCReview(c, kyc.ActionRequestInfo, true, "More information requested")
+}
+
+// ApproveKYCReview handles POST /kyc/reviews/:customer_address/approve requests
+//
+// The risk level must have been set first. High risk submissions stay in AWAITING_SECOND_APPROVAL
+// until a second, different verifier approves.
+func ApproveKYCReview(c *gin.Context) {
+	decideKYCReview(c, kyc.ActionApprove, false, "KYC review approved")
+}
+
+// RejectKYCReview handles POST /kyc/reviews/:customer_address/reject requests, the comments are required
+func RejectKYCReview(c *gin.Context) {
+	decideKYCReview(c, kyc.ActionReject, true, "KYC review rejected")
+}
+
+// decideKYCReview runs a verifier's decision on a review
+func decideKYCReview(c *gin.Context, action string, commentsRequired bool, message string) {
+	verifier, ok := currentVerifier(c)
+	if !ok {
+		return
+	}
+	var req KYCReviewDecisionRequest
+	if err := c.ShouldBindJSON(&req); err != nil && c.Request.ContentLength > 0 {
+		utils.Logger.Warn("Failed to bind request body", "error", err)
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(utils.NewBadRequestError("Invalid request body", err)))
+		return
+	}
+	if err := validator.New().Struct(&req); err != nil {
+		utils.Logger.Warn("Failed to validate request parameters", "error", err)
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(utils.NewBadRequestError("Parameter validation failed", err)))
+		return
+	}
+	if commentsRequired && req.Comments == "" {
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(utils.NewBadRequestError("Comments are required", nil)))
+		return
+	}
+
+	service := kyc.NewKYCReviewService(db.DB)
+	ctx := c.Request.Context()
+	address := c.Param("customer_address")
+	var err error
+	var review *models.KYCReview
+	switch action {
+	case kyc.ActionRequestInfo:
+		review, err = service.RequestInfo(ctx, address, verifier, req.Comments)
+	case kyc.ActionApprove:
+		review, err = service.Approve(ctx, address, verifier, req.Comments)
+	case kyc.ActionReject:
+		review, err = service.Reject(ctx, address, verifier, req.Comments)
+	}
+	if err != nil {
+		utils.Logger.Warn("Failed to update KYC review", "customer_address", address, "action", action, "verifier", verifier, "error", err)
+		c.JSON(serviceErrorStatus(err), utils.NewErrorResponse(err))
+		return
+	}
+	if review.Status == models.KYCReviewStatusAwaitingSecondApproval {
+		message = "First approval recorded, a second verifier must approve"
+	}
+	c.JSON(http.StatusOK, utils.SuccessResponse(message, review))
+}
+
+// ResubmitMyKYC handles PUT /me/kyc requests
+//
+// The caller updates their KYC data after more information was requested, after a rejection
+// or once their verification expired, which puts the review back in the queue.
+func ResubmitMyKYC(c *gin.Context) {
+	address, _ := c.Get("customer_address")
+	customerAddress, ok := address.(string)
+	if !ok || customerAddress == "" {
+		c.JSON(http.StatusForbidden, utils.ErrorResponse(utils.ErrCodeForbidden, "Token has no customer address", nil))
+		return
+	}
+	var req ResubmitKYCRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Logger.Warn("Failed to bind request body", "error", err)
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(utils.NewBadRequestError("Invalid request body", err)))
+		return
+	}
+	if err := validator.New().Struct(&req); err != nil {
+		utils.Logger.Warn("Failed to validate request parameters", "error", err)
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(utils.NewBadRequestError("Parameter validation failed", err)))
+		return
+	}
+	var documentExpiry *time.Time
+	if req.DocumentExpiryDate != "" {
+		date, _ := time.Parse("2006-01-02", req.DocumentExpiryDate)
+		documentExpiry = &date
+	}
+
+	service := kyc.NewKYCReviewService(db.DB)
+	review, err := service.Resubmit(c.Request.Context(), customerAddress, kyc.ResubmitParams{
+		Name:               req.Name,
+		ResidentialAddress: req.ResidentialAddress,
+		PhoneNumber:        req.PhoneNumber,
+		Email:              req.Email,
+		DocumentType:       req.DocumentType,
+		DocumentNumber:     req.DocumentNumber,
+		FilePath:           req.FilePath,
+		SourceOfFunds:      req.SourceOfFunds,
+		Occupation:         req.Occupation,
+		TaxID:              req.TaxID,
+		DocumentExpiryDate: documentExpiry,
+		Comments:           req.Comments,
+	})
+	if err != nil {
+		utils.Logger.Warn("Failed to resubmit KYC data", "customer_address", customerAddress, "error", err)
+		c.JSON(serviceErrorStatus(err), utils.NewErrorResponse(err))
+		return
+	}
+	c.JSON(http.StatusOK, utils.SuccessResponse("KYC data resubmitted for review", review))
+}
@@ -15,14 +15,18 @@ import (
 
 // CreateLotteryRequest defines the request structure for creating a lottery
 type CreateLotteryRequest struct {
-	TypeID                 string  `json:"type_id" validate:"required,max=36"`
-	TicketName             string  `json:"ticket_name" validate:"required,max=100"`
-	TicketSupply           int64   `json:"ticket_supply" validate:"required,gt=0"`
-	TicketPrice            float64 `json:"ticket_price" validate:"required,gt=0"`
-	BettingRules           string  `json:"betting_rules" validate:"required"`
-	PrizeStructure         string  `json:"prize_structure" validate:"required"`
-	RegisteredAddr         string  `json:"registered_addr" validate:"required,len=42,eth_addr"`
-	RolloutContractAddress string  `json:"rollout_contract_address" validate:"required,len=42,eth_addr"`
+	TypeID                 string   `json:"type_id" validate:"required,max=36"`
+	TicketName             string   `json:"ticket_name" validate:"required,max=100"`
+	TicketSupply           int64    `json:"ticket_supply" validate:"required,gt=0"`
+	TicketPrice            float64  `json:"ticket_price" validate:"required,gt=0"`
+	BettingRules           string   `json:"betting_rules" validate:"required"`
+	PrizeStructure         string   `json:"prize_structure" validate:"required"`
+	RegisteredAddr         string   `json:"registered_addr" validate:"required,len=42,eth_addr"`
+	RolloutContractAddress string   `json:"rollout_contract_address" validate:"required,len=42,eth_addr"`
+	IssueNumberPattern     string   `json:"issue_number_pattern" validate:"omitempty,max=100"`
+	RolloverPolicy         string   `json:"rollover_policy" validate:"omitempty,oneof=ROLLOVER RETURN_TO_OWNER SPLIT_LOWER_TIERS"`
+	AllowedCountries       []string `json:"allowed_countries" validate:"omitempty,max=250,dive,len=2,alpha"` // ISO 3166-1 alpha-2, empty for everywhere
+	BlockedCountries       []string `json:"blocked_countries" validate:"omitempty,max=250,dive,len=2,alpha"`
 }
 
 // CreateLotteryResponse defines the response structure, including the lottery and transaction hash
@@ -41,6 +45,8 @@ type CreateLotteryResponse struct {
 //   - ticket_price: Ticket price (required, positive float)
 //   - registered_addr: Owner Ethereum address (required, 42-character hex)
 //   - rollout_contract_address: Rollout contract address (required, 42-character hex)
+//   - issue_number_pattern: Issue number pattern such as {yyyyMMdd}-{seq} (optional, defaults to ISSUE_NUMBER_PATTERN)
+//   - rollover_policy: What happens to the pool when nobody wins, ROLLOVER, RETURN_TO_OWNER or SPLIT_LOWER_TIERS (optional, defaults to ROLLOVER)
 //
 // Responses:
 //   - 201: Success, returns Response{Message, Code, Data}, Data is CreateLotteryResponse
@@ -86,6 +92,10 @@ func NewLottery(c *gin.Context) {
 		PrizeStructure:         req.PrizeStructure,
 		RegisteredAddr:         req.RegisteredAddr,
 		RolloutContractAddress: req.RolloutContractAddress,
+		IssueNumberPattern:     req.IssueNumberPattern,
+		RolloverPolicy:         req.RolloverPolicy,
+		AllowedCountries:       req.AllowedCountries,
+		BlockedCountries:       req.BlockedCountries,
 	})
 	if err != nil {
 		utils.Logger.Error("Failed to create lottery", "error", err)
@@ -0,0 +1,53 @@
+package controllers
+
+import (
+	"net/http"
+
+	"backend/db"
+	jurisdictionService "backend/services/lottery"
+	"backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// SetJurisdictionsRequest defines the request structure for changing a lottery's country restrictions
+type SetJurisdictionsRequest struct {
+	AllowedCountries []string `json:"allowed_countries" validate:"max=250,dive,len=2,alpha"`
+	BlockedCountries []string `json:"blocked_countries" validate:"max=250,dive,len=2,alpha"`
+}
+
+// SetJurisdictions handles POST /lottery/lottery/v2/:lottery_id/jurisdictions requests
+//
+// Replaces both lists; ISO 3166-1 alpha-2 codes, an empty allowed list meaning every country not blocked.
+//
+// Responses:
+//   - 200: Success, purchases and logins are checked against the new lists
+//   - 400: Lottery not found, invalid code or a country both allowed and blocked
+//   - 403: Caller is not an administrator
+//   - 500: Server error
+func SetJurisdictions(c *gin.Context) {
+	if _, ok := currentAdmin(c); !ok {
+		return
+	}
+	var req SetJurisdictionsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Logger.Warn("Failed to bind request body", "error", err)
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(utils.NewBadRequestError("Invalid request body", err)))
+		return
+	}
+	if err := validator.New().Struct(&req); err != nil {
+		utils.Logger.Warn("Failed to validate request parameters", "error", err)
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(utils.NewBadRequestError("Parameter validation failed", err)))
+		return
+	}
+
+	service := jurisdictionService.NewJurisdictionService(db.DB)
+	lottery, err := service.SetJurisdictions(c.Request.Context(), c.Param("lottery_id"), req.AllowedCountries, req.BlockedCountries)
+	if err != nil {
+		utils.Logger.Error("Failed to set country restrictions", "lottery_id", c.Param("lottery_id"), "error", err)
+		c.JSON(serviceErrorStatus(err), utils.NewErrorResponse(err))
+		return
+	}
+	c.JSON(http.StatusOK, utils.SuccessResponse("Country restrictions updated", LotteryLifecycleResponse{Lottery: *lottery}))
+}
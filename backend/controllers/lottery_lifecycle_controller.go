@@ -0,0 +1,123 @@
+package controllers
+
+import (
+	"net/http"
+
+	"backend/db"
+	"backend/models"
+	lotteryLifecycleService "backend/services/lottery"
+	"backend/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LotteryLifecycleResponse defines the response structure for lifecycle operations
+type LotteryLifecycleResponse struct {
+	Lottery models.Lottery `json:"lottery"`
+	TxHash  string         `json:"tx_hash,omitempty"`
+}
+
+// PauseLottery handles POST /lottery/lottery/v2/:lottery_id/pause requests
+//
+// Responses:
+//   - 200: Success, ticket sales and issue creation are stopped
+//   - 400: Lottery not found or not ACTIVE
+//   - 403: Caller is not an administrator
+//   - 500: Server error
+func PauseLottery(c *gin.Context) {
+	if _, ok := currentAdmin(c); !ok {
+		return
+	}
+	service := lotteryLifecycleService.NewLotteryLifecycleService(db.DB)
+	lottery, err := service.PauseLottery(c.Request.Context(), c.Param("lottery_id"))
+	if err != nil {
+		utils.Logger.Error("Failed to pause lottery", "lottery_id", c.Param("lottery_id"), "error", err)
+		c.JSON(serviceErrorStatus(err), utils.NewErrorResponse(err))
+		return
+	}
+	c.JSON(http.StatusOK, utils.SuccessResponse("Lottery paused", LotteryLifecycleResponse{Lottery: *lottery}))
+}
+
+// ResumeLottery handles POST /lottery/lottery/v2/:lottery_id/resume requests
+//
+// Responses:
+//   - 200: Success, ticket sales are reopened
+//   - 400: Lottery not found or not PAUSED
+//   - 403: Caller is not an administrator
+//   - 500: Server error
+func ResumeLottery(c *gin.Context) {
+	if _, ok := currentAdmin(c); !ok {
+		return
+	}
+	service := lotteryLifecycleService.NewLotteryLifecycleService(db.DB)
+	lottery, err := service.ResumeLottery(c.Request.Context(), c.Param("lottery_id"))
+	if err != nil {
+		utils.Logger.Error("Failed to resume lottery", "lottery_id", c.Param("lottery_id"), "error", err)
+		c.JSON(serviceErrorStatus(err), utils.NewErrorResponse(err))
+		return
+	}
+	c.JSON(http.StatusOK, utils.SuccessResponse("Lottery resumed", LotteryLifecycleResponse{Lottery: *lottery}))
+}
+
+// TerminateLottery handles POST /lottery/lottery/v2/:lottery_id/terminate requests
+//
+// Responses:
+//   - 200: Success, the contract moved from Ready to Terminal
+//   - 400: Lottery not found, already retired, has open issues or contract is not Ready
+//   - 403: Caller is not an administrator
+//   - 500: Server error
+func TerminateLottery(c *gin.Context) {
+	if _, ok := currentAdmin(c); !ok {
+		return
+	}
+	service := lotteryLifecycleService.NewLotteryLifecycleService(db.DB)
+	lottery, txHash, err := service.TerminateLottery(c.Request.Context(), c.Param("lottery_id"))
+	if err != nil {
+		utils.Logger.Error("Failed to terminate lottery", "lottery_id", c.Param("lottery_id"), "error", err)
+		c.JSON(serviceErrorStatus(err), utils.NewErrorResponse(err))
+		return
+	}
+	c.JSON(http.StatusOK, utils.SuccessResponse("Lottery terminated", LotteryLifecycleResponse{Lottery: *lottery, TxHash: txHash.Hex()}))
+}
+
+// DestroyLottery handles POST /lottery/lottery/v2/:lottery_id/destroy requests
+//
+// Responses:
+//   - 200: Success, the contract is destroyed
+//   - 400: Lottery not found, already destroyed, has open issues or contract is not Ready/Terminal
+//   - 403: Caller is not an administrator
+//   - 500: Server error
+func DestroyLottery(c *gin.Context) {
+	if _, ok := currentAdmin(c); !ok {
+		return
+	}
+	service := lotteryLifecycleService.NewLotteryLifecycleService(db.DB)
+	lottery, txHash, err := service.DestroyLottery(c.Request.Context(), c.Param("lottery_id"))
+	if err != nil {
+		utils.Logger.Error("Failed to destroy lottery", "lottery_id", c.Param("lottery_id"), "error", err)
+		c.JSON(serviceErrorStatus(err), utils.NewErrorResponse(err))
+		return
+	}
+	c.JSON(http.StatusOK, utils.SuccessResponse("Lottery destroyed", LotteryLifecycleResponse{Lottery: *lottery, TxHash: txHash.Hex()}))
+}
+
+// serviceErrorStatus maps service errors to HTTP status codes
+func serviceErrorStatus(err error) int {
+	if customErr, ok := err.(*utils.Error); ok && (customErr.Code == http.StatusBadRequest || customErr.Code == http.StatusForbidden) {
+		return customErr.Code
+	}
+	return http.StatusInternalServerError
+}
+
+// currentAdmin returns the caller's address when the caller is an administrator, otherwise it writes a 403 response
+func currentAdmin(c *gin.Context) (string, bool) {
+	role, _ := c.Get("role")
+	roleName, _ := role.(string)
+	address, _ := c.Get("customer_address")
+	admin, _ := address.(string)
+	if roleName != "admin" || admin == "" {
+		c.JSON(http.StatusForbidden, utils.ErrorResponse(utils.ErrCodeForbidden, "Insufficient permissions", nil))
+		return "", false
+	}
+	return admin, true
+}
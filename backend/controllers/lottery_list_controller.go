@@ -20,8 +20,10 @@ type GetAllLotteryResponse struct {
 
 // GetAllLotteryQuery 定义查询彩票的请求结构
 type GetAllLotteryQuery struct {
-	TypeID     string `form:"type_id" validate:"omitempty,max=50"`
-	TicketName string `form:"ticket_name" validate:"omitempty,max=255"`
+	TypeID         string `form:"type_id" validate:"omitempty,max=50"`
+	TicketName     string `form:"ticket_name" validate:"omitempty,max=255"`
+	Status         string `form:"status" validate:"omitempty,oneof=ACTIVE PAUSED TERMINATED DESTROYED"`
+	IncludeRetired bool   `form:"include_retired"`
 }
 
 // GetAllLottery 处理 GET /lottery/lottery 请求
@@ -47,8 +49,10 @@ func ListAllLotteries(c *gin.Context) {
 	// 调用 service 层
 	lListService := lotteryListService.NewLotteryListService(db.DB)
 	result, err := lListService.GetAllLotteries(c.Request.Context(), lotteryListService.LotteryQueryParams{
-		TypeID:     query.TypeID,
-		TicketName: query.TicketName,
+		TypeID:         query.TypeID,
+		TicketName:     query.TicketName,
+		Status:         query.Status,
+		IncludeRetired: query.IncludeRetired,
 	})
 	if err != nil {
 		utils.Logger.Error("get lottery list failed", "error", err)
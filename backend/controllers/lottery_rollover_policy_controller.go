@@ -0,0 +1,50 @@
+package controllers
+
+import (
+	"net/http"
+
+	"backend/db"
+	rolloverPolicyService "backend/services/lottery"
+	"backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// SetRolloverPolicyRequest defines the request structure for changing the rollover policy
+type SetRolloverPolicyRequest struct {
+	RolloverPolicy string `json:"rollover_policy" validate:"required,oneof=ROLLOVER RETURN_TO_OWNER SPLIT_LOWER_TIERS"`
+}
+
+// SetRolloverPolicy handles POST /lottery/lottery/v2/:lottery_id/rollover-policy requests
+//
+// Responses:
+//   - 200: Success, future no-winner draws use the new policy
+//   - 400: Lottery not found or invalid policy
+//   - 403: Caller is not an administrator
+//   - 500: Server error
+func SetRolloverPolicy(c *gin.Context) {
+	if _, ok := currentAdmin(c); !ok {
+		return
+	}
+	var req SetRolloverPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Logger.Warn("Failed to bind request body", "error", err)
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(utils.NewBadRequestError("Invalid request body", err)))
+		return
+	}
+	if err := validator.New().Struct(&req); err != nil {
+		utils.Logger.Warn("Failed to validate request parameters", "error", err)
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(utils.NewBadRequestError("Parameter validation failed", err)))
+		return
+	}
+
+	service := rolloverPolicyService.NewRolloverPolicyService(db.DB)
+	lottery, err := service.SetRolloverPolicy(c.Request.Context(), c.Param("lottery_id"), req.RolloverPolicy)
+	if err != nil {
+		utils.Logger.Error("Failed to set rollover policy", "lottery_id", c.Param("lottery_id"), "error", err)
+		c.JSON(serviceErrorStatus(err), utils.NewErrorResponse(err))
+		return
+	}
+	c.JSON(http.StatusOK, utils.SuccessResponse("Rollover policy updated", LotteryLifecycleResponse{Lottery: *lottery}))
+}
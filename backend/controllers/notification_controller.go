@@ -0,0 +1,126 @@
+package controllers
+
+import (
+	"net/http"
+
+	"backend/db"
+	notificationService "backend/services/notification"
+	"backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// NotificationPreferencesRequest defines the request structure for changing notification preferences,
+// omitted fields keep their current value
+type NotificationPreferencesRequest struct {
+	EmailEnabled     *bool `json:"email_enabled"`
+	KYCUpdates       *bool `json:"kyc_updates"`
+	PurchaseReceipts *bool `json:"purchase_receipts"`
+	PrizeAlerts      *bool `json:"prize_alerts"`
+}
+
+// NotificationLogsQuery defines the query parameters for the notification send log
+type NotificationLogsQuery struct {
+	CustomerAddress string `form:"customer_address" validate:"omitempty,max=255"`
+	Limit           int    `form:"limit" validate:"omitempty,min=1,max=500"`
+}
+
+// GetMyNotificationPreferences handles GET /me/notification-preferences requests
+func GetMyNotificationPreferences(c *gin.Context) {
+	address, _ := c.Get("customer_address")
+	customerAddress, ok := address.(string)
+	if !ok || customerAddress == "" {
+		c.JSON(http.StatusForbidden, utils.ErrorResponse(utils.ErrCodeForbidden, "Token has no customer address", nil))
+		return
+	}
+
+	service := notificationService.NewNotificationService(db.DB)
+	pref, err := service.GetPreferences(c.Request.Context(), customerAddress)
+	if err != nil {
+		utils.Logger.Error("Failed to fetch notification preferences", "address", customerAddress, "error", err)
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(err))
+		return
+	}
+	c.JSON(http.StatusOK, utils.SuccessResponse("Notification preferences retrieved successfully", pref))
+}
+
+// UpdateMyNotificationPreferences handles PUT /me/notification-preferences requests
+//
+// Request body:
+//   - email_enabled: Master switch for emails (optional)
+//   - kyc_updates: KYC approval and rejection (optional)
+//   - purchase_receipts: Ticket purchase receipts (optional)
+//   - prize_alerts: Prize won (optional)
+//
+// Responses:
+//   - 200: Success, returns the saved preferences
+//   - 400: Invalid input
+//   - 403: Missing or invalid token
+//   - 500: Server error
+func UpdateMyNotificationPreferences(c *gin.Context) {
+	address, _ := c.Get("customer_address")
+	customerAddress, ok := address.(string)
+	if !ok || customerAddress == "" {
+		c.JSON(http.StatusForbidden, utils.ErrorResponse(utils.ErrCodeForbidden, "Token has no customer address", nil))
+		return
+	}
+
+	var req NotificationPreferencesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Logger.Warn("Failed to bind request body", "error", err)
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(utils.NewBadRequestError("Invalid request body", err)))
+		return
+	}
+
+	service := notificationService.NewNotificationService(db.DB)
+	pref, err := service.SavePreferences(c.Request.Context(), customerAddress, notificationService.PreferenceParams{
+		EmailEnabled:     req.EmailEnabled,
+		KYCUpdates:       req.KYCUpdates,
+		PurchaseReceipts: req.PurchaseReceipts,
+		PrizeAlerts:      req.PrizeAlerts,
+	})
+	if err != nil {
+		utils.Logger.Error("Failed to save notification preferences", "address", customerAddress, "error", err)
+		c.JSON(serviceErrorStatus(err), utils.NewErrorResponse(err))
+		return
+	}
+	c.JSON(http.StatusOK, utils.SuccessResponse("Notification preferences saved", pref))
+}
+
+// ListNotificationLogs handles GET /notifications/v2/logs requests
+//
+// Query parameters:
+//   - customer_address: Only this customer's notifications (optional)
+//   - limit: Number of entries, default 100, max 500 (optional)
+//
+// Responses:
+//   - 200: Success, returns the notification logs
+//   - 400: Invalid query parameters
+//   - 403: Caller is not an administrator
+//   - 500: Server error
+func ListNotificationLogs(c *gin.Context) {
+	if _, ok := currentAdmin(c); !ok {
+		return
+	}
+	var query NotificationLogsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.Logger.Warn("Failed to bind query parameters", "error", err)
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(utils.NewBadRequestError("Invalid query parameters", err)))
+		return
+	}
+	if err := validator.New().Struct(&query); err != nil {
+		utils.Logger.Warn("Failed to validate query parameters", "error", err)
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(utils.NewBadRequestError("Invalid query parameters", err)))
+		return
+	}
+
+	service := notificationService.NewNotificationService(db.DB)
+	logs, err := service.ListLogs(c.Request.Context(), query.CustomerAddress, query.Limit)
+	if err != nil {
+		utils.Logger.Error("Failed to list notification logs", "error", err)
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(err))
+		return
+	}
+	c.JSON(http.StatusOK, utils.SuccessResponse("Notification logs retrieved successfully", logs))
+}
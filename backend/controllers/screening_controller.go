@@ -0,0 +1,157 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"backend/db"
+	"backend/models"
+	"backend/services/screening"
+	"backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// ScreeningHitQuery defines the query parameters of the screening hit list
+type ScreeningHitQuery struct {
+	Status          string `form:"status" validate:"omitempty,oneof=OPEN CONFIRMED CLEARED"`
+	CustomerAddress string `form:"customer_address" validate:"omitempty,max=255"`
+	Page            int    `form:"page" validate:"omitempty,min=1"`
+	PageSize        int    `form:"page_size" validate:"omitempty,min=1,max=100"`
+}
+
+// ResolveScreeningHitRequest defines the request structure for confirming or clearing a hit
+type ResolveScreeningHitRequest struct {
+	Notes string `json:"notes" validate:"max=2000"`
+}
+
+// ListScreeningHits handles GET /screening/hits requests
+//
+// Query parameters:
+//   - status: OPEN, CONFIRMED or CLEARED (optional)
+//   - customer_address: Screened wallet address (optional)
+//   - page: Page number, default 1 (optional)
+//   - page_size: Records per page, default 20, max 100 (optional)
+//
+// Responses:
+//   - 200: Success, returns the hits, newest first
+//   - 400: Invalid query parameters
+//   - 403: Not a verifier
+//   - 500: Server error
+func ListScreeningHits(c *gin.Context) {
+	if _, ok := currentVerifier(c); !ok {
+		return
+	}
+	var query ScreeningHitQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.Logger.Warn("Failed to bind query parameters", "error", err)
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(utils.NewBadRequestError("Invalid query parameters", err)))
+		return
+	}
+	if err := validator.New().Struct(&query); err != nil {
+		utils.Logger.Warn("Failed to validate query parameters", "error", err)
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(utils.NewBadRequestError("Invalid query parameters", err)))
+		return
+	}
+
+	service := screening.NewScreeningService(db.DB)
+	result, err := service.ListHits(c.Request.Context(), screening.HitFilter{
+		Status:          query.Status,
+		CustomerAddress: query.CustomerAddress,
+		Page:            query.Page,
+		PageSize:        query.PageSize,
+	})
+	if err != nil {
+		utils.Logger.Error("Failed to list screening hits", "error", err)
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(err))
+		return
+	}
+	c.JSON(http.StatusOK, utils.SuccessResponse("Screening hits retrieved successfully", result))
+}
+
+// ConfirmScreeningHit handles POST /screening/hits/:hit_id/confirm requests, the customer stays blocked
+func ConfirmScreeningHit(c *gin.Context) {
+	resolveScreeningHit(c, models.ScreeningHitConfirmed, "Screening hit confirmed")
+}
+
+// ClearScreeningHit handles POST /screening/hits/:hit_id/clear requests
+//
+// Clears a false positive, the notes are required. A cleared hit no longer blocks the customer
+// and is not reopened by later screenings.
+func ClearScreeningHit(c *gin.Context) {
+	resolveScreeningHit(c, models.ScreeningHitCleared, "Screening hit cleared")
+}
+
+// resolveScreeningHit records a verifier's decision on a hit
+func resolveScreeningHit(c *gin.Context, status, message string) {
+	verifier, ok := currentVerifier(c)
+	if !ok {
+		return
+	}
+	hitID, err := strconv.ParseUint(c.Param("hit_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(utils.NewBadRequestError("Invalid hit_id", err)))
+		return
+	}
+	var req ResolveScreeningHitRequest
+	if err := c.ShouldBindJSON(&req); err != nil && c.Request.ContentLength > 0 {
+		utils.Logger.Warn("Failed to bind request body", "error", err)
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(utils.NewBadRequestError("Invalid request body", err)))
+		return
+	}
+	if err := validator.New().Struct(&req); err != nil {
+		utils.Logger.Warn("Failed to validate request parameters", "error", err)
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(utils.NewBadRequestError("Parameter validation failed", err)))
+		return
+	}
+	if status == models.ScreeningHitCleared && req.Notes == "" {
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(utils.NewBadRequestError("Notes are required", nil)))
+		return
+	}
+
+	service := screening.NewScreeningService(db.DB)
+	hit, err := service.ResolveHit(c.Request.Context(), uint(hitID), status, verifier, req.Notes)
+	if err != nil {
+		utils.Logger.Warn("Failed to resolve screening hit", "hit_id", hitID, "status", status, "verifier", verifier, "error", err)
+		c.JSON(serviceErrorStatus(err), utils.NewErrorResponse(err))
+		return
+	}
+	c.JSON(http.StatusOK, utils.SuccessResponse(message, hit))
+}
+
+// ListSanctionsLists handles GET /screening/lists requests, returning the imported lists
+func ListSanctionsLists(c *gin.Context) {
+	if _, ok := currentVerifier(c); !ok {
+		return
+	}
+	service := screening.NewScreeningService(db.DB)
+	lists, err := service.ListLists(c.Request.Context())
+	if err != nil {
+		utils.Logger.Error("Failed to list sanctions lists", "error", err)
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(err))
+		return
+	}
+	c.JSON(http.StatusOK, utils.SuccessResponse("Sanctions lists retrieved successfully", lists))
+}
+
+// RefreshSanctionsLists handles POST /screening/lists/refresh requests
+//
+// Imports the changed list files now instead of waiting for the watcher, re-screening every
+// customer when a list changed.
+func RefreshSanctionsLists(c *gin.Context) {
+	if _, ok := currentVerifier(c); !ok {
+		return
+	}
+	service := screening.NewScreeningService(db.DB)
+	changed, err := service.Refresh(c.Request.Context())
+	if err != nil {
+		utils.Logger.Error("Failed to refresh sanctions lists", "error", err)
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(err))
+		return
+	}
+	if changed == nil {
+		changed = []string{}
+	}
+	c.JSON(http.StatusOK, utils.SuccessResponse("Sanctions lists refreshed", gin.H{"changed": changed}))
+}
@@ -63,6 +63,7 @@ func NewPurchaseTicket(c *gin.Context) {
 		PurchaseAmount: req.PurchaseAmount,
 		BetContent:     req.BetContent,
 		TicketID:       uuid.NewString(),
+		ClientIP:       c.ClientIP(),
 	})
 	if err != nil {
 		utils.Logger.Error("Failed to buy ticket",
@@ -71,7 +72,7 @@ func NewPurchaseTicket(c *gin.Context) {
 			"purchase_amount", req.PurchaseAmount,
 			"txHash", txHash,
 			"error", err)
-		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(err))
+		c.JSON(serviceErrorStatus(err), utils.NewErrorResponse(err))
 		return
 	}
 
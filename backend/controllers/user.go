@@ -2,14 +2,13 @@
 package controllers
 
 import (
+	"backend/db"
 	"backend/models"
 	"backend/services"
+	"backend/services/document"
+	"backend/services/kyc"
 	"backend/utils"
-	"fmt"
 	"net/http"
-	"os"
-	"path/filepath"
-	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -72,63 +71,38 @@ func UploadPhoto(c *gin.Context) {
 		return
 	}
 
-	// 验证文件类型（只允许图片）
-	allowedExtensions := map[string]bool{
-		".jpg":  true,
-		".jpeg": true,
-		".png":  true,
-	}
-	ext := strings.ToLower(filepath.Ext(file.Filename))
-	if !allowedExtensions[ext] {
-		c.JSON(http.StatusBadRequest, utils.ErrorResponse(utils.ErrCodeInvalidInput, "Only JPG, JPEG, and PNG files are allowed", nil))
+	// 验证文件大小，文件类型由上传流程根据文件内容判断，不信任扩展名
+	if file.Size > document.MaxUploadBytes {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse(utils.ErrCodeInvalidInput, "File size exceeds 5MB limit", nil))
 		return
 	}
 
-	// 验证文件大小（例如限制为 5MB）
-	const maxSize = 5 << 20 // 5MB
-	if file.Size > maxSize {
-		c.JSON(http.StatusBadRequest, utils.ErrorResponse(utils.ErrCodeInvalidInput, "File size exceeds 5MB limit", nil))
+	src, err := file.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse(utils.ErrCodeInvalidInput, "Failed to read photo", err.Error()))
 		return
 	}
+	defer src.Close()
 
-	// 检查是否配置了S3
-	var fileURL string
-	if utils.S3Client != nil {
-		// 使用S3存储
-		url, err := utils.UploadFileToS3(file, "photos")
-		if err != nil {
-			utils.Logger.WithField("error", err.Error()).Error("Failed to upload file to S3")
-			c.JSON(http.StatusInternalServerError, utils.ErrorResponse(utils.ErrCodeInternalServer, "Failed to upload file to S3", err.Error()))
-			return
-		}
-		fileURL = url
-	} else {
-		// 使用本地存储作为备选
-		// 创建存储路径（uploads/目录）
-		uploadDir := "uploads"
-		if err := os.MkdirAll(uploadDir, os.ModePerm); err != nil {
-			c.JSON(http.StatusInternalServerError, utils.ErrorResponse(utils.ErrCodeInternalServer, "Failed to create upload directory", err.Error()))
-			return
-		}
-
-		// 生成唯一的文件名（使用时间戳和原始文件名）
-		filename := fmt.Sprintf("%d_%s", time.Now().UnixNano(), file.Filename)
-		filePath := filepath.Join(uploadDir, filename)
-
-		// 保存文件到指定路径
-		if err := c.SaveUploadedFile(file, filePath); err != nil {
-			c.JSON(http.StatusInternalServerError, utils.ErrorResponse(utils.ErrCodeInternalServer, "Failed to save photo", err.Error()))
-			return
-		}
-
-		// 构造文件访问地址（假设服务端运行在 localhost:8080）
-		// 实际部署时，应使用真实的域名或 CDN 地址
-		fileURL = fmt.Sprintf("/%s", filePath)
+	// 证件（JPEG、PNG 或 PDF）经过恶意软件扫描，图片重新编码去除 EXIF，
+	// 保存在私有存储中（配置了 S3 时为私有存储桶，否则为本地加密存储），只返回不透明的存储键
+	service, err := document.NewDocumentService(db.DB)
+	if err != nil {
+		utils.Logger.Error("Document storage is not configured", "error", err)
+		c.JSON(http.StatusInternalServerError, utils.ErrorResponse(utils.ErrCodeInternalServer, "Document storage is not configured", nil))
+		return
+	}
+	doc, err := service.Upload(c.Request.Context(), src)
+	if err != nil {
+		utils.Logger.Error("Failed to store KYC document", "file", file.Filename, "error", err)
+		c.JSON(serviceErrorStatus(err), utils.NewErrorResponse(err))
+		return
 	}
 
-	// 返回成功响应
+	// 返回成功响应，file_url 保留为旧字段名，前端仍将其写入 KYCData.FilePath
 	c.JSON(http.StatusOK, utils.SuccessResponse("Photo uploaded successfully", map[string]string{
-		"file_url": fileURL,
+		"file_key": doc.DocumentKey,
+		"file_url": doc.DocumentKey,
 	}))
 }
 
@@ -161,11 +135,21 @@ func CreateCustomer(c *gin.Context) {
 	// 注册时不分配角色，role_id 设为 0（未分配）
 	cust.RoleID = 0
 
+	// 认证状态、到期时间和风险等级只由审核流程设置，新提交的风险等级为 High，等待审核人员评估
+	cust.IsVerified = false
+	cust.KYCExpiresAt = nil
+	cust.KYCRemindedAt = nil
+	cust.KYCData.RiskLevel = kyc.DefaultRiskLevel
+
 	// 注册时间
 	cust.RegistrationTime = time.Now()
 
 	// 调用服务层创建用户
 	if err := services.CreateCustomer(cust); err != nil {
+		if status := serviceErrorStatus(err); status != http.StatusInternalServerError {
+			c.JSON(status, utils.NewErrorResponse(err))
+			return
+		}
 		c.JSON(http.StatusInternalServerError, utils.ErrorResponse(utils.ErrCodeInternalServer, "Failed to create customer", err.Error()))
 		return
 	}
@@ -186,9 +170,12 @@ func CreateCustomer(c *gin.Context) {
 // @Security BearerAuth
 // @Router /auth/verify [post]
 func VerifyCustomer(c *gin.Context) {
-	// 检查权限（确保调用者是 lottery_admin）
-	role, exists := c.Get("role")
-	if !exists || role != "admin" {
+	// 检查权限（确保调用者是 KYC 审核人员）
+	role, _ := c.Get("role")
+	roleName, _ := role.(string)
+	address, _ := c.Get("customer_address")
+	verifierAddress, _ := address.(string)
+	if !kyc.IsVerifier(roleName) || verifierAddress == "" {
 		c.JSON(http.StatusForbidden, utils.ErrorResponse(utils.ErrCodeForbidden, "Insufficient permissions", nil))
 		return
 	}
@@ -204,17 +191,27 @@ func VerifyCustomer(c *gin.Context) {
 	logrus.Info("verification: ", verification)
 
 	verification.VerificationDate = time.Now()
+	// 审核人员以令牌中的地址为准，四眼原则要求区分不同的审核人员
+	verification.VerifierAddress = verifierAddress
 	// 调用服务层进行验证
-	if err := services.VerifyCustomer(&verification); err != nil {
+	review, err := services.VerifyCustomer(&verification)
+	if err != nil {
+		if status := serviceErrorStatus(err); status != http.StatusInternalServerError {
+			c.JSON(status, utils.NewErrorResponse(err))
+			return
+		}
 		c.JSON(http.StatusInternalServerError, utils.ErrorResponse(utils.ErrCodeInternalServer, "Failed to verify customer", err.Error()))
 		return
 	}
 
-	// 根据验证状态返回响应
-	if verification.VerifyStatus == "Approved" {
-		c.JSON(http.StatusOK, utils.SuccessResponse("Verification successful", nil))
-	} else if verification.VerifyStatus == "Rejected" {
-		c.JSON(http.StatusOK, utils.SuccessResponse("Verification failed", nil))
+	// 根据审核结果返回响应
+	switch review.Status {
+	case models.KYCReviewStatusApproved:
+		c.JSON(http.StatusOK, utils.SuccessResponse("Verification successful", review))
+	case models.KYCReviewStatusAwaitingSecondApproval:
+		c.JSON(http.StatusOK, utils.SuccessResponse("First approval recorded, a second verifier must approve", review))
+	default:
+		c.JSON(http.StatusOK, utils.SuccessResponse("Verification failed", review))
 	}
 }
 
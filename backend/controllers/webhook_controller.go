@@ -0,0 +1,169 @@
+package controllers
+
+import (
+	"net/http"
+
+	"backend/db"
+	webhookService "backend/services/webhook"
+	"backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// CreateWebhookRequest defines the request structure for registering a webhook endpoint
+type CreateWebhookRequest struct {
+	URL         string   `json:"url" validate:"required,url,max=500"`
+	EventTypes  []string `json:"event_types" validate:"required,min=1,dive,oneof=issue.opened issue.sales_closed issue.drawn winner.recorded"`
+	LotteryID   string   `json:"lottery_id" validate:"omitempty,max=50"`
+	Description string   `json:"description" validate:"omitempty,max=255"`
+}
+
+// WebhookDeliveriesQuery defines the query parameters for a subscription's delivery log
+type WebhookDeliveriesQuery struct {
+	Status   string `form:"status" validate:"omitempty,oneof=PENDING DELIVERED DEAD"`
+	Page     int    `form:"page" validate:"omitempty,min=1"`
+	PageSize int    `form:"page_size" validate:"omitempty,min=1,max=100"`
+}
+
+// CreateWebhook handles POST /webhooks/v2 requests
+//
+// Request body:
+//   - url: Endpoint the events are posted to (required)
+//   - event_types: issue.opened, issue.sales_closed, issue.drawn and/or winner.recorded (required)
+//   - lottery_id: Only events of this lottery (optional, defaults to all lotteries)
+//   - description: Free text (optional)
+//
+// Responses:
+//   - 200: Success, returns the subscription with its signing secret, which is not shown again
+//   - 400: Invalid input
+//   - 403: Caller is not an administrator
+//   - 500: Server error
+func CreateWebhook(c *gin.Context) {
+	if _, ok := currentAdmin(c); !ok {
+		return
+	}
+	var req CreateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Logger.Warn("Failed to bind request body", "error", err)
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(utils.NewBadRequestError("Invalid request body", err)))
+		return
+	}
+	if err := validator.New().Struct(&req); err != nil {
+		utils.Logger.Warn("Failed to validate request parameters", "error", err)
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(utils.NewBadRequestError("Parameter validation failed", err)))
+		return
+	}
+
+	service := webhookService.NewWebhookService(db.DB)
+	subscription, err := service.CreateSubscription(c.Request.Context(), webhookService.SubscriptionParams{
+		URL:         req.URL,
+		EventTypes:  req.EventTypes,
+		LotteryID:   req.LotteryID,
+		Description: req.Description,
+	})
+	if err != nil {
+		utils.Logger.Error("Failed to create webhook subscription", "url", req.URL, "error", err)
+		c.JSON(serviceErrorStatus(err), utils.NewErrorResponse(err))
+		return
+	}
+	c.JSON(http.StatusOK, utils.SuccessResponse("Webhook subscription created", subscription))
+}
+
+// ListWebhooks handles GET /webhooks/v2 requests
+func ListWebhooks(c *gin.Context) {
+	if _, ok := currentAdmin(c); !ok {
+		return
+	}
+	service := webhookService.NewWebhookService(db.DB)
+	subscriptions, err := service.ListSubscriptions(c.Request.Context())
+	if err != nil {
+		utils.Logger.Error("Failed to list webhook subscriptions", "error", err)
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(err))
+		return
+	}
+	c.JSON(http.StatusOK, utils.SuccessResponse("Webhook subscriptions retrieved successfully", subscriptions))
+}
+
+// DeleteWebhook handles DELETE /webhooks/v2/:subscription_id requests, its pending deliveries are abandoned
+func DeleteWebhook(c *gin.Context) {
+	if _, ok := currentAdmin(c); !ok {
+		return
+	}
+	service := webhookService.NewWebhookService(db.DB)
+	if err := service.DeactivateSubscription(c.Request.Context(), c.Param("subscription_id")); err != nil {
+		utils.Logger.Error("Failed to deactivate webhook subscription", "subscription_id", c.Param("subscription_id"), "error", err)
+		c.JSON(serviceErrorStatus(err), utils.NewErrorResponse(err))
+		return
+	}
+	c.JSON(http.StatusOK, utils.SuccessResponse("Webhook subscription deactivated", nil))
+}
+
+// ListWebhookDeliveries handles GET /webhooks/v2/:subscription_id/deliveries requests
+//
+// Query parameters:
+//   - status: PENDING, DELIVERED or DEAD (optional)
+//   - page: Page number, default 1 (optional)
+//   - page_size: Records per page, default 20, max 100 (optional)
+//
+// Responses:
+//   - 200: Success, returns the deliveries with every attempt's status code, error and duration
+//   - 400: Invalid query parameters
+//   - 403: Caller is not an administrator
+//   - 500: Server error
+func ListWebhookDeliveries(c *gin.Context) {
+	if _, ok := currentAdmin(c); !ok {
+		return
+	}
+	var query WebhookDeliveriesQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.Logger.Warn("Failed to bind query parameters", "error", err)
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(utils.NewBadRequestError("Invalid query parameters", err)))
+		return
+	}
+	if err := validator.New().Struct(&query); err != nil {
+		utils.Logger.Warn("Failed to validate query parameters", "error", err)
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(utils.NewBadRequestError("Invalid query parameters", err)))
+		return
+	}
+
+	service := webhookService.NewWebhookService(db.DB)
+	result, err := service.ListDeliveries(c.Request.Context(), c.Param("subscription_id"), query.Status, query.Page, query.PageSize)
+	if err != nil {
+		utils.Logger.Error("Failed to list webhook deliveries", "subscription_id", c.Param("subscription_id"), "error", err)
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(err))
+		return
+	}
+	c.JSON(http.StatusOK, utils.SuccessResponse("Webhook deliveries retrieved successfully", result))
+}
+
+// ListWebhookDeadLetters handles GET /webhooks/v2/dead-letters requests
+func ListWebhookDeadLetters(c *gin.Context) {
+	if _, ok := currentAdmin(c); !ok {
+		return
+	}
+	service := webhookService.NewWebhookService(db.DB)
+	letters, err := service.ListDeadLetters(c.Request.Context())
+	if err != nil {
+		utils.Logger.Error("Failed to list webhook dead letters", "error", err)
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(err))
+		return
+	}
+	c.JSON(http.StatusOK, utils.SuccessResponse("Webhook dead letters retrieved successfully", letters))
+}
+
+// ReplayWebhookDeadLetter handles POST /webhooks/v2/dead-letters/:delivery_id/replay requests
+//
+// Puts the delivery back in the queue with a fresh retry budget.
+func ReplayWebhookDeadLetter(c *gin.Context) {
+	if _, ok := currentAdmin(c); !ok {
+		return
+	}
+	service := webhookService.NewWebhookService(db.DB)
+	if err := service.ReplayDeadLetter(c.Request.Context(), c.Param("delivery_id")); err != nil {
+		utils.Logger.Error("Failed to replay webhook dead letter", "delivery_id", c.Param("delivery_id"), "error", err)
+		c.JSON(serviceErrorStatus(err), utils.NewErrorResponse(err))
+		return
+	}
+	c.JSON(http.StatusOK, utils.SuccessResponse("Webhook delivery requeued", nil))
+}
@@ -0,0 +1,95 @@
+package controllers
+
+import (
+	"net/http"
+
+	"backend/db"
+	"backend/models"
+	winnerPayoutService "backend/services/winner"
+	"backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// RetryPayoutResponse defines the response structure for a payout retry
+type RetryPayoutResponse struct {
+	Winner models.Winner `json:"winner"`
+	TxHash string        `json:"tx_hash,omitempty"`
+}
+
+// MyWinningsQuery defines the query parameters for fetching the caller's winnings
+type MyWinningsQuery struct {
+	PayoutStatus string `form:"payout_status" validate:"omitempty,oneof=PENDING PAID FAILED PENDING_MANUAL SUBMITTED"`
+	Page         int    `form:"page" validate:"omitempty,min=1"`
+	PageSize     int    `form:"page_size" validate:"omitempty,min=1,max=100"`
+}
+
+// RetryWinnerPayout handles POST /lottery/winners/v2/:winner_id/retry-payout requests
+//
+// Pays a FAILED or PENDING_MANUAL prize from the treasury (the admin account's token balance).
+//
+// Responses:
+//   - 200: Success, returns RetryPayoutResponse
+//   - 400: Winner not found, already paid, not retryable or treasury balance too low
+//   - 403: Caller is not an administrator
+//   - 500: Server error
+func RetryWinnerPayout(c *gin.Context) {
+	if _, ok := currentAdmin(c); !ok {
+		return
+	}
+	service := winnerPayoutService.NewWinnerPayoutService(db.DB)
+	winner, txHash, err := service.RetryPayout(c.Request.Context(), c.Param("winner_id"))
+	if err != nil {
+		utils.Logger.Error("Failed to retry payout", "winner_id", c.Param("winner_id"), "error", err)
+		c.JSON(serviceErrorStatus(err), utils.NewErrorResponse(err))
+		return
+	}
+	response := RetryPayoutResponse{Winner: *winner}
+	if txHash.Big().Sign() != 0 {
+		response.TxHash = txHash.Hex()
+	}
+	c.JSON(http.StatusOK, utils.SuccessResponse("Payout retried", response))
+}
+
+// ListMyWinnings handles GET /lottery/winners/v2/me requests
+//
+// Query parameters:
+//   - payout_status: PENDING, PAID, FAILED, PENDING_MANUAL or SUBMITTED (optional)
+//   - page: Page number, default 1 (optional)
+//   - page_size: Records per page, default 20, max 100 (optional)
+//
+// Responses:
+//   - 200: Success, returns the caller's prizes with their payout status
+//   - 400: Invalid query parameters
+//   - 403: Missing or invalid token
+//   - 500: Server error
+func ListMyWinnings(c *gin.Context) {
+	var query MyWinningsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.Logger.Warn("Failed to bind query parameters", "error", err)
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(utils.NewBadRequestError("Invalid query parameters", err)))
+		return
+	}
+	if err := validator.New().Struct(&query); err != nil {
+		utils.Logger.Warn("Failed to validate query parameters", "error", err)
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(utils.NewBadRequestError("Invalid query parameters", err)))
+		return
+	}
+
+	address, _ := c.Get("customer_address")
+	customerAddress, ok := address.(string)
+	if !ok || customerAddress == "" {
+		c.JSON(http.StatusForbidden, utils.ErrorResponse(utils.ErrCodeForbidden, "Token has no customer address", nil))
+		return
+	}
+
+	service := winnerPayoutService.NewWinnerPayoutService(db.DB)
+	result, err := service.GetWinningsByAddress(c.Request.Context(), customerAddress, query.PayoutStatus, query.Page, query.PageSize)
+	if err != nil {
+		utils.Logger.Error("Failed to query winnings", "address", customerAddress, "error", err)
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(err))
+		return
+	}
+	c.JSON(http.StatusOK, utils.SuccessResponse("Successfully queried winnings", result))
+}
@@ -0,0 +1,186 @@
+package controllers
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"time"
+
+	"backend/db"
+	winnerTaxService "backend/services/winner"
+	"backend/utils"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// SaveTaxRuleRequest defines the request structure for saving a withholding rule
+type SaveTaxRuleRequest struct {
+	Jurisdiction string   `json:"jurisdiction" validate:"required,max=50"`
+	Threshold    float64  `json:"threshold" validate:"gte=0"`
+	Rate         *float64 `json:"rate" validate:"required,gte=0,lte=1"`
+	Description  string   `json:"description" validate:"omitempty,max=255"`
+}
+
+// WinningsStatementQuery defines the query parameters for exporting a winnings statement
+type WinningsStatementQuery struct {
+	Year   int    `form:"year" validate:"omitempty,min=2000,max=9999"`
+	Format string `form:"format" validate:"omitempty,oneof=csv pdf"`
+}
+
+// SaveTaxRule handles POST /lottery/tax-rules/v2 requests
+//
+// Request body:
+//   - jurisdiction: Nationality the rule applies to, as stored in KYC data, or DEFAULT (required)
+//   - threshold: Gross prize from which the whole prize is withheld (optional, default 0)
+//   - rate: Withholding rate between 0 and 1 (required)
+//   - description: Free text (optional)
+//
+// Responses:
+//   - 200: Success, returns the saved rule, an existing rule of the jurisdiction is replaced
+//   - 400: Invalid input
+//   - 403: Caller is not an administrator
+//   - 500: Server error
+func SaveTaxRule(c *gin.Context) {
+	if _, ok := currentAdmin(c); !ok {
+		return
+	}
+	var req SaveTaxRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Logger.Warn("Failed to bind request body", "error", err)
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(utils.NewBadRequestError("Invalid request body", err)))
+		return
+	}
+	if err := validator.New().Struct(&req); err != nil {
+		utils.Logger.Warn("Failed to validate request parameters", "error", err)
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(utils.NewBadRequestError("Parameter validation failed", err)))
+		return
+	}
+
+	service := winnerTaxService.NewWinnerTaxService(db.DB)
+	rule, err := service.SaveRule(c.Request.Context(), winnerTaxService.TaxRuleParams{
+		Jurisdiction: req.Jurisdiction,
+		Threshold:    req.Threshold,
+		Rate:         *req.Rate,
+		Description:  req.Description,
+	})
+	if err != nil {
+		utils.Logger.Error("Failed to save withholding rule", "jurisdiction", req.Jurisdiction, "error", err)
+		c.JSON(serviceErrorStatus(err), utils.NewErrorResponse(err))
+		return
+	}
+	c.JSON(http.StatusOK, utils.SuccessResponse("Withholding rule saved", rule))
+}
+
+// ListTaxRules handles GET /lottery/tax-rules/v2 requests
+func ListTaxRules(c *gin.Context) {
+	if _, ok := currentAdmin(c); !ok {
+		return
+	}
+	service := winnerTaxService.NewWinnerTaxService(db.DB)
+	rules, err := service.ListRules(c.Request.Context())
+	if err != nil {
+		utils.Logger.Error("Failed to list withholding rules", "error", err)
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(err))
+		return
+	}
+	c.JSON(http.StatusOK, utils.SuccessResponse("Withholding rules retrieved successfully", rules))
+}
+
+// DeleteTaxRule handles DELETE /lottery/tax-rules/v2/:rule_id requests
+func DeleteTaxRule(c *gin.Context) {
+	if _, ok := currentAdmin(c); !ok {
+		return
+	}
+	service := winnerTaxService.NewWinnerTaxService(db.DB)
+	if err := service.DeleteRule(c.Request.Context(), c.Param("rule_id")); err != nil {
+		utils.Logger.Error("Failed to delete withholding rule", "rule_id", c.Param("rule_id"), "error", err)
+		c.JSON(serviceErrorStatus(err), utils.NewErrorResponse(err))
+		return
+	}
+	c.JSON(http.StatusOK, utils.SuccessResponse("Withholding rule deleted", nil))
+}
+
+// ExportMyWinningsStatement handles GET /lottery/winners/v2/me/statement requests
+//
+// Query parameters:
+//   - year: Calendar year (optional, defaults to the current year)
+//   - format: csv or pdf (optional, defaults to csv)
+//
+// Responses:
+//   - 200: The statement file
+//   - 400: Invalid query parameters
+//   - 403: Missing or invalid token
+//   - 500: Server error
+func ExportMyWinningsStatement(c *gin.Context) {
+	address, _ := c.Get("customer_address")
+	customerAddress, ok := address.(string)
+	if !ok || customerAddress == "" {
+		c.JSON(http.StatusForbidden, utils.ErrorResponse(utils.ErrCodeForbidden, "Token has no customer address", nil))
+		return
+	}
+	exportWinningsStatement(c, customerAddress)
+}
+
+// ExportWinningsStatement handles GET /lottery/winners/v2/statements/:customer_address requests for administrators
+//
+// Takes the same query parameters as ExportMyWinningsStatement.
+func ExportWinningsStatement(c *gin.Context) {
+	if _, ok := currentAdmin(c); !ok {
+		return
+	}
+	customerAddress := c.Param("customer_address")
+	if !common.IsHexAddress(customerAddress) {
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(utils.NewBadRequestError("Invalid customer address", nil)))
+		return
+	}
+	exportWinningsStatement(c, customerAddress)
+}
+
+// exportWinningsStatement renders the annual statement of a customer in the requested format
+func exportWinningsStatement(c *gin.Context, customerAddress string) {
+	var query WinningsStatementQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.Logger.Warn("Failed to bind query parameters", "error", err)
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(utils.NewBadRequestError("Invalid query parameters", err)))
+		return
+	}
+	if err := validator.New().Struct(&query); err != nil {
+		utils.Logger.Warn("Failed to validate query parameters", "error", err)
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(utils.NewBadRequestError("Invalid query parameters", err)))
+		return
+	}
+	if query.Year == 0 {
+		query.Year = time.Now().UTC().Year()
+	}
+	if query.Format == "" {
+		query.Format = "csv"
+	}
+
+	service := winnerTaxService.NewWinnerTaxService(db.DB)
+	statement, err := service.BuildStatement(c.Request.Context(), customerAddress, query.Year)
+	if err != nil {
+		utils.Logger.Error("Failed to build winnings statement", "address", customerAddress, "year", query.Year, "error", err)
+		c.JSON(serviceErrorStatus(err), utils.NewErrorResponse(err))
+		return
+	}
+
+	var buf bytes.Buffer
+	contentType := "text/csv"
+	if query.Format == "pdf" {
+		contentType = "application/pdf"
+		err = winnerTaxService.WriteStatementPDF(&buf, statement)
+	} else {
+		err = winnerTaxService.WriteStatementCSV(&buf, statement)
+	}
+	if err != nil {
+		utils.Logger.Error("Failed to render winnings statement", "address", customerAddress, "format", query.Format, "error", err)
+		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(utils.NewInternalError("Failed to render winnings statement", err)))
+		return
+	}
+
+	filename := fmt.Sprintf("winnings-%s-%d.%s", customerAddress, query.Year, query.Format)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Data(http.StatusOK, contentType, buf.Bytes())
+}
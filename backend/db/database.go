@@ -66,13 +66,12 @@ func InitDB() {
 
 	log.Println("Database connected successfully")
 
-	// 可选：自动迁移表结构（取消注释以启用）
-	// err = DB.AutoMigrate(&models.Role{}, &models.RoleMenu{}, &models.Customer{},
-	// 	&models.KYCData{}, &models.KYCVerificationHistory{},
-	// 	&models.LotteryType{}, &models.Lottery{}, &models.LotteryIssue{},
-	// 	&models.LotteryTicket{}, &models.Winner{})
-	// if err != nil {
-	// 	log.Fatalf("Failed to auto-migrate database: %v", err)
-	// }
-	// log.Println("Database schema migrated successfully")
+	// 可选：启动时执行版本化迁移（DB_AUTO_MIGRATE=true 启用）
+	if config.AppConfig.DBAutoMigrate {
+		count, err := MigrateUp(DB)
+		if err != nil {
+			log.Fatalf("Failed to migrate database: %v", err)
+		}
+		log.Printf("Database schema migrated successfully, %d migrations applied", count)
+	}
 }
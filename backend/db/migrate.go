@@ -0,0 +1,212 @@
+package db
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"log"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// migrationFiles 内嵌的版本化 SQL 迁移文件，命名格式为 <版本>_<名称>.(up|down).sql
+//
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+var migrationFilePattern = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// Migration 表示一个版本化的数据库迁移
+type Migration struct {
+	Version uint
+	Name    string
+	UpSQL   string
+	DownSQL string
+}
+
+// SchemaMigration 已执行迁移的记录表模型
+type SchemaMigration struct {
+	Version   uint      `gorm:"primaryKey;autoIncrement:false" json:"version"`
+	Name      string    `gorm:"size:255;not null" json:"name"`
+	AppliedAt time.Time `gorm:"type:timestamptz;not null" json:"applied_at"`
+}
+
+// MigrationStatus 表示单个迁移的执行状态
+type MigrationStatus struct {
+	Version   uint       `json:"version"`
+	Name      string     `json:"name"`
+	Applied   bool       `json:"applied"`
+	AppliedAt *time.Time `json:"applied_at,omitempty"`
+}
+
+// LoadMigrations 读取内嵌的迁移文件，按版本号升序返回
+func LoadMigrations() ([]Migration, error) {
+	entries, err := fs.ReadDir(migrationFiles, "migrations")
+	if err != nil {
+		return nil, fmt.Errorf("read migrations directory: %w", err)
+	}
+
+	byVersion := make(map[uint]*Migration)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		matches := migrationFilePattern.FindStringSubmatch(entry.Name())
+		if matches == nil {
+			return nil, fmt.Errorf("invalid migration file name: %s", entry.Name())
+		}
+		version, err := strconv.ParseUint(matches[1], 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version in %s: %w", entry.Name(), err)
+		}
+		content, err := migrationFiles.ReadFile(path.Join("migrations", entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("read migration %s: %w", entry.Name(), err)
+		}
+
+		m, ok := byVersion[uint(version)]
+		if !ok {
+			m = &Migration{Version: uint(version), Name: matches[2]}
+			byVersion[uint(version)] = m
+		} else if m.Name != matches[2] {
+			return nil, fmt.Errorf("migration version %d has conflicting names: %s, %s", version, m.Name, matches[2])
+		}
+		if matches[3] == "up" {
+			m.UpSQL = string(content)
+		} else {
+			m.DownSQL = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.UpSQL == "" || m.DownSQL == "" {
+			return nil, fmt.Errorf("migration %d_%s must have both up and down files", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+// ensureMigrationTable 确保迁移记录表存在
+func ensureMigrationTable(conn *gorm.DB) error {
+	return conn.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version BIGINT PRIMARY KEY,
+		name VARCHAR(255) NOT NULL,
+		applied_at TIMESTAMP WITH TIME ZONE NOT NULL
+	)`).Error
+}
+
+// appliedMigrations 查询已执行的迁移记录
+func appliedMigrations(conn *gorm.DB) (map[uint]SchemaMigration, error) {
+	if err := ensureMigrationTable(conn); err != nil {
+		return nil, fmt.Errorf("create schema_migrations table: %w", err)
+	}
+	var records []SchemaMigration
+	if err := conn.Order("version").Find(&records).Error; err != nil {
+		return nil, fmt.Errorf("query schema_migrations: %w", err)
+	}
+	applied := make(map[uint]SchemaMigration, len(records))
+	for _, r := range records {
+		applied[r.Version] = r
+	}
+	return applied, nil
+}
+
+// MigrateUp 按版本顺序执行所有未执行的迁移，每个迁移在独立事务中执行
+func MigrateUp(conn *gorm.DB) (int, error) {
+	migrations, err := LoadMigrations()
+	if err != nil {
+		return 0, err
+	}
+	applied, err := appliedMigrations(conn)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, m := range migrations {
+		if _, ok := applied[m.Version]; ok {
+			continue
+		}
+		err := conn.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Exec(m.UpSQL).Error; err != nil {
+				return err
+			}
+			return tx.Create(&SchemaMigration{Version: m.Version, Name: m.Name, AppliedAt: time.Now()}).Error
+		})
+		if err != nil {
+			return count, fmt.Errorf("apply migration %d_%s: %w", m.Version, m.Name, err)
+		}
+		log.Printf("Applied migration %d_%s", m.Version, m.Name)
+		count++
+	}
+	return count, nil
+}
+
+// MigrateDown 按版本倒序回滚最近执行的 steps 个迁移
+func MigrateDown(conn *gorm.DB, steps int) (int, error) {
+	if steps <= 0 {
+		return 0, fmt.Errorf("steps must be positive, got %d", steps)
+	}
+	migrations, err := LoadMigrations()
+	if err != nil {
+		return 0, err
+	}
+	applied, err := appliedMigrations(conn)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for i := len(migrations) - 1; i >= 0 && count < steps; i-- {
+		m := migrations[i]
+		if _, ok := applied[m.Version]; !ok {
+			continue
+		}
+		err := conn.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Exec(m.DownSQL).Error; err != nil {
+				return err
+			}
+			return tx.Delete(&SchemaMigration{}, "version = ?", m.Version).Error
+		})
+		if err != nil {
+			return count, fmt.Errorf("revert migration %d_%s: %w", m.Version, m.Name, err)
+		}
+		log.Printf("Reverted migration %d_%s", m.Version, m.Name)
+		count++
+	}
+	return count, nil
+}
+
+// GetMigrationStatus 返回所有迁移及其执行状态
+func GetMigrationStatus(conn *gorm.DB) ([]MigrationStatus, error) {
+	migrations, err := LoadMigrations()
+	if err != nil {
+		return nil, err
+	}
+	applied, err := appliedMigrations(conn)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(migrations))
+	for _, m := range migrations {
+		status := MigrationStatus{Version: m.Version, Name: m.Name}
+		if record, ok := applied[m.Version]; ok {
+			appliedAt := record.AppliedAt
+			status.Applied = true
+			status.AppliedAt = &appliedAt
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
@@ -0,0 +1,10 @@
+DROP TABLE IF EXISTS winners CASCADE;
+DROP TABLE IF EXISTS lottery_tickets CASCADE;
+DROP TABLE IF EXISTS lottery_issues CASCADE;
+DROP TABLE IF EXISTS lotteries CASCADE;
+DROP TABLE IF EXISTS lottery_types CASCADE;
+DROP TABLE IF EXISTS kyc_verification_histories CASCADE;
+DROP TABLE IF EXISTS kyc_data CASCADE;
+DROP TABLE IF EXISTS customers CASCADE;
+DROP TABLE IF EXISTS role_menus CASCADE;
+DROP TABLE IF EXISTS roles CASCADE;
@@ -0,0 +1,151 @@
+-- 角色表
+CREATE TABLE IF NOT EXISTS roles (
+    role_id INTEGER PRIMARY KEY,
+    role_name VARCHAR(50) NOT NULL,
+    role_type VARCHAR(50),
+    description TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+CREATE UNIQUE INDEX IF NOT EXISTS uq_roles_role_name ON roles (role_name);
+
+-- 角色菜单表
+CREATE TABLE IF NOT EXISTS role_menus (
+    role_menu_id INTEGER PRIMARY KEY,
+    role_id INTEGER NOT NULL REFERENCES roles (role_id) ON DELETE CASCADE,
+    menu_name VARCHAR(50) NOT NULL,
+    menu_path VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_role_menus_role_id ON role_menus (role_id);
+
+-- 用户表（注册时 role_id 为 0，表示未分配角色，因此不加外键）
+CREATE TABLE IF NOT EXISTS customers (
+    customer_address VARCHAR(255) PRIMARY KEY,
+    is_verified BOOLEAN DEFAULT FALSE,
+    verifier_address VARCHAR(255),
+    verification_time TIMESTAMP WITH TIME ZONE,
+    registration_time TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    role_id INTEGER NOT NULL,
+    assigned_date TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_customers_role_id ON customers (role_id);
+CREATE INDEX IF NOT EXISTS idx_customers_is_verified ON customers (is_verified);
+
+-- KYC 数据表
+CREATE TABLE IF NOT EXISTS kyc_data (
+    customer_address VARCHAR(255) PRIMARY KEY REFERENCES customers (customer_address) ON DELETE CASCADE,
+    name VARCHAR(100),
+    birth_date DATE,
+    nationality VARCHAR(50),
+    residential_address TEXT,
+    phone_number VARCHAR(20),
+    email VARCHAR(255),
+    document_type VARCHAR(50),
+    document_number VARCHAR(50),
+    file_path TEXT,
+    submission_date TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    risk_level VARCHAR(20),
+    source_of_funds TEXT,
+    occupation VARCHAR(100),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_kyc_data_email ON kyc_data (email);
+
+-- KYC 验证历史表
+CREATE TABLE IF NOT EXISTS kyc_verification_histories (
+    history_id INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    customer_address VARCHAR(255) NOT NULL REFERENCES customers (customer_address) ON DELETE CASCADE,
+    verify_status VARCHAR(50),
+    verifier_address VARCHAR(255),
+    verification_date TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    comments TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_kyc_verification_histories_customer ON kyc_verification_histories (customer_address);
+
+-- 彩票类型表
+CREATE TABLE IF NOT EXISTS lottery_types (
+    type_id VARCHAR(50) PRIMARY KEY,
+    type_name VARCHAR(255) NOT NULL,
+    description VARCHAR(1000),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+CREATE UNIQUE INDEX IF NOT EXISTS uq_lottery_types_type_name ON lottery_types (type_name);
+
+-- 彩票表
+CREATE TABLE IF NOT EXISTS lotteries (
+    lottery_id VARCHAR(50) PRIMARY KEY,
+    type_id VARCHAR(50) NOT NULL REFERENCES lottery_types (type_id),
+    ticket_name VARCHAR(255) NOT NULL,
+    ticket_price NUMERIC NOT NULL,
+    ticket_supply NUMERIC NOT NULL,
+    betting_rules VARCHAR(1000) NOT NULL,
+    prize_structure VARCHAR(1000) NOT NULL,
+    registered_addr VARCHAR(255) NOT NULL,
+    rollout_contract_address VARCHAR(255) NOT NULL,
+    contract_address VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_lotteries_type_id ON lotteries (type_id);
+CREATE UNIQUE INDEX IF NOT EXISTS uq_lotteries_contract_address ON lotteries (contract_address);
+
+-- 彩票期号表
+CREATE TABLE IF NOT EXISTS lottery_issues (
+    issue_id VARCHAR(50) PRIMARY KEY,
+    lottery_id VARCHAR(50) NOT NULL REFERENCES lotteries (lottery_id),
+    issue_number VARCHAR(50) NOT NULL,
+    sale_end_time TIMESTAMP WITH TIME ZONE NOT NULL,
+    draw_time TIMESTAMP WITH TIME ZONE NOT NULL,
+    status VARCHAR(100) NOT NULL,
+    prize_pool NUMERIC NOT NULL DEFAULT 0,
+    winning_numbers VARCHAR(100),
+    random_seed VARCHAR(100),
+    draw_tx_hash VARCHAR(66),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_lottery_issues_draw_after_sale CHECK (draw_time >= sale_end_time)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS uq_lottery_issues_lottery_number ON lottery_issues (lottery_id, issue_number);
+CREATE INDEX IF NOT EXISTS idx_lottery_issues_status ON lottery_issues (status);
+CREATE INDEX IF NOT EXISTS idx_lottery_issues_sale_end_time ON lottery_issues (sale_end_time);
+
+-- 彩票票据表
+CREATE TABLE IF NOT EXISTS lottery_tickets (
+    ticket_id VARCHAR(50) PRIMARY KEY,
+    issue_id VARCHAR(50) NOT NULL REFERENCES lottery_issues (issue_id),
+    buyer_address VARCHAR(66) NOT NULL,
+    purchase_time TIMESTAMP WITH TIME ZONE NOT NULL,
+    bet_content VARCHAR(100) NOT NULL,
+    purchase_amount NUMERIC NOT NULL,
+    transaction_hash VARCHAR(66),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_lottery_tickets_amount_positive CHECK (purchase_amount > 0)
+);
+CREATE INDEX IF NOT EXISTS idx_lottery_tickets_issue_id ON lottery_tickets (issue_id);
+CREATE INDEX IF NOT EXISTS idx_lottery_tickets_buyer_address ON lottery_tickets (buyer_address);
+CREATE INDEX IF NOT EXISTS idx_lottery_tickets_transaction_hash ON lottery_tickets (transaction_hash);
+
+-- 中奖者表
+CREATE TABLE IF NOT EXISTS winners (
+    winner_id VARCHAR(50) PRIMARY KEY,
+    issue_id VARCHAR(50) NOT NULL REFERENCES lottery_issues (issue_id),
+    ticket_id VARCHAR(50) NOT NULL REFERENCES lottery_tickets (ticket_id),
+    address VARCHAR(66) NOT NULL,
+    prize_level VARCHAR(50) NOT NULL,
+    prize_amount NUMERIC NOT NULL,
+    claim_tx_hash VARCHAR(66),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_winners_issue_id ON winners (issue_id);
+CREATE INDEX IF NOT EXISTS idx_winners_address ON winners (address);
+CREATE UNIQUE INDEX IF NOT EXISTS uq_winners_ticket_level ON winners (ticket_id, prize_level);
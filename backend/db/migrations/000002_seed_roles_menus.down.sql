@@ -0,0 +1,2 @@
+DELETE FROM role_menus WHERE role_menu_id IN (1, 2, 3, 4, 5, 6, 7);
+DELETE FROM roles WHERE role_id IN (1, 2, 3);
@@ -0,0 +1,17 @@
+-- 初始化角色
+INSERT INTO roles (role_id, role_name, role_type, description) VALUES
+    (1, 'admin', 'admin', 'Administrator for all lottery management,can manage all lottery'),
+    (2, 'normal_user', 'user', 'Normal user with limited access'),
+    (3, 'lottery_admin', 'lottery_admin', 'Administrator for only one lottery management,can not manage other lottery')
+ON CONFLICT (role_id) DO NOTHING;
+
+-- 初始化菜单
+INSERT INTO role_menus (role_menu_id, role_id, menu_name, menu_path) VALUES
+    (1, 1, 'lottery_management', '/lottery/manage'),
+    (2, 1, 'purchase_page', '/lottery/purchase'),
+    (3, 1, 'account_management', '/account'),
+    (4, 2, 'purchase_page', '/lottery/purchase'),
+    (5, 2, 'account_management', '/account'),
+    (6, 3, 'lottery_management', '/lottery/manage'),
+    (7, 3, 'account_management', '/account')
+ON CONFLICT (role_menu_id) DO NOTHING;
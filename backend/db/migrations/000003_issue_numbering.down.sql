@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS lottery_issue_sequences;
+DROP INDEX IF EXISTS uq_lottery_issues_idempotency_key;
+ALTER TABLE lottery_issues DROP COLUMN IF EXISTS idempotency_key;
+ALTER TABLE lotteries DROP COLUMN IF EXISTS issue_number_pattern;
@@ -0,0 +1,16 @@
+-- 彩票期号编号规则，为空时使用全局默认规则
+ALTER TABLE lotteries ADD COLUMN IF NOT EXISTS issue_number_pattern VARCHAR(100);
+
+-- 期号创建幂等键
+ALTER TABLE lottery_issues ADD COLUMN IF NOT EXISTS idempotency_key VARCHAR(100);
+CREATE UNIQUE INDEX IF NOT EXISTS uq_lottery_issues_idempotency_key
+    ON lottery_issues (lottery_id, idempotency_key) WHERE idempotency_key IS NOT NULL;
+
+-- 期号序列表，按彩票和周期递增分配序号
+CREATE TABLE IF NOT EXISTS lottery_issue_sequences (
+    lottery_id VARCHAR(50) NOT NULL REFERENCES lotteries (lottery_id) ON DELETE CASCADE,
+    period_key VARCHAR(50) NOT NULL,
+    last_seq BIGINT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (lottery_id, period_key)
+);
@@ -0,0 +1,16 @@
+-- 幂等键表，保存状态变更请求的摘要和响应，用于重复请求回放
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    idempotency_key VARCHAR(100) NOT NULL,
+    method VARCHAR(10) NOT NULL,
+    path VARCHAR(255) NOT NULL,
+    request_hash VARCHAR(64) NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    response_code INTEGER,
+    content_type VARCHAR(100),
+    response_body BYTEA,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (idempotency_key, method, path)
+);
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_expires_at ON idempotency_keys (expires_at);
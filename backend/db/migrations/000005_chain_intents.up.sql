@@ -0,0 +1,15 @@
+-- 链上操作意图表（outbox），用于恢复链上已执行但数据库未写入的操作
+CREATE TABLE IF NOT EXISTS chain_intents (
+    intent_id VARCHAR(50) PRIMARY KEY,
+    operation VARCHAR(50) NOT NULL,
+    reference_id VARCHAR(50) NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    payload TEXT,
+    tx_hash VARCHAR(66),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error VARCHAR(1000),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_chain_intents_status ON chain_intents (status, updated_at);
+CREATE INDEX IF NOT EXISTS idx_chain_intents_reference_id ON chain_intents (reference_id);
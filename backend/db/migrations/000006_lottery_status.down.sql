@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_lotteries_status;
+ALTER TABLE lotteries DROP COLUMN IF EXISTS status;
@@ -0,0 +1,3 @@
+-- 彩票生命周期状态：ACTIVE、PAUSED、TERMINATED、DESTROYED
+ALTER TABLE lotteries ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'ACTIVE';
+CREATE INDEX IF NOT EXISTS idx_lotteries_status ON lotteries (status);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS uq_lottery_issues_lottery_epoch;
+ALTER TABLE lottery_issues DROP COLUMN IF EXISTS epoch;
@@ -0,0 +1,3 @@
+-- 期号绑定合约 epoch，同一彩票的每个 epoch 只能对应一个期号
+ALTER TABLE lottery_issues ADD COLUMN IF NOT EXISTS epoch BIGINT;
+CREATE UNIQUE INDEX IF NOT EXISTS uq_lottery_issues_lottery_epoch ON lottery_issues (lottery_id, epoch) WHERE epoch IS NOT NULL;
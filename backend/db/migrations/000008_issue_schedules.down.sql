@@ -0,0 +1,2 @@
+ALTER TABLE lottery_issues DROP COLUMN IF EXISTS carry_over;
+DROP TABLE IF EXISTS issue_schedules;
@@ -0,0 +1,19 @@
+-- 期号定时计划：每次开奖完成后自动开下一期
+CREATE TABLE IF NOT EXISTS issue_schedules (
+    schedule_id VARCHAR(50) PRIMARY KEY,
+    lottery_id VARCHAR(50) NOT NULL REFERENCES lotteries(lottery_id),
+    frequency VARCHAR(20) NOT NULL,
+    cron_expr VARCHAR(100),
+    draw_at VARCHAR(5),
+    weekday INTEGER NOT NULL DEFAULT 0,
+    time_zone VARCHAR(50) NOT NULL,
+    sale_close_minutes INTEGER NOT NULL DEFAULT 0,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    last_opened_issue_id VARCHAR(50),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_issue_schedules_lottery_id ON issue_schedules (lottery_id);
+
+-- 从上一期结转到本期奖池的金额
+ALTER TABLE lottery_issues ADD COLUMN IF NOT EXISTS carry_over NUMERIC NOT NULL DEFAULT 0;
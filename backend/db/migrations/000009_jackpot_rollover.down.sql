@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS rollover_entries;
+ALTER TABLE lotteries DROP COLUMN IF EXISTS rollover_policy;
@@ -0,0 +1,17 @@
+-- 无人中奖时奖池的处理方式：ROLLOVER、RETURN_TO_OWNER、SPLIT_LOWER_TIERS
+ALTER TABLE lotteries ADD COLUMN IF NOT EXISTS rollover_policy VARCHAR(30) NOT NULL DEFAULT 'ROLLOVER';
+
+-- 奖池结转台账
+CREATE TABLE IF NOT EXISTS rollover_entries (
+    entry_id VARCHAR(50) PRIMARY KEY,
+    lottery_id VARCHAR(50) NOT NULL REFERENCES lotteries(lottery_id),
+    source_issue_id VARCHAR(50) NOT NULL REFERENCES lottery_issues(issue_id),
+    target_issue_id VARCHAR(50) REFERENCES lottery_issues(issue_id),
+    policy VARCHAR(30) NOT NULL,
+    amount NUMERIC NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_rollover_entries_source_issue_id ON rollover_entries (source_issue_id);
+CREATE INDEX IF NOT EXISTS idx_rollover_entries_lottery_status ON rollover_entries (lottery_id, status);
@@ -0,0 +1,22 @@
+-- 开奖随机数证明：VRF 请求、回填随机数、区块哈希及号码映射
+CREATE TABLE IF NOT EXISTS draw_proofs (
+    issue_id VARCHAR(50) PRIMARY KEY REFERENCES lottery_issues(issue_id),
+    lottery_id VARCHAR(50) NOT NULL REFERENCES lotteries(lottery_id),
+    rollout_contract VARCHAR(42) NOT NULL,
+    coordinator_address VARCHAR(42),
+    vrf_request_id VARCHAR(100) NOT NULL,
+    rollout_epoch BIGINT NOT NULL DEFAULT 0,
+    request_tx_hash VARCHAR(66) NOT NULL,
+    request_block_number BIGINT NOT NULL,
+    request_block_hash VARCHAR(66) NOT NULL,
+    fulfill_tx_hash VARCHAR(66),
+    fulfill_block_number BIGINT NOT NULL DEFAULT 0,
+    fulfill_block_hash VARCHAR(66),
+    random_words TEXT,
+    number_range BIGINT NOT NULL,
+    winning_numbers VARCHAR(100) NOT NULL,
+    results_topic VARCHAR(66),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_draw_proofs_vrf_request_id ON draw_proofs (vrf_request_id);
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_winners_payout_status;
+ALTER TABLE winners DROP COLUMN IF EXISTS paid_at;
+ALTER TABLE winners DROP COLUMN IF EXISTS payout_error;
+ALTER TABLE winners DROP COLUMN IF EXISTS payout_attempts;
+ALTER TABLE winners DROP COLUMN IF EXISTS payout_amount;
+ALTER TABLE winners DROP COLUMN IF EXISTS payout_status;
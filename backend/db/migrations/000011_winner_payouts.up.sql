@@ -0,0 +1,7 @@
+-- 派奖状态：PENDING、PAID、FAILED、PENDING_MANUAL、SUBMITTED
+ALTER TABLE winners ADD COLUMN IF NOT EXISTS payout_status VARCHAR(20) NOT NULL DEFAULT 'PENDING';
+ALTER TABLE winners ADD COLUMN IF NOT EXISTS payout_amount NUMERIC NOT NULL DEFAULT 0;
+ALTER TABLE winners ADD COLUMN IF NOT EXISTS payout_attempts INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE winners ADD COLUMN IF NOT EXISTS payout_error VARCHAR(255);
+ALTER TABLE winners ADD COLUMN IF NOT EXISTS paid_at TIMESTAMP WITH TIME ZONE;
+CREATE INDEX IF NOT EXISTS idx_winners_payout_status ON winners (payout_status);
@@ -0,0 +1,7 @@
+ALTER TABLE kyc_data DROP COLUMN IF EXISTS tax_id;
+ALTER TABLE winners DROP COLUMN IF EXISTS tax_rate;
+ALTER TABLE winners DROP COLUMN IF EXISTS tax_jurisdiction;
+ALTER TABLE winners DROP COLUMN IF EXISTS net_amount;
+ALTER TABLE winners DROP COLUMN IF EXISTS withheld_amount;
+ALTER TABLE winners DROP COLUMN IF EXISTS gross_amount;
+DROP TABLE IF EXISTS tax_withholding_rules;
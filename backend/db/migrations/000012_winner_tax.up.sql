@@ -0,0 +1,23 @@
+-- 奖金代扣税规则
+CREATE TABLE IF NOT EXISTS tax_withholding_rules (
+    rule_id VARCHAR(50) PRIMARY KEY,
+    jurisdiction VARCHAR(50) NOT NULL,
+    threshold NUMERIC NOT NULL DEFAULT 0,
+    rate NUMERIC NOT NULL,
+    description VARCHAR(255),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tax_withholding_rules_jurisdiction ON tax_withholding_rules (jurisdiction);
+
+-- 中奖者的税前、代扣、税后金额
+ALTER TABLE winners ADD COLUMN IF NOT EXISTS gross_amount NUMERIC NOT NULL DEFAULT 0;
+ALTER TABLE winners ADD COLUMN IF NOT EXISTS withheld_amount NUMERIC NOT NULL DEFAULT 0;
+ALTER TABLE winners ADD COLUMN IF NOT EXISTS net_amount NUMERIC NOT NULL DEFAULT 0;
+ALTER TABLE winners ADD COLUMN IF NOT EXISTS tax_jurisdiction VARCHAR(50);
+ALTER TABLE winners ADD COLUMN IF NOT EXISTS tax_rate NUMERIC NOT NULL DEFAULT 0;
+-- 已有中奖记录未代扣
+UPDATE winners SET gross_amount = prize_amount, net_amount = prize_amount WHERE gross_amount = 0;
+
+-- 纳税人识别号
+ALTER TABLE kyc_data ADD COLUMN IF NOT EXISTS tax_id VARCHAR(50);
@@ -0,0 +1,58 @@
+-- Webhook 订阅
+CREATE TABLE IF NOT EXISTS webhook_subscriptions (
+    subscription_id VARCHAR(50) PRIMARY KEY,
+    url VARCHAR(500) NOT NULL,
+    secret VARCHAR(100) NOT NULL,
+    event_types VARCHAR(255) NOT NULL,
+    lottery_id VARCHAR(50),
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    description VARCHAR(255),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Webhook 投递，与业务数据在同一事务中写入
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    delivery_id VARCHAR(50) PRIMARY KEY,
+    subscription_id VARCHAR(50) NOT NULL REFERENCES webhook_subscriptions(subscription_id),
+    event_id VARCHAR(50) NOT NULL,
+    event_key VARCHAR(150) NOT NULL,
+    event_type VARCHAR(50) NOT NULL,
+    payload TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    last_status_code INT,
+    last_error VARCHAR(1000),
+    delivered_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+-- 同一事件对同一订阅只投递一次
+CREATE UNIQUE INDEX IF NOT EXISTS idx_webhook_deliveries_subscription_event ON webhook_deliveries (subscription_id, event_key);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due ON webhook_deliveries (status, next_attempt_at);
+
+-- Webhook 投递日志
+CREATE TABLE IF NOT EXISTS webhook_delivery_attempts (
+    attempt_id VARCHAR(50) PRIMARY KEY,
+    delivery_id VARCHAR(50) NOT NULL REFERENCES webhook_deliveries(delivery_id),
+    attempt INT NOT NULL,
+    status_code INT,
+    error VARCHAR(1000),
+    response_body VARCHAR(1000),
+    duration_ms BIGINT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_webhook_delivery_attempts_delivery ON webhook_delivery_attempts (delivery_id);
+
+-- Webhook 死信
+CREATE TABLE IF NOT EXISTS webhook_dead_letters (
+    delivery_id VARCHAR(50) PRIMARY KEY REFERENCES webhook_deliveries(delivery_id),
+    subscription_id VARCHAR(50) NOT NULL,
+    event_type VARCHAR(50) NOT NULL,
+    payload TEXT NOT NULL,
+    attempts INT NOT NULL,
+    last_error VARCHAR(1000),
+    replayed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
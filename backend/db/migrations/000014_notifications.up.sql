@@ -0,0 +1,24 @@
+-- 用户通知偏好
+CREATE TABLE IF NOT EXISTS notification_preferences (
+    customer_address VARCHAR(255) PRIMARY KEY,
+    email_enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    kyc_updates BOOLEAN NOT NULL DEFAULT TRUE,
+    purchase_receipts BOOLEAN NOT NULL DEFAULT FALSE,
+    prize_alerts BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- 通知发送日志
+CREATE TABLE IF NOT EXISTS notification_logs (
+    log_id VARCHAR(50) PRIMARY KEY,
+    customer_address VARCHAR(255) NOT NULL,
+    channel VARCHAR(20) NOT NULL,
+    template VARCHAR(50) NOT NULL,
+    recipient VARCHAR(255),
+    subject VARCHAR(255),
+    status VARCHAR(20) NOT NULL,
+    error VARCHAR(1000),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_notification_logs_customer ON notification_logs (customer_address, created_at);
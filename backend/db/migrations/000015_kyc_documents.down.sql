@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS document_access_logs;
+DROP TABLE IF EXISTS kyc_documents;
@@ -0,0 +1,26 @@
+-- KYC 证件文件，私有存储，通过短期签名链接访问
+CREATE TABLE IF NOT EXISTS kyc_documents (
+    document_key VARCHAR(255) PRIMARY KEY,
+    storage VARCHAR(20) NOT NULL,
+    content_type VARCHAR(100) NOT NULL,
+    size BIGINT NOT NULL,
+    sha256 VARCHAR(64) NOT NULL,
+    owner_address VARCHAR(255),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_kyc_documents_owner ON kyc_documents (owner_address);
+
+-- KYC 证件访问审计日志
+CREATE TABLE IF NOT EXISTS document_access_logs (
+    log_id VARCHAR(50) PRIMARY KEY,
+    document_key VARCHAR(255) NOT NULL,
+    customer_address VARCHAR(255),
+    accessor_address VARCHAR(255),
+    accessor_role VARCHAR(50),
+    action VARCHAR(20) NOT NULL,
+    reason VARCHAR(255),
+    ip VARCHAR(64),
+    user_agent VARCHAR(255),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_document_access_logs_customer ON document_access_logs (customer_address, created_at);
@@ -0,0 +1,3 @@
+DELETE FROM role_menus WHERE role_menu_id IN (8, 9);
+DELETE FROM roles WHERE role_id = 4;
+DROP TABLE IF EXISTS kyc_reviews;
@@ -0,0 +1,42 @@
+-- KYC 审核队列
+CREATE TABLE IF NOT EXISTS kyc_reviews (
+    customer_address VARCHAR(255) PRIMARY KEY REFERENCES customers (customer_address) ON DELETE CASCADE,
+    status VARCHAR(30) NOT NULL,
+    risk_level VARCHAR(20),
+    assigned_verifier VARCHAR(255),
+    first_approver VARCHAR(255),
+    first_approved_at TIMESTAMP WITH TIME ZONE,
+    submitted_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    decided_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_kyc_reviews_status ON kyc_reviews (status, submitted_at);
+CREATE INDEX IF NOT EXISTS idx_kyc_reviews_assigned_verifier ON kyc_reviews (assigned_verifier);
+
+-- 已提交的 KYC 进入审核队列：已验证的为通过，最近一次审核被拒绝的为拒绝，其余待审核
+INSERT INTO kyc_reviews (customer_address, status, risk_level, submitted_at, decided_at)
+SELECT k.customer_address,
+       CASE
+           WHEN c.is_verified THEN 'APPROVED'
+           WHEN (SELECT h.verify_status FROM kyc_verification_histories h
+                 WHERE h.customer_address = k.customer_address
+                 ORDER BY h.history_id DESC LIMIT 1) = 'Rejected' THEN 'REJECTED'
+           ELSE 'PENDING'
+       END,
+       k.risk_level,
+       COALESCE(k.submission_date, CURRENT_TIMESTAMP),
+       CASE WHEN c.is_verified THEN c.verification_time END
+FROM kyc_data k
+JOIN customers c ON c.customer_address = k.customer_address
+ON CONFLICT (customer_address) DO NOTHING;
+
+-- KYC 审核人员角色
+INSERT INTO roles (role_id, role_name, role_type, description) VALUES
+    (4, 'kyc_verifier', 'verifier', 'KYC verifier, reviews customer submissions')
+ON CONFLICT (role_id) DO NOTHING;
+
+INSERT INTO role_menus (role_menu_id, role_id, menu_name, menu_path) VALUES
+    (8, 4, 'kyc_review', '/kyc/reviews'),
+    (9, 4, 'account_management', '/account')
+ON CONFLICT (role_menu_id) DO NOTHING;
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_customers_kyc_expires_at;
+ALTER TABLE customers DROP COLUMN IF EXISTS kyc_reminded_at;
+ALTER TABLE customers DROP COLUMN IF EXISTS kyc_expires_at;
+ALTER TABLE kyc_data DROP COLUMN IF EXISTS document_expiry_date;
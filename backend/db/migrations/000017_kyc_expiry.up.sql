@@ -0,0 +1,7 @@
+-- 证件到期日
+ALTER TABLE kyc_data ADD COLUMN IF NOT EXISTS document_expiry_date DATE;
+
+-- KYC 有效期，已验证用户的有效期由后台任务按风险等级补算
+ALTER TABLE customers ADD COLUMN IF NOT EXISTS kyc_expires_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE customers ADD COLUMN IF NOT EXISTS kyc_reminded_at TIMESTAMP WITH TIME ZONE;
+CREATE INDEX IF NOT EXISTS idx_customers_kyc_expires_at ON customers (kyc_expires_at);
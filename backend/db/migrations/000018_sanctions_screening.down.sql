@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS screening_hits;
+DROP TABLE IF EXISTS sanctions_entries;
+DROP TABLE IF EXISTS sanctions_lists;
@@ -0,0 +1,39 @@
+-- 制裁名单
+CREATE TABLE IF NOT EXISTS sanctions_lists (
+    list_name VARCHAR(100) PRIMARY KEY,
+    source VARCHAR(500) NOT NULL,
+    checksum VARCHAR(64) NOT NULL,
+    entry_count INTEGER NOT NULL,
+    imported_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS sanctions_entries (
+    entry_id SERIAL PRIMARY KEY,
+    list_name VARCHAR(100) NOT NULL REFERENCES sanctions_lists (list_name) ON DELETE CASCADE,
+    entry_ref VARCHAR(100) NOT NULL,
+    name VARCHAR(255),
+    normalized_name VARCHAR(255),
+    birth_date DATE,
+    wallet_address VARCHAR(255)
+);
+CREATE INDEX IF NOT EXISTS idx_sanctions_entries_list_name ON sanctions_entries (list_name);
+CREATE INDEX IF NOT EXISTS idx_sanctions_entries_wallet_address ON sanctions_entries (wallet_address) WHERE wallet_address <> '';
+
+-- 筛查命中，不引用 customers：注册被拒的钱包也要留痕
+CREATE TABLE IF NOT EXISTS screening_hits (
+    hit_id SERIAL PRIMARY KEY,
+    customer_address VARCHAR(255) NOT NULL,
+    list_name VARCHAR(100) NOT NULL,
+    entry_ref VARCHAR(100) NOT NULL,
+    entry_name VARCHAR(255),
+    match_type VARCHAR(20) NOT NULL,
+    score DOUBLE PRECISION NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    reviewed_by VARCHAR(255),
+    reviewed_at TIMESTAMP WITH TIME ZONE,
+    notes TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+CREATE UNIQUE INDEX IF NOT EXISTS uq_screening_hits_entry ON screening_hits (customer_address, list_name, entry_ref, match_type);
+CREATE INDEX IF NOT EXISTS idx_screening_hits_status ON screening_hits (status, created_at);
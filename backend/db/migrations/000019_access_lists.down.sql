@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS access_list_audits;
+DROP TABLE IF EXISTS access_list_entries;
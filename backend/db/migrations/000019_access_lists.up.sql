@@ -0,0 +1,25 @@
+-- IP 和钱包黑白名单
+CREATE TABLE IF NOT EXISTS access_list_entries (
+    entry_id SERIAL PRIMARY KEY,
+    list_type VARCHAR(10) NOT NULL,
+    kind VARCHAR(10) NOT NULL,
+    value VARCHAR(255) NOT NULL,
+    reason VARCHAR(500),
+    expires_at TIMESTAMP WITH TIME ZONE,
+    created_by VARCHAR(255),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+CREATE UNIQUE INDEX IF NOT EXISTS uq_access_list_entries_value ON access_list_entries (list_type, kind, value);
+
+-- 黑白名单变更审计，条目删除后保留
+CREATE TABLE IF NOT EXISTS access_list_audits (
+    audit_id SERIAL PRIMARY KEY,
+    entry_id INTEGER NOT NULL,
+    action VARCHAR(10) NOT NULL,
+    actor VARCHAR(255) NOT NULL,
+    before TEXT,
+    after TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_access_list_audits_entry_id ON access_list_audits (entry_id, created_at);
@@ -0,0 +1,5 @@
+ALTER TABLE lottery_tickets DROP COLUMN IF EXISTS geo_decision;
+ALTER TABLE lottery_tickets DROP COLUMN IF EXISTS purchase_country;
+ALTER TABLE lottery_tickets DROP COLUMN IF EXISTS purchase_ip;
+ALTER TABLE lotteries DROP COLUMN IF EXISTS blocked_countries;
+ALTER TABLE lotteries DROP COLUMN IF EXISTS allowed_countries;
@@ -0,0 +1,8 @@
+-- 按国家限制购买：每个彩票的允许和禁止国家列表（ISO 3166-1 两位代码，逗号分隔）
+ALTER TABLE lotteries ADD COLUMN IF NOT EXISTS allowed_countries VARCHAR(1000) NOT NULL DEFAULT '';
+ALTER TABLE lotteries ADD COLUMN IF NOT EXISTS blocked_countries VARCHAR(1000) NOT NULL DEFAULT '';
+
+-- 每张彩票记录购买时的 IP、国家和地区判定
+ALTER TABLE lottery_tickets ADD COLUMN IF NOT EXISTS purchase_ip VARCHAR(64);
+ALTER TABLE lottery_tickets ADD COLUMN IF NOT EXISTS purchase_country VARCHAR(2);
+ALTER TABLE lottery_tickets ADD COLUMN IF NOT EXISTS geo_decision VARCHAR(20);
@@ -0,0 +1,27 @@
+-- 登录会话，撤销后其下的刷新令牌和访问令牌全部失效
+CREATE TABLE IF NOT EXISTS auth_sessions (
+    session_id VARCHAR(36) PRIMARY KEY,
+    customer_address VARCHAR(42) NOT NULL,
+    ip VARCHAR(45),
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    revoked_reason VARCHAR(100),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_auth_sessions_customer_address ON auth_sessions (customer_address);
+
+-- 刷新令牌，只保存哈希，每次刷新轮换
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+    token_id VARCHAR(36) PRIMARY KEY,
+    session_id VARCHAR(36) NOT NULL REFERENCES auth_sessions (session_id) ON DELETE CASCADE,
+    token_hash VARCHAR(64) NOT NULL,
+    access_jti VARCHAR(36) NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    used_at TIMESTAMP WITH TIME ZONE,
+    replaced_by VARCHAR(36),
+    created_ip VARCHAR(45),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_refresh_tokens_token_hash ON refresh_tokens (token_hash);
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_session_id ON refresh_tokens (session_id);
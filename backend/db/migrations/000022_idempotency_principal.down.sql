@@ -0,0 +1,5 @@
+-- 幂等键只是短期缓存，回滚时清空以免主键冲突
+DELETE FROM idempotency_keys;
+ALTER TABLE idempotency_keys DROP CONSTRAINT IF EXISTS idempotency_keys_pkey;
+ALTER TABLE idempotency_keys DROP COLUMN IF EXISTS principal;
+ALTER TABLE idempotency_keys ADD PRIMARY KEY (idempotency_key, method, path);
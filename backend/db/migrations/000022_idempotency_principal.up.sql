@@ -0,0 +1,4 @@
+-- 幂等键按调用方隔离：已登录请求为用户钱包地址，匿名请求为客户端 IP，避免他人的幂等键重放自己的响应
+ALTER TABLE idempotency_keys ADD COLUMN IF NOT EXISTS principal VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE idempotency_keys DROP CONSTRAINT IF EXISTS idempotency_keys_pkey;
+ALTER TABLE idempotency_keys ADD PRIMARY KEY (idempotency_key, principal, method, path);
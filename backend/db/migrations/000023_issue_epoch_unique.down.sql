@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_lottery_issues_lottery_epoch;
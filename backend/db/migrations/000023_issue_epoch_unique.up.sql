@@ -0,0 +1,2 @@
+-- 每个合约 epoch 只能对应一个期号，防止并发请求为同一次 Distribute 重复保存期号
+CREATE UNIQUE INDEX IF NOT EXISTS idx_lottery_issues_lottery_epoch ON lottery_issues (lottery_id, epoch) WHERE epoch IS NOT NULL;
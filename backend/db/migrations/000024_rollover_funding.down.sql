@@ -0,0 +1 @@
+ALTER TABLE rollover_entries DROP COLUMN IF EXISTS funding_tx_hash;
@@ -0,0 +1,2 @@
+-- 结转奖池从金库转入下一期合约的交易哈希
+ALTER TABLE rollover_entries ADD COLUMN IF NOT EXISTS funding_tx_hash VARCHAR(66);
@@ -0,0 +1,2 @@
+UPDATE winners SET withheld_amount = tax_owed_amount, net_amount = gross_amount - tax_owed_amount WHERE tax_owed_amount > 0;
+ALTER TABLE winners DROP COLUMN IF EXISTS tax_owed_amount;
@@ -0,0 +1,5 @@
+-- 合约按税前金额派发的奖金无法代扣，应缴税额单独记录
+ALTER TABLE winners ADD COLUMN IF NOT EXISTS tax_owed_amount NUMERIC NOT NULL DEFAULT 0;
+-- 已有记录中由合约派发的奖金并未实际代扣，改记为应缴税额
+UPDATE winners SET tax_owed_amount = withheld_amount, withheld_amount = 0, net_amount = gross_amount
+WHERE withheld_amount > 0 AND payout_status IN ('PENDING', 'PAID') AND payout_attempts = 0;
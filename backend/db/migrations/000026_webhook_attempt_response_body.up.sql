@@ -0,0 +1,2 @@
+-- 订阅方的响应内容不再保存，避免通过投递日志读取内网服务的响应
+ALTER TABLE webhook_delivery_attempts DROP COLUMN IF EXISTS response_body;
@@ -0,0 +1,3 @@
+ALTER TABLE chain_intents DROP COLUMN IF EXISTS nonce;
+ALTER TABLE chain_intents DROP COLUMN IF EXISTS sender;
+ALTER TABLE chain_intents DROP COLUMN IF EXISTS raw_tx;
@@ -0,0 +1,4 @@
+-- 保存已签名交易，交易先落库再广播，恢复任务可重新广播或依据 nonce 判断交易是否已丢弃
+ALTER TABLE chain_intents ADD COLUMN IF NOT EXISTS raw_tx TEXT;
+ALTER TABLE chain_intents ADD COLUMN IF NOT EXISTS sender VARCHAR(42);
+ALTER TABLE chain_intents ADD COLUMN IF NOT EXISTS nonce BIGINT NOT NULL DEFAULT 0;
@@ -0,0 +1,2 @@
+-- 处理中的幂等键增加租约，请求中断未释放幂等键时，租约过期后相同请求可接管该幂等键
+ALTER TABLE idempotency_keys ADD COLUMN IF NOT EXISTS locked_until TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP;
@@ -0,0 +1,2 @@
+ALTER TABLE kyc_reviews DROP COLUMN IF EXISTS risk_assessed_at;
+ALTER TABLE kyc_reviews DROP COLUMN IF EXISTS risk_assessed_by;
@@ -0,0 +1,9 @@
+-- 风险等级由审核人员评估后设置，不再使用用户提交的值；审核通过前必须先评估风险等级
+ALTER TABLE kyc_reviews ADD COLUMN IF NOT EXISTS risk_assessed_by VARCHAR(255);
+ALTER TABLE kyc_reviews ADD COLUMN IF NOT EXISTS risk_assessed_at TIMESTAMP WITH TIME ZONE;
+
+-- 未决的审核使用的是用户提交的风险等级，重置为 High，等待审核人员评估
+UPDATE kyc_reviews SET risk_level = 'High'
+WHERE status IN ('PENDING', 'IN_REVIEW', 'INFO_REQUESTED');
+UPDATE kyc_data SET risk_level = 'High'
+WHERE customer_address IN (SELECT customer_address FROM kyc_reviews WHERE status IN ('PENDING', 'IN_REVIEW', 'INFO_REQUESTED'));
@@ -0,0 +1,85 @@
+// middleware/access_list.go
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"backend/db"
+	"backend/services/accesslist"
+	"backend/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxWalletBody 查找钱包地址时最多读取的请求体长度
+const maxWalletBody = 1 << 20
+
+// walletFields 请求体中可能携带钱包地址的字段
+var walletFields = []string{"wallet_address", "buyer_address", "customer_address"}
+
+// AccessListMiddleware IP 和钱包黑白名单中间件，用于登录和购票等路由
+//
+// IP 取自 c.ClientIP()，钱包地址由 requestWallets 获取，任一地址被拒绝时返回 403。
+func AccessListMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		wallets, err := requestWallets(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, utils.ErrorResponse(utils.ErrCodeInvalidInput, "Failed to read request body", err.Error()))
+			c.Abort()
+			return
+		}
+
+		service := accesslist.NewAccessListService(db.DB)
+		if err := service.Check(c.Request.Context(), c.ClientIP(), wallets...); err != nil {
+			status := http.StatusInternalServerError
+			if appErr, ok := err.(*utils.Error); ok && appErr.Code == http.StatusForbidden {
+				status = http.StatusForbidden
+			}
+			c.JSON(status, utils.NewErrorResponse(err))
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// requestWallets 返回请求携带的所有钱包地址：AuthMiddleware 写入的 customer_address，以及 JSON 请求体中
+// 出现的每一个钱包字段，避免用另一个字段携带被拒绝的地址绕过检查。请求体读取后会还原供后续处理使用
+func requestWallets(c *gin.Context) ([]string, error) {
+	var wallets []string
+	if address, ok := c.Get("customer_address"); ok {
+		if wallet, _ := address.(string); wallet != "" {
+			wallets = append(wallets, wallet)
+		}
+	}
+	if c.Request.Body == nil {
+		return wallets, nil
+	}
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxWalletBody))
+	if err != nil {
+		return nil, err
+	}
+	// 超出读取长度的部分原样保留
+	c.Request.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), c.Request.Body))
+	var fields map[string]interface{}
+	if json.Unmarshal(body, &fields) == nil {
+		for _, field := range walletFields {
+			if value, ok := fields[field].(string); ok && value != "" {
+				wallets = append(wallets, value)
+			}
+		}
+	}
+	return wallets, nil
+}
+
+// requestWallet 返回请求的主钱包地址，即 requestWallets 中的第一个，没有时返回空
+func requestWallet(c *gin.Context) (string, error) {
+	wallets, err := requestWallets(c)
+	if err != nil || len(wallets) == 0 {
+		return "", err
+	}
+	return wallets[0], nil
+}
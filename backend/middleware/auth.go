@@ -2,12 +2,13 @@
 package middleware
 
 import (
-	"backend/config"
+	"backend/db"
+	"backend/services/authtoken"
 	"backend/utils"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
 )
 
 // AuthMiddleware 定义验证 JWT 的中间件
@@ -27,20 +28,22 @@ func AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 		token := authHeader[7:]
-		// 验证 JWT 令牌
-		claims := jwt.MapClaims{}
-		parsedToken, err := jwt.ParseWithClaims(token, claims, func(token *jwt.Token) (interface{}, error) {
-			return []byte(config.AppConfig.JWTSecret), nil
-		})
-		if err != nil || !parsedToken.Valid {
-			c.JSON(http.StatusForbidden, utils.ErrorResponse(utils.ErrCodeForbidden, "Invalid token", err.Error()))
+		// 验证访问令牌的签名、签发者、有效期，并检查所属会话未被撤销
+		claims, err := authtoken.NewTokenService(db.DB).Authenticate(c.Request.Context(), token, time.Now())
+		if err != nil {
+			status := http.StatusForbidden
+			if appErr, ok := err.(*utils.Error); ok && appErr.Code == http.StatusInternalServerError {
+				status = http.StatusInternalServerError
+			}
+			c.JSON(status, utils.ErrorResponse(utils.ErrCodeForbidden, "Invalid token", err.Error()))
 			c.Abort()
 			return
 		}
 		// 将用户信息存入上下文
-		c.Set("customer_address", claims["customer_address"])
-		c.Set("email", claims["email"])
-		c.Set("role", claims["role"])
+		c.Set("customer_address", claims.CustomerAddress)
+		c.Set("role", claims.Role)
+		c.Set("session_id", claims.SessionID)
+		c.Set("jti", claims.ID)
 		c.Next()
 	}
 }
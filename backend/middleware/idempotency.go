@@ -0,0 +1,161 @@
+// middleware/idempotency.go
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"backend/db"
+	"backend/models"
+	"backend/services/idempotency"
+	"backend/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// IdempotencyKeyHeader 客户端传入的幂等键请求头
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader 标记响应为重放的历史响应
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+	// maxIdempotencyKeyLength 幂等键最大长度，与表字段长度一致
+	maxIdempotencyKeyLength = 100
+	// idempotencyStoreTimeout 保存或释放幂等键的超时时间
+	idempotencyStoreTimeout = 5 * time.Second
+)
+
+// responseCaptureWriter 在写出响应的同时保存响应体
+type responseCaptureWriter struct {
+	gin.ResponseWriter
+	body *bytes.Buffer
+}
+
+func (w *responseCaptureWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *responseCaptureWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// IdempotencyMiddleware 幂等中间件，处理带 Idempotency-Key 请求头的写请求
+//
+// 幂等键按调用方隔离（见 idempotencyPrincipal）。首次请求正常执行并保存 2xx、4xx 响应，5xx 响应不保存；相同幂等键和相同请求体的重复请求直接重放已保存的响应，
+// 若首次请求仍在处理中则返回 409（租约过期后由重试请求接管）；相同幂等键但请求体不同则返回 422。
+// 未携带 Idempotency-Key 的请求不受影响。
+func IdempotencyMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if key == "" {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			c.JSON(http.StatusBadRequest, utils.ErrorResponse(utils.ErrCodeInvalidInput, "Idempotency-Key is too long", nil))
+			c.Abort()
+			return
+		}
+
+		// 读取请求体计算摘要，并还原请求体供后续处理使用
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, utils.ErrorResponse(utils.ErrCodeInvalidInput, "Failed to read request body", err.Error()))
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		principal := idempotencyPrincipal(c)
+		method := c.Request.Method
+		path := c.Request.URL.Path
+		hash := sha256.New()
+		hash.Write([]byte(method + " " + path + "\n"))
+		hash.Write(body)
+		requestHash := hex.EncodeToString(hash.Sum(nil))
+
+		service := idempotency.NewIdempotencyService(db.DB)
+		ctx := c.Request.Context()
+		record, acquired, err := service.Begin(ctx, key, principal, method, path, requestHash)
+		if errors.Is(err, idempotency.ErrKeyReused) {
+			c.JSON(http.StatusUnprocessableEntity, utils.ErrorResponse(utils.ErrCodeInvalidInput, "Idempotency-Key has already been used for a different request", nil))
+			c.Abort()
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(err))
+			c.Abort()
+			return
+		}
+		if !acquired {
+			if record.Status == models.IdempotencyStatusInProgress {
+				c.JSON(http.StatusConflict, utils.ErrorResponse(utils.ErrCodeConflict, "A request with the same Idempotency-Key is still in progress", nil))
+				c.Abort()
+				return
+			}
+			utils.Logger.Info("Replaying idempotent response", "idempotency_key", key, "path", path)
+			c.Header(IdempotentReplayedHeader, "true")
+			c.Data(record.ResponseCode, record.ContentType, record.ResponseBody)
+			c.Abort()
+			return
+		}
+
+		// 保存和释放幂等键不使用请求上下文：客户端断开后请求上下文已取消，但仍需保存响应或释放幂等键，
+		// 否则幂等键会停留在处理中，直到租约过期
+		storeCtx := func() (context.Context, context.CancelFunc) {
+			return context.WithTimeout(context.WithoutCancel(ctx), idempotencyStoreTimeout)
+		}
+
+		// 处理过程中发生 panic 或返回 5xx 时释放幂等键，允许客户端使用同一幂等键重试
+		completed := false
+		defer func() {
+			if completed {
+				return
+			}
+			releaseCtx, cancel := storeCtx()
+			defer cancel()
+			if err := service.Release(releaseCtx, key, principal, method, path); err != nil {
+				utils.Logger.Error("Failed to release idempotency key, retries are refused until its lease expires",
+					"idempotency_key", key, "path", path, "error", err)
+			}
+		}()
+
+		writer := &responseCaptureWriter{ResponseWriter: c.Writer, body: &bytes.Buffer{}}
+		c.Writer = writer
+		c.Next()
+
+		// 服务端错误（5xx）不保存，释放幂等键允许客户端重试；2xx 和 4xx 响应保存用于重放
+		if writer.Status() >= http.StatusInternalServerError {
+			return
+		}
+		completeCtx, cancel := storeCtx()
+		defer cancel()
+		if err := service.Complete(completeCtx, key, principal, method, path, writer.Status(), writer.Header().Get("Content-Type"), writer.body.Bytes()); err != nil {
+			utils.Logger.Error("Failed to store idempotent response, releasing the key",
+				"idempotency_key", key, "path", path, "status", writer.Status(), "error", err)
+			return
+		}
+		completed = true
+	}
+}
+
+// idempotencyPrincipal 返回幂等键所属的调用方：已认证请求为用户钱包地址，匿名请求为 "ip:" 加客户端 IP
+func idempotencyPrincipal(c *gin.Context) string {
+	if address, ok := c.Get("customer_address"); ok {
+		if wallet, _ := address.(string); wallet != "" {
+			return strings.ToLower(wallet)
+		}
+	}
+	if subject := tokenSubject(c); subject != "" {
+		return strings.ToLower(subject)
+	}
+	return "ip:" + c.ClientIP()
+}
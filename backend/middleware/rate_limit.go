@@ -0,0 +1,73 @@
+// middleware/rate_limit.go
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"backend/config"
+	"backend/db"
+	"backend/services/authtoken"
+	"backend/services/ratelimit"
+	"backend/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimitMiddleware 令牌桶限流中间件，policy 为 ratelimit 中的路由策略（登录、注册、购票）
+//
+// 按 IP、钱包地址（见 requestWallet）和 JWT 的 subject 分别计数，任一超限即返回 429 并带上 Retry-After。
+// 存储不可用时记录日志并放行，避免 Redis 故障导致无法登录和购票。
+func RateLimitMiddleware(policy string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !config.AppConfig.RateLimitEnabled {
+			c.Next()
+			return
+		}
+		wallet, err := requestWallet(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, utils.ErrorResponse(utils.ErrCodeInvalidInput, "Failed to read request body", err.Error()))
+			c.Abort()
+			return
+		}
+
+		identity := ratelimit.Identity{IP: c.ClientIP(), Wallet: wallet, Subject: tokenSubject(c)}
+		result, err := ratelimit.DefaultLimiter().Allow(c.Request.Context(), policy, identity, time.Now())
+		if err != nil {
+			utils.Logger.Error("Rate limit store failed, request allowed", "policy", policy, "error", err)
+			c.Next()
+			return
+		}
+
+		c.Header("X-RateLimit-Limit", strconv.Itoa(result.Limit))
+		c.Header("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		if !result.Allowed {
+			retryAfter := int(math.Ceil(result.RetryAfter.Seconds()))
+			if retryAfter < 1 {
+				retryAfter = 1
+			}
+			c.Header("Retry-After", strconv.Itoa(retryAfter))
+			utils.Logger.Warn("Request rate limited", "policy", policy, "ip", identity.IP, "wallet", identity.Wallet, "subject", identity.Subject, "retry_after", retryAfter)
+			c.JSON(http.StatusTooManyRequests, utils.ErrorResponse(utils.ErrCodeTooManyRequests, "Too many requests, please retry later", gin.H{"retry_after": retryAfter}))
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// tokenSubject 返回请求携带的有效访问令牌的 subject（即用户钱包地址）；没有或无效的令牌返回空
+func tokenSubject(c *gin.Context) string {
+	authHeader := c.GetHeader("Authorization")
+	if !strings.HasPrefix(authHeader, "Bearer ") {
+		return ""
+	}
+	claims, err := authtoken.NewTokenService(db.DB).ParseAccessToken(authHeader[7:], time.Now())
+	if err != nil {
+		return ""
+	}
+	return claims.Subject
+}
@@ -0,0 +1,48 @@
+// models/access_list.go
+package models
+
+import "time"
+
+const (
+	// AccessListAllow 白名单：存在白名单时只允许名单内的 IP 或钱包，且白名单优先于黑名单
+	AccessListAllow = "ALLOW"
+	// AccessListDeny 黑名单
+	AccessListDeny = "DENY"
+)
+
+const (
+	// AccessListKindIP IP 地址或 CIDR 网段
+	AccessListKindIP = "IP"
+	// AccessListKindWallet 钱包地址
+	AccessListKindWallet = "WALLET"
+)
+
+const (
+	AccessListAuditCreate = "CREATE"
+	AccessListAuditUpdate = "UPDATE"
+	AccessListAuditDelete = "DELETE"
+)
+
+// AccessListEntry IP 和钱包黑白名单表模型
+type AccessListEntry struct {
+	EntryID   uint       `gorm:"primaryKey;autoIncrement" json:"entry_id"`
+	ListType  string     `gorm:"size:10;not null" json:"list_type"` // ALLOW 或 DENY
+	Kind      string     `gorm:"size:10;not null" json:"kind"`      // IP 或 WALLET
+	Value     string     `gorm:"size:255;not null" json:"value"`    // 规范化的 CIDR（单个 IP 保存为 /32 或 /128）或小写钱包地址
+	Reason    string     `gorm:"size:500" json:"reason"`
+	ExpiresAt *time.Time `gorm:"type:timestamptz" json:"expires_at"` // 为空表示永久有效
+	CreatedBy string     `gorm:"size:255" json:"created_by"`
+	CreatedAt time.Time  `gorm:"type:timestamptz;default:now()" json:"created_at"`
+	UpdatedAt time.Time  `gorm:"type:timestamptz;default:now()" json:"updated_at"`
+}
+
+// AccessListAudit 黑白名单变更审计表模型
+type AccessListAudit struct {
+	AuditID   uint      `gorm:"primaryKey;autoIncrement" json:"audit_id"`
+	EntryID   uint      `gorm:"not null" json:"entry_id"`
+	Action    string    `gorm:"size:10;not null" json:"action"` // CREATE、UPDATE 或 DELETE
+	Actor     string    `gorm:"size:255;not null" json:"actor"`
+	Before    string    `gorm:"type:text" json:"before"` // 变更前的条目（JSON），新建时为空
+	After     string    `gorm:"type:text" json:"after"`  // 变更后的条目（JSON），删除时为空
+	CreatedAt time.Time `gorm:"type:timestamptz;default:now()" json:"created_at"`
+}
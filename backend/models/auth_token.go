@@ -0,0 +1,33 @@
+// models/auth_token.go
+package models
+
+import "time"
+
+// AuthSession 登录会话表模型，一次登录对应一个会话，刷新令牌轮换时会话不变
+//
+// 会话被撤销后，其下的刷新令牌和已签发的访问令牌都会失效。
+type AuthSession struct {
+	SessionID       string     `gorm:"primaryKey;size:36" json:"session_id"`
+	CustomerAddress string     `gorm:"size:42;not null;index" json:"customer_address"`
+	IP              string     `gorm:"size:45" json:"ip"`                           // 登录时的 IP
+	ExpiresAt       time.Time  `gorm:"type:timestamptz;not null" json:"expires_at"` // 最长有效期，到期后需要重新登录
+	RevokedAt       *time.Time `gorm:"type:timestamptz" json:"revoked_at"`          // 撤销时间，为空表示有效
+	RevokedReason   string     `gorm:"size:100" json:"revoked_reason"`              // 撤销原因：退出登录、管理员撤销、刷新令牌被重复使用等
+	CreatedAt       time.Time  `gorm:"type:timestamptz;default:now()" json:"created_at"`
+	UpdatedAt       time.Time  `gorm:"type:timestamptz;default:now()" json:"updated_at"` // 最近一次刷新的时间
+}
+
+// RefreshToken 刷新令牌表模型，只保存令牌的 SHA-256 哈希
+//
+// 每次刷新都会换发新的刷新令牌，已使用的刷新令牌再次出现时视为泄露，撤销整个会话。
+type RefreshToken struct {
+	TokenID    string     `gorm:"primaryKey;size:36" json:"token_id"`
+	SessionID  string     `gorm:"size:36;not null;index" json:"session_id"`
+	TokenHash  string     `gorm:"size:64;not null;uniqueIndex" json:"-"`
+	AccessJTI  string     `gorm:"size:36;not null" json:"access_jti"` // 与该刷新令牌一同签发的访问令牌的 jti
+	ExpiresAt  time.Time  `gorm:"type:timestamptz;not null" json:"expires_at"`
+	UsedAt     *time.Time `gorm:"type:timestamptz" json:"used_at"` // 换发新令牌的时间，为空表示尚未使用
+	ReplacedBy string     `gorm:"size:36" json:"replaced_by"`      // 换发的新刷新令牌
+	CreatedIP  string     `gorm:"size:45" json:"created_ip"`
+	CreatedAt  time.Time  `gorm:"type:timestamptz;default:now()" json:"created_at"`
+}
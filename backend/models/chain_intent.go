@@ -0,0 +1,39 @@
+// models/chain_intent.go
+package models
+
+import "time"
+
+const (
+	// ChainIntentOpCreateLottery 部署彩票合约并保存彩票记录
+	ChainIntentOpCreateLottery = "CREATE_LOTTERY"
+	// ChainIntentOpPurchaseTicket 链上购买彩票并保存票据记录
+	ChainIntentOpPurchaseTicket = "PURCHASE_TICKET"
+)
+
+const (
+	// ChainIntentStatusPending 已记录意图，交易尚未发出
+	ChainIntentStatusPending = "PENDING"
+	// ChainIntentStatusSubmitted 交易已签名并保存，随后广播，等待回执或数据库写入
+	ChainIntentStatusSubmitted = "SUBMITTED"
+	// ChainIntentStatusCompleted 链上交易与数据库写入均已完成
+	ChainIntentStatusCompleted = "COMPLETED"
+	// ChainIntentStatusFailed 交易失败或未发出，无需补写数据库
+	ChainIntentStatusFailed = "FAILED"
+)
+
+// ChainIntent 链上操作意图表（outbox），在发起链上交易前写入，数据库写入完成后标记完成
+type ChainIntent struct {
+	IntentID    string    `gorm:"primaryKey;size:50" json:"intent_id"`
+	Operation   string    `gorm:"size:50;not null" json:"operation"`
+	ReferenceID string    `gorm:"size:50;not null" json:"reference_id"` // 关联业务记录 ID，如 lottery_id、ticket_id
+	Status      string    `gorm:"size:20;not null" json:"status"`
+	Payload     string    `gorm:"type:text" json:"payload"` // 交易确认后需要写入数据库的记录（JSON）
+	TxHash      string    `gorm:"size:66" json:"tx_hash"`
+	RawTx       string    `gorm:"type:text" json:"-"`              // 已签名交易（十六进制），交易未被节点收到时由恢复任务重新广播
+	Sender      string    `gorm:"size:42" json:"sender"`           // 交易发送方地址
+	Nonce       uint64    `gorm:"not null;default:0" json:"nonce"` // 交易 nonce，发送方 nonce 超过该值且交易不存在时视为交易已丢弃
+	Attempts    int       `gorm:"not null;default:0" json:"attempts"`
+	LastError   string    `gorm:"size:1000" json:"last_error"`
+	CreatedAt   time.Time `gorm:"type:timestamptz;default:now()" json:"created_at"`
+	UpdatedAt   time.Time `gorm:"type:timestamptz;default:now()" json:"updated_at"`
+}
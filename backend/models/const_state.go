@@ -20,3 +20,67 @@ const (
 	//IssueStatusDrawn 已开奖
 	IssueStatusDrawn = "DRAWN"
 )
+
+const (
+	//ScheduleFrequencyDaily 每天开奖
+	ScheduleFrequencyDaily = "DAILY"
+	//ScheduleFrequencyWeekly 每周开奖
+	ScheduleFrequencyWeekly = "WEEKLY"
+	//ScheduleFrequencyCron 按 cron 表达式开奖
+	ScheduleFrequencyCron = "CRON"
+)
+
+const (
+	//RolloverPolicyRollover 结转到下一期奖池，开下一期时由金库转入合约
+	RolloverPolicyRollover = "ROLLOVER"
+	//RolloverPolicyReturnToOwner 退还给彩票所有者（合约 clear() 的默认行为）
+	RolloverPolicyReturnToOwner = "RETURN_TO_OWNER"
+	//RolloverPolicySplitLowerTiers 分配给命中部分号码的低等奖
+	RolloverPolicySplitLowerTiers = "SPLIT_LOWER_TIERS"
+)
+
+const (
+	//RolloverStatusPending 等待结转到下一期
+	RolloverStatusPending = "PENDING"
+	//RolloverStatusApplied 已从金库转入下一期合约并计入其奖池
+	RolloverStatusApplied = "APPLIED"
+	//RolloverStatusReturned 已退还给所有者
+	RolloverStatusReturned = "RETURNED"
+	//RolloverStatusDistributed 已分配给低等奖
+	RolloverStatusDistributed = "DISTRIBUTED"
+)
+
+const (
+	//PayoutStatusPending 等待确认链上派奖结果
+	PayoutStatusPending = "PENDING"
+	//PayoutStatusPaid 已派奖
+	PayoutStatusPaid = "PAID"
+	//PayoutStatusFailed rolloutCallback 中代币转账失败（RolloutCallbakTXFailed），可由金库补发
+	PayoutStatusFailed = "FAILED"
+	//PayoutStatusPendingManual 合约不派发的奖金（如低等奖），等待运营从金库发放
+	PayoutStatusPendingManual = "PENDING_MANUAL"
+	//PayoutStatusSubmitted 金库补发交易已发送，等待确认
+	PayoutStatusSubmitted = "SUBMITTED"
+)
+
+const (
+	//LotteryStatusActive 正常销售
+	LotteryStatusActive = "ACTIVE"
+	//LotteryStatusPaused 暂停销售
+	LotteryStatusPaused = "PAUSED"
+	//LotteryStatusTerminated 合约已终止（Terminal）
+	LotteryStatusTerminated = "TERMINATED"
+	//LotteryStatusDestroyed 合约已销毁
+	LotteryStatusDestroyed = "DESTROYED"
+)
+
+const (
+	//GeoDecisionAllowed GeoIP 解析出国家且该国家允许购买
+	GeoDecisionAllowed = "ALLOWED"
+	//GeoDecisionUnknown GeoIP 无法解析国家（如内网 IP），按配置放行
+	GeoDecisionUnknown = "UNKNOWN"
+	//GeoDecisionUnchecked 未配置 GeoIP 数据库，未检查
+	GeoDecisionUnchecked = "UNCHECKED"
+	//GeoDecisionBlocked 国家不允许购买，拒绝（只出现在日志中）
+	GeoDecisionBlocked = "BLOCKED"
+)
@@ -0,0 +1,38 @@
+// models/document.go
+package models
+
+import "time"
+
+const (
+	// DocumentAccessURLIssued 签发了短期访问链接
+	DocumentAccessURLIssued = "URL_ISSUED"
+	// DocumentAccessDownloaded 通过签名链接下载了文件（仅本地存储可记录）
+	DocumentAccessDownloaded = "DOWNLOADED"
+	// DocumentAccessDenied 无权访问或签名无效
+	DocumentAccessDenied = "DENIED"
+)
+
+// KYCDocument KYC 证件文件表模型，KYCData.FilePath 保存 DocumentKey
+type KYCDocument struct {
+	DocumentKey  string    `gorm:"primaryKey;size:255" json:"document_key"` // 不透明的存储键，如 kyc/<uuid>
+	Storage      string    `gorm:"size:20;not null" json:"storage"`         // s3 或 local
+	ContentType  string    `gorm:"size:100;not null" json:"content_type"`
+	Size         int64     `gorm:"not null" json:"size"`
+	SHA256       string    `gorm:"size:64;not null" json:"sha256"`
+	OwnerAddress string    `gorm:"size:255" json:"owner_address"` // 注册时认领，认领后不能被其他用户使用
+	CreatedAt    time.Time `gorm:"type:timestamptz;default:now()" json:"created_at"`
+}
+
+// DocumentAccessLog KYC 证件访问审计日志表模型
+type DocumentAccessLog struct {
+	LogID           string    `gorm:"primaryKey;size:50" json:"log_id"`
+	DocumentKey     string    `gorm:"size:255;not null" json:"document_key"`
+	CustomerAddress string    `gorm:"size:255" json:"customer_address"` // 证件所属用户
+	AccessorAddress string    `gorm:"size:255" json:"accessor_address"` // 访问者，签名链接下载时为签发对象
+	AccessorRole    string    `gorm:"size:50" json:"accessor_role"`
+	Action          string    `gorm:"size:20;not null" json:"action"`
+	Reason          string    `gorm:"size:255" json:"reason"`
+	IP              string    `gorm:"size:64" json:"ip"`
+	UserAgent       string    `gorm:"size:255" json:"user_agent"`
+	CreatedAt       time.Time `gorm:"type:timestamptz;default:now()" json:"created_at"`
+}
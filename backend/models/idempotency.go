@@ -0,0 +1,28 @@
+// models/idempotency.go
+package models
+
+import "time"
+
+const (
+	// IdempotencyStatusInProgress 请求处理中
+	IdempotencyStatusInProgress = "IN_PROGRESS"
+	// IdempotencyStatusCompleted 请求已完成，响应已保存
+	IdempotencyStatusCompleted = "COMPLETED"
+)
+
+// IdempotencyKey 幂等键表模型，保存请求摘要和首次请求的响应
+type IdempotencyKey struct {
+	IdempotencyKey string    `gorm:"primaryKey;size:100" json:"idempotency_key"`
+	Principal      string    `gorm:"primaryKey;size:255" json:"principal"` // 调用方：用户钱包地址，匿名请求为 "ip:" 加客户端 IP
+	Method         string    `gorm:"primaryKey;size:10" json:"method"`
+	Path           string    `gorm:"primaryKey;size:255" json:"path"`
+	RequestHash    string    `gorm:"size:64;not null" json:"request_hash"`
+	Status         string    `gorm:"size:20;not null" json:"status"`
+	ResponseCode   int       `json:"response_code"`
+	ContentType    string    `gorm:"size:100" json:"content_type"`
+	ResponseBody   []byte    `gorm:"type:bytea" json:"-"`
+	ExpiresAt      time.Time `gorm:"type:timestamptz;not null" json:"expires_at"`
+	LockedUntil    time.Time `gorm:"type:timestamptz;not null;default:now()" json:"locked_until"` // 处理中租约到期时间，过期后相同请求可接管
+	CreatedAt      time.Time `gorm:"type:timestamptz;default:now()" json:"created_at"`
+	UpdatedAt      time.Time `gorm:"type:timestamptz;default:now()" json:"updated_at"`
+}
@@ -0,0 +1,50 @@
+// models/kyc_review.go
+package models
+
+import "time"
+
+const (
+	// KYCReviewStatusPending 已提交，等待分配审核人员
+	KYCReviewStatusPending = "PENDING"
+	// KYCReviewStatusInReview 已分配审核人员，审核中
+	KYCReviewStatusInReview = "IN_REVIEW"
+	// KYCReviewStatusInfoRequested 退回用户补充资料
+	KYCReviewStatusInfoRequested = "INFO_REQUESTED"
+	// KYCReviewStatusAwaitingSecondApproval 高风险用户已有一名审核人员通过，等待另一名审核人员复核
+	KYCReviewStatusAwaitingSecondApproval = "AWAITING_SECOND_APPROVAL"
+	// KYCReviewStatusApproved 审核通过
+	KYCReviewStatusApproved = "APPROVED"
+	// KYCReviewStatusRejected 审核拒绝
+	KYCReviewStatusRejected = "REJECTED"
+	// KYCReviewStatusExpired 证件到期或到了定期复核时间，需要重新提交
+	KYCReviewStatusExpired = "EXPIRED"
+)
+
+// KYCVerificationHistory.VerifyStatus 的取值，审核流程的每一步追加一条记录
+const (
+	KYCVerifyStatusPending       = "Pending"
+	KYCVerifyStatusAssigned      = "Assigned"
+	KYCVerifyStatusRiskAssessed  = "RiskAssessed"
+	KYCVerifyStatusInfoRequested = "InfoRequested"
+	KYCVerifyStatusResubmitted   = "Resubmitted"
+	KYCVerifyStatusFirstApproval = "FirstApproval"
+	KYCVerifyStatusApproved      = "Approved"
+	KYCVerifyStatusRejected      = "Rejected"
+	KYCVerifyStatusExpired       = "Expired"
+)
+
+// KYCReview KYC 审核队列表模型，每个用户一条，记录当前审核状态
+type KYCReview struct {
+	CustomerAddress  string     `gorm:"primaryKey;size:255" json:"customer_address"`
+	Status           string     `gorm:"size:30;not null" json:"status"`
+	RiskLevel        string     `gorm:"size:20" json:"risk_level"`        // 风险等级，提交时为 High，由审核人员评估后设置，High 需要两名审核人员通过
+	RiskAssessedBy   string     `gorm:"size:255" json:"risk_assessed_by"` // 设置风险等级的审核人员，为空时不能审核通过
+	RiskAssessedAt   *time.Time `gorm:"type:timestamptz" json:"risk_assessed_at"`
+	AssignedVerifier string     `gorm:"size:255" json:"assigned_verifier"` // 当前负责的审核人员
+	FirstApprover    string     `gorm:"size:255" json:"first_approver"`    // 高风险用户的第一名审核通过人员
+	FirstApprovedAt  *time.Time `gorm:"type:timestamptz" json:"first_approved_at"`
+	SubmittedAt      time.Time  `gorm:"type:timestamptz;not null" json:"submitted_at"`
+	DecidedAt        *time.Time `gorm:"type:timestamptz" json:"decided_at"` // 通过或拒绝的时间
+	CreatedAt        time.Time  `gorm:"type:timestamptz;default:now()" json:"created_at"`
+	UpdatedAt        time.Time  `gorm:"type:timestamptz;default:now()" json:"updated_at"`
+}
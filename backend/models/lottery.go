@@ -27,6 +27,11 @@ type Lottery struct {
 	RegisteredAddr         string      `gorm:"size:255;not null" json:"registered_addr"`
 	RolloutContractAddress string      `gorm:"size:255;not null" json:"rollout_contract_address"`
 	ContractAddress        string      `gorm:"size:255;not null" json:"contract_address"`
+	IssueNumberPattern     string      `gorm:"size:100" json:"issue_number_pattern"`
+	Status                 string      `gorm:"size:20;not null;default:ACTIVE" json:"status"`
+	RolloverPolicy         string      `gorm:"size:30;not null;default:ROLLOVER" json:"rollover_policy"` // 无人中奖时奖池的处理方式
+	AllowedCountries       string      `gorm:"size:1000;not null;default:''" json:"allowed_countries"`   // 允许购买的国家（ISO 3166-1 两位代码，逗号分隔），为空表示不限制
+	BlockedCountries       string      `gorm:"size:1000;not null;default:''" json:"blocked_countries"`   // 禁止购买的国家，优先于允许列表
 	CreatedAt              time.Time   `gorm:"type:timestamptz;default:now()" json:"created_at"`
 	UpdatedAt              time.Time   `gorm:"type:timestamptz;default:now()" json:"updated_at"`
 	LotteryType            LotteryType `gorm:"foreignKey:TypeID;references:TypeID"`
@@ -44,11 +49,85 @@ type LotteryIssue struct {
 	WinningNumbers string    `gorm:"size:100" json:"winning_numbers"`
 	RandomSeed     string    `gorm:"size:100" json:"random_seed"`
 	DrawTxHash     string    `gorm:"size:66" json:"draw_tx_hash"`
+	Epoch          *int64    `json:"epoch"`                                             // 期号对应的合约 epoch，开奖结果按 epoch 归属到期号
+	CarryOver      float64   `gorm:"type:numeric;not null;default:0" json:"carry_over"` // 从上一期结转到本期奖池的金额
+	IdempotencyKey *string   `gorm:"size:100" json:"-"`
 	CreatedAt      time.Time `gorm:"type:timestamptz;default:now()" json:"created_at"`
 	UpdatedAt      time.Time `gorm:"type:timestamptz;default:now()" json:"updated_at"`
 	Lottery        Lottery   `gorm:"foreignKey:LotteryID;references:LotteryID"`
 }
 
+// LotteryIssueSequence 期号序列表模型，按彩票和周期（如日期）递增
+type LotteryIssueSequence struct {
+	LotteryID string    `gorm:"primaryKey;size:50" json:"lottery_id"`
+	PeriodKey string    `gorm:"primaryKey;size:50" json:"period_key"`
+	LastSeq   int64     `gorm:"not null;default:0" json:"last_seq"`
+	UpdatedAt time.Time `gorm:"type:timestamptz;default:now()" json:"updated_at"`
+}
+
+// IssueSchedule 期号定时计划表模型，每个彩票最多一个计划
+type IssueSchedule struct {
+	ScheduleID        string    `gorm:"primaryKey;size:50" json:"schedule_id"`
+	LotteryID         string    `gorm:"size:50;not null;uniqueIndex" json:"lottery_id"`
+	Frequency         string    `gorm:"size:20;not null" json:"frequency"`            // DAILY、WEEKLY、CRON
+	CronExpr          string    `gorm:"size:100" json:"cron_expr"`                    // Frequency 为 CRON 时的标准五段 cron 表达式
+	DrawAt            string    `gorm:"size:5" json:"draw_at"`                        // DAILY、WEEKLY 的开奖时刻，格式 HH:mm
+	Weekday           int       `gorm:"not null;default:0" json:"weekday"`            // WEEKLY 的开奖日，0 表示周日
+	TimeZone          string    `gorm:"size:50;not null" json:"time_zone"`            // IANA 时区，如 Asia/Shanghai
+	SaleCloseMinutes  int       `gorm:"not null;default:0" json:"sale_close_minutes"` // 开奖前多少分钟停止销售
+	Enabled           bool      `gorm:"not null" json:"enabled"`
+	LastOpenedIssueID string    `gorm:"size:50" json:"last_opened_issue_id"`
+	CreatedAt         time.Time `gorm:"type:timestamptz;default:now()" json:"created_at"`
+	UpdatedAt         time.Time `gorm:"type:timestamptz;default:now()" json:"updated_at"`
+}
+
+// RolloverEntry 奖池结转台账，记录无人中奖期号的奖池去向
+type RolloverEntry struct {
+	EntryID       string    `gorm:"primaryKey;size:50" json:"entry_id"`
+	LotteryID     string    `gorm:"size:50;not null" json:"lottery_id"`
+	SourceIssueID string    `gorm:"size:50;not null;uniqueIndex" json:"source_issue_id"` // 无人中奖的期号
+	TargetIssueID *string   `gorm:"size:50" json:"target_issue_id"`                      // 结转到的期号，ROLLOVER 应用后填写
+	Policy        string    `gorm:"size:30;not null" json:"policy"`
+	Amount        float64   `gorm:"type:numeric;not null" json:"amount"`
+	Status        string    `gorm:"size:20;not null" json:"status"`
+	FundingTxHash string    `gorm:"size:66" json:"funding_tx_hash"` // 从金库转入下一期合约的交易，合约余额已足够时为空
+	CreatedAt     time.Time `gorm:"type:timestamptz;default:now()" json:"created_at"`
+	UpdatedAt     time.Time `gorm:"type:timestamptz;default:now()" json:"updated_at"`
+}
+
+// TaxWithholdingRule 奖金代扣税规则，按 KYC 国籍匹配辖区，税前奖金达到起征额时按税率全额代扣
+type TaxWithholdingRule struct {
+	RuleID       string    `gorm:"primaryKey;size:50" json:"rule_id"`
+	Jurisdiction string    `gorm:"size:50;not null;uniqueIndex" json:"jurisdiction"` // 与 KYCData.Nationality 比较（不区分大小写），DEFAULT 适用于其他辖区
+	Threshold    float64   `gorm:"type:numeric;not null;default:0" json:"threshold"` // 起征额（代币），税前奖金不低于该值时代扣
+	Rate         float64   `gorm:"type:numeric;not null" json:"rate"`                // 代扣税率，0.24 表示 24%
+	Description  string    `gorm:"size:255" json:"description"`
+	CreatedAt    time.Time `gorm:"type:timestamptz;default:now()" json:"created_at"`
+	UpdatedAt    time.Time `gorm:"type:timestamptz;default:now()" json:"updated_at"`
+}
+
+// DrawProof 开奖随机数证明，记录 VRF 请求、回填的随机数及其到中奖号码的映射，供任何人复核开奖
+type DrawProof struct {
+	IssueID            string    `gorm:"primaryKey;size:50" json:"issue_id"`
+	LotteryID          string    `gorm:"size:50;not null" json:"lottery_id"`
+	RolloutContract    string    `gorm:"size:42;not null" json:"rollout_contract"`
+	CoordinatorAddress string    `gorm:"size:42" json:"coordinator_address"` // 发出 RandomWordsRequested 的 VRF 协调器
+	VRFRequestID       string    `gorm:"column:vrf_request_id;size:100;not null" json:"vrf_request_id"`
+	RolloutEpoch       int64     `gorm:"not null;default:0" json:"rollout_epoch"` // DiceRolled 事件中的 rollout 合约轮次
+	RequestTxHash      string    `gorm:"size:66;not null" json:"request_tx_hash"` // rolloutCall 交易
+	RequestBlockNumber uint64    `gorm:"not null" json:"request_block_number"`
+	RequestBlockHash   string    `gorm:"size:66;not null" json:"request_block_hash"`
+	FulfillTxHash      string    `gorm:"size:66" json:"fulfill_tx_hash"` // 回填随机数的交易
+	FulfillBlockNumber uint64    `gorm:"not null;default:0" json:"fulfill_block_number"`
+	FulfillBlockHash   string    `gorm:"size:66" json:"fulfill_block_hash"`
+	RandomWords        string    `gorm:"type:text" json:"random_words"` // 逗号分隔的十进制随机数，无法从回填交易解码时为空
+	NumberRange        int64     `gorm:"not null" json:"number_range"`  // 号码映射规则 word % NumberRange + 1
+	WinningNumbers     string    `gorm:"size:100;not null" json:"winning_numbers"`
+	ResultsTopic       string    `gorm:"size:66" json:"results_topic"` // DiceLanded 事件中 indexed 结果数组的哈希
+	CreatedAt          time.Time `gorm:"type:timestamptz;default:now()" json:"created_at"`
+	UpdatedAt          time.Time `gorm:"type:timestamptz;default:now()" json:"updated_at"`
+}
+
 // LotteryTicket 彩票票据表模型
 type LotteryTicket struct {
 	TicketID        string       `gorm:"primaryKey;size:50" json:"ticket_id"`
@@ -58,6 +137,9 @@ type LotteryTicket struct {
 	BetContent      string       `gorm:"size:100;not null" json:"bet_content"`
 	PurchaseAmount  float64      `gorm:"type:numeric;not null" json:"purchase_amount"`
 	TransactionHash string       `gorm:"size:66" json:"transaction_hash"`
+	PurchaseIP      string       `gorm:"size:64" json:"purchase_ip"`     // 购买请求的客户端 IP
+	PurchaseCountry string       `gorm:"size:2" json:"purchase_country"` // GeoIP 解析的国家，未知时为空
+	GeoDecision     string       `gorm:"size:20" json:"geo_decision"`    // 购买时的地区判定，见 GeoDecision 常量
 	CreatedAt       time.Time    `gorm:"type:timestamptz;default:now()" json:"created_at"`
 	UpdatedAt       time.Time    `gorm:"type:timestamptz;default:now()" json:"updated_at"`
 	LotteryIssue    LotteryIssue `gorm:"foreignKey:IssueID;references:IssueID"`
@@ -65,15 +147,29 @@ type LotteryTicket struct {
 
 // Winner 中奖者表模型
 type Winner struct {
-	WinnerID    string    `gorm:"primaryKey;size:50" json:"winner_id"`
-	IssueID     string    `gorm:"size:50;not null" json:"issue_id"`
-	TicketID    string    `gorm:"size:50;not null" json:"ticket_id"`
-	Address     string    `gorm:"size:66;not null" json:"address"`
-	PrizeLevel  string    `gorm:"size:50;not null" json:"prize_level"`
-	PrizeAmount float64   `gorm:"type:numeric;not null" json:"prize_amount"`
-	ClaimTxHash string    `gorm:"size:66" json:"claim_tx_hash"`
-	CreatedAt   time.Time `gorm:"type:timestamptz;default:now()" json:"created_at"`
-	UpdatedAt   time.Time `gorm:"type:timestamptz;default:now()" json:"updated_at"`
+	WinnerID    string  `gorm:"primaryKey;size:50" json:"winner_id"`
+	IssueID     string  `gorm:"size:50;not null" json:"issue_id"`
+	TicketID    string  `gorm:"size:50;not null" json:"ticket_id"`
+	Address     string  `gorm:"size:66;not null" json:"address"`
+	PrizeLevel  string  `gorm:"size:50;not null" json:"prize_level"`
+	PrizeAmount float64 `gorm:"type:numeric;not null" json:"prize_amount"`
+	ClaimTxHash string  `gorm:"size:66" json:"claim_tx_hash"` // 派奖交易：rolloutCallback 或金库补发的转账交易
+
+	PayoutStatus   string     `gorm:"size:20;not null;default:PENDING" json:"payout_status"` // PENDING、PAID、FAILED、PENDING_MANUAL、SUBMITTED
+	PayoutAmount   float64    `gorm:"type:numeric;not null;default:0" json:"payout_amount"`  // 待补发的奖金（代币），链上派奖失败或低等奖时填写
+	PayoutAttempts int        `gorm:"not null;default:0" json:"payout_attempts"`
+	PayoutError    string     `gorm:"size:255" json:"payout_error"`
+	PaidAt         *time.Time `gorm:"type:timestamptz" json:"paid_at"`
+
+	GrossAmount     float64 `gorm:"type:numeric;not null;default:0" json:"gross_amount"`    // 税前奖金
+	WithheldAmount  float64 `gorm:"type:numeric;not null;default:0" json:"withheld_amount"` // 代扣税额，仅由金库按税后金额发放的奖金
+	NetAmount       float64 `gorm:"type:numeric;not null;default:0" json:"net_amount"`      // 实际发放给中奖者的奖金（税前奖金减代扣税额）
+	TaxOwedAmount   float64 `gorm:"type:numeric;not null;default:0" json:"tax_owed_amount"` // 合约按税前金额派发、未能代扣的应缴税额
+	TaxJurisdiction string  `gorm:"size:50" json:"tax_jurisdiction"`                        // 适用的代扣规则辖区
+	TaxRate         float64 `gorm:"type:numeric;not null;default:0" json:"tax_rate"`        // 适用的代扣税率，0.24 表示 24%
+
+	CreatedAt time.Time `gorm:"type:timestamptz;default:now()" json:"created_at"`
+	UpdatedAt time.Time `gorm:"type:timestamptz;default:now()" json:"updated_at"`
 
 	LotteryIssue  LotteryIssue  `gorm:"foreignKey:IssueID;references:IssueID"`
 	LotteryTicket LotteryTicket `gorm:"foreignKey:TicketID;references:TicketID"`
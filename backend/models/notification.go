@@ -0,0 +1,37 @@
+// models/notification.go
+package models
+
+import "time"
+
+const (
+	// NotificationStatusSent 已交给发送通道
+	NotificationStatusSent = "SENT"
+	// NotificationStatusFailed 发送失败
+	NotificationStatusFailed = "FAILED"
+	// NotificationStatusSkipped 用户未开启该类通知或没有联系方式，未发送
+	NotificationStatusSkipped = "SKIPPED"
+)
+
+// NotificationPreference 用户通知偏好表模型，没有记录时使用默认偏好
+type NotificationPreference struct {
+	CustomerAddress  string    `gorm:"primaryKey;size:255" json:"customer_address"`
+	EmailEnabled     bool      `gorm:"not null" json:"email_enabled"`     // 总开关
+	KYCUpdates       bool      `gorm:"not null" json:"kyc_updates"`       // KYC 审核结果
+	PurchaseReceipts bool      `gorm:"not null" json:"purchase_receipts"` // 购票回执
+	PrizeAlerts      bool      `gorm:"not null" json:"prize_alerts"`      // 中奖通知
+	CreatedAt        time.Time `gorm:"type:timestamptz;default:now()" json:"created_at"`
+	UpdatedAt        time.Time `gorm:"type:timestamptz;default:now()" json:"updated_at"`
+}
+
+// NotificationLog 通知发送日志表模型
+type NotificationLog struct {
+	LogID           string    `gorm:"primaryKey;size:50" json:"log_id"`
+	CustomerAddress string    `gorm:"size:255;not null" json:"customer_address"`
+	Channel         string    `gorm:"size:20;not null" json:"channel"`
+	Template        string    `gorm:"size:50;not null" json:"template"`
+	Recipient       string    `gorm:"size:255" json:"recipient"`
+	Subject         string    `gorm:"size:255" json:"subject"`
+	Status          string    `gorm:"size:20;not null" json:"status"`
+	Error           string    `gorm:"size:1000" json:"error"`
+	CreatedAt       time.Time `gorm:"type:timestamptz;default:now()" json:"created_at"`
+}
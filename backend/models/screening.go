@@ -0,0 +1,57 @@
+// models/screening.go
+package models
+
+import "time"
+
+const (
+	// ScreeningMatchName 姓名（及出生日期）模糊匹配
+	ScreeningMatchName = "NAME"
+	// ScreeningMatchWallet 钱包地址精确匹配
+	ScreeningMatchWallet = "WALLET"
+)
+
+const (
+	// ScreeningHitOpen 待合规人员处理，阻止注册、登录和购票
+	ScreeningHitOpen = "OPEN"
+	// ScreeningHitConfirmed 确认命中，继续阻止
+	ScreeningHitConfirmed = "CONFIRMED"
+	// ScreeningHitCleared 确认为误报，不再阻止，重新筛查时也不会再次打开
+	ScreeningHitCleared = "CLEARED"
+)
+
+// SanctionsList 制裁名单表模型，每个导入的名单文件一条
+type SanctionsList struct {
+	ListName   string    `gorm:"primaryKey;size:100" json:"list_name"` // 文件名（不含扩展名）
+	Source     string    `gorm:"size:500;not null" json:"source"`      // 文件路径
+	Checksum   string    `gorm:"size:64;not null" json:"checksum"`     // 文件内容的 SHA-256，变化时重新导入并重新筛查
+	EntryCount int       `gorm:"not null" json:"entry_count"`
+	ImportedAt time.Time `gorm:"type:timestamptz;not null" json:"imported_at"`
+}
+
+// SanctionsEntry 制裁名单条目表模型
+type SanctionsEntry struct {
+	EntryID        uint       `gorm:"primaryKey;autoIncrement" json:"entry_id"`
+	ListName       string     `gorm:"size:100;not null" json:"list_name"`
+	EntryRef       string     `gorm:"size:100;not null" json:"entry_ref"` // 名单内的编号，没有时使用行号
+	Name           string     `gorm:"size:255" json:"name"`
+	NormalizedName string     `gorm:"size:255" json:"normalized_name"` // 小写、去除变音符号和标点、按词排序
+	BirthDate      *time.Time `gorm:"type:date" json:"birth_date"`
+	WalletAddress  string     `gorm:"size:255" json:"wallet_address"` // 小写
+}
+
+// ScreeningHit 筛查命中表模型，同一用户对同一名单条目只记录一次
+type ScreeningHit struct {
+	HitID           uint       `gorm:"primaryKey;autoIncrement" json:"hit_id"`
+	CustomerAddress string     `gorm:"size:255;not null" json:"customer_address"` // 被筛查的钱包地址，注册被拒时可能没有用户记录
+	ListName        string     `gorm:"size:100;not null" json:"list_name"`
+	EntryRef        string     `gorm:"size:100;not null" json:"entry_ref"`
+	EntryName       string     `gorm:"size:255" json:"entry_name"`
+	MatchType       string     `gorm:"size:20;not null" json:"match_type"`
+	Score           float64    `gorm:"not null" json:"score"` // 姓名相似度 0-1，钱包匹配为 1
+	Status          string     `gorm:"size:20;not null" json:"status"`
+	ReviewedBy      string     `gorm:"size:255" json:"reviewed_by"`
+	ReviewedAt      *time.Time `gorm:"type:timestamptz" json:"reviewed_at"`
+	Notes           string     `gorm:"type:text" json:"notes"`
+	CreatedAt       time.Time  `gorm:"type:timestamptz;default:now()" json:"created_at"`
+	UpdatedAt       time.Time  `gorm:"type:timestamptz;default:now()" json:"updated_at"`
+}
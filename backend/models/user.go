@@ -14,6 +14,8 @@ type Customer struct {
 	RegistrationTime time.Time                `gorm:"type:timestamptz;default:now()" json:"registration_time"`
 	RoleID           int                      `gorm:"not null" json:"role_id"`
 	AssignedDate     time.Time                `gorm:"type:timestamptz;default:now()" json:"assigned_date"`
+	KYCExpiresAt     *time.Time               `gorm:"type:timestamptz" json:"kyc_expires_at"`  // KYC 有效期截止时间，取证件到期日和按风险等级计算的复核日期中较早者
+	KYCRemindedAt    *time.Time               `gorm:"type:timestamptz" json:"kyc_reminded_at"` // 已发送即将到期提醒的时间
 	CreatedAt        time.Time                `gorm:"type:timestamptz;default:now()" json:"created_at"`
 	UpdatedAt        time.Time                `gorm:"type:timestamptz;default:now()" json:"updated_at"`
 	KYCData          KYCData                  `gorm:"-" json:"kyc_data"`          // KYC 数据，忽略 GORM 映射
@@ -23,27 +25,29 @@ type Customer struct {
 
 // KYCData KYC 数据表模型
 type KYCData struct {
-	CustomerAddress    string    `gorm:"primaryKey;size:255" json:"customer_address"`
-	Name               string    `gorm:"size:100" json:"name"`
-	BirthDate          time.Time `gorm:"type:date" json:"birth_date"`
-	Nationality        string    `gorm:"size:50" json:"nationality"`
-	ResidentialAddress string    `gorm:"type:text" json:"residential_address"`
-	PhoneNumber        string    `gorm:"size:20" json:"phone_number"`
-	Email              string    `gorm:"size:255" json:"email"`
-	DocumentType       string    `gorm:"size:50" json:"document_type"`
-	DocumentNumber     string    `gorm:"size:50" json:"document_number"`
-	FilePath           string    `gorm:"type:text" json:"file_path"`
-	SubmissionDate     time.Time `gorm:"type:timestamptz;default:now()" json:"submission_date"`
-	RiskLevel          string    `gorm:"size:20" json:"risk_level"`
-	SourceOfFunds      string    `gorm:"type:text" json:"source_of_funds"`
-	Occupation         string    `gorm:"size:100" json:"occupation"`
-	CreatedAt          time.Time `gorm:"type:timestamptz;default:now()" json:"created_at"`
-	UpdatedAt          time.Time `gorm:"type:timestamptz;default:now()" json:"updated_at"`
+	CustomerAddress    string     `gorm:"primaryKey;size:255" json:"customer_address"`
+	Name               string     `gorm:"size:100" json:"name"`
+	BirthDate          time.Time  `gorm:"type:date" json:"birth_date"`
+	Nationality        string     `gorm:"size:50" json:"nationality"`
+	ResidentialAddress string     `gorm:"type:text" json:"residential_address"`
+	PhoneNumber        string     `gorm:"size:20" json:"phone_number"`
+	Email              string     `gorm:"size:255" json:"email"`
+	DocumentType       string     `gorm:"size:50" json:"document_type"`
+	DocumentNumber     string     `gorm:"size:50" json:"document_number"`
+	DocumentExpiryDate *time.Time `gorm:"type:date" json:"document_expiry_date"` // 证件到期日
+	FilePath           string     `gorm:"type:text" json:"file_path"`
+	SubmissionDate     time.Time  `gorm:"type:timestamptz;default:now()" json:"submission_date"`
+	RiskLevel          string     `gorm:"size:20" json:"risk_level"`
+	SourceOfFunds      string     `gorm:"type:text" json:"source_of_funds"`
+	Occupation         string     `gorm:"size:100" json:"occupation"`
+	TaxID              string     `gorm:"size:50" json:"tax_id"` // 纳税人识别号，用于中奖代扣申报
+	CreatedAt          time.Time  `gorm:"type:timestamptz;default:now()" json:"created_at"`
+	UpdatedAt          time.Time  `gorm:"type:timestamptz;default:now()" json:"updated_at"`
 }
 
 // KYCVerificationHistory 表示 KYC 验证历史表结构
 type KYCVerificationHistory struct {
-	HistoryID        int       `gorm:"primaryKey;autoIncrement:false" json:"history_id"`
+	HistoryID        int       `gorm:"primaryKey" json:"history_id"`
 	CustomerAddress  string    `gorm:"size:255;not null" json:"customer_address"`
 	VerifyStatus     string    `gorm:"size:50" json:"verify_status"`
 	VerifierAddress  string    `gorm:"size:255" json:"verifier_address"`
@@ -0,0 +1,78 @@
+// models/webhook.go
+package models
+
+import "time"
+
+const (
+	// WebhookEventIssueOpened 新期号开售
+	WebhookEventIssueOpened = "issue.opened"
+	// WebhookEventIssueSalesClosed 期号停止销售（到达截止时间或开始开奖）
+	WebhookEventIssueSalesClosed = "issue.sales_closed"
+	// WebhookEventIssueDrawn 期号开奖完成
+	WebhookEventIssueDrawn = "issue.drawn"
+	// WebhookEventWinnerRecorded 记录了一名中奖者
+	WebhookEventWinnerRecorded = "winner.recorded"
+)
+
+const (
+	// WebhookDeliveryStatusPending 等待投递或等待重试
+	WebhookDeliveryStatusPending = "PENDING"
+	// WebhookDeliveryStatusDelivered 对方返回 2xx
+	WebhookDeliveryStatusDelivered = "DELIVERED"
+	// WebhookDeliveryStatusDead 重试次数用尽，已转入死信表
+	WebhookDeliveryStatusDead = "DEAD"
+)
+
+// WebhookSubscription Webhook 订阅表模型
+type WebhookSubscription struct {
+	SubscriptionID string    `gorm:"primaryKey;size:50" json:"subscription_id"`
+	URL            string    `gorm:"size:500;not null" json:"url"`
+	Secret         string    `gorm:"size:100;not null" json:"-"`           // HMAC 签名密钥，仅在创建时返回
+	EventTypes     string    `gorm:"size:255;not null" json:"event_types"` // 订阅的事件类型，逗号分隔
+	LotteryID      string    `gorm:"size:50" json:"lottery_id"`            // 为空时订阅所有彩票
+	Active         bool      `gorm:"not null" json:"active"`
+	Description    string    `gorm:"size:255" json:"description"`
+	CreatedAt      time.Time `gorm:"type:timestamptz;default:now()" json:"created_at"`
+	UpdatedAt      time.Time `gorm:"type:timestamptz;default:now()" json:"updated_at"`
+}
+
+// WebhookDelivery Webhook 投递表模型，每个事件对每个订阅生成一条，与业务数据在同一事务中写入
+type WebhookDelivery struct {
+	DeliveryID     string     `gorm:"primaryKey;size:50" json:"delivery_id"`
+	SubscriptionID string     `gorm:"size:50;not null" json:"subscription_id"`
+	EventID        string     `gorm:"size:50;not null" json:"event_id"`   // 同一事件投递给多个订阅时相同
+	EventKey       string     `gorm:"size:150;not null" json:"event_key"` // 事件去重键，如 issue.drawn:<issue_id>
+	EventType      string     `gorm:"size:50;not null" json:"event_type"`
+	Payload        string     `gorm:"type:text;not null" json:"payload"`
+	Status         string     `gorm:"size:20;not null" json:"status"`
+	Attempts       int        `gorm:"not null;default:0" json:"attempts"`
+	NextAttemptAt  time.Time  `gorm:"type:timestamptz;not null" json:"next_attempt_at"`
+	LastStatusCode int        `json:"last_status_code"`
+	LastError      string     `gorm:"size:1000" json:"last_error"`
+	DeliveredAt    *time.Time `gorm:"type:timestamptz" json:"delivered_at"`
+	CreatedAt      time.Time  `gorm:"type:timestamptz;default:now()" json:"created_at"`
+	UpdatedAt      time.Time  `gorm:"type:timestamptz;default:now()" json:"updated_at"`
+}
+
+// WebhookDeliveryAttempt Webhook 投递日志，记录每一次请求的结果
+type WebhookDeliveryAttempt struct {
+	AttemptID  string    `gorm:"primaryKey;size:50" json:"attempt_id"`
+	DeliveryID string    `gorm:"size:50;not null" json:"delivery_id"`
+	Attempt    int       `gorm:"not null" json:"attempt"`
+	StatusCode int       `json:"status_code"`
+	Error      string    `gorm:"size:1000" json:"error"`
+	DurationMs int64     `json:"duration_ms"`
+	CreatedAt  time.Time `gorm:"type:timestamptz;default:now()" json:"created_at"`
+}
+
+// WebhookDeadLetter Webhook 死信表，重试次数用尽的投递，可由运营重新投递
+type WebhookDeadLetter struct {
+	DeliveryID     string     `gorm:"primaryKey;size:50" json:"delivery_id"`
+	SubscriptionID string     `gorm:"size:50;not null" json:"subscription_id"`
+	EventType      string     `gorm:"size:50;not null" json:"event_type"`
+	Payload        string     `gorm:"type:text;not null" json:"payload"`
+	Attempts       int        `gorm:"not null" json:"attempts"`
+	LastError      string     `gorm:"size:1000" json:"last_error"`
+	ReplayedAt     *time.Time `gorm:"type:timestamptz" json:"replayed_at"`
+	CreatedAt      time.Time  `gorm:"type:timestamptz;default:now()" json:"created_at"`
+}
@@ -4,6 +4,7 @@ package routes
 import (
 	"backend/controllers"
 	"backend/middleware"
+	"backend/services/ratelimit"
 	"time"
 
 	"github.com/gin-contrib/cors"
@@ -15,29 +16,121 @@ func SetupOpRoutes(r *gin.Engine) {
 
 	// 配置 CORS 中间件
 	config := cors.Config{
-		AllowOrigins:     []string{"http://localhost:3000"},                   // 允许的来源，设置为你的前端地址
-		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}, // 允许的 HTTP 方法
-		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"}, // 允许的请求头
-		ExposeHeaders:    []string{"Content-Length"},                          // 暴露给前端的响应头
-		AllowCredentials: true,                                                // 是否允许发送凭证（如 cookies）
-		MaxAge:           12 * time.Hour,                                      // 预检请求（OPTIONS）的缓存时间
+		AllowOrigins:     []string{"http://localhost:3000"},                                                                              // 允许的来源，设置为你的前端地址
+		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},                                                            // 允许的 HTTP 方法
+		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", "Idempotency-Key"},                                         // 允许的请求头
+		ExposeHeaders:    []string{"Content-Length", "Idempotent-Replayed", "Retry-After", "X-RateLimit-Limit", "X-RateLimit-Remaining"}, // 暴露给前端的响应头
+		AllowCredentials: true,                                                                                                           // 是否允许发送凭证（如 cookies）
+		MaxAge:           12 * time.Hour,                                                                                                 // 预检请求（OPTIONS）的缓存时间
 	}
 
 	// 应用 CORS 中间件
 	r.Use(cors.New(config))
 
-	r.POST("/login", controllers.Login)
+	r.POST("/login", middleware.RateLimitMiddleware(ratelimit.PolicyLogin), middleware.AccessListMiddleware(), controllers.Login)
 
 	// 稳定币管理相关
 	// 增加/设置稳定币
-	r.POST("/stablecoin", controllers.SetStableCoin)
+	r.POST("/stablecoin", middleware.IdempotencyMiddleware(), controllers.SetStableCoin)
 	// 删除稳定币
 	r.DELETE("/stablecoin", controllers.RemoveStableCoin)
 
+	// 彩票生命周期管理：暂停/恢复销售、终止（Ready -> Terminal）、销毁合约，仅管理员可用
+	r.POST("/lottery/lottery/v2/:lottery_id/pause", middleware.AuthMiddleware(), middleware.IdempotencyMiddleware(), controllers.PauseLottery)
+	r.POST("/lottery/lottery/v2/:lottery_id/resume", middleware.AuthMiddleware(), middleware.IdempotencyMiddleware(), controllers.ResumeLottery)
+	r.POST("/lottery/lottery/v2/:lottery_id/terminate", middleware.AuthMiddleware(), middleware.IdempotencyMiddleware(), controllers.TerminateLottery)
+	r.POST("/lottery/lottery/v2/:lottery_id/destroy", middleware.AuthMiddleware(), middleware.IdempotencyMiddleware(), controllers.DestroyLottery)
+	// 无人中奖时奖池的处理方式：结转、退还所有者、分配给低等奖，仅管理员可用
+	r.POST("/lottery/lottery/v2/:lottery_id/rollover-policy", middleware.AuthMiddleware(), middleware.IdempotencyMiddleware(), controllers.SetRolloverPolicy)
+	// 按国家限制购买和登录：允许和禁止的国家列表，仅管理员可用
+	r.POST("/lottery/lottery/v2/:lottery_id/jurisdictions", middleware.AuthMiddleware(), middleware.IdempotencyMiddleware(), controllers.SetJurisdictions)
+
+	// 期号定时计划：开奖完成后自动开下一期，仅管理员可用
+	schedules := r.Group("/lottery/schedules/v2")
+	schedules.Use(middleware.AuthMiddleware())
+	{
+		schedules.POST("", middleware.IdempotencyMiddleware(), controllers.SaveIssueSchedule)
+		schedules.GET("", controllers.ListIssueSchedules)
+		schedules.DELETE("/:schedule_id", controllers.DisableIssueSchedule)
+	}
+
+	// 派奖：从金库补发链上派奖失败或需人工发放的奖金，仅管理员可用
+	r.POST("/lottery/winners/v2/:winner_id/retry-payout", middleware.AuthMiddleware(), middleware.IdempotencyMiddleware(), controllers.RetryWinnerPayout)
+
+	// 奖金代扣税：按辖区配置代扣规则，导出客户年度中奖对账单（含姓名和纳税人识别号），仅管理员可用
+	taxRules := r.Group("/lottery/tax-rules/v2")
+	taxRules.Use(middleware.AuthMiddleware())
+	{
+		taxRules.POST("", middleware.IdempotencyMiddleware(), controllers.SaveTaxRule)
+		taxRules.GET("", controllers.ListTaxRules)
+		taxRules.DELETE("/:rule_id", controllers.DeleteTaxRule)
+	}
+	r.GET("/lottery/winners/v2/statements/:customer_address", middleware.AuthMiddleware(), controllers.ExportWinningsStatement)
+
+	// Webhook：期号开售、停售、开奖完成、记录中奖者时回调订阅方，失败按指数退避重试，用尽后转入死信，仅管理员可用
+	webhooks := r.Group("/webhooks/v2")
+	webhooks.Use(middleware.AuthMiddleware())
+	{
+		webhooks.POST("", middleware.IdempotencyMiddleware(), controllers.CreateWebhook)
+		webhooks.GET("", controllers.ListWebhooks)
+		webhooks.DELETE("/:subscription_id", controllers.DeleteWebhook)
+		webhooks.GET("/:subscription_id/deliveries", controllers.ListWebhookDeliveries)
+		webhooks.GET("/dead-letters", controllers.ListWebhookDeadLetters)
+		webhooks.POST("/dead-letters/:delivery_id/replay", middleware.IdempotencyMiddleware(), controllers.ReplayWebhookDeadLetter)
+	}
+
+	// 通知发送日志：KYC 审核结果、购票回执、中奖通知，仅管理员可用
+	r.GET("/notifications/v2/logs", middleware.AuthMiddleware(), controllers.ListNotificationLogs)
+
+	// KYC 证件：审核人员获取短期签名链接，管理员查询证件访问审计日志
+	r.GET("/customers/:customer_address/document-url", middleware.AuthMiddleware(), controllers.GetCustomerDocumentURL)
+	r.GET("/documents/v2/content", controllers.GetDocumentContent)
+	r.GET("/documents/v2/access-logs", middleware.AuthMiddleware(), controllers.ListDocumentAccessLogs)
+
+	// KYC 审核队列：分配审核人员、退回补充资料、通过（高风险用户需两名不同审核人员）、拒绝
+	reviews := r.Group("/kyc/reviews")
+	reviews.Use(middleware.AuthMiddleware())
+	{
+		reviews.GET("", controllers.ListKYCReviews)
+		reviews.GET("/:customer_address", controllers.GetKYCReview)
+		reviews.POST("/:customer_address/assign", middleware.IdempotencyMiddleware(), controllers.AssignKYCReview)
+		reviews.POST("/:customer_address/risk-level", middleware.IdempotencyMiddleware(), controllers.SetKYCRiskLevel)
+		reviews.POST("/:customer_address/request-info", middleware.IdempotencyMiddleware(), controllers.RequestKYCInfo)
+		reviews.POST("/:customer_address/approve", middleware.IdempotencyMiddleware(), controllers.ApproveKYCReview)
+		reviews.POST("/:customer_address/reject", middleware.IdempotencyMiddleware(), controllers.RejectKYCReview)
+	}
+
+	// 制裁名单筛查：处理命中、查看和导入名单
+	screeningGroup := r.Group("/screening")
+	screeningGroup.Use(middleware.AuthMiddleware())
+	{
+		screeningGroup.GET("/hits", controllers.ListScreeningHits)
+		screeningGroup.POST("/hits/:hit_id/confirm", middleware.IdempotencyMiddleware(), controllers.ConfirmScreeningHit)
+		screeningGroup.POST("/hits/:hit_id/clear", middleware.IdempotencyMiddleware(), controllers.ClearScreeningHit)
+		screeningGroup.GET("/lists", controllers.ListSanctionsLists)
+		screeningGroup.POST("/lists/refresh", middleware.IdempotencyMiddleware(), controllers.RefreshSanctionsLists)
+	}
+
+	// IP 和钱包黑白名单管理，仅管理员可用，每次修改记录审计
+	accessLists := r.Group("/access-lists")
+	accessLists.Use(middleware.AuthMiddleware())
+	{
+		accessLists.GET("", controllers.ListAccessListEntries)
+		accessLists.POST("", middleware.IdempotencyMiddleware(), controllers.CreateAccessListEntry)
+		accessLists.PUT("/:entry_id", controllers.UpdateAccessListEntry)
+		accessLists.DELETE("/:entry_id", controllers.DeleteAccessListEntry)
+		accessLists.GET("/audit", controllers.ListAccessListAudit)
+	}
+
+	// 刷新令牌本身即凭证，访问令牌过期后仍可刷新，不经过 AuthMiddleware
+	r.POST("/auth/refresh", controllers.RefreshToken)    // 用刷新令牌换发新的访问令牌和刷新令牌
+	r.GET("/.well-known/jwks.json", controllers.GetJWKS) // RS256/EdDSA 访问令牌的验证公钥
+
 	auth := r.Group("/auth")
 	auth.Use(middleware.AuthMiddleware())
 	{
-		auth.POST("/refresh", controllers.RefreshToken)
-		auth.POST("/verify", controllers.VerifyCustomer)
+		auth.POST("/logout", controllers.Logout)         // 退出登录，撤销当前会话或全部会话
+		auth.POST("/revoke", controllers.RevokeSessions) // 管理员撤销指定用户的所有会话
+		auth.POST("/verify", middleware.IdempotencyMiddleware(), controllers.VerifyCustomer)
 	}
 }
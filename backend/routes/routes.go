@@ -5,6 +5,7 @@ import (
 	"backend/controllers"
 	"backend/middleware"
 	"backend/models"
+	"backend/services/ratelimit"
 	"time"
 
 	"github.com/gin-contrib/cors"
@@ -16,50 +17,89 @@ func SetupRoutes(r *gin.Engine) {
 
 	// 配置 CORS 中间件
 	config := cors.Config{
-		AllowOrigins:     []string{"http://localhost:3000"},                   // 允许的来源，设置为你的前端地址
-		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}, // 允许的 HTTP 方法
-		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"}, // 允许的请求头
-		ExposeHeaders:    []string{"Content-Length"},                          // 暴露给前端的响应头
-		AllowCredentials: true,                                                // 是否允许发送凭证（如 cookies）
-		MaxAge:           12 * time.Hour,                                      // 预检请求（OPTIONS）的缓存时间
+		AllowOrigins:     []string{"http://localhost:3000"},                                                                              // 允许的来源，设置为你的前端地址
+		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},                                                            // 允许的 HTTP 方法
+		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", "Idempotency-Key"},                                         // 允许的请求头
+		ExposeHeaders:    []string{"Content-Length", "Idempotent-Replayed", "Retry-After", "X-RateLimit-Limit", "X-RateLimit-Remaining"}, // 暴露给前端的响应头
+		AllowCredentials: true,                                                                                                           // 是否允许发送凭证（如 cookies）
+		MaxAge:           12 * time.Hour,                                                                                                 // 预检请求（OPTIONS）的缓存时间
 	}
 
 	// 应用 CORS 中间件
 	r.Use(cors.New(config))
 
 	// 用户相关路由
-	r.POST("/login", controllers.Login)                                                                   //TODO 登录接口,需要改成根据地址登录，前端先连接钱包，后端验证
-	r.POST("/customers/upload-photo", controllers.UploadPhoto)                                            // KYC 上传用户身份信息，上传用户头像等
-	r.POST("/customers", middleware.ValidationMiddleware(&models.Customer{}), controllers.CreateCustomer) // KYC 用户注册接口
-	r.GET("/customers", controllers.GetCustomers)                                                         // 获取所有用户，管理员员使用，需要分页，可以和下面的接口合并
-	r.GET("/customers/:customer_address", controllers.GetCustomerByAddress)                               // 根据用户地址获取用户信息，需要验证用户身份
-	r.GET("/customers/roles", controllers.GetRoleList)                                                    // 获取用户角色，需要验证用户身份
-
-	r.POST("/lottery/types/v2", controllers.NewLotteryType)
+	r.POST("/login", middleware.RateLimitMiddleware(ratelimit.PolicyLogin), middleware.AccessListMiddleware(), controllers.Login)                                                                       //TODO 登录接口,需要改成根据地址登录，前端先连接钱包，后端验证
+	r.POST("/customers/upload-photo", middleware.IdempotencyMiddleware(), controllers.UploadPhoto)                                                                                                      // KYC 上传用户身份信息，上传用户头像等
+	r.POST("/customers", middleware.RateLimitMiddleware(ratelimit.PolicyRegister), middleware.IdempotencyMiddleware(), middleware.ValidationMiddleware(&models.Customer{}), controllers.CreateCustomer) // KYC 用户注册接口
+	r.GET("/customers", controllers.GetCustomers)                                                                                                                                                       // 获取所有用户，管理员员使用，需要分页，可以和下面的接口合并
+	r.GET("/customers/:customer_address", controllers.GetCustomerByAddress)                                                                                                                             // 根据用户地址获取用户信息，需要验证用户身份
+	r.GET("/customers/roles", controllers.GetRoleList)                                                                                                                                                  // 获取用户角色，需要验证用户身份
+
+	r.POST("/lottery/types/v2", middleware.IdempotencyMiddleware(), controllers.NewLotteryType)
 	r.GET("/lottery/types/v2", controllers.ListLotteryTypes)
 
-	r.POST("/lottery/lottery/v2", controllers.NewLottery)      // 创建彩票
-	r.GET("/lottery/lottery/v2", controllers.ListAllLotteries) // 获取所有彩票信息
+	r.POST("/lottery/lottery/v2", middleware.IdempotencyMiddleware(), controllers.NewLottery) // 创建彩票
+	r.GET("/lottery/lottery/v2", controllers.ListAllLotteries)                                // 获取所有彩票信息
+
+	r.POST("/lottery/issues/v2", middleware.IdempotencyMiddleware(), controllers.NewLotteryIssue) // 发行彩票
+	r.GET("/lottery/issues/v2", controllers.ListAllIssues)                                        // 通过分页获取所有发行信息
+	r.GET("/lottery/issues/v2/:issue_id/proof", controllers.GetIssueDrawProof)                    // 获取开奖随机数证明，任何人可复核
+
+	r.POST("/lottery/tickets/v2", middleware.RateLimitMiddleware(ratelimit.PolicyPurchase), middleware.AccessListMiddleware(), middleware.IdempotencyMiddleware(), controllers.NewPurchaseTicket) // 购买彩票
+	r.GET("lottery/tickets/v2", controllers.ListPurchasedTickets)                                                                                                                                 // 获取用户购买过的彩票信息
+
+	r.POST("/lottery/draw/v2", middleware.IdempotencyMiddleware(), controllers.NewDrawLottery) // 开奖
+
+	r.GET("/lottery/winners/v2", controllers.ListWinners)                                                         // 获取近期得奖的用户信息
+	r.GET("/lottery/winners/v2/me", middleware.AuthMiddleware(), controllers.ListMyWinnings)                      // 获取当前用户的中奖及派奖状态
+	r.GET("/lottery/winners/v2/me/statement", middleware.AuthMiddleware(), controllers.ExportMyWinningsStatement) // 导出当前用户的年度中奖对账单（CSV/PDF）
 
-	r.POST("/lottery/issues/v2", controllers.NewLotteryIssue) // 发行彩票
-	r.GET("/lottery/issues/v2", controllers.ListAllIssues)    // 通过分页获取所有发行信息
+	r.GET("/lottery/pools/v2", controllers.CountIssuePools)      // 获取彩票所有奖池总额
+	r.GET("/lottery/events/v2", controllers.StreamLotteryEvents) // 订阅期号状态、售票和中奖事件（Server-Sent Events）
 
-	r.POST("/lottery/tickets/v2", controllers.NewPurchaseTicket)  // 购买彩票
-	r.GET("lottery/tickets/v2", controllers.ListPurchasedTickets) // 获取用户购买过的彩票信息
+	r.GET("/me/summary", middleware.AuthMiddleware(), controllers.GetMySummary)                                     // 获取当前用户的账户汇总（消费、中奖、余额、KYC 状态）
+	r.GET("/me/notification-preferences", middleware.AuthMiddleware(), controllers.GetMyNotificationPreferences)    // 获取当前用户的通知偏好
+	r.PUT("/me/notification-preferences", middleware.AuthMiddleware(), controllers.UpdateMyNotificationPreferences) // 修改当前用户的通知偏好
 
-	r.POST("/lottery/draw/v2", controllers.NewDrawLottery) // 开奖
+	// KYC 证件不再通过静态目录公开访问，只能由本人或审核人员获取短期签名链接
+	r.GET("/customers/:customer_address/document-url", middleware.AuthMiddleware(), controllers.GetCustomerDocumentURL) // 获取用户 KYC 证件的短期访问链接，仅本人或审核人员
+	r.GET("/documents/v2/content", controllers.GetDocumentContent)                                                      // 通过签名链接下载本地加密存储的证件
 
-	r.GET("/lottery/winners/v2", controllers.ListWinners) // 获取近期得奖的用户信息
+	r.PUT("/me/kyc", middleware.AuthMiddleware(), controllers.ResubmitMyKYC) // 补充资料或被拒绝后重新提交 KYC 信息
 
-	r.GET("/lottery/pools/v2", controllers.CountIssuePools) // 获取彩票所有奖池总额
+	// KYC 审核队列，仅审核人员（admin、kyc_verifier）可用，高风险用户需要两名不同的审核人员通过
+	reviews := r.Group("/kyc/reviews")
+	reviews.Use(middleware.AuthMiddleware())
+	{
+		reviews.GET("", controllers.ListKYCReviews)                                                                     // 按状态、审核人员、风险等级查询审核队列
+		reviews.GET("/:customer_address", controllers.GetKYCReview)                                                     // 获取审核详情及全部审核历史
+		reviews.POST("/:customer_address/assign", middleware.IdempotencyMiddleware(), controllers.AssignKYCReview)      // 分配审核人员
+		reviews.POST("/:customer_address/risk-level", middleware.IdempotencyMiddleware(), controllers.SetKYCRiskLevel)  // 评估风险等级，审核通过前必须设置
+		reviews.POST("/:customer_address/request-info", middleware.IdempotencyMiddleware(), controllers.RequestKYCInfo) // 退回用户补充资料
+		reviews.POST("/:customer_address/approve", middleware.IdempotencyMiddleware(), controllers.ApproveKYCReview)    // 审核通过
+		reviews.POST("/:customer_address/reject", middleware.IdempotencyMiddleware(), controllers.RejectKYCReview)      // 审核拒绝
+	}
+
+	// 制裁名单筛查，仅审核人员可用，命中的用户在确认误报前无法注册、登录和购票
+	screeningGroup := r.Group("/screening")
+	screeningGroup.Use(middleware.AuthMiddleware())
+	{
+		screeningGroup.GET("/hits", controllers.ListScreeningHits)                                                        // 按状态、钱包地址查询命中
+		screeningGroup.POST("/hits/:hit_id/confirm", middleware.IdempotencyMiddleware(), controllers.ConfirmScreeningHit) // 确认命中
+		screeningGroup.POST("/hits/:hit_id/clear", middleware.IdempotencyMiddleware(), controllers.ClearScreeningHit)     // 解除误报
+		screeningGroup.GET("/lists", controllers.ListSanctionsLists)                                                      // 已导入的名单
+		screeningGroup.POST("/lists/refresh", middleware.IdempotencyMiddleware(), controllers.RefreshSanctionsLists)      // 立即导入变化的名单并重新筛查
+	}
 
-	// 静态文件服务（用于访问 uploads 目录下的文件）
-	r.Static("/uploads", "./uploads")
+	// 刷新令牌本身即凭证，访问令牌过期后仍可刷新，不经过 AuthMiddleware
+	r.POST("/auth/refresh", controllers.RefreshToken)    // 用刷新令牌换发新的访问令牌和刷新令牌
+	r.GET("/.well-known/jwks.json", controllers.GetJWKS) // RS256/EdDSA 访问令牌的验证公钥
 
 	auth := r.Group("/auth")
 	auth.Use(middleware.AuthMiddleware())
 	{
-		auth.POST("/refresh", controllers.RefreshToken)
-		auth.POST("/verify", controllers.VerifyCustomer)
+		auth.POST("/logout", controllers.Logout) // 退出登录，撤销当前会话或全部会话
+		auth.POST("/verify", middleware.IdempotencyMiddleware(), controllers.VerifyCustomer)
 	}
 }
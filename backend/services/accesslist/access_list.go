@@ -0,0 +1,93 @@
+package accesslist
+
+import (
+	"net/netip"
+	"strings"
+	"time"
+
+	"backend/models"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Decision is the outcome of checking a value against the access lists
+type Decision struct {
+	Allowed bool
+	Reason  string
+	EntryID uint // The entry that decided, 0 when none did
+}
+
+// NormalizeValue validates a value of a kind and returns its stored form: a masked CIDR for IPs,
+// a single address becoming a /32 or /128, and a lowercase address for wallets
+func NormalizeValue(kind, value string) (string, bool) {
+	value = strings.TrimSpace(value)
+	switch kind {
+	case models.AccessListKindIP:
+		if prefix, err := netip.ParsePrefix(value); err == nil {
+			return prefix.Masked().String(), true
+		}
+		addr, err := netip.ParseAddr(value)
+		if err != nil {
+			return "", false
+		}
+		addr = addr.Unmap()
+		return netip.PrefixFrom(addr, addr.BitLen()).String(), true
+	case models.AccessListKindWallet:
+		if !common.IsHexAddress(value) {
+			return "", false
+		}
+		return strings.ToLower(value), true
+	}
+	return "", false
+}
+
+// matches reports whether an entry covers a value of its kind
+func matches(entry models.AccessListEntry, value string, addr netip.Addr) bool {
+	if entry.Kind == models.AccessListKindIP {
+		prefix, err := netip.ParsePrefix(entry.Value)
+		return err == nil && addr.IsValid() && prefix.Contains(addr)
+	}
+	return strings.EqualFold(entry.Value, value)
+}
+
+// Evaluate checks a value of a kind against the entries that are not expired at now
+//
+// An allow entry that matches always wins. Otherwise a matching deny entry refuses the value, and so
+// does the mere presence of allow entries of the kind, which turns the allow list into a whitelist.
+// An empty value, e.g. an unknown wallet, is allowed.
+func Evaluate(entries []models.AccessListEntry, kind, value string, now time.Time) Decision {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return Decision{Allowed: true}
+	}
+	var addr netip.Addr
+	if kind == models.AccessListKindIP {
+		if parsed, err := netip.ParseAddr(value); err == nil {
+			addr = parsed.Unmap()
+		}
+	}
+
+	hasAllow := false
+	var deny *models.AccessListEntry
+	for i := range entries {
+		entry := entries[i]
+		if entry.Kind != kind || (entry.ExpiresAt != nil && !entry.ExpiresAt.After(now)) {
+			continue
+		}
+		if entry.ListType == models.AccessListAllow {
+			hasAllow = true
+			if matches(entry, value, addr) {
+				return Decision{Allowed: true, Reason: "allow listed", EntryID: entry.EntryID}
+			}
+		} else if deny == nil && matches(entry, value, addr) {
+			deny = &entries[i]
+		}
+	}
+	if deny != nil {
+		return Decision{Allowed: false, Reason: "deny listed", EntryID: deny.EntryID}
+	}
+	if hasAllow {
+		return Decision{Allowed: false, Reason: "not on the allow list"}
+	}
+	return Decision{Allowed: true}
+}
@@ -0,0 +1,285 @@
+package accesslist
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"backend/config"
+	"backend/models"
+	"backend/utils"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// entriesCacheKey is the utils.Cache key of all access list entries
+const entriesCacheKey = "access_list_entries"
+
+// AccessListService manages the IP and wallet allow/deny lists and checks requests against them
+type AccessListService struct {
+	db *gorm.DB
+}
+
+// NewAccessListService creates an AccessListService
+func NewAccessListService(db *gorm.DB) *AccessListService {
+	return &AccessListService{db: db}
+}
+
+// entries returns all entries, cached for ACCESS_LIST_CACHE_SECONDS so that changes made
+// through the other server are picked up quickly
+func (s *AccessListService) entries(ctx context.Context) ([]models.AccessListEntry, error) {
+	if utils.Cache != nil {
+		if cached, ok := utils.Cache.Get(entriesCacheKey); ok {
+			if entries, ok := cached.([]models.AccessListEntry); ok {
+				return entries, nil
+			}
+		}
+	}
+	var entries []models.AccessListEntry
+	if err := s.db.WithContext(ctx).Find(&entries).Error; err != nil {
+		return nil, utils.NewInternalError("Failed to fetch access lists", err)
+	}
+	if utils.Cache != nil {
+		utils.Cache.Set(entriesCacheKey, entries, time.Duration(config.AppConfig.AccessListCacheSeconds)*time.Second)
+	}
+	return entries, nil
+}
+
+// invalidate drops the cached entries
+func invalidate() {
+	if utils.Cache != nil {
+		utils.Cache.Delete(entriesCacheKey)
+	}
+}
+
+// Check refuses an IP address or any of the wallets that the access lists deny; empty wallets are not checked
+func (s *AccessListService) Check(ctx context.Context, ip string, wallets ...string) error {
+	entries, err := s.entries(ctx)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	if decision := Evaluate(entries, models.AccessListKindIP, ip, now); !decision.Allowed {
+		utils.Logger.Warn("Request refused by IP access list", "ip", ip, "reason", decision.Reason, "entry_id", decision.EntryID)
+		return utils.NewForbiddenError("Access from this IP address is not allowed", nil)
+	}
+	for _, wallet := range wallets {
+		if decision := Evaluate(entries, models.AccessListKindWallet, wallet, now); !decision.Allowed {
+			utils.Logger.Warn("Request refused by wallet access list", "wallet", wallet, "reason", decision.Reason, "entry_id", decision.EntryID)
+			return utils.NewForbiddenError("This wallet address is not allowed", nil)
+		}
+	}
+	return nil
+}
+
+// EntryFilter defines the filters of the entry list
+type EntryFilter struct {
+	ListType string
+	Kind     string
+}
+
+// List returns the entries, newest first
+func (s *AccessListService) List(ctx context.Context, filter EntryFilter) ([]models.AccessListEntry, error) {
+	query := s.db.WithContext(ctx).Model(&models.AccessListEntry{})
+	if filter.ListType != "" {
+		query = query.Where("list_type = ?", filter.ListType)
+	}
+	if filter.Kind != "" {
+		query = query.Where("kind = ?", filter.Kind)
+	}
+	entries := []models.AccessListEntry{}
+	if err := query.Order("entry_id DESC").Find(&entries).Error; err != nil {
+		return nil, utils.NewInternalError("Failed to fetch access lists", err)
+	}
+	return entries, nil
+}
+
+// EntryParams defines an entry to create, or the fields to change; nil fields are left unchanged on update
+type EntryParams struct {
+	ListType  *string
+	Kind      *string
+	Value     *string
+	Reason    *string
+	ExpiresAt *time.Time
+}
+
+// Create adds an entry
+func (s *AccessListService) Create(ctx context.Context, params EntryParams, actor string) (*models.AccessListEntry, error) {
+	if params.ListType == nil || params.Kind == nil || params.Value == nil {
+		return nil, utils.NewBadRequestError("list_type, kind and value are required", nil)
+	}
+	now := time.Now()
+	entry := models.AccessListEntry{CreatedBy: actor, CreatedAt: now, UpdatedAt: now}
+	if err := apply(&entry, params); err != nil {
+		return nil, err
+	}
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := checkDuplicate(tx, entry); err != nil {
+			return err
+		}
+		if err := tx.Create(&entry).Error; err != nil {
+			return utils.NewInternalError("Failed to create access list entry", err)
+		}
+		return audit(tx, entry.EntryID, models.AccessListAuditCreate, actor, nil, &entry)
+	})
+	if err != nil {
+		return nil, err
+	}
+	invalidate()
+	utils.Logger.Info("Access list entry created", "entry_id", entry.EntryID, "list_type", entry.ListType, "kind", entry.Kind, "value", entry.Value, "actor", actor)
+	return &entry, nil
+}
+
+// Update changes an entry
+func (s *AccessListService) Update(ctx context.Context, entryID uint, params EntryParams, actor string) (*models.AccessListEntry, error) {
+	var entry models.AccessListEntry
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := lock(tx, entryID, &entry); err != nil {
+			return err
+		}
+		before := entry
+		if err := apply(&entry, params); err != nil {
+			return err
+		}
+		if err := checkDuplicate(tx, entry); err != nil {
+			return err
+		}
+		entry.UpdatedAt = time.Now()
+		if err := tx.Save(&entry).Error; err != nil {
+			return utils.NewInternalError("Failed to update access list entry", err)
+		}
+		return audit(tx, entry.EntryID, models.AccessListAuditUpdate, actor, &before, &entry)
+	})
+	if err != nil {
+		return nil, err
+	}
+	invalidate()
+	utils.Logger.Info("Access list entry updated", "entry_id", entry.EntryID, "actor", actor)
+	return &entry, nil
+}
+
+// Delete removes an entry, its audit trail is kept
+func (s *AccessListService) Delete(ctx context.Context, entryID uint, actor string) error {
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var entry models.AccessListEntry
+		if err := lock(tx, entryID, &entry); err != nil {
+			return err
+		}
+		if err := tx.Delete(&entry).Error; err != nil {
+			return utils.NewInternalError("Failed to delete access list entry", err)
+		}
+		return audit(tx, entry.EntryID, models.AccessListAuditDelete, actor, &entry, nil)
+	})
+	if err != nil {
+		return err
+	}
+	invalidate()
+	utils.Logger.Info("Access list entry deleted", "entry_id", entryID, "actor", actor)
+	return nil
+}
+
+// ListAudit returns the audit trail, newest first, of one entry or of all entries when entryID is 0
+func (s *AccessListService) ListAudit(ctx context.Context, entryID uint, limit int) ([]models.AccessListAudit, error) {
+	if limit <= 0 || limit > 500 {
+		limit = 100
+	}
+	query := s.db.WithContext(ctx).Model(&models.AccessListAudit{})
+	if entryID != 0 {
+		query = query.Where("entry_id = ?", entryID)
+	}
+	audits := []models.AccessListAudit{}
+	if err := query.Order("audit_id DESC").Limit(limit).Find(&audits).Error; err != nil {
+		return nil, utils.NewInternalError("Failed to fetch access list audit trail", err)
+	}
+	return audits, nil
+}
+
+// apply validates params and sets them on an entry
+func apply(entry *models.AccessListEntry, params EntryParams) error {
+	if params.ListType != nil {
+		if *params.ListType != models.AccessListAllow && *params.ListType != models.AccessListDeny {
+			return utils.NewBadRequestError("list_type must be ALLOW or DENY", nil)
+		}
+		entry.ListType = *params.ListType
+	}
+	if params.Kind != nil {
+		if *params.Kind != models.AccessListKindIP && *params.Kind != models.AccessListKindWallet {
+			return utils.NewBadRequestError("kind must be IP or WALLET", nil)
+		}
+		entry.Kind = *params.Kind
+	}
+	value := entry.Value
+	if params.Value != nil {
+		value = *params.Value
+	}
+	normalized, ok := NormalizeValue(entry.Kind, value)
+	if !ok {
+		if entry.Kind == models.AccessListKindIP {
+			return utils.NewBadRequestError("value must be an IP address or CIDR", nil)
+		}
+		return utils.NewBadRequestError("value must be a wallet address", nil)
+	}
+	entry.Value = normalized
+	if params.Reason != nil {
+		entry.Reason = *params.Reason
+	}
+	if params.ExpiresAt != nil {
+		if params.ExpiresAt.IsZero() {
+			entry.ExpiresAt = nil
+		} else {
+			expiresAt := *params.ExpiresAt
+			entry.ExpiresAt = &expiresAt
+		}
+	}
+	return nil
+}
+
+// lock fetches an entry for update
+func lock(tx *gorm.DB, entryID uint, entry *models.AccessListEntry) error {
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(entry, entryID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return utils.NewBadRequestError("Access list entry not found", err)
+		}
+		return utils.NewInternalError("Failed to fetch access list entry", err)
+	}
+	return nil
+}
+
+// checkDuplicate refuses a second entry with the same list type, kind and value
+func checkDuplicate(tx *gorm.DB, entry models.AccessListEntry) error {
+	var count int64
+	if err := tx.Model(&models.AccessListEntry{}).
+		Where("list_type = ? AND kind = ? AND value = ? AND entry_id <> ?", entry.ListType, entry.Kind, entry.Value, entry.EntryID).
+		Count(&count).Error; err != nil {
+		return utils.NewInternalError("Failed to check access list entry", err)
+	}
+	if count > 0 {
+		return utils.NewBadRequestError("The entry already exists", nil)
+	}
+	return nil
+}
+
+// audit records a change of an entry with its state before and after
+func audit(tx *gorm.DB, entryID uint, action, actor string, before, after *models.AccessListEntry) error {
+	snapshot := func(entry *models.AccessListEntry) string {
+		if entry == nil {
+			return ""
+		}
+		data, _ := json.Marshal(entry)
+		return string(data)
+	}
+	record := models.AccessListAudit{
+		EntryID:   entryID,
+		Action:    action,
+		Actor:     actor,
+		Before:    snapshot(before),
+		After:     snapshot(after),
+		CreatedAt: time.Now(),
+	}
+	if err := tx.Create(&record).Error; err != nil {
+		return utils.NewInternalError("Failed to record access list audit trail", err)
+	}
+	return nil
+}
@@ -0,0 +1,242 @@
+package account
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"backend/blockchain"
+	"backend/config"
+	"backend/models"
+	"backend/utils"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"gorm.io/gorm"
+)
+
+// KYC statuses reported on the account summary
+const (
+	KYCStatusNotSubmitted = "NOT_SUBMITTED"
+	KYCStatusPending      = "PENDING"
+	KYCStatusApproved     = "APPROVED"
+	KYCStatusRejected     = "REJECTED"
+	KYCStatusExpired      = "EXPIRED"
+)
+
+// AccountSummaryService aggregates a customer's tickets, wins, balance and KYC status
+type AccountSummaryService struct {
+	db *gorm.DB
+}
+
+// NewAccountSummaryService creates a new AccountSummaryService instance
+func NewAccountSummaryService(db *gorm.DB) *AccountSummaryService {
+	return &AccountSummaryService{db: db}
+}
+
+// OpenIssueTickets are the tickets a customer holds in an issue that is not drawn yet
+type OpenIssueTickets struct {
+	IssueID     string    `json:"issue_id"`
+	IssueNumber string    `json:"issue_number"`
+	LotteryID   string    `json:"lottery_id"`
+	TicketName  string    `json:"ticket_name"`
+	Status      string    `json:"status"`
+	DrawTime    time.Time `json:"draw_time"`
+	TicketCount int64     `json:"ticket_count"`
+	TotalAmount float64   `json:"total_amount"`
+}
+
+// AccountAggregates are the database aggregates of a summary, cached per customer
+type AccountAggregates struct {
+	TotalSpent    float64            `json:"total_spent"`    // Sum of tickets bought times their ticket price
+	TotalWon      float64            `json:"total_won"`      // Sum of net prizes
+	TotalWithheld float64            `json:"total_withheld"` // Sum of withheld tax
+	TicketCount   int64              `json:"ticket_count"`
+	WinCount      int64              `json:"win_count"`
+	OpenTickets   []OpenIssueTickets `json:"open_tickets"`
+	KYCStatus     string             `json:"kyc_status"`
+	ComputedAt    time.Time          `json:"computed_at"`
+}
+
+// AccountSummary is the dashboard summary of a customer
+type AccountSummary struct {
+	CustomerAddress string `json:"customer_address"`
+	AccountAggregates
+	NetPosition  float64 `json:"net_position"` // TotalWon - TotalSpent
+	LOTBalance   *string `json:"lot_balance"`  // LOT balanceOf in base units, null when the chain is unreachable
+	BalanceError string  `json:"balance_error,omitempty"`
+}
+
+// summaryCacheKey returns the cache key of a customer's aggregates
+func summaryCacheKey(address string) string {
+	return "account_summary:" + strings.ToLower(address)
+}
+
+// InvalidateAccountSummary drops the cached aggregates of a customer, called when their tickets or wins change
+func InvalidateAccountSummary(address string) {
+	if utils.Cache != nil {
+		utils.Cache.Delete(summaryCacheKey(address))
+	}
+}
+
+// GetSummary returns the summary of a customer, aggregates are served from the cache for
+// ACCOUNT_SUMMARY_CACHE_SECONDS while the LOT balance is always read from the chain
+func (s *AccountSummaryService) GetSummary(ctx context.Context, address string) (*AccountSummary, error) {
+	aggregates, err := s.cachedAggregates(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &AccountSummary{
+		CustomerAddress:   address,
+		AccountAggregates: *aggregates,
+		NetPosition:       aggregates.TotalWon - aggregates.TotalSpent,
+	}
+	if balance, err := s.lotBalance(ctx, address); err != nil {
+		utils.Logger.Warn("Failed to read LOT balance", "address", address, "error", err)
+		summary.BalanceError = "LOT balance unavailable"
+	} else {
+		summary.LOTBalance = &balance
+	}
+	return summary, nil
+}
+
+// cachedAggregates returns the aggregates from the cache or computes and caches them
+func (s *AccountSummaryService) cachedAggregates(ctx context.Context, address string) (*AccountAggregates, error) {
+	key := summaryCacheKey(address)
+	if utils.Cache != nil {
+		if cached, ok := utils.Cache.Get(key); ok {
+			return cached.(*AccountAggregates), nil
+		}
+	}
+	aggregates, err := s.computeAggregates(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+	if utils.Cache != nil {
+		utils.Cache.Set(key, aggregates, time.Duration(config.AppConfig.AccountSummaryCacheSeconds)*time.Second)
+	}
+	return aggregates, nil
+}
+
+// computeAggregates runs the aggregate queries of a customer
+func (s *AccountSummaryService) computeAggregates(ctx context.Context, address string) (*AccountAggregates, error) {
+	db := s.db.WithContext(ctx)
+	aggregates := &AccountAggregates{OpenTickets: []OpenIssueTickets{}, ComputedAt: time.Now()}
+
+	var tickets struct {
+		Count int64
+		Total float64
+	}
+	// purchase_amount is the number of tickets bought, the amount spent is that number times the lottery's ticket price
+	if err := db.Table("lottery_tickets AS t").
+		Select("COUNT(*) AS count, COALESCE(SUM(t.purchase_amount * l.ticket_price), 0) AS total").
+		Joins("JOIN lottery_issues AS i ON i.issue_id = t.issue_id").
+		Joins("JOIN lotteries AS l ON l.lottery_id = i.lottery_id").
+		Where("LOWER(t.buyer_address) = LOWER(?)", address).
+		Scan(&tickets).Error; err != nil {
+		utils.Logger.Error("Failed to aggregate tickets", "address", address, "error", err)
+		return nil, utils.NewInternalError("Failed to aggregate tickets", err)
+	}
+	aggregates.TicketCount = tickets.Count
+	aggregates.TotalSpent = tickets.Total
+
+	var wins struct {
+		Count    int64
+		Net      float64
+		Withheld float64
+	}
+	if err := db.Model(&models.Winner{}).
+		Select("COUNT(*) AS count, COALESCE(SUM(net_amount), 0) AS net, COALESCE(SUM(withheld_amount), 0) AS withheld").
+		Where("LOWER(address) = LOWER(?)", address).
+		Scan(&wins).Error; err != nil {
+		utils.Logger.Error("Failed to aggregate winnings", "address", address, "error", err)
+		return nil, utils.NewInternalError("Failed to aggregate winnings", err)
+	}
+	aggregates.WinCount = wins.Count
+	aggregates.TotalWon = wins.Net
+	aggregates.TotalWithheld = wins.Withheld
+
+	if err := db.Table("lottery_tickets AS t").
+		Select("i.issue_id, i.issue_number, i.lottery_id, l.ticket_name, i.status, i.draw_time, COUNT(*) AS ticket_count, COALESCE(SUM(t.purchase_amount * l.ticket_price), 0) AS total_amount").
+		Joins("JOIN lottery_issues AS i ON i.issue_id = t.issue_id").
+		Joins("JOIN lotteries AS l ON l.lottery_id = i.lottery_id").
+		Where("LOWER(t.buyer_address) = LOWER(?) AND i.status IN ?", address, []string{models.IssueStatusPending, models.IssueStatusDrawing}).
+		Group("i.issue_id, i.issue_number, i.lottery_id, l.ticket_name, i.status, i.draw_time").
+		Order("i.draw_time").
+		Scan(&aggregates.OpenTickets).Error; err != nil {
+		utils.Logger.Error("Failed to aggregate open tickets", "address", address, "error", err)
+		return nil, utils.NewInternalError("Failed to aggregate open tickets", err)
+	}
+
+	status, err := s.kycStatus(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+	aggregates.KYCStatus = status
+	return aggregates, nil
+}
+
+// DeriveKYCStatus maps the KYC data, the customer's verified flag and the latest verification result to a summary status
+func DeriveKYCStatus(submitted, verified bool, latestVerifyStatus string) string {
+	switch {
+	case !submitted:
+		return KYCStatusNotSubmitted
+	case latestVerifyStatus == "Expired":
+		// Under the restrict expiry action the customer keeps the verified flag
+		return KYCStatusExpired
+	case verified:
+		return KYCStatusApproved
+	case latestVerifyStatus == "Rejected":
+		return KYCStatusRejected
+	}
+	return KYCStatusPending
+}
+
+// kycStatus derives the KYC status from the KYC data, the customer record and the latest verification
+func (s *AccountSummaryService) kycStatus(ctx context.Context, address string) (string, error) {
+	db := s.db.WithContext(ctx)
+	var kycCount int64
+	if err := db.Model(&models.KYCData{}).Where("LOWER(customer_address) = LOWER(?)", address).Count(&kycCount).Error; err != nil {
+		return "", utils.NewInternalError("Failed to fetch KYC data", err)
+	}
+	if kycCount == 0 {
+		return KYCStatusNotSubmitted, nil
+	}
+
+	var customer models.Customer
+	if err := db.Where("LOWER(customer_address) = LOWER(?)", address).First(&customer).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return DeriveKYCStatus(true, false, ""), nil
+		}
+		return "", utils.NewInternalError("Failed to fetch customer", err)
+	}
+	var latest models.KYCVerificationHistory
+	if err := db.Where("customer_address = ?", customer.CustomerAddress).
+		Order("verification_date DESC").
+		Limit(1).
+		Find(&latest).Error; err != nil {
+		return "", utils.NewInternalError("Failed to fetch KYC verification history", err)
+	}
+	return DeriveKYCStatus(true, customer.IsVerified, latest.VerifyStatus), nil
+}
+
+// lotBalance reads the LOT token balance of an address in base units
+func (s *AccountSummaryService) lotBalance(ctx context.Context, address string) (string, error) {
+	if !common.IsHexAddress(address) {
+		return "", errors.New("not a valid address")
+	}
+	if err := blockchain.EnsureInitialized(); err != nil {
+		return "", err
+	}
+	token, err := blockchain.ConnectTokenContract(config.AppConfig.TokenContractAddress)
+	if err != nil {
+		return "", err
+	}
+	balance, err := token.BalanceOf(&bind.CallOpts{Context: ctx}, common.HexToAddress(address))
+	if err != nil {
+		return "", err
+	}
+	return balance.String(), nil
+}
@@ -2,30 +2,37 @@
 package services
 
 import (
-	"backend/config"
 	"backend/db"
 	"backend/models"
+	"backend/services/authtoken"
+	"backend/services/geo"
+	"backend/services/screening"
+	"context"
 	"errors"
 	"time"
-
-	"github.com/golang-jwt/jwt/v5"
 )
 
 // LoginResult 定义登录返回结果
 type LoginResult struct {
-	Customer *models.Customer // 用户信息
-	Role     *models.Role     // 角色信息
-	Token    string           // JWT 令牌
+	Customer *models.Customer     // 用户信息
+	Role     *models.Role         // 角色信息
+	Token    string               // 访问令牌（JWT）
+	Tokens   *authtoken.TokenPair // 访问令牌、刷新令牌及其有效期
 }
 
-// Login 登录逻辑，返回用户、角色和 JWT 令牌
+// Login 登录逻辑，返回用户、角色、短期访问令牌和刷新令牌
 func Login(walletAddress, ip string) (*LoginResult, error) {
+	// IP 和钱包地址的黑白名单由路由上的 AccessListMiddleware 检查
 
-	//TODO : 检查 IP 地址是否在黑名单中
-	//TODO: 检查 IP 地址是否在白名单中
+	// 所在国家没有任何可购买的彩票时禁止登录
+	if err := geo.NewGeoService(db.DB).CheckLogin(context.Background(), ip); err != nil {
+		return nil, err
+	}
 
-	//TODO: 检查 walletAddress 是否在黑名单中
-	//TODO: 检查 walletAddress 是否在白名单中
+	// 制裁名单筛查：钱包地址在名单中或有未解除的命中时禁止登录
+	if err := screening.NewScreeningService(db.DB).CheckAllowed(context.Background(), walletAddress); err != nil {
+		return nil, err
+	}
 
 	// 根据 walletAddress 从Customer表中查询用户信息,验证用户是否通过KYC
 	var customer models.Customer
@@ -48,13 +55,8 @@ func Login(walletAddress, ip string) (*LoginResult, error) {
 		return nil, err
 	}
 
-	// 生成 JWT 令牌
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"customer_address": customer.CustomerAddress,
-		"role":             role.RoleName,
-		"exp":              time.Now().Add(time.Hour * 24).Unix(),
-	})
-	tokenString, err := token.SignedString([]byte(config.AppConfig.JWTSecret))
+	// 创建登录会话，签发访问令牌和刷新令牌
+	tokens, err := authtoken.NewTokenService(db.DB).StartSession(context.Background(), customer.CustomerAddress, role.RoleName, ip, time.Now())
 	if err != nil {
 		return nil, err
 	}
@@ -62,21 +64,12 @@ func Login(walletAddress, ip string) (*LoginResult, error) {
 	return &LoginResult{
 		Customer: &customer,
 		Role:     &role,
-		Token:    tokenString,
+		Token:    tokens.AccessToken,
+		Tokens:   tokens,
 	}, nil
 }
 
-// RefreshToken 刷新 JWT 令牌
-func RefreshToken(customerAddress, role string) (string, error) {
-	// 生成新的 JWT 令牌
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"customer_address": customerAddress,
-		"role":             role,
-		"exp":              time.Now().Add(time.Hour * 24).Unix(),
-	})
-	tokenString, err := token.SignedString([]byte(config.AppConfig.JWTSecret))
-	if err != nil {
-		return "", err
-	}
-	return tokenString, nil
+// RefreshToken 用刷新令牌换发新的访问令牌和刷新令牌，旧的刷新令牌随即失效
+func RefreshToken(refreshToken, ip string) (*authtoken.TokenPair, error) {
+	return authtoken.NewTokenService(db.DB).Refresh(context.Background(), refreshToken, ip, time.Now())
 }
@@ -0,0 +1,225 @@
+package authtoken
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"sync"
+
+	"backend/config"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Supported signing algorithms
+const (
+	AlgorithmHS256 = "HS256"
+	AlgorithmRS256 = "RS256"
+	AlgorithmEdDSA = "EdDSA"
+)
+
+// signingKey is an asymmetric key pair identified by its JWK thumbprint
+type signingKey struct {
+	id      string
+	private crypto.PrivateKey
+	public  crypto.PublicKey
+}
+
+// KeySet signs access tokens with one key and verifies them with every configured key,
+// so that tokens signed before a key rotation stay valid until they expire
+type KeySet struct {
+	method  jwt.SigningMethod
+	secret  []byte                 // HS256 only
+	signing *signingKey            // RS256 and EdDSA only
+	keys    map[string]*signingKey // Verification keys by kid, the signing key included
+	order   []string               // kids in configuration order, for the JWKS
+}
+
+// NewHMACKeySet creates an HS256 key set from a shared secret
+func NewHMACKeySet(secret string) (*KeySet, error) {
+	if secret == "" {
+		return nil, errors.New("JWT_SECRET is required for HS256")
+	}
+	return &KeySet{method: jwt.SigningMethodHS256, secret: []byte(secret)}, nil
+}
+
+// NewKeySet creates an RS256 or EdDSA key set from PEM private keys; the first key signs
+func NewKeySet(algorithm string, pemKeys [][]byte) (*KeySet, error) {
+	set := &KeySet{keys: make(map[string]*signingKey)}
+	switch algorithm {
+	case AlgorithmRS256:
+		set.method = jwt.SigningMethodRS256
+	case AlgorithmEdDSA:
+		set.method = jwt.SigningMethodEdDSA
+	default:
+		return nil, fmt.Errorf("unsupported JWT algorithm %q", algorithm)
+	}
+	if len(pemKeys) == 0 {
+		return nil, fmt.Errorf("%s needs at least one private key in JWT_PRIVATE_KEYS", algorithm)
+	}
+	for i, pemKey := range pemKeys {
+		key, err := parsePrivateKey(algorithm, pemKey)
+		if err != nil {
+			return nil, fmt.Errorf("key %d: %w", i+1, err)
+		}
+		if _, exists := set.keys[key.id]; exists {
+			continue
+		}
+		set.keys[key.id] = key
+		set.order = append(set.order, key.id)
+		if set.signing == nil {
+			set.signing = key
+		}
+	}
+	return set, nil
+}
+
+// parsePrivateKey reads a PEM private key of the algorithm and derives its kid
+func parsePrivateKey(algorithm string, pemKey []byte) (*signingKey, error) {
+	if algorithm == AlgorithmRS256 {
+		private, err := jwt.ParseRSAPrivateKeyFromPEM(pemKey)
+		if err != nil {
+			return nil, err
+		}
+		if private.N.BitLen() < 2048 {
+			return nil, errors.New("RSA keys must be at least 2048 bits")
+		}
+		key := &signingKey{private: private, public: &private.PublicKey}
+		key.id = thumbprint(key.jwk(algorithm))
+		return key, nil
+	}
+	private, err := jwt.ParseEdPrivateKeyFromPEM(pemKey)
+	if err != nil {
+		return nil, err
+	}
+	edPrivate, ok := private.(ed25519.PrivateKey)
+	if !ok {
+		return nil, errors.New("not an Ed25519 private key")
+	}
+	key := &signingKey{private: edPrivate, public: edPrivate.Public()}
+	key.id = thumbprint(key.jwk(algorithm))
+	return key, nil
+}
+
+// Algorithm returns the signing algorithm
+func (k *KeySet) Algorithm() string {
+	return k.method.Alg()
+}
+
+// Sign signs claims with the current key, naming it in the kid header
+func (k *KeySet) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(k.method, claims)
+	if k.signing == nil {
+		return token.SignedString(k.secret)
+	}
+	token.Header["kid"] = k.signing.id
+	return token.SignedString(k.signing.private)
+}
+
+// Parse verifies a token with the key named by its kid and decodes its claims
+//
+// Only the configured algorithm is accepted, so that a token cannot pick a weaker one.
+func (k *KeySet) Parse(tokenString string, claims jwt.Claims, options ...jwt.ParserOption) (*jwt.Token, error) {
+	options = append(options, jwt.WithValidMethods([]string{k.method.Alg()}))
+	return jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		if k.signing == nil {
+			return k.secret, nil
+		}
+		kid, _ := token.Header["kid"].(string)
+		key, ok := k.keys[kid]
+		if !ok {
+			return nil, fmt.Errorf("unknown signing key %q", kid)
+		}
+		return key.public, nil
+	}, options...)
+}
+
+// JWK is a public key in JSON Web Key format
+type JWK struct {
+	Kty string `json:"kty"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	Kid string `json:"kid,omitempty"`
+	Crv string `json:"crv,omitempty"` // OKP
+	X   string `json:"x,omitempty"`   // OKP
+	N   string `json:"n,omitempty"`   // RSA
+	E   string `json:"e,omitempty"`   // RSA
+}
+
+// JWKS is a JSON Web Key Set
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS returns the public verification keys, the signing key first; HS256 publishes none
+func (k *KeySet) JWKS() JWKS {
+	jwks := JWKS{Keys: []JWK{}}
+	for _, kid := range k.order {
+		jwk := k.keys[kid].jwk(k.method.Alg())
+		jwk.Use, jwk.Alg, jwk.Kid = "sig", k.method.Alg(), kid
+		jwks.Keys = append(jwks.Keys, jwk)
+	}
+	return jwks
+}
+
+// jwk returns the required members of the key's JWK
+func (s *signingKey) jwk(algorithm string) JWK {
+	encode := base64.RawURLEncoding.EncodeToString
+	if algorithm == AlgorithmRS256 {
+		public := s.public.(*rsa.PublicKey)
+		return JWK{Kty: "RSA", N: encode(public.N.Bytes()), E: encode(big.NewInt(int64(public.E)).Bytes())}
+	}
+	return JWK{Kty: "OKP", Crv: "Ed25519", X: encode(s.public.(ed25519.PublicKey))}
+}
+
+// thumbprint computes the RFC 7638 thumbprint of a JWK: the SHA-256 of its required members in lexical order
+func thumbprint(jwk JWK) string {
+	var members string
+	if jwk.Kty == "RSA" {
+		members = fmt.Sprintf(`{"e":%q,"kty":"RSA","n":%q}`, jwk.E, jwk.N)
+	} else {
+		members = fmt.Sprintf(`{"crv":%q,"kty":"OKP","x":%q}`, jwk.Crv, jwk.X)
+	}
+	sum := sha256.Sum256([]byte(members))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+var (
+	defaultKeySet     *KeySet
+	defaultKeySetErr  error
+	defaultKeySetOnce sync.Once
+)
+
+// DefaultKeySet returns the key set of the configuration, loaded once
+func DefaultKeySet() (*KeySet, error) {
+	defaultKeySetOnce.Do(func() {
+		algorithm := strings.TrimSpace(config.AppConfig.JWTAlgorithm)
+		if algorithm == "" || strings.EqualFold(algorithm, AlgorithmHS256) {
+			defaultKeySet, defaultKeySetErr = NewHMACKeySet(config.AppConfig.JWTSecret)
+			return
+		}
+		if strings.EqualFold(algorithm, AlgorithmEdDSA) {
+			algorithm = AlgorithmEdDSA
+		} else {
+			algorithm = strings.ToUpper(algorithm)
+		}
+		var pemKeys [][]byte
+		for _, path := range config.AppConfig.JWTPrivateKeys {
+			pemKey, err := os.ReadFile(path)
+			if err != nil {
+				defaultKeySetErr = fmt.Errorf("read JWT key %s: %w", path, err)
+				return
+			}
+			pemKeys = append(pemKeys, pemKey)
+		}
+		defaultKeySet, defaultKeySetErr = NewKeySet(algorithm, pemKeys)
+	})
+	return defaultKeySet, defaultKeySetErr
+}
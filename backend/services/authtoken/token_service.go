@@ -0,0 +1,405 @@
+package authtoken
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"backend/config"
+	"backend/models"
+	"backend/utils"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Reasons a session is revoked
+const (
+	RevokeReasonLogout     = "logout"
+	RevokeReasonAdmin      = "revoked by administrator"
+	RevokeReasonReuse      = "refresh token reused"
+	RevokeReasonUnverified = "customer no longer verified"
+)
+
+// sessionCacheTTL bounds how long another server keeps accepting access tokens of a revoked session
+const sessionCacheTTL = 30 * time.Second
+
+// AccessClaims are the claims of an access token
+type AccessClaims struct {
+	CustomerAddress string `json:"customer_address"`
+	Role            string `json:"role"`
+	SessionID       string `json:"sid"`
+	jwt.RegisteredClaims
+}
+
+// TokenPair is what a login or a refresh hands out
+type TokenPair struct {
+	AccessToken           string
+	AccessTokenExpiresAt  time.Time
+	RefreshToken          string
+	RefreshTokenExpiresAt time.Time
+	SessionID             string
+}
+
+// HashRefreshToken returns the stored form of a refresh token
+func HashRefreshToken(refreshToken string) string {
+	sum := sha256.Sum256([]byte(refreshToken))
+	return hex.EncodeToString(sum[:])
+}
+
+// newRefreshToken returns a random opaque refresh token
+func newRefreshToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// TokenService issues, refreshes and revokes tokens
+type TokenService struct {
+	db      *gorm.DB
+	keys    *KeySet
+	keysErr error
+}
+
+// NewTokenService creates a TokenService with the configured keys
+func NewTokenService(db *gorm.DB) *TokenService {
+	keys, err := DefaultKeySet()
+	return &TokenService{db: db, keys: keys, keysErr: err}
+}
+
+// NewTokenServiceWithKeys creates a TokenService with the given keys
+func NewTokenServiceWithKeys(db *gorm.DB, keys *KeySet) *TokenService {
+	return &TokenService{db: db, keys: keys}
+}
+
+// keySet returns the keys, or why they could not be loaded
+func (s *TokenService) keySet() (*KeySet, error) {
+	if s.keysErr != nil {
+		return nil, utils.NewInternalError("JWT keys are not configured", s.keysErr)
+	}
+	return s.keys, nil
+}
+
+// SignAccessToken signs a short-lived access token of a session, returning the token and its claims
+func (s *TokenService) SignAccessToken(customerAddress, role, sessionID string, now time.Time) (string, *AccessClaims, error) {
+	keys, err := s.keySet()
+	if err != nil {
+		return "", nil, err
+	}
+	claims := &AccessClaims{
+		CustomerAddress: customerAddress,
+		Role:            role,
+		SessionID:       sessionID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			Issuer:    config.AppConfig.JWTIssuer,
+			Subject:   customerAddress,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(time.Duration(config.AppConfig.JWTAccessTTL) * time.Second)),
+		},
+	}
+	signed, err := keys.Sign(claims)
+	if err != nil {
+		return "", nil, utils.NewInternalError("Failed to sign access token", err)
+	}
+	return signed, claims, nil
+}
+
+// ParseAccessToken verifies an access token's signature, issuer and expiry; it does not check the session
+func (s *TokenService) ParseAccessToken(tokenString string, now time.Time) (*AccessClaims, error) {
+	keys, err := s.keySet()
+	if err != nil {
+		return nil, err
+	}
+	claims := &AccessClaims{}
+	parsed, err := keys.Parse(tokenString, claims,
+		jwt.WithIssuer(config.AppConfig.JWTIssuer),
+		jwt.WithExpirationRequired(),
+		jwt.WithTimeFunc(func() time.Time { return now }),
+	)
+	if err != nil || !parsed.Valid {
+		return nil, utils.NewForbiddenError("Invalid token", err)
+	}
+	if claims.ID == "" || claims.SessionID == "" || claims.CustomerAddress == "" {
+		return nil, utils.NewForbiddenError("Invalid token", errors.New("missing jti, sid or customer_address"))
+	}
+	return claims, nil
+}
+
+// Authenticate parses an access token and checks that its session is still active
+func (s *TokenService) Authenticate(ctx context.Context, tokenString string, now time.Time) (*AccessClaims, error) {
+	claims, err := s.ParseAccessToken(tokenString, now)
+	if err != nil {
+		return nil, err
+	}
+	active, err := s.sessionActive(ctx, claims.SessionID, now)
+	if err != nil {
+		return nil, err
+	}
+	if !active {
+		return nil, utils.NewForbiddenError("Session has been revoked or has expired", nil)
+	}
+	return claims, nil
+}
+
+// sessionCacheKey is the utils.Cache key of a session's state
+func sessionCacheKey(sessionID string) string {
+	return "auth_session:" + sessionID
+}
+
+// sessionActive tells whether a session is neither revoked nor expired, caching the answer briefly
+func (s *TokenService) sessionActive(ctx context.Context, sessionID string, now time.Time) (bool, error) {
+	if utils.Cache != nil {
+		if cached, ok := utils.Cache.Get(sessionCacheKey(sessionID)); ok {
+			if session, ok := cached.(models.AuthSession); ok {
+				return session.RevokedAt == nil && now.Before(session.ExpiresAt), nil
+			}
+		}
+	}
+	var session models.AuthSession
+	err := s.db.WithContext(ctx).Where("session_id = ?", sessionID).First(&session).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, utils.NewInternalError("Failed to check session", err)
+	}
+	if utils.Cache != nil {
+		utils.Cache.Set(sessionCacheKey(sessionID), session, sessionCacheTTL)
+	}
+	return session.RevokedAt == nil && now.Before(session.ExpiresAt), nil
+}
+
+// StartSession opens a session for a customer who has just logged in and issues its first token pair
+func (s *TokenService) StartSession(ctx context.Context, customerAddress, role, ip string, now time.Time) (*TokenPair, error) {
+	session := models.AuthSession{
+		SessionID:       uuid.NewString(),
+		CustomerAddress: customerAddress,
+		IP:              ip,
+		ExpiresAt:       now.Add(time.Duration(config.AppConfig.JWTSessionMaxAge) * time.Second),
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+	var pair *TokenPair
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&session).Error; err != nil {
+			return utils.NewInternalError("Failed to create session", err)
+		}
+		var err error
+		pair, _, err = s.issue(tx, session, role, ip, now)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return pair, nil
+}
+
+// issue signs an access token and stores a new refresh token of the session, returning the pair
+// and the ID of the stored refresh token
+func (s *TokenService) issue(tx *gorm.DB, session models.AuthSession, role, ip string, now time.Time) (*TokenPair, string, error) {
+	accessToken, claims, err := s.SignAccessToken(session.CustomerAddress, role, session.SessionID, now)
+	if err != nil {
+		return nil, "", err
+	}
+	refreshToken, err := newRefreshToken()
+	if err != nil {
+		return nil, "", utils.NewInternalError("Failed to generate refresh token", err)
+	}
+	expiresAt := now.Add(time.Duration(config.AppConfig.JWTRefreshTTL) * time.Second)
+	if session.ExpiresAt.Before(expiresAt) {
+		expiresAt = session.ExpiresAt
+	}
+	record := models.RefreshToken{
+		TokenID:   uuid.NewString(),
+		SessionID: session.SessionID,
+		TokenHash: HashRefreshToken(refreshToken),
+		AccessJTI: claims.ID,
+		ExpiresAt: expiresAt,
+		CreatedIP: ip,
+		CreatedAt: now,
+	}
+	if err := tx.Create(&record).Error; err != nil {
+		return nil, "", utils.NewInternalError("Failed to store refresh token", err)
+	}
+	return &TokenPair{
+		AccessToken:           accessToken,
+		AccessTokenExpiresAt:  claims.ExpiresAt.Time,
+		RefreshToken:          refreshToken,
+		RefreshTokenExpiresAt: expiresAt,
+		SessionID:             session.SessionID,
+	}, record.TokenID, nil
+}
+
+// Refresh exchanges a refresh token for a new token pair; the refresh token cannot be used again
+//
+// A refresh token that was already exchanged means it leaked, so the whole session is revoked.
+// The role is read again, and a customer who is no longer verified loses the session.
+func (s *TokenService) Refresh(ctx context.Context, refreshToken, ip string, now time.Time) (*TokenPair, error) {
+	var pair *TokenPair
+	var revokedFor string
+	var session models.AuthSession
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var record models.RefreshToken
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("token_hash = ?", HashRefreshToken(refreshToken)).
+			First(&record).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return utils.NewForbiddenError("Invalid refresh token", nil)
+		}
+		if err != nil {
+			return utils.NewInternalError("Failed to fetch refresh token", err)
+		}
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("session_id = ?", record.SessionID).
+			First(&session).Error; err != nil {
+			return utils.NewInternalError("Failed to fetch session", err)
+		}
+		if session.RevokedAt != nil {
+			return utils.NewForbiddenError("Session has been revoked", nil)
+		}
+		if record.UsedAt != nil {
+			revokedFor = RevokeReasonReuse
+			return revoke(tx, []string{session.SessionID}, revokedFor, now)
+		}
+		if !now.Before(record.ExpiresAt) || !now.Before(session.ExpiresAt) {
+			return utils.NewForbiddenError("Refresh token has expired, please log in again", nil)
+		}
+
+		var customer struct {
+			IsVerified bool
+			RoleName   string
+		}
+		if err := tx.Table("customers").
+			Select("customers.is_verified, roles.role_name").
+			Joins("LEFT JOIN roles ON roles.role_id = customers.role_id").
+			Where("customers.customer_address = ?", session.CustomerAddress).
+			Take(&customer).Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return utils.NewInternalError("Failed to fetch customer", err)
+		}
+		if !customer.IsVerified {
+			revokedFor = RevokeReasonUnverified
+			return revoke(tx, []string{session.SessionID}, revokedFor, now)
+		}
+
+		var replacementID string
+		pair, replacementID, err = s.issue(tx, session, customer.RoleName, ip, now)
+		if err != nil {
+			return err
+		}
+		if err := tx.Model(&models.RefreshToken{}).Where("token_id = ?", record.TokenID).
+			Updates(map[string]interface{}{"used_at": now, "replaced_by": replacementID}).Error; err != nil {
+			return utils.NewInternalError("Failed to rotate refresh token", err)
+		}
+		return tx.Model(&models.AuthSession{}).Where("session_id = ?", session.SessionID).
+			Update("updated_at", now).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	if revokedFor != "" {
+		invalidateSessions([]string{session.SessionID})
+		utils.Logger.Warn("Session revoked on refresh", "session_id", session.SessionID, "customer_address", session.CustomerAddress, "ip", ip, "reason", revokedFor)
+		if revokedFor == RevokeReasonReuse {
+			return nil, utils.NewForbiddenError("Refresh token has already been used, the session has been revoked", nil)
+		}
+		return nil, utils.NewForbiddenError("KYC verification is required, please log in again", nil)
+	}
+	return pair, nil
+}
+
+// RevokeSession revokes one session, e.g. on logout
+func (s *TokenService) RevokeSession(ctx context.Context, sessionID, reason string, now time.Time) error {
+	if err := revoke(s.db.WithContext(ctx), []string{sessionID}, reason, now); err != nil {
+		return err
+	}
+	invalidateSessions([]string{sessionID})
+	return nil
+}
+
+// RevokeCustomer revokes every active session of a customer and returns how many were revoked
+func (s *TokenService) RevokeCustomer(ctx context.Context, customerAddress, reason string, now time.Time) (int, error) {
+	var sessionIDs []string
+	if err := s.db.WithContext(ctx).Model(&models.AuthSession{}).
+		Where("LOWER(customer_address) = LOWER(?) AND revoked_at IS NULL AND expires_at > ?", customerAddress, now).
+		Pluck("session_id", &sessionIDs).Error; err != nil {
+		return 0, utils.NewInternalError("Failed to fetch sessions", err)
+	}
+	if len(sessionIDs) == 0 {
+		return 0, nil
+	}
+	if err := revoke(s.db.WithContext(ctx), sessionIDs, reason, now); err != nil {
+		return 0, err
+	}
+	invalidateSessions(sessionIDs)
+	return len(sessionIDs), nil
+}
+
+// revoke marks sessions revoked; sessions revoked earlier keep their first reason
+func revoke(tx *gorm.DB, sessionIDs []string, reason string, now time.Time) error {
+	if err := tx.Model(&models.AuthSession{}).
+		Where("session_id IN ? AND revoked_at IS NULL", sessionIDs).
+		Updates(map[string]interface{}{"revoked_at": now, "revoked_reason": reason, "updated_at": now}).Error; err != nil {
+		return utils.NewInternalError("Failed to revoke session", err)
+	}
+	return nil
+}
+
+// invalidateSessions drops the cached state of sessions so that this server refuses their tokens at once
+func invalidateSessions(sessionIDs []string) {
+	if utils.Cache == nil {
+		return
+	}
+	for _, sessionID := range sessionIDs {
+		utils.Cache.Delete(sessionCacheKey(sessionID))
+	}
+}
+
+// PurgeExpired deletes sessions, and with them their refresh tokens, that ended more than a day ago
+func (s *TokenService) PurgeExpired(ctx context.Context, now time.Time) (int64, error) {
+	cutoff := now.Add(-24 * time.Hour)
+	var purged int64
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		ended := tx.Model(&models.AuthSession{}).Select("session_id").
+			Where("expires_at < ? OR revoked_at < ?", cutoff, cutoff)
+		if err := tx.Where("session_id IN (?)", ended).Delete(&models.RefreshToken{}).Error; err != nil {
+			return err
+		}
+		result := tx.Where("expires_at < ? OR revoked_at < ?", cutoff, cutoff).Delete(&models.AuthSession{})
+		purged = result.RowsAffected
+		return result.Error
+	})
+	if err != nil {
+		return 0, utils.NewInternalError("Failed to purge sessions", err)
+	}
+	return purged, nil
+}
+
+// StartCleanupWorker purges ended sessions every hour until ctx is cancelled
+func StartCleanupWorker(ctx context.Context, db *gorm.DB) {
+	service := NewTokenService(db)
+	go func() {
+		ticker := time.NewTicker(time.Hour)
+		defer ticker.Stop()
+		for {
+			if purged, err := service.PurgeExpired(ctx, time.Now()); err != nil {
+				utils.Logger.Error("Session cleanup failed", "error", err)
+			} else if purged > 0 {
+				utils.Logger.Info("Ended sessions purged", "sessions", purged)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
@@ -0,0 +1,299 @@
+package document
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"time"
+
+	"backend/config"
+	"backend/models"
+	"backend/utils"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// legacyUploadDir is where documents were written, and served publicly, before they were private
+const legacyUploadDir = "uploads"
+
+// Accessor identifies who asks for a document, for the access decision and the audit log
+type Accessor struct {
+	Address   string
+	Role      string
+	Verifier  bool // Whether the role may view any customer's documents
+	IP        string
+	UserAgent string
+}
+
+// SignedDocumentURL is a short-lived URL to a customer's KYC document
+type SignedDocumentURL struct {
+	DocumentKey string    `json:"document_key"`
+	URL         string    `json:"url"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// Content is a document read from the local store through a signed URL
+type Content struct {
+	ContentType string
+	Data        []byte
+}
+
+// DocumentService stores KYC documents privately, issues signed URLs to them and audits every access
+type DocumentService struct {
+	db       *gorm.DB
+	store    Store
+	pipeline *Pipeline
+}
+
+// NewDocumentService creates a DocumentService on the private bucket when S3 is configured,
+// or on the encrypted local store otherwise
+func NewDocumentService(db *gorm.DB) (*DocumentService, error) {
+	store, err := DefaultStore()
+	if err != nil {
+		return nil, err
+	}
+	pipeline, err := NewPipeline()
+	if err != nil {
+		return nil, err
+	}
+	return &DocumentService{db: db, store: store, pipeline: pipeline}, nil
+}
+
+// NewDocumentServiceWithStore creates a DocumentService on the given store and upload pipeline
+func NewDocumentServiceWithStore(db *gorm.DB, store Store, pipeline *Pipeline) *DocumentService {
+	return &DocumentService{db: db, store: store, pipeline: pipeline}
+}
+
+// DefaultStore returns the configured document store
+func DefaultStore() (Store, error) {
+	if utils.S3Client != nil {
+		return &S3Store{Client: utils.S3Client, Bucket: config.AppConfig.BucketName}, nil
+	}
+	if config.AppConfig.DocumentEncryptionKey == "" {
+		return nil, errors.New("DOCUMENT_ENCRYPTION_KEY is required to store KYC documents without S3")
+	}
+	return NewLocalStore(config.AppConfig.DocumentStorageDir, legacyUploadDir, config.AppConfig.DocumentEncryptionKey)
+}
+
+// urlTTL returns the lifetime of signed URLs
+func urlTTL() time.Duration {
+	seconds := config.AppConfig.DocumentURLTTLSeconds
+	if seconds <= 0 {
+		seconds = 300
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// Upload runs a document through the upload pipeline, stores it under a new opaque key and
+// records it, unowned until registration claims it
+func (s *DocumentService) Upload(ctx context.Context, r io.Reader) (*models.KYCDocument, error) {
+	processed, err := s.pipeline.Process(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	defer processed.Close()
+
+	doc := models.KYCDocument{
+		DocumentKey: "kyc/" + uuid.NewString(),
+		Storage:     s.store.Name(),
+		ContentType: processed.ContentType,
+		Size:        processed.Size,
+		SHA256:      processed.SHA256,
+		CreatedAt:   time.Now(),
+	}
+	if err := s.store.Put(ctx, doc.DocumentKey, doc.ContentType, processed.File, processed.Size); err != nil {
+		return nil, utils.NewInternalError("Failed to store document", err)
+	}
+	if err := s.db.WithContext(ctx).Create(&doc).Error; err != nil {
+		return nil, utils.NewInternalError("Failed to record document", err)
+	}
+	utils.Logger.Info("KYC document stored", "document_key", doc.DocumentKey, "content_type", doc.ContentType, "size", doc.Size, "scanner", processed.Scanner)
+	return &doc, nil
+}
+
+// ClaimDocument binds an uploaded document to its owner inside the registration transaction
+//
+// An opaque key must exist and not belong to another customer. A path written before documents were
+// private is only accepted if it is the customer's own previous path, so neither kind of key can be used
+// to point at someone else's document.
+func ClaimDocument(tx *gorm.DB, key, owner, previousPath string) error {
+	if key == "" {
+		return nil
+	}
+	if !IsDocumentKey(key) {
+		if previousPath != "" && key == previousPath {
+			return nil
+		}
+		return utils.NewBadRequestError("Documents must be uploaded through the upload endpoint", nil)
+	}
+	var doc models.KYCDocument
+	if err := tx.Where("document_key = ?", key).First(&doc).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return utils.NewBadRequestError("Unknown document key", err)
+		}
+		return utils.NewInternalError("Failed to fetch document", err)
+	}
+	if doc.OwnerAddress != "" {
+		if !strings.EqualFold(doc.OwnerAddress, owner) {
+			return utils.NewBadRequestError("Document belongs to another customer", nil)
+		}
+		return nil
+	}
+	if err := tx.Model(&models.KYCDocument{}).Where("document_key = ? AND owner_address = ''", key).
+		Update("owner_address", owner).Error; err != nil {
+		return utils.NewInternalError("Failed to claim document", err)
+	}
+	return nil
+}
+
+// IssueURL returns a short-lived URL to a customer's KYC document for its owner or a verifier
+//
+// Only a document the customer owns is signed: an opaque key claimed by the customer, or a legacy
+// path no other customer refers to.
+func (s *DocumentService) IssueURL(ctx context.Context, customerAddress string, accessor Accessor) (*SignedDocumentURL, error) {
+	var kyc models.KYCData
+	if err := s.db.WithContext(ctx).Select("customer_address", "file_path").
+		Where("LOWER(customer_address) = LOWER(?)", customerAddress).
+		First(&kyc).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, utils.NewBadRequestError("Customer has no KYC data", err)
+		}
+		return nil, utils.NewInternalError("Failed to fetch KYC data", err)
+	}
+	key := LegacyKey(kyc.FilePath, config.AppConfig.BucketName)
+
+	if !strings.EqualFold(accessor.Address, customerAddress) && !accessor.Verifier {
+		s.audit(ctx, key, customerAddress, accessor, models.DocumentAccessDenied, "not the owner or a verifier")
+		return nil, utils.NewForbiddenError("Not allowed to view this document", nil)
+	}
+	if key == "" {
+		return nil, utils.NewBadRequestError("Customer has no KYC document", nil)
+	}
+	owned, err := s.ownsDocument(ctx, kyc, key)
+	if err != nil {
+		return nil, err
+	}
+	if !owned {
+		s.audit(ctx, key, customerAddress, accessor, models.DocumentAccessDenied, "document not owned by the customer")
+		return nil, utils.NewForbiddenError("Document does not belong to this customer", nil)
+	}
+
+	ttl := urlTTL()
+	signed, err := s.store.SignedURL(ctx, key, accessor.Address, ttl)
+	if err != nil {
+		return nil, utils.NewInternalError("Failed to sign document URL", err)
+	}
+	// A view that cannot be audited is not granted
+	if err := s.audit(ctx, key, customerAddress, accessor, models.DocumentAccessURLIssued, ""); err != nil {
+		return nil, err
+	}
+	return &SignedDocumentURL{DocumentKey: key, URL: signed, ExpiresAt: time.Now().Add(ttl)}, nil
+}
+
+// ownsDocument reports whether the document a customer's KYC data points at belongs to the customer
+func (s *DocumentService) ownsDocument(ctx context.Context, kyc models.KYCData, key string) (bool, error) {
+	if IsDocumentKey(key) {
+		var doc models.KYCDocument
+		if err := s.db.WithContext(ctx).Select("owner_address").Where("document_key = ?", key).First(&doc).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return false, nil
+			}
+			return false, utils.NewInternalError("Failed to fetch document", err)
+		}
+		return doc.OwnerAddress != "" && strings.EqualFold(doc.OwnerAddress, kyc.CustomerAddress), nil
+	}
+	var others int64
+	if err := s.db.WithContext(ctx).Model(&models.KYCData{}).
+		Where("file_path = ? AND LOWER(customer_address) <> LOWER(?)", kyc.FilePath, kyc.CustomerAddress).
+		Count(&others).Error; err != nil {
+		return false, utils.NewInternalError("Failed to check document owner", err)
+	}
+	return others == 0, nil
+}
+
+// OpenSignedContent reads a document of the local store after checking the URL's signature and expiry
+func (s *DocumentService) OpenSignedContent(ctx context.Context, key, accessorAddress, expires, sig string, accessor Accessor) (*Content, error) {
+	local, ok := s.store.(*LocalStore)
+	if !ok {
+		return nil, utils.NewBadRequestError("Documents are served by the object store", nil)
+	}
+	accessor.Address = accessorAddress
+	owner := s.ownerOf(ctx, key)
+	if !local.VerifyURL(key, accessorAddress, expires, sig, time.Now()) {
+		s.audit(ctx, key, owner, accessor, models.DocumentAccessDenied, "invalid or expired signature")
+		return nil, utils.NewForbiddenError("Invalid or expired document URL", nil)
+	}
+	data, err := local.Get(key)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, utils.NewBadRequestError("Document not found", err)
+		}
+		return nil, utils.NewInternalError("Failed to read document", err)
+	}
+	if err := s.audit(ctx, key, owner, accessor, models.DocumentAccessDownloaded, ""); err != nil {
+		return nil, err
+	}
+
+	contentType := ContentType(key)
+	var doc models.KYCDocument
+	if err := s.db.WithContext(ctx).Select("content_type").Where("document_key = ?", key).First(&doc).Error; err == nil {
+		contentType = doc.ContentType
+	}
+	return &Content{ContentType: contentType, Data: data}, nil
+}
+
+// ownerOf returns the customer who claimed a document, empty for legacy keys
+func (s *DocumentService) ownerOf(ctx context.Context, key string) string {
+	var doc models.KYCDocument
+	if err := s.db.WithContext(ctx).Select("owner_address").Where("document_key = ?", key).First(&doc).Error; err == nil {
+		return doc.OwnerAddress
+	}
+	return ""
+}
+
+// ListAccessLogs returns the most recent document accesses, optionally of one customer
+func (s *DocumentService) ListAccessLogs(ctx context.Context, customerAddress string, limit int) ([]models.DocumentAccessLog, error) {
+	if limit < 1 || limit > 500 {
+		limit = 100
+	}
+	query := s.db.WithContext(ctx).Order("created_at DESC").Limit(limit)
+	if customerAddress != "" {
+		query = query.Where("LOWER(customer_address) = LOWER(?)", customerAddress)
+	}
+	var logs []models.DocumentAccessLog
+	if err := query.Find(&logs).Error; err != nil {
+		return nil, utils.NewInternalError("Failed to fetch document access logs", err)
+	}
+	return logs, nil
+}
+
+// audit records a document access
+func (s *DocumentService) audit(ctx context.Context, key, customerAddress string, accessor Accessor, action, reason string) error {
+	entry := models.DocumentAccessLog{
+		LogID:           uuid.NewString(),
+		DocumentKey:     key,
+		CustomerAddress: customerAddress,
+		AccessorAddress: accessor.Address,
+		AccessorRole:    accessor.Role,
+		Action:          action,
+		Reason:          reason,
+		IP:              accessor.IP,
+		UserAgent:       truncate(accessor.UserAgent, 255),
+		CreatedAt:       time.Now(),
+	}
+	if err := s.db.WithContext(ctx).Create(&entry).Error; err != nil {
+		utils.Logger.Error("Failed to write document access log", "document_key", key, "action", action, "error", err)
+		return utils.NewInternalError("Failed to write document access log", err)
+	}
+	return nil
+}
+
+// truncate shortens a string to at most n bytes
+func truncate(value string, n int) string {
+	if len(value) > n {
+		return value[:n]
+	}
+	return value
+}
@@ -0,0 +1,235 @@
+package document
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// Storage backends
+const (
+	StorageS3    = "s3"
+	StorageLocal = "local"
+)
+
+// ContentPath is the endpoint serving documents of the local store through signed URLs
+const ContentPath = "/documents/v2/content"
+
+// documentKeyPattern matches the opaque keys of uploaded documents
+var documentKeyPattern = regexp.MustCompile(`^kyc/[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`)
+
+// ErrNotFound is returned when a document does not exist in the store
+var ErrNotFound = errors.New("document not found")
+
+// IsDocumentKey reports whether a key is an opaque key of an uploaded document
+func IsDocumentKey(key string) bool {
+	return documentKeyPattern.MatchString(key)
+}
+
+// LegacyKey maps a FilePath written before documents were private to a store key:
+// "/uploads/<file>" to "uploads/<file>" and a public S3 URL to its object key
+func LegacyKey(filePath, bucket string) string {
+	if strings.HasPrefix(filePath, "/uploads/") {
+		return "uploads/" + filepath.Base(filePath)
+	}
+	if parsed, err := url.Parse(filePath); err == nil && parsed.Host != "" {
+		path := strings.TrimPrefix(parsed.Path, "/")
+		if bucket != "" {
+			path = strings.TrimPrefix(path, bucket+"/")
+		}
+		return path
+	}
+	return filePath
+}
+
+// Store keeps KYC documents private and hands out short-lived URLs to them
+type Store interface {
+	Name() string
+	Put(ctx context.Context, key, contentType string, body io.ReadSeeker, size int64) error
+	SignedURL(ctx context.Context, key, accessor string, ttl time.Duration) (string, error)
+}
+
+// S3Store stores documents in a private bucket with server-side encryption and pre-signs GET requests
+type S3Store struct {
+	Client *s3.Client
+	Bucket string
+}
+
+// Name returns the storage name
+func (s *S3Store) Name() string { return StorageS3 }
+
+// Put streams a document to the bucket, objects are private by default and encrypted at rest
+func (s *S3Store) Put(ctx context.Context, key, contentType string, body io.ReadSeeker, size int64) error {
+	_, err := s.Client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:               aws.String(s.Bucket),
+		Key:                  aws.String(key),
+		Body:                 body,
+		ContentLength:        aws.Int64(size),
+		ContentType:          aws.String(contentType),
+		ACL:                  types.ObjectCannedACLPrivate,
+		ServerSideEncryption: types.ServerSideEncryptionAes256,
+	})
+	return err
+}
+
+// SignedURL pre-signs a GET of the object valid for ttl
+func (s *S3Store) SignedURL(ctx context.Context, key, accessor string, ttl time.Duration) (string, error) {
+	request, err := s3.NewPresignClient(s.Client).PresignGetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.Bucket),
+		Key:    aws.String(key),
+	}, s3.WithPresignExpires(ttl))
+	if err != nil {
+		return "", err
+	}
+	return request.URL, nil
+}
+
+// LocalStore encrypts documents with AES-256-GCM on disk, outside of any statically served directory
+type LocalStore struct {
+	Dir       string // Directory of the encrypted documents
+	LegacyDir string // Directory of the plaintext uploads written before documents were private
+	aead      cipher.AEAD
+	signKey   []byte
+}
+
+// NewLocalStore creates a local store from a hex encoded 32 byte key
+func NewLocalStore(dir, legacyDir, hexKey string) (*LocalStore, error) {
+	key, err := hex.DecodeString(hexKey)
+	if err != nil || len(key) != 32 {
+		return nil, errors.New("DOCUMENT_ENCRYPTION_KEY must be 32 bytes hex encoded")
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	signKey := sha256.Sum256(append([]byte("document-url:"), key...))
+	return &LocalStore{Dir: dir, LegacyDir: legacyDir, aead: aead, signKey: signKey[:]}, nil
+}
+
+// Name returns the storage name
+func (s *LocalStore) Name() string { return StorageLocal }
+
+// path returns the file of an uploaded document
+func (s *LocalStore) path(key string) (string, error) {
+	if !IsDocumentKey(key) {
+		return "", fmt.Errorf("invalid document key %q", key)
+	}
+	return filepath.Join(s.Dir, strings.TrimPrefix(key, "kyc/")+".enc"), nil
+}
+
+// Put encrypts a document and writes it readable by the service user only
+//
+// GCM seals the document in one piece, which the upload size limit keeps small.
+func (s *LocalStore) Put(ctx context.Context, key, contentType string, body io.ReadSeeker, size int64) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	data, err := io.ReadAll(io.LimitReader(body, size))
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.Dir, 0o700); err != nil {
+		return err
+	}
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	sealed := s.aead.Seal(nonce, nonce, data, []byte(key))
+	return os.WriteFile(path, sealed, 0o600)
+}
+
+// Get reads and decrypts a document, legacy "uploads/<file>" keys are read as plaintext
+func (s *LocalStore) Get(key string) ([]byte, error) {
+	if strings.HasPrefix(key, "uploads/") {
+		data, err := os.ReadFile(filepath.Join(s.LegacyDir, filepath.Base(key)))
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, ErrNotFound
+		}
+		return data, err
+	}
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < s.aead.NonceSize() {
+		return nil, errors.New("document is truncated")
+	}
+	nonce, ciphertext := sealed[:s.aead.NonceSize()], sealed[s.aead.NonceSize():]
+	return s.aead.Open(nil, nonce, ciphertext, []byte(key))
+}
+
+// SignedURL returns a relative URL of the content endpoint signed for the accessor until now + ttl
+func (s *LocalStore) SignedURL(ctx context.Context, key, accessor string, ttl time.Duration) (string, error) {
+	expires := time.Now().Add(ttl).Unix()
+	query := url.Values{}
+	query.Set("key", key)
+	query.Set("accessor", accessor)
+	query.Set("expires", strconv.FormatInt(expires, 10))
+	query.Set("sig", SignURL(s.signKey, key, accessor, expires))
+	return ContentPath + "?" + query.Encode(), nil
+}
+
+// VerifyURL checks the signature and expiry of a content URL's parameters
+func (s *LocalStore) VerifyURL(key, accessor, expires, sig string, now time.Time) bool {
+	return VerifyURL(s.signKey, key, accessor, expires, sig, now)
+}
+
+// SignURL returns the hex HMAC-SHA256 of a document key, accessor and expiry
+func SignURL(signKey []byte, key, accessor string, expires int64) string {
+	mac := hmac.New(sha256.New, signKey)
+	io.WriteString(mac, key+"\n"+accessor+"\n"+strconv.FormatInt(expires, 10))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyURL checks a signature made by SignURL and that it has not expired
+func VerifyURL(signKey []byte, key, accessor, expires, sig string, now time.Time) bool {
+	expiresAt, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || now.Unix() > expiresAt {
+		return false
+	}
+	return hmac.Equal([]byte(SignURL(signKey, key, accessor, expiresAt)), []byte(sig))
+}
+
+// ContentType returns the content type of an allowed document file name
+func ContentType(filename string) string {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".jpg", ".jpeg":
+		return "image/jpeg"
+	case ".png":
+		return "image/png"
+	case ".pdf":
+		return "application/pdf"
+	}
+	return "application/octet-stream"
+}
@@ -0,0 +1,155 @@
+package document
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+
+	"backend/config"
+)
+
+// Scanner backends
+const (
+	ScannerClamd = "clamd"
+	ScannerStub  = "stub"
+)
+
+// clamdChunkSize is the size of the INSTREAM chunks, below clamd's default StreamMaxLength
+const clamdChunkSize = 32 << 10
+
+// eicarSignature is the standard anti-virus test string, the only thing the stub scanner detects
+const eicarSignature = `X5O!P%@AP[4\PZX54(P^)7CC)7}$EICAR-STANDARD-ANTIVIRUS-TEST-FILE!$H+H*`
+
+// ScanResult is the verdict of a malware scan
+type ScanResult struct {
+	Clean     bool
+	Signature string // Name of the detected malware when not clean
+}
+
+// Scanner scans uploaded content for malware
+type Scanner interface {
+	Name() string
+	Scan(ctx context.Context, r io.Reader) (ScanResult, error)
+}
+
+// DefaultScanner returns the scanner selected by UPLOAD_SCANNER
+//
+// clamd is the default, so uploads are rejected while no clamd is reachable. The stub scanner,
+// which only detects the EICAR test file, must be chosen explicitly, and unknown values are refused.
+func DefaultScanner() (Scanner, error) {
+	switch config.AppConfig.UploadScanner {
+	case ScannerClamd:
+		return &ClamdScanner{
+			Address: config.AppConfig.ClamdAddress,
+			Timeout: time.Duration(config.AppConfig.ClamdTimeoutSeconds) * time.Second,
+		}, nil
+	case ScannerStub:
+		return StubScanner{}, nil
+	default:
+		return nil, fmt.Errorf("unknown UPLOAD_SCANNER %q, expected %s or %s", config.AppConfig.UploadScanner, ScannerClamd, ScannerStub)
+	}
+}
+
+// ClamdScanner streams content to a clamd daemon with the INSTREAM command
+type ClamdScanner struct {
+	Address string        // host:port, or the path of a unix socket
+	Timeout time.Duration // Bounds the whole exchange
+}
+
+// Name returns the scanner name
+func (s *ClamdScanner) Name() string { return ScannerClamd }
+
+// Scan sends the content in length-prefixed chunks and parses the "stream: ..." reply
+func (s *ClamdScanner) Scan(ctx context.Context, r io.Reader) (ScanResult, error) {
+	network := "tcp"
+	if strings.HasPrefix(s.Address, "/") {
+		network = "unix"
+	}
+	timeout := s.Timeout
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, network, s.Address)
+	if err != nil {
+		return ScanResult{}, fmt.Errorf("failed to connect to clamd: %w", err)
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	if _, err := conn.Write([]byte("zINSTREAM\x00")); err != nil {
+		return ScanResult{}, fmt.Errorf("failed to send clamd command: %w", err)
+	}
+	chunk := make([]byte, clamdChunkSize)
+	size := make([]byte, 4)
+	for {
+		n, readErr := r.Read(chunk)
+		if n > 0 {
+			binary.BigEndian.PutUint32(size, uint32(n))
+			if _, err := conn.Write(size); err != nil {
+				return ScanResult{}, fmt.Errorf("failed to stream to clamd: %w", err)
+			}
+			if _, err := conn.Write(chunk[:n]); err != nil {
+				return ScanResult{}, fmt.Errorf("failed to stream to clamd: %w", err)
+			}
+		}
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			return ScanResult{}, readErr
+		}
+	}
+	if _, err := conn.Write([]byte{0, 0, 0, 0}); err != nil {
+		return ScanResult{}, fmt.Errorf("failed to stream to clamd: %w", err)
+	}
+
+	reply, err := bufio.NewReader(conn).ReadString(0)
+	if err != nil && !(errors.Is(err, io.EOF) && reply != "") {
+		return ScanResult{}, fmt.Errorf("failed to read clamd reply: %w", err)
+	}
+	return ParseClamdReply(reply)
+}
+
+// ParseClamdReply parses a reply such as "stream: OK" or "stream: Eicar-Signature FOUND"
+func ParseClamdReply(reply string) (ScanResult, error) {
+	reply = strings.TrimSpace(strings.TrimRight(reply, "\x00"))
+	verdict := strings.TrimPrefix(reply, "stream: ")
+	switch {
+	case verdict == "OK":
+		return ScanResult{Clean: true}, nil
+	case strings.HasSuffix(verdict, " FOUND"):
+		return ScanResult{Signature: strings.TrimSuffix(verdict, " FOUND")}, nil
+	}
+	return ScanResult{}, fmt.Errorf("unexpected clamd reply %q", reply)
+}
+
+// StubScanner is a local scanner for development and tests that only detects the EICAR test file
+type StubScanner struct{}
+
+// Name returns the scanner name
+func (StubScanner) Name() string { return ScannerStub }
+
+// Scan looks for the EICAR test string
+func (StubScanner) Scan(ctx context.Context, r io.Reader) (ScanResult, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return ScanResult{}, err
+	}
+	if bytes.Contains(data, []byte(eicarSignature)) {
+		return ScanResult{Signature: "Eicar-Test-Signature"}, nil
+	}
+	return ScanResult{Clean: true}, nil
+}
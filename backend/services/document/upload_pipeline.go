@@ -0,0 +1,293 @@
+package document
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"os"
+
+	"backend/utils"
+)
+
+// Detected document types
+const (
+	TypeJPEG = "image/jpeg"
+	TypePNG  = "image/png"
+	TypePDF  = "application/pdf"
+)
+
+// MaxUploadBytes is the largest accepted document
+const MaxUploadBytes = 5 << 20
+
+// MaxImagePixels bounds decoded images, so a small file cannot expand into gigabytes of pixels
+const MaxImagePixels = 40_000_000
+
+// jpegQuality is the quality re-encoded JPEG images are written with
+const jpegQuality = 90
+
+// DetectType returns the document type from the magic bytes at the start of the content,
+// or an empty string when it is not an accepted type
+func DetectType(head []byte) string {
+	switch {
+	case bytes.HasPrefix(head, []byte{0xFF, 0xD8, 0xFF}):
+		return TypeJPEG
+	case bytes.HasPrefix(head, []byte("\x89PNG\r\n\x1a\n")):
+		return TypePNG
+	case bytes.HasPrefix(head, []byte("%PDF-")):
+		return TypePDF
+	}
+	return ""
+}
+
+// ProcessedUpload is an upload that passed the pipeline, spooled to a temporary file
+type ProcessedUpload struct {
+	File        *os.File
+	ContentType string
+	Size        int64
+	SHA256      string
+	Scanner     string
+}
+
+// Close removes the temporary file
+func (u *ProcessedUpload) Close() error {
+	name := u.File.Name()
+	u.File.Close()
+	return os.Remove(name)
+}
+
+// Pipeline checks and sanitises an upload before it reaches storage
+//
+// The content is spooled to a temporary file, its type detected from magic bytes and not
+// from the file name, scanned for malware, and images are decoded and re-encoded, which
+// drops EXIF and any other metadata or data appended to the image. PDFs are kept as they are.
+type Pipeline struct {
+	Scanner  Scanner
+	MaxBytes int64
+}
+
+// NewPipeline creates a pipeline with the configured scanner
+func NewPipeline() (*Pipeline, error) {
+	scanner, err := DefaultScanner()
+	if err != nil {
+		return nil, err
+	}
+	return &Pipeline{Scanner: scanner, MaxBytes: MaxUploadBytes}, nil
+}
+
+// Process runs an upload through the pipeline, the caller closes the result
+func (p *Pipeline) Process(ctx context.Context, r io.Reader) (*ProcessedUpload, error) {
+	spooled, err := os.CreateTemp("", "kyc-upload-*")
+	if err != nil {
+		return nil, utils.NewInternalError("Failed to buffer upload", err)
+	}
+	defer func() {
+		spooled.Close()
+		os.Remove(spooled.Name())
+	}()
+
+	written, err := io.Copy(spooled, io.LimitReader(r, p.MaxBytes+1))
+	if err != nil {
+		return nil, utils.NewBadRequestError("Failed to read upload", err)
+	}
+	if written > p.MaxBytes {
+		return nil, utils.NewBadRequestError("File size exceeds the upload limit", nil)
+	}
+
+	head := make([]byte, 512)
+	n, _ := spooled.ReadAt(head, 0)
+	contentType := DetectType(head[:n])
+	if contentType == "" {
+		return nil, utils.NewBadRequestError("Only JPEG, PNG and PDF documents are allowed", nil)
+	}
+
+	if _, err := spooled.Seek(0, io.SeekStart); err != nil {
+		return nil, utils.NewInternalError("Failed to buffer upload", err)
+	}
+	result, err := p.Scanner.Scan(ctx, spooled)
+	if err != nil {
+		// Fail closed, an unscanned document is never stored
+		return nil, utils.NewServiceError("Malware scan is unavailable", err)
+	}
+	if !result.Clean {
+		utils.Logger.Warn("Upload rejected by malware scan", "scanner", p.Scanner.Name(), "signature", result.Signature)
+		return nil, utils.NewBadRequestError("File rejected by malware scan", nil)
+	}
+
+	output, err := os.CreateTemp("", "kyc-document-*")
+	if err != nil {
+		return nil, utils.NewInternalError("Failed to buffer upload", err)
+	}
+	processed := &ProcessedUpload{File: output, ContentType: contentType, Scanner: p.Scanner.Name()}
+	if err := p.sanitise(spooled, output, contentType); err != nil {
+		processed.Close()
+		return nil, err
+	}
+
+	hash := sha256.New()
+	if _, err := output.Seek(0, io.SeekStart); err != nil {
+		processed.Close()
+		return nil, utils.NewInternalError("Failed to buffer upload", err)
+	}
+	size, err := io.Copy(hash, output)
+	if err != nil {
+		processed.Close()
+		return nil, utils.NewInternalError("Failed to buffer upload", err)
+	}
+	if _, err := output.Seek(0, io.SeekStart); err != nil {
+		processed.Close()
+		return nil, utils.NewInternalError("Failed to buffer upload", err)
+	}
+	processed.Size = size
+	processed.SHA256 = hex.EncodeToString(hash.Sum(nil))
+	return processed, nil
+}
+
+// sanitise writes the stored form of an upload: images re-encoded, PDFs copied
+func (p *Pipeline) sanitise(src *os.File, dst io.Writer, contentType string) error {
+	if _, err := src.Seek(0, io.SeekStart); err != nil {
+		return utils.NewInternalError("Failed to buffer upload", err)
+	}
+	if contentType == TypePDF {
+		if _, err := io.Copy(dst, src); err != nil {
+			return utils.NewInternalError("Failed to buffer upload", err)
+		}
+		return nil
+	}
+
+	cfg, _, err := image.DecodeConfig(src)
+	if err != nil {
+		return utils.NewBadRequestError("Invalid image", err)
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || int64(cfg.Width)*int64(cfg.Height) > MaxImagePixels {
+		return utils.NewBadRequestError("Image dimensions are too large", nil)
+	}
+	if _, err := src.Seek(0, io.SeekStart); err != nil {
+		return utils.NewInternalError("Failed to buffer upload", err)
+	}
+
+	var img image.Image
+	if contentType == TypeJPEG {
+		// The orientation lives in the EXIF data that re-encoding drops, apply it to the pixels first
+		head := make([]byte, 128<<10)
+		n, _ := src.ReadAt(head, 0)
+		orientation := JPEGOrientation(head[:n])
+		if img, err = jpeg.Decode(src); err != nil {
+			return utils.NewBadRequestError("Invalid image", err)
+		}
+		img = ApplyOrientation(img, orientation)
+		err = jpeg.Encode(dst, img, &jpeg.Options{Quality: jpegQuality})
+	} else {
+		if img, err = png.Decode(src); err != nil {
+			return utils.NewBadRequestError("Invalid image", err)
+		}
+		err = png.Encode(dst, img)
+	}
+	if err != nil {
+		return utils.NewInternalError("Failed to re-encode image", err)
+	}
+	return nil
+}
+
+// JPEGOrientation returns the EXIF orientation (1 to 8) of a JPEG from its leading bytes, 1 when absent
+func JPEGOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+	for pos := 2; pos+4 <= len(data); {
+		if data[pos] != 0xFF {
+			return 1
+		}
+		marker := data[pos+1]
+		if marker == 0xDA || marker == 0xD9 {
+			return 1 // Metadata segments come before the image data
+		}
+		length := int(binary.BigEndian.Uint16(data[pos+2:]))
+		if length < 2 || pos+2+length > len(data) {
+			return 1
+		}
+		segment := data[pos+4 : pos+2+length]
+		if marker == 0xE1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return tiffOrientation(segment[6:])
+		}
+		pos += 2 + length
+	}
+	return 1
+}
+
+// tiffOrientation reads the orientation tag (0x0112) of the first IFD of EXIF TIFF data
+func tiffOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd < 8 || ifd+2 > len(tiff) {
+		return 1
+	}
+	count := int(order.Uint16(tiff[ifd:]))
+	for i := 0; i < count; i++ {
+		entry := ifd + 2 + i*12
+		if entry+12 > len(tiff) {
+			return 1
+		}
+		if order.Uint16(tiff[entry:]) == 0x0112 {
+			value := int(order.Uint16(tiff[entry+8:]))
+			if value >= 1 && value <= 8 {
+				return value
+			}
+			return 1
+		}
+	}
+	return 1
+}
+
+// ApplyOrientation returns the image as it should be displayed for an EXIF orientation
+func ApplyOrientation(img image.Image, orientation int) image.Image {
+	if orientation <= 1 || orientation > 8 {
+		return img
+	}
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+	out := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		for x := 0; x < dw; x++ {
+			var sx, sy int
+			switch orientation {
+			case 2: // Mirrored horizontally
+				sx, sy = w-1-x, y
+			case 3: // Rotated 180
+				sx, sy = w-1-x, h-1-y
+			case 4: // Mirrored vertically
+				sx, sy = x, h-1-y
+			case 5: // Transposed
+				sx, sy = y, x
+			case 6: // Rotated 90 clockwise
+				sx, sy = y, h-1-x
+			case 7: // Transversed
+				sx, sy = w-1-y, h-1-x
+			case 8: // Rotated 90 counter-clockwise
+				sx, sy = w-1-y, x
+			}
+			out.Set(x, y, img.At(bounds.Min.X+sx, bounds.Min.Y+sy))
+		}
+	}
+	return out
+}
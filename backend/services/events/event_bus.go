@@ -0,0 +1,188 @@
+package events
+
+import (
+	"sync"
+	"time"
+)
+
+// Event types published on the bus
+const (
+	TypeIssueStatus     = "issue.status"     // An issue moved to PENDING, DRAWING or DRAWN
+	TypeTicketSold      = "ticket.sold"      // A ticket was sold and the issue's prize pool grew
+	TypeWinnerAnnounced = "winner.announced" // A winner was recorded for a drawn issue
+)
+
+// Event is a message on the bus, IDs increase monotonically within a process
+type Event struct {
+	ID        uint64      `json:"id"`
+	Type      string      `json:"type"`
+	LotteryID string      `json:"lottery_id,omitempty"`
+	IssueID   string      `json:"issue_id,omitempty"`
+	Data      interface{} `json:"data"`
+	Timestamp time.Time   `json:"timestamp"`
+}
+
+// IssueStatusData is the payload of an issue.status event
+type IssueStatusData struct {
+	IssueNumber    string  `json:"issue_number"`
+	Status         string  `json:"status"`
+	PrizePool      float64 `json:"prize_pool"`
+	WinningNumbers string  `json:"winning_numbers,omitempty"`
+	DrawTxHash     string  `json:"draw_tx_hash,omitempty"`
+}
+
+// TicketSoldData is the payload of a ticket.sold event, the buyer is not published
+type TicketSoldData struct {
+	TicketID       string  `json:"ticket_id"`
+	PurchaseAmount float64 `json:"purchase_amount"`
+	PrizePool      float64 `json:"prize_pool"`
+}
+
+// WinnerAnnouncedData is the payload of a winner.announced event
+type WinnerAnnouncedData struct {
+	WinnerID    string  `json:"winner_id"`
+	TicketID    string  `json:"ticket_id"`
+	Address     string  `json:"address"`
+	PrizeLevel  string  `json:"prize_level"`
+	PrizeAmount float64 `json:"prize_amount"`
+}
+
+// Filter selects the events a subscriber receives, empty fields match everything
+type Filter struct {
+	LotteryID string
+	IssueID   string
+	Types     map[string]bool
+}
+
+// Matches reports whether an event passes the filter
+func (f Filter) Matches(event Event) bool {
+	if f.LotteryID != "" && f.LotteryID != event.LotteryID {
+		return false
+	}
+	if f.IssueID != "" && f.IssueID != event.IssueID {
+		return false
+	}
+	if len(f.Types) > 0 && !f.Types[event.Type] {
+		return false
+	}
+	return true
+}
+
+// subscription is a subscriber's channel and filter
+type subscription struct {
+	ch     chan Event
+	filter Filter
+}
+
+// Bus is an in-process publish/subscribe bus
+//
+// Publishing never blocks: a subscriber whose buffer is full is dropped and its channel closed,
+// it reconnects with the last event ID it saw and the missed events are replayed from the history.
+type Bus struct {
+	mu          sync.Mutex
+	bufferSize  int
+	historySize int
+	lastID      uint64
+	history     []Event
+	nextSubID   uint64
+	subscribers map[uint64]*subscription
+}
+
+// NewBus creates a bus with the given subscriber buffer and replay history sizes
+func NewBus(bufferSize, historySize int) *Bus {
+	if bufferSize <= 0 {
+		bufferSize = 1
+	}
+	return &Bus{
+		bufferSize:  bufferSize,
+		historySize: historySize,
+		subscribers: make(map[uint64]*subscription),
+	}
+}
+
+// Publish assigns the event an ID and timestamp and delivers it to the matching subscribers
+func (b *Bus) Publish(event Event) Event {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.lastID++
+	event.ID = b.lastID
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now().UTC()
+	}
+	if b.historySize > 0 {
+		b.history = append(b.history, event)
+		if len(b.history) > b.historySize {
+			b.history = b.history[len(b.history)-b.historySize:]
+		}
+	}
+
+	for id, sub := range b.subscribers {
+		if !sub.filter.Matches(event) {
+			continue
+		}
+		select {
+		case sub.ch <- event:
+		default:
+			close(sub.ch)
+			delete(b.subscribers, id)
+		}
+	}
+	return event
+}
+
+// Subscribe registers a subscriber, replaying the kept events after lastEventID first (0 replays nothing)
+//
+// The returned function unsubscribes, the channel is closed when the subscriber is dropped or unsubscribed.
+func (b *Bus) Subscribe(filter Filter, lastEventID uint64) (<-chan Event, func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var replay []Event
+	if lastEventID > 0 {
+		for _, event := range b.history {
+			if event.ID > lastEventID && filter.Matches(event) {
+				replay = append(replay, event)
+			}
+		}
+	}
+	size := b.bufferSize
+	if len(replay) > size {
+		size = len(replay)
+	}
+	sub := &subscription{ch: make(chan Event, size), filter: filter}
+	for _, event := range replay {
+		sub.ch <- event
+	}
+
+	b.nextSubID++
+	id := b.nextSubID
+	b.subscribers[id] = sub
+
+	var once sync.Once
+	return sub.ch, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+			if _, ok := b.subscribers[id]; ok {
+				close(sub.ch)
+				delete(b.subscribers, id)
+			}
+		})
+	}
+}
+
+// SubscriberCount returns the number of active subscribers
+func (b *Bus) SubscriberCount() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.subscribers)
+}
+
+// Default is the process-wide bus fed by the draw and ticket services
+var Default = NewBus(64, 1000)
+
+// Publish publishes an event on the default bus
+func Publish(event Event) Event {
+	return Default.Publish(event)
+}
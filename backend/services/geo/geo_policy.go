@@ -0,0 +1,86 @@
+package geo
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"backend/models"
+)
+
+// Decision is the outcome of checking a client's country against a country policy
+type Decision struct {
+	Allowed bool   `json:"allowed"`
+	Country string `json:"country"` // ISO 3166-1 alpha-2, empty when unknown
+	Result  string `json:"result"`  // One of the models.GeoDecision constants
+	Reason  string `json:"reason,omitempty"`
+}
+
+// ParseCountries parses a comma-separated list of ISO 3166-1 alpha-2 codes, returning them uppercased,
+// deduplicated and sorted
+func ParseCountries(list string) ([]string, error) {
+	seen := make(map[string]bool)
+	var countries []string
+	for _, code := range strings.Split(list, ",") {
+		code = strings.ToUpper(strings.TrimSpace(code))
+		if code == "" {
+			continue
+		}
+		if len(code) != 2 || code[0] < 'A' || code[0] > 'Z' || code[1] < 'A' || code[1] > 'Z' {
+			return nil, fmt.Errorf("invalid country code %q, want ISO 3166-1 alpha-2", code)
+		}
+		if !seen[code] {
+			seen[code] = true
+			countries = append(countries, code)
+		}
+	}
+	sort.Strings(countries)
+	return countries, nil
+}
+
+// FormatCountries joins country codes the way they are stored on a lottery
+func FormatCountries(countries []string) string {
+	return strings.Join(countries, ",")
+}
+
+// contains reports whether a stored country list holds a country
+func contains(list, country string) bool {
+	for _, code := range strings.Split(list, ",") {
+		if strings.EqualFold(strings.TrimSpace(code), country) {
+			return true
+		}
+	}
+	return false
+}
+
+// Restricted reports whether a lottery limits where it can be bought from
+func Restricted(lottery models.Lottery) bool {
+	return strings.TrimSpace(lottery.AllowedCountries) != "" || strings.TrimSpace(lottery.BlockedCountries) != ""
+}
+
+// Decide checks a country against a lottery's allowed and blocked lists
+//
+// checked is false when no GeoIP database is available, a restricted lottery is then refused rather than
+// sold everywhere. A blocked country is refused; with an allowed list, only its countries pass. An unknown
+// country passes unless blockUnknown is set and the lottery is restricted.
+func Decide(lottery models.Lottery, country string, checked, blockUnknown bool) Decision {
+	if !checked {
+		if Restricted(lottery) {
+			return Decision{Allowed: false, Result: models.GeoDecisionUnchecked, Reason: "country restrictions cannot be checked without a GeoIP database"}
+		}
+		return Decision{Allowed: true, Result: models.GeoDecisionUnchecked}
+	}
+	if country == "" {
+		if blockUnknown && Restricted(lottery) {
+			return Decision{Allowed: false, Result: models.GeoDecisionBlocked, Reason: "country could not be determined"}
+		}
+		return Decision{Allowed: true, Result: models.GeoDecisionUnknown}
+	}
+	if contains(lottery.BlockedCountries, country) {
+		return Decision{Allowed: false, Country: country, Result: models.GeoDecisionBlocked, Reason: "country is blocked"}
+	}
+	if strings.TrimSpace(lottery.AllowedCountries) != "" && !contains(lottery.AllowedCountries, country) {
+		return Decision{Allowed: false, Country: country, Result: models.GeoDecisionBlocked, Reason: "country is not allowed"}
+	}
+	return Decision{Allowed: true, Country: country, Result: models.GeoDecisionAllowed}
+}
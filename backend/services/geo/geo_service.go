@@ -0,0 +1,81 @@
+package geo
+
+import (
+	"context"
+	"net"
+
+	"backend/config"
+	"backend/models"
+	"backend/utils"
+
+	"gorm.io/gorm"
+)
+
+// GeoService enforces the country restrictions of the lotteries
+type GeoService struct {
+	db      *gorm.DB
+	locator Locator
+}
+
+// NewGeoService creates a GeoService with the default locator
+func NewGeoService(db *gorm.DB) *GeoService {
+	return NewGeoServiceWithLocator(db, DefaultLocator())
+}
+
+// NewGeoServiceWithLocator creates a GeoService with a given locator, nil disabling the checks
+func NewGeoServiceWithLocator(db *gorm.DB, locator Locator) *GeoService {
+	return &GeoService{db: db, locator: locator}
+}
+
+// Locate returns the country of an IP address and whether it could be checked at all
+func (s *GeoService) Locate(ip string) (string, bool) {
+	if s.locator == nil {
+		return "", false
+	}
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return "", true
+	}
+	country, err := s.locator.Country(parsed)
+	if err != nil {
+		utils.Logger.Warn("GeoIP lookup failed", "ip", ip, "error", err)
+		return "", true
+	}
+	return country, true
+}
+
+// CheckPurchase decides whether a lottery can be bought from an IP address and logs the decision
+func (s *GeoService) CheckPurchase(lottery models.Lottery, ip string) (Decision, error) {
+	country, checked := s.Locate(ip)
+	decision := Decide(lottery, country, checked, config.AppConfig.GeoIPBlockUnknown)
+	if !decision.Allowed {
+		utils.Logger.Warn("Purchase refused by country restriction", "lottery_id", lottery.LotteryID, "ip", ip, "country", country, "reason", decision.Reason)
+		return decision, utils.NewForbiddenError("Purchases of this lottery are not allowed from your country", nil)
+	}
+	return decision, nil
+}
+
+// CheckLogin refuses a login from a country where no active lottery can be bought
+//
+// Without restricted active lotteries every login passes. Without a GeoIP database only the
+// unrestricted lotteries can be bought, so logins pass only if there is one.
+func (s *GeoService) CheckLogin(ctx context.Context, ip string) error {
+	country, checked := s.Locate(ip)
+	var lotteries []models.Lottery
+	if err := s.db.WithContext(ctx).
+		Select("lottery_id, allowed_countries, blocked_countries").
+		Where("status = ?", models.LotteryStatusActive).
+		Find(&lotteries).Error; err != nil {
+		return utils.NewInternalError("Failed to fetch lotteries", err)
+	}
+	if len(lotteries) == 0 {
+		return nil
+	}
+	for _, lottery := range lotteries {
+		if Decide(lottery, country, checked, config.AppConfig.GeoIPBlockUnknown).Allowed {
+			return nil
+		}
+	}
+	utils.Logger.Warn("Login refused by country restriction", "ip", ip, "country", country)
+	return utils.NewForbiddenError("The service is not available in your country", nil)
+}
@@ -0,0 +1,78 @@
+package geo
+
+import (
+	"net"
+	"strings"
+	"sync"
+
+	"backend/config"
+	"backend/utils"
+
+	"github.com/oschwald/maxminddb-golang"
+)
+
+// Locator resolves the country of an IP address
+type Locator interface {
+	// Country returns the ISO 3166-1 alpha-2 code of ip, empty when the database does not know it
+	Country(ip net.IP) (string, error)
+}
+
+// MMDBLocator reads countries from a MaxMind database file (GeoIP2/GeoLite2 Country or City, or compatible)
+type MMDBLocator struct {
+	reader *maxminddb.Reader
+}
+
+// OpenMMDB opens a MaxMind database file
+func OpenMMDB(path string) (*MMDBLocator, error) {
+	reader, err := maxminddb.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	return &MMDBLocator{reader: reader}, nil
+}
+
+// mmdbRecord is the part of a Country or City record the locator reads
+type mmdbRecord struct {
+	Country struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"country"`
+	RegisteredCountry struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"registered_country"`
+}
+
+// Country looks ip up, falling back to the country the network is registered in
+func (l *MMDBLocator) Country(ip net.IP) (string, error) {
+	var record mmdbRecord
+	if err := l.reader.Lookup(ip, &record); err != nil {
+		return "", err
+	}
+	if record.Country.ISOCode != "" {
+		return strings.ToUpper(record.Country.ISOCode), nil
+	}
+	return strings.ToUpper(record.RegisteredCountry.ISOCode), nil
+}
+
+var (
+	defaultLocator     Locator
+	defaultLocatorOnce sync.Once
+)
+
+// DefaultLocator returns the locator of GEOIP_DB_PATH, loaded once; nil when no database is configured
+// or it cannot be opened, in which case lotteries with country restrictions cannot be bought
+func DefaultLocator() Locator {
+	defaultLocatorOnce.Do(func() {
+		path := config.AppConfig.GeoIPDBPath
+		if path == "" {
+			utils.Logger.Warn("GEOIP_DB_PATH is not set, lotteries with country restrictions cannot be bought")
+			return
+		}
+		locator, err := OpenMMDB(path)
+		if err != nil {
+			utils.Logger.Error("Failed to open GeoIP database, lotteries with country restrictions cannot be bought", "path", path, "error", err)
+			return
+		}
+		defaultLocator = locator
+	})
+	return defaultLocator
+}
@@ -0,0 +1,139 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"backend/config"
+	"backend/models"
+	"backend/utils"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrKeyReused is returned when an idempotency key is reused with a different request payload
+var ErrKeyReused = errors.New("idempotency key already used for a different request")
+
+// IdempotencyService stores idempotency keys together with the first response for each key
+type IdempotencyService struct {
+	db    *gorm.DB
+	ttl   time.Duration
+	lease time.Duration // How long an in-progress key stays reserved before a retry can take it over
+}
+
+// NewIdempotencyService creates a new IdempotencyService instance
+func NewIdempotencyService(db *gorm.DB) *IdempotencyService {
+	return &IdempotencyService{
+		db:    db,
+		ttl:   time.Duration(config.AppConfig.IdempotencyKeyTTLHours) * time.Hour,
+		lease: time.Duration(config.AppConfig.IdempotencyLeaseSeconds) * time.Second,
+	}
+}
+
+// Begin reserves an idempotency key for a request
+//
+// Keys are scoped to the principal making the request, so a key never replays another caller's response.
+// An in-progress key is leased to the request that reserved it; if that request died without completing or
+// releasing the key, a retry with the same payload takes the key over once the lease has expired.
+//
+// Returns:
+//   - *IdempotencyKey: The stored record for the key
+//   - bool: true if the key was reserved by this call and the request should be executed
+//   - error: ErrKeyReused if the key belongs to a different request, or a database error
+func (s *IdempotencyService) Begin(ctx context.Context, key, principal, method, path, requestHash string) (*models.IdempotencyKey, bool, error) {
+	// Expired keys can be reused
+	if err := s.db.WithContext(ctx).
+		Where("idempotency_key = ? AND principal = ? AND method = ? AND path = ? AND expires_at < ?", key, principal, method, path, time.Now()).
+		Delete(&models.IdempotencyKey{}).Error; err != nil {
+		return nil, false, utils.NewInternalError("Failed to purge expired idempotency key", err)
+	}
+
+	record := models.IdempotencyKey{
+		IdempotencyKey: key,
+		Principal:      principal,
+		Method:         method,
+		Path:           path,
+		RequestHash:    requestHash,
+		Status:         models.IdempotencyStatusInProgress,
+		ExpiresAt:      time.Now().Add(s.ttl),
+		LockedUntil:    time.Now().Add(s.lease),
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
+	}
+	result := s.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&record)
+	if result.Error != nil {
+		return nil, false, utils.NewInternalError("Failed to reserve idempotency key", result.Error)
+	}
+	if result.RowsAffected == 1 {
+		return &record, true, nil
+	}
+
+	// The key already exists, load it to decide between replay and conflict
+	var existing models.IdempotencyKey
+	if err := s.db.WithContext(ctx).
+		Where("idempotency_key = ? AND principal = ? AND method = ? AND path = ?", key, principal, method, path).
+		First(&existing).Error; err != nil {
+		return nil, false, utils.NewInternalError("Failed to load idempotency key", err)
+	}
+	if existing.RequestHash != requestHash {
+		utils.Logger.Warn("Idempotency key reused with different payload", "idempotency_key", key, "path", path)
+		return &existing, false, ErrKeyReused
+	}
+	if existing.Status == models.IdempotencyStatusInProgress && existing.LockedUntil.Before(time.Now()) {
+		return s.takeOver(ctx, &existing)
+	}
+	return &existing, false, nil
+}
+
+// takeOver reserves an in-progress key whose lease has expired, only one concurrent retry wins it
+func (s *IdempotencyService) takeOver(ctx context.Context, existing *models.IdempotencyKey) (*models.IdempotencyKey, bool, error) {
+	lockedUntil := time.Now().Add(s.lease)
+	result := s.db.WithContext(ctx).Model(&models.IdempotencyKey{}).
+		Where("idempotency_key = ? AND principal = ? AND method = ? AND path = ? AND status = ? AND locked_until < ?",
+			existing.IdempotencyKey, existing.Principal, existing.Method, existing.Path, models.IdempotencyStatusInProgress, time.Now()).
+		Updates(map[string]interface{}{
+			"locked_until": lockedUntil,
+			"updated_at":   time.Now(),
+		})
+	if result.Error != nil {
+		return nil, false, utils.NewInternalError("Failed to take over idempotency key", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return existing, false, nil
+	}
+	utils.Logger.Warn("Taking over idempotency key with expired lease", "idempotency_key", existing.IdempotencyKey, "path", existing.Path)
+	existing.LockedUntil = lockedUntil
+	return existing, true, nil
+}
+
+// Complete stores the response of a request so duplicates can be replayed
+func (s *IdempotencyService) Complete(ctx context.Context, key, principal, method, path string, responseCode int, contentType string, body []byte) error {
+	err := s.db.WithContext(ctx).Model(&models.IdempotencyKey{}).
+		Where("idempotency_key = ? AND principal = ? AND method = ? AND path = ?", key, principal, method, path).
+		Updates(map[string]interface{}{
+			"status":        models.IdempotencyStatusCompleted,
+			"response_code": responseCode,
+			"content_type":  contentType,
+			"response_body": body,
+			"updated_at":    time.Now(),
+		}).Error
+	if err != nil {
+		utils.Logger.Error("Failed to store idempotent response", "idempotency_key", key, "error", err)
+		return utils.NewInternalError("Failed to store idempotent response", err)
+	}
+	return nil
+}
+
+// Release removes an in-progress key so the request can be retried with the same key
+func (s *IdempotencyService) Release(ctx context.Context, key, principal, method, path string) error {
+	err := s.db.WithContext(ctx).
+		Where("idempotency_key = ? AND principal = ? AND method = ? AND path = ? AND status = ?", key, principal, method, path, models.IdempotencyStatusInProgress).
+		Delete(&models.IdempotencyKey{}).Error
+	if err != nil {
+		utils.Logger.Error("Failed to release idempotency key", "idempotency_key", key, "error", err)
+		return utils.NewInternalError("Failed to release idempotency key", err)
+	}
+	return nil
+}
@@ -6,10 +6,12 @@ import (
 
 	"backend/blockchain"
 	"backend/models"
+	"backend/services/webhook"
 	"backend/utils"
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/google/uuid"
 	"github.com/pkg/errors"
 	"gorm.io/gorm"
 )
@@ -25,6 +27,31 @@ type CreateIssueParams struct {
 	WinningNumbers string
 	RandomSeed     string
 	DrawTxHash     string
+	IdempotencyKey string // 幂等键，相同彩票下重复提交返回已创建的期号
+}
+
+// IssueStartAction 创建期号时对合约的操作
+type IssueStartAction int
+
+const (
+	// IssueStartRefuse 上一期仍在销售或开奖，不能开新期
+	IssueStartRefuse IssueStartAction = iota
+	// IssueStartDistribute 合约处于 Ready，发送 Distribute 交易开始新的 epoch
+	IssueStartDistribute
+	// IssueStartAdopt 合约已处于 Distribute 但当前 epoch 没有期号：之前的请求交易已上链、保存期号失败，直接保存期号
+	IssueStartAdopt
+)
+
+// PlanIssueStart 根据合约状态和当前 epoch 是否已有期号决定创建期号时的操作
+func PlanIssueStart(state uint8, epochHasIssue bool) IssueStartAction {
+	switch {
+	case state == uint8(models.ContractStateReady):
+		return IssueStartDistribute
+	case state == uint8(models.ContractStateDistribute) && !epochHasIssue:
+		return IssueStartAdopt
+	default:
+		return IssueStartRefuse
+	}
 }
 
 // IssueCreateService 封装期号创建的业务逻辑
@@ -50,16 +77,34 @@ func (s *IssueCreateService) validateCreateIssueParams(params CreateIssueParams)
 		}
 		return utils.NewInternalError("Failed to check lottery ID", errors.Wrap(err, "database error"))
 	}
+	if lottery.Status != models.LotteryStatusActive {
+		utils.Logger.Warn("Lottery is not active", "lottery_id", params.LotteryID, "status", lottery.Status)
+		return utils.NewBadRequestError("Lottery is not active", nil)
+	}
 
-	// 验证 issue_number 唯一性
-	var existingIssue models.LotteryIssue
-	if err := s.db.WithContext(context.Background()).
-		Where("lottery_id = ? AND issue_number = ?", params.LotteryID, params.IssueNumber).
-		First(&existingIssue).Error; err == nil {
-		utils.Logger.Warn("Issue number already exists", "issue_number", params.IssueNumber)
-		return utils.NewBadRequestError("Issue number already exists", nil)
-	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
-		return utils.NewInternalError("Failed to check issue number uniqueness", errors.Wrap(err, "database error"))
+	// 合约同一时间只记录一个 epoch 的投注，上一期未开奖完成前不能开新期
+	var openIssues int64
+	if err := s.db.WithContext(context.Background()).Model(&models.LotteryIssue{}).
+		Where("lottery_id = ? AND status IN ?", params.LotteryID, []string{models.IssueStatusPending, models.IssueStatusDrawing}).
+		Count(&openIssues).Error; err != nil {
+		return utils.NewInternalError("Failed to check open issues", errors.Wrap(err, "database error"))
+	}
+	if openIssues > 0 {
+		utils.Logger.Warn("Lottery has an open issue", "lottery_id", params.LotteryID)
+		return utils.NewBadRequestError("Lottery already has an issue on sale or being drawn", nil)
+	}
+
+	// 验证 issue_number 唯一性（未指定时自动生成）
+	if params.IssueNumber != "" {
+		var existingIssue models.LotteryIssue
+		if err := s.db.WithContext(context.Background()).
+			Where("lottery_id = ? AND issue_number = ?", params.LotteryID, params.IssueNumber).
+			First(&existingIssue).Error; err == nil {
+			utils.Logger.Warn("Issue number already exists", "issue_number", params.IssueNumber)
+			return utils.NewBadRequestError("Issue number already exists", nil)
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return utils.NewInternalError("Failed to check issue number uniqueness", errors.Wrap(err, "database error"))
+		}
 	}
 
 	// 验证状态
@@ -76,10 +121,20 @@ func (s *IssueCreateService) validateCreateIssueParams(params CreateIssueParams)
 	}
 
 	// 验证字段长度
+	if len(params.IssueNumber) > 50 || len(params.IdempotencyKey) > 100 {
+		return utils.NewBadRequestError("Issue number or idempotency key too long", nil)
+	}
 	if len(params.WinningNumbers) > 100 || len(params.RandomSeed) > 100 || len(params.DrawTxHash) > 66 {
 		return utils.NewBadRequestError("Optional field length exceeded", nil)
 	}
 
+	// 验证期号编号规则
+	if params.IssueNumber == "" {
+		if err := ValidateIssueNumberPattern(issueNumberPattern(&lottery)); err != nil {
+			return utils.NewBadRequestError("Invalid issue number pattern", err)
+		}
+	}
+
 	return nil
 }
 
@@ -94,26 +149,50 @@ func (s *IssueCreateService) validateCreateIssueParams(params CreateIssueParams)
 //   - common.Hash: 区块链交易哈希
 //   - error: 创建错误或参数无效
 func (s *IssueCreateService) CreateIssue(ctx context.Context, params CreateIssueParams) (*models.LotteryIssue, common.Hash, error) {
+	// 幂等处理：相同幂等键已创建过期号时直接返回
+	if params.IdempotencyKey != "" {
+		existing, err := s.findIssueByIdempotencyKey(ctx, params.LotteryID, params.IdempotencyKey)
+		if err != nil {
+			return nil, common.Hash{}, err
+		}
+		if existing != nil {
+			utils.Logger.Info("Issue already created for idempotency key", "issue_id", existing.IssueID, "idempotency_key", params.IdempotencyKey)
+			return existing, common.Hash{}, nil
+		}
+	}
+
 	// 验证参数
 	if err := s.validateCreateIssueParams(params); err != nil {
 		return nil, common.Hash{}, err
 	}
 
+	// 未指定期号编号时按规则自动生成
+	if params.IssueNumber == "" {
+		issueNumber, err := s.generateIssueNumber(ctx, params.LotteryID, params.DrawTime)
+		if err != nil {
+			return nil, common.Hash{}, err
+		}
+		params.IssueNumber = issueNumber
+	}
+
 	// 构造期号记录
 	issue := models.LotteryIssue{
-		IssueID:        generateUUID(),
+		IssueID:        uuid.NewString(),
 		LotteryID:      params.LotteryID,
 		IssueNumber:    params.IssueNumber,
 		SaleEndTime:    params.SaleEndTime,
 		DrawTime:       params.DrawTime,
 		Status:         params.Status,
-		PrizePool:      0, // 按原始代码，初始奖池为 0
+		PrizePool:      0, // 初始奖池为 0，保存时计入待结转的奖池
 		WinningNumbers: params.WinningNumbers,
 		RandomSeed:     params.RandomSeed,
 		DrawTxHash:     params.DrawTxHash,
 		CreatedAt:      time.Now(),
 		UpdatedAt:      time.Now(),
 	}
+	if params.IdempotencyKey != "" {
+		issue.IdempotencyKey = &params.IdempotencyKey
+	}
 
 	var lottery models.Lottery
 
@@ -122,6 +201,10 @@ func (s *IssueCreateService) CreateIssue(ctx context.Context, params CreateIssue
 
 	// 执行区块链交易
 	executeTx := func() (common.Hash, error) {
+		// 重试时从初始值开始计算，避免上次失败尝试中计入的结转被重复计入
+		issue.CarryOver = 0
+		issue.PrizePool = 0
+
 		if err := s.db.WithContext(ctx).Preload("LotteryType").
 			Where("lottery_id = ?", issue.LotteryID).
 			First(&lottery).Error; err != nil {
@@ -154,35 +237,94 @@ func (s *IssueCreateService) CreateIssue(ctx context.Context, params CreateIssue
 		}
 		utils.Logger.Info("Current contract state", "state", currentState)
 
-		// 设置合约状态为 Distribute
-		tx, err := contract.TransState(blockchain.Auth, uint8(1))
+		// 记录本期投注所属的合约 epoch，Distribute 不改变 epoch（开奖后 Rollout -> Ready 时递增）
+		epoch, err := contract.Epoch(&bind.CallOpts{Context: ctx})
 		if err != nil {
-			utils.Logger.Error("Failed to set state to Distribute", "error", err)
-			if tx != nil {
-				return tx.Hash(), utils.NewInternalError("Failed to set state to Distribute", errors.Wrap(err, "transaction error"))
+			utils.Logger.Error("Failed to get contract epoch", "error", err)
+			return common.Hash{}, utils.NewInternalError("Failed to get contract epoch", errors.Wrap(err, "contract epoch error"))
+		}
+		issueEpoch := epoch.Int64()
+		issue.Epoch = &issueEpoch
+
+		var epochIssues int64
+		if err := s.db.WithContext(ctx).Model(&models.LotteryIssue{}).
+			Where("lottery_id = ? AND epoch = ?", lottery.LotteryID, issueEpoch).
+			Count(&epochIssues).Error; err != nil {
+			return common.Hash{}, utils.NewInternalError("Failed to check issues of contract epoch", errors.Wrap(err, "database error"))
+		}
+
+		var txHash common.Hash
+		switch PlanIssueStart(currentState, epochIssues > 0) {
+		case IssueStartAdopt:
+			// 上次请求的 Distribute 交易已上链但期号未保存，直接为该 epoch 补写期号
+			utils.Logger.Warn("Contract already in Distribute for an epoch without issue, saving the issue without a new transaction",
+				"lottery_id", lottery.LotteryID, "epoch", issueEpoch)
+		case IssueStartDistribute:
+			// 设置合约状态为 Distribute
+			tx, err := contract.TransState(blockchain.Auth, uint8(models.ContractStateDistribute))
+			if err != nil {
+				utils.Logger.Error("Failed to set state to Distribute", "error", err)
+				if tx != nil {
+					return tx.Hash(), utils.NewInternalError("Failed to set state to Distribute", errors.Wrap(err, "transaction error"))
+				}
+				return common.Hash{}, utils.NewInternalError("Failed to set state to Distribute", errors.Wrap(err, "transaction error"))
+			}
+
+			// 等待交易确认
+			receipt, err := bind.WaitMined(ctx, blockchain.Client, tx)
+			if err != nil {
+				utils.Logger.Error("Transaction failed", "tx_hash", tx.Hash().Hex(), "error", err)
+				return tx.Hash(), utils.NewInternalError("Transaction failed", errors.Wrap(err, "transaction mining error"))
+			}
+			if receipt.Status != 1 {
+				utils.Logger.Error("Transaction failed", "tx_hash", tx.Hash().Hex(), "status", receipt.Status)
+				return tx.Hash(), utils.NewInternalError("Transaction failed", nil)
 			}
-			return common.Hash{}, utils.NewInternalError("Failed to set state to Distribute", errors.Wrap(err, "transaction error"))
+			txHash = tx.Hash()
+		default:
+			// Distribute/Rollout 且该 epoch 已有期号，表示上一期仍在销售或开奖
+			utils.Logger.Warn("Contract is not ready for a new issue", "lottery_id", lottery.LotteryID, "state", currentState, "epoch", issueEpoch)
+			return common.Hash{}, blockchain.NonRetryable(utils.NewBadRequestError("Contract is not ready for a new issue, the previous issue is still on sale or being drawn", nil))
 		}
 
-		// 等待交易确认
-		receipt, err := bind.WaitMined(ctx, blockchain.Client, tx)
+		// 之前无人中奖期号的奖池已随 clear() 转给 owner，先从金库转入合约，链上到账后才计入本期奖池
+		rollovers, carryOver, err := pendingRollovers(s.db.WithContext(ctx), lottery.LotteryID)
 		if err != nil {
-			utils.Logger.Error("Transaction failed", "tx_hash", tx.Hash().Hex(), "error", err)
-			return tx.Hash(), utils.NewInternalError("Transaction failed", errors.Wrap(err, "transaction mining error"))
+			return txHash, utils.NewInternalError("Failed to fetch pending rollovers", errors.Wrap(err, "database error"))
 		}
-		if receipt.Status != 1 {
-			utils.Logger.Error("Transaction failed", "tx_hash", tx.Hash().Hex(), "status", receipt.Status)
-			return tx.Hash(), utils.NewInternalError("Transaction failed", nil)
+		var fundingTxHash common.Hash
+		if carryOver > 0 {
+			funded, hash, err := fundRollovers(ctx, lottery.ContractAddress, carryOver)
+			if err != nil {
+				return txHash, err
+			}
+			if !funded {
+				rollovers = nil
+			}
+			fundingTxHash = hash
 		}
 
-		// 保存到数据库
-		if err := s.db.WithContext(ctx).Create(&issue).Error; err != nil {
+		// 保存到数据库，同时将已到账的结转计入本期
+		err = s.db.WithContext(ctx).Transaction(func(dbTx *gorm.DB) error {
+			if err := applyPendingRollovers(dbTx, &issue, rollovers, fundingTxHash); err != nil {
+				return err
+			}
+			if err := dbTx.Create(&issue).Error; err != nil {
+				return err
+			}
+			// 与期号在同一事务中记录开售通知
+			if issue.Status != models.IssueStatusPending {
+				return nil
+			}
+			return webhook.Enqueue(dbTx, models.WebhookEventIssueOpened, models.WebhookEventIssueOpened+":"+issue.IssueID, issue.LotteryID, webhook.NewIssueData(&issue))
+		})
+		if err != nil {
 			utils.Logger.Error("Failed to save issue to database", "error", err)
-			return tx.Hash(), utils.NewInternalError("failed to save issue to database", err)
+			return txHash, utils.NewInternalError("failed to save issue to database", err)
 		}
 
-		utils.Logger.Info("Issue created successfully", "issue_id", issue.IssueID)
-		return tx.Hash(), nil
+		utils.Logger.Info("Issue created successfully", "issue_id", issue.IssueID, "epoch", issueEpoch)
+		return txHash, nil
 	}
 
 	// 执行区块链交易
@@ -195,7 +337,39 @@ func (s *IssueCreateService) CreateIssue(ctx context.Context, params CreateIssue
 	return &issue, txhash, nil
 }
 
-// generateUUID 生成唯一 ID（示例）
-func generateUUID() string {
-	return "issue-" + time.Now().Format("20060102150405")
+// findIssueByIdempotencyKey 根据幂等键查询已创建的期号，不存在时返回 nil
+func (s *IssueCreateService) findIssueByIdempotencyKey(ctx context.Context, lotteryID, key string) (*models.LotteryIssue, error) {
+	var issue models.LotteryIssue
+	err := s.db.WithContext(ctx).Preload("Lottery").
+		Where("lottery_id = ? AND idempotency_key = ?", lotteryID, key).
+		First(&issue).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, utils.NewInternalError("Failed to check idempotency key", errors.Wrap(err, "database error"))
+	}
+	return &issue, nil
+}
+
+// generateIssueNumber 按彩票的期号规则生成期号编号，序号由数据库序列表分配
+func (s *IssueCreateService) generateIssueNumber(ctx context.Context, lotteryID string, drawTime time.Time) (string, error) {
+	var lottery models.Lottery
+	if err := s.db.WithContext(ctx).Where("lottery_id = ?", lotteryID).First(&lottery).Error; err != nil {
+		return "", utils.NewInternalError("Failed to load lottery", errors.Wrap(err, "database error"))
+	}
+
+	pattern := issueNumberPattern(&lottery)
+	seq, err := nextIssueSequence(s.db.WithContext(ctx), lotteryID, IssuePeriodKey(pattern, drawTime))
+	if err != nil {
+		utils.Logger.Error("Failed to allocate issue sequence", "lottery_id", lotteryID, "error", err)
+		return "", utils.NewInternalError("Failed to allocate issue sequence", errors.Wrap(err, "database error"))
+	}
+
+	issueNumber := FormatIssueNumber(pattern, drawTime, seq)
+	if len(issueNumber) > 50 {
+		return "", utils.NewBadRequestError("Generated issue number exceeds 50 characters", nil)
+	}
+	utils.Logger.Info("Generated issue number", "lottery_id", lotteryID, "issue_number", issueNumber)
+	return issueNumber, nil
 }
@@ -0,0 +1,132 @@
+package issue
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"backend/config"
+	"backend/models"
+
+	"gorm.io/gorm"
+)
+
+// defaultSeqWidth {seq} 占位符的默认补零宽度
+const defaultSeqWidth = 3
+
+var (
+	issuePatternToken = regexp.MustCompile(`\{([^{}]+)\}`)
+	seqToken          = regexp.MustCompile(`^seq(?::(\d{1,2}))?$`)
+	// dateLayoutTokens 日期占位符到 Go 时间格式的映射，按长度优先匹配
+	dateLayoutTokens = []struct{ token, layout string }{
+		{"yyyy", "2006"},
+		{"yy", "06"},
+		{"MM", "01"},
+		{"dd", "02"},
+		{"HH", "15"},
+		{"mm", "04"},
+	}
+)
+
+// ValidateIssueNumberPattern 验证期号编号规则
+//
+// 规则由字面量和占位符组成，占位符包括日期（如 {yyyyMMdd}、{yyyy}-{MM}）和序号 {seq}（可指定宽度，如 {seq:4}），
+// 且必须且只能包含一个 {seq}
+func ValidateIssueNumberPattern(pattern string) error {
+	if pattern == "" {
+		return fmt.Errorf("issue number pattern is empty")
+	}
+	seqCount := 0
+	for _, match := range issuePatternToken.FindAllStringSubmatch(pattern, -1) {
+		if seqToken.MatchString(match[1]) {
+			seqCount++
+			continue
+		}
+		if _, ok := dateLayout(match[1]); !ok {
+			return fmt.Errorf("unknown placeholder %s in issue number pattern", match[0])
+		}
+	}
+	if seqCount != 1 {
+		return fmt.Errorf("issue number pattern must contain exactly one {seq} placeholder")
+	}
+	return nil
+}
+
+// FormatIssueNumber 按规则生成期号编号
+func FormatIssueNumber(pattern string, t time.Time, seq int64) string {
+	return issuePatternToken.ReplaceAllStringFunc(pattern, func(token string) string {
+		name := token[1 : len(token)-1]
+		if m := seqToken.FindStringSubmatch(name); m != nil {
+			width := defaultSeqWidth
+			if m[1] != "" {
+				width, _ = strconv.Atoi(m[1])
+			}
+			return fmt.Sprintf("%0*d", width, seq)
+		}
+		if layout, ok := dateLayout(name); ok {
+			return t.Format(layout)
+		}
+		return token
+	})
+}
+
+// IssuePeriodKey 返回序号所属的周期，规则中去掉 {seq} 后的部分相同的期号共享同一个序列
+func IssuePeriodKey(pattern string, t time.Time) string {
+	key := issuePatternToken.ReplaceAllStringFunc(pattern, func(token string) string {
+		if seqToken.MatchString(token[1 : len(token)-1]) {
+			return ""
+		}
+		return token
+	})
+	return FormatIssueNumber(key, t, 0)
+}
+
+// dateLayout 将日期占位符（如 yyyyMMdd）转换为 Go 时间格式
+func dateLayout(name string) (string, bool) {
+	var layout strings.Builder
+	for rest := name; rest != ""; {
+		matched := false
+		for _, dt := range dateLayoutTokens {
+			if strings.HasPrefix(rest, dt.token) {
+				layout.WriteString(dt.layout)
+				rest = rest[len(dt.token):]
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			// 允许日期各部分之间使用分隔符
+			if strings.ContainsRune("-_./", rune(rest[0])) {
+				layout.WriteByte(rest[0])
+				rest = rest[1:]
+				continue
+			}
+			return "", false
+		}
+	}
+	return layout.String(), true
+}
+
+// issueNumberPattern 返回彩票使用的期号编号规则，未配置时使用全局默认规则
+func issueNumberPattern(lottery *models.Lottery) string {
+	if lottery.IssueNumberPattern != "" {
+		return lottery.IssueNumberPattern
+	}
+	return config.AppConfig.IssueNumberPattern
+}
+
+// nextIssueSequence 通过数据库原子递增分配期号序号，并发创建不会得到相同序号
+func nextIssueSequence(db *gorm.DB, lotteryID, periodKey string) (int64, error) {
+	var seq int64
+	err := db.Raw(`INSERT INTO lottery_issue_sequences (lottery_id, period_key, last_seq, updated_at)
+		VALUES (?, ?, 1, NOW())
+		ON CONFLICT (lottery_id, period_key)
+		DO UPDATE SET last_seq = lottery_issue_sequences.last_seq + 1, updated_at = NOW()
+		RETURNING last_seq`, lotteryID, periodKey).Scan(&seq).Error
+	if err != nil {
+		return 0, err
+	}
+	return seq, nil
+}
@@ -0,0 +1,137 @@
+package issue
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"backend/blockchain"
+	"backend/config"
+	"backend/models"
+	winnerService "backend/services/winner"
+	"backend/utils"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"gorm.io/gorm"
+)
+
+// pendingRollovers 查询彩票待结转的奖池台账
+func pendingRollovers(db *gorm.DB, lotteryID string) ([]models.RolloverEntry, float64, error) {
+	var entries []models.RolloverEntry
+	if err := db.Where("lottery_id = ? AND status = ?", lotteryID, models.RolloverStatusPending).
+		Order("created_at").
+		Find(&entries).Error; err != nil {
+		return nil, 0, err
+	}
+	var total float64
+	for _, entry := range entries {
+		total += entry.Amount
+	}
+	return entries, total, nil
+}
+
+// RolloverShortfall 返回合约余额距离结转金额的差额，不足部分需要从金库转入；余额已足够时返回 0
+func RolloverShortfall(carryOver, contractBalance *big.Int) *big.Int {
+	shortfall := new(big.Int).Sub(carryOver, contractBalance)
+	if shortfall.Sign() < 0 {
+		return new(big.Int)
+	}
+	return shortfall
+}
+
+// fundRollovers 将待结转的奖池从金库（管理员账户的代币余额）转入彩票合约
+//
+// 无人中奖时奖池在开奖回调的 clear() 中已转给合约 owner，链上不会自动结转，
+// 因此在新一期 Distribute 之后、开售之前按结转金额补足合约余额。此时合约刚被清空且尚无投注，
+// 转账金额按合约当前余额计算差额，请求重试时不会重复转账。
+//
+// 返回:
+//   - bool: 合约余额已覆盖结转金额；金库余额不足时为 false，台账保持 PENDING，留待之后的期号结转
+//   - common.Hash: 转账交易哈希，无需转账时为空
+//   - error: 链上查询或转账错误
+func fundRollovers(ctx context.Context, contractAddress string, carryOver float64) (bool, common.Hash, error) {
+	token, err := blockchain.ConnectTokenContract(config.AppConfig.TokenContractAddress)
+	if err != nil {
+		return false, common.Hash{}, utils.NewInternalError("Failed to connect to LOTToken contract", err)
+	}
+	contract := common.HexToAddress(contractAddress)
+	balance, err := token.BalanceOf(&bind.CallOpts{Context: ctx}, contract)
+	if err != nil {
+		return false, common.Hash{}, utils.NewInternalError("Failed to get lottery contract balance", err)
+	}
+	shortfall := RolloverShortfall(winnerService.UnitsFromTokens(carryOver), balance)
+	if shortfall.Sign() == 0 {
+		return true, common.Hash{}, nil
+	}
+
+	treasury, err := token.BalanceOf(&bind.CallOpts{Context: ctx}, blockchain.Auth.From)
+	if err != nil {
+		return false, common.Hash{}, utils.NewInternalError("Failed to get treasury balance", err)
+	}
+	if treasury.Cmp(shortfall) < 0 {
+		utils.Logger.Warn("Treasury balance is lower than the rollover, carry over postponed",
+			"contract_address", contractAddress, "shortfall", shortfall.String(), "treasury", treasury.String())
+		return false, common.Hash{}, nil
+	}
+
+	// Distribute 交易已在同一次尝试中使用了当前 Nonce，转账前重新获取
+	nonce, err := blockchain.BlockchainMgr.GetNextNonce(ctx)
+	if err != nil {
+		return false, common.Hash{}, utils.NewInternalError("Failed to get next nonce", err)
+	}
+	blockchain.Auth.Nonce = new(big.Int).SetUint64(nonce)
+
+	tx, err := token.Transfer(blockchain.Auth, contract, shortfall)
+	if err != nil {
+		utils.Logger.Error("Failed to send rollover funding", "contract_address", contractAddress, "error", err)
+		return false, common.Hash{}, utils.NewInternalError("Failed to send rollover funding", err)
+	}
+	receipt, err := bind.WaitMined(ctx, blockchain.Client, tx)
+	if err != nil {
+		utils.Logger.Error("Failed to confirm rollover funding", "tx_hash", tx.Hash().Hex(), "error", err)
+		return false, tx.Hash(), blockchain.NonRetryable(utils.NewInternalError("Failed to confirm rollover funding", err))
+	}
+	if receipt.Status != 1 {
+		utils.Logger.Error("Rollover funding reverted", "tx_hash", tx.Hash().Hex())
+		return false, tx.Hash(), blockchain.NonRetryable(utils.NewInternalError("Rollover funding reverted", nil))
+	}
+	utils.Logger.Info("Funded rollover into lottery contract", "contract_address", contractAddress, "amount", shortfall.String(), "tx_hash", tx.Hash().Hex())
+	return true, tx.Hash(), nil
+}
+
+// applyPendingRollovers 将已转入合约的结转台账计入新期号，需在保存期号的事务中调用
+func applyPendingRollovers(tx *gorm.DB, issue *models.LotteryIssue, entries []models.RolloverEntry, fundingTxHash common.Hash) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	entryIDs := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		issue.CarryOver += entry.Amount
+		entryIDs = append(entryIDs, entry.EntryID)
+	}
+	issue.PrizePool += issue.CarryOver
+
+	updates := map[string]interface{}{
+		"status":          models.RolloverStatusApplied,
+		"target_issue_id": issue.IssueID,
+		"updated_at":      time.Now(),
+	}
+	if fundingTxHash != (common.Hash{}) {
+		updates["funding_tx_hash"] = fundingTxHash.Hex()
+	}
+	result := tx.Model(&models.RolloverEntry{}).
+		Where("entry_id IN ? AND status = ?", entryIDs, models.RolloverStatusPending).
+		Updates(updates)
+	if result.Error != nil {
+		return result.Error
+	}
+	// 并发创建的期号已经计入了部分结转，回滚避免重复计入
+	if result.RowsAffected != int64(len(entryIDs)) {
+		return fmt.Errorf("rollover entries were applied concurrently")
+	}
+	utils.Logger.Info("Applied jackpot rollover to issue", "issue_id", issue.IssueID, "entries", len(entries), "carry_over", issue.CarryOver)
+	return nil
+}
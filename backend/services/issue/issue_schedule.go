@@ -0,0 +1,95 @@
+package issue
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+
+	"backend/models"
+
+	"github.com/robfig/cron/v3"
+)
+
+var drawAtPattern = regexp.MustCompile(`^([01]\d|2[0-3]):([0-5]\d)$`)
+
+// scheduleParser 解析标准五段 cron 表达式（分 时 日 月 周）
+var scheduleParser = cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+// ScheduleSpec 将计划转换为 cron 表达式，DAILY、WEEKLY 由开奖时刻和开奖日生成
+func ScheduleSpec(schedule *models.IssueSchedule) (string, error) {
+	switch schedule.Frequency {
+	case models.ScheduleFrequencyDaily, models.ScheduleFrequencyWeekly:
+		m := drawAtPattern.FindStringSubmatch(schedule.DrawAt)
+		if m == nil {
+			return "", fmt.Errorf("draw_at must be in HH:mm format")
+		}
+		hour, _ := strconv.Atoi(m[1])
+		minute, _ := strconv.Atoi(m[2])
+		if schedule.Frequency == models.ScheduleFrequencyDaily {
+			return fmt.Sprintf("%d %d * * *", minute, hour), nil
+		}
+		if schedule.Weekday < 0 || schedule.Weekday > 6 {
+			return "", fmt.Errorf("weekday must be between 0 (Sunday) and 6 (Saturday)")
+		}
+		return fmt.Sprintf("%d %d * * %d", minute, hour, schedule.Weekday), nil
+	case models.ScheduleFrequencyCron:
+		if schedule.CronExpr == "" {
+			return "", fmt.Errorf("cron_expr is required for CRON schedules")
+		}
+		return schedule.CronExpr, nil
+	default:
+		return "", fmt.Errorf("unknown schedule frequency %s", schedule.Frequency)
+	}
+}
+
+// ValidateIssueSchedule 验证计划的频率、时区和销售截止设置
+func ValidateIssueSchedule(schedule *models.IssueSchedule) error {
+	spec, err := ScheduleSpec(schedule)
+	if err != nil {
+		return err
+	}
+	if _, err := scheduleParser.Parse(spec); err != nil {
+		return fmt.Errorf("invalid cron expression: %w", err)
+	}
+	if _, err := time.LoadLocation(schedule.TimeZone); err != nil {
+		return fmt.Errorf("invalid time zone %s", schedule.TimeZone)
+	}
+	if schedule.SaleCloseMinutes < 0 {
+		return fmt.Errorf("sale_close_minutes cannot be negative")
+	}
+	return nil
+}
+
+// NextScheduledDraw 计算 after 之后的下一次开奖时间及对应的销售截止时间
+//
+// 开奖时刻按计划的时区解释，例如 Asia/Shanghai 的 DAILY 20:00 始终是北京时间 20:00。
+// 销售截止时间已过的开奖时刻会被跳过，保证新开的期号有销售时间。
+func NextScheduledDraw(schedule *models.IssueSchedule, after time.Time) (drawTime, saleEndTime time.Time, err error) {
+	spec, err := ScheduleSpec(schedule)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	cronSchedule, err := scheduleParser.Parse(spec)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid cron expression: %w", err)
+	}
+	location, err := time.LoadLocation(schedule.TimeZone)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid time zone %s", schedule.TimeZone)
+	}
+
+	saleClose := time.Duration(schedule.SaleCloseMinutes) * time.Minute
+	next := after.In(location)
+	// cron 表达式可能永远不会触发（如 2 月 30 日），限制查找次数
+	for i := 0; i < 1000; i++ {
+		next = cronSchedule.Next(next)
+		if next.IsZero() {
+			break
+		}
+		if next.Add(-saleClose).After(after) {
+			return next, next.Add(-saleClose), nil
+		}
+	}
+	return time.Time{}, time.Time{}, fmt.Errorf("schedule has no upcoming draw time")
+}
@@ -0,0 +1,240 @@
+package issue
+
+import (
+	"context"
+	"time"
+
+	"backend/config"
+	"backend/models"
+	"backend/utils"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// SaveIssueScheduleParams 定义创建或更新期号计划的参数
+type SaveIssueScheduleParams struct {
+	LotteryID        string
+	Frequency        string
+	CronExpr         string
+	DrawAt           string
+	Weekday          int
+	TimeZone         string
+	SaleCloseMinutes int
+	Enabled          bool
+}
+
+// IssueScheduleService 封装期号定时计划及自动开期的业务逻辑
+type IssueScheduleService struct {
+	db *gorm.DB
+}
+
+// NewIssueScheduleService 创建 IssueScheduleService 实例
+func NewIssueScheduleService(db *gorm.DB) *IssueScheduleService {
+	return &IssueScheduleService{db: db}
+}
+
+// SaveSchedule 创建或更新彩票的期号计划，每个彩票只有一个计划
+//
+// 参数:
+//   - ctx: 请求上下文
+//   - params: 计划参数，包括频率、开奖时刻、时区等
+//
+// 返回:
+//   - *IssueSchedule: 保存后的计划
+//   - error: 参数无效或数据库错误
+func (s *IssueScheduleService) SaveSchedule(ctx context.Context, params SaveIssueScheduleParams) (*models.IssueSchedule, error) {
+	var lottery models.Lottery
+	if err := s.db.WithContext(ctx).Where("lottery_id = ?", params.LotteryID).First(&lottery).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, utils.NewBadRequestError("Lottery not found", nil)
+		}
+		return nil, utils.NewInternalError("Failed to check lottery ID", errors.Wrap(err, "database error"))
+	}
+
+	var schedule models.IssueSchedule
+	err := s.db.WithContext(ctx).Where("lottery_id = ?", params.LotteryID).First(&schedule).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, utils.NewInternalError("Failed to load issue schedule", errors.Wrap(err, "database error"))
+	}
+	isNew := errors.Is(err, gorm.ErrRecordNotFound)
+	if isNew {
+		schedule.ScheduleID = uuid.NewString()
+		schedule.LotteryID = params.LotteryID
+		schedule.CreatedAt = time.Now()
+	}
+	schedule.Frequency = params.Frequency
+	schedule.CronExpr = params.CronExpr
+	schedule.DrawAt = params.DrawAt
+	schedule.Weekday = params.Weekday
+	schedule.TimeZone = params.TimeZone
+	schedule.SaleCloseMinutes = params.SaleCloseMinutes
+	schedule.Enabled = params.Enabled
+	schedule.UpdatedAt = time.Now()
+
+	if err := ValidateIssueSchedule(&schedule); err != nil {
+		return nil, utils.NewBadRequestError("Invalid issue schedule", err)
+	}
+
+	if isNew {
+		err = s.db.WithContext(ctx).Create(&schedule).Error
+	} else {
+		err = s.db.WithContext(ctx).Save(&schedule).Error
+	}
+	if err != nil {
+		utils.Logger.Error("Failed to save issue schedule", "lottery_id", params.LotteryID, "error", err)
+		return nil, utils.NewInternalError("Failed to save issue schedule", errors.Wrap(err, "database error"))
+	}
+	utils.Logger.Info("Issue schedule saved", "schedule_id", schedule.ScheduleID, "lottery_id", schedule.LotteryID, "frequency", schedule.Frequency)
+	return &schedule, nil
+}
+
+// ListSchedules 查询期号计划，lotteryID 为空时返回所有计划
+func (s *IssueScheduleService) ListSchedules(ctx context.Context, lotteryID string) ([]models.IssueSchedule, error) {
+	query := s.db.WithContext(ctx).Model(&models.IssueSchedule{})
+	if lotteryID != "" {
+		query = query.Where("lottery_id = ?", lotteryID)
+	}
+	var schedules []models.IssueSchedule
+	if err := query.Order("created_at DESC").Find(&schedules).Error; err != nil {
+		return nil, utils.NewInternalError("Failed to list issue schedules", errors.Wrap(err, "database error"))
+	}
+	return schedules, nil
+}
+
+// DisableSchedule 停用期号计划，已开出的期号不受影响
+func (s *IssueScheduleService) DisableSchedule(ctx context.Context, scheduleID string) (*models.IssueSchedule, error) {
+	var schedule models.IssueSchedule
+	if err := s.db.WithContext(ctx).Where("schedule_id = ?", scheduleID).First(&schedule).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, utils.NewBadRequestError("Issue schedule not found", nil)
+		}
+		return nil, utils.NewInternalError("Failed to load issue schedule", errors.Wrap(err, "database error"))
+	}
+	schedule.Enabled = false
+	schedule.UpdatedAt = time.Now()
+	if err := s.db.WithContext(ctx).Save(&schedule).Error; err != nil {
+		return nil, utils.NewInternalError("Failed to disable issue schedule", errors.Wrap(err, "database error"))
+	}
+	utils.Logger.Info("Issue schedule disabled", "schedule_id", scheduleID)
+	return &schedule, nil
+}
+
+// OpenNextIssue 按计划为彩票开下一期，待结转的奖池在创建期号时计入
+//
+// 彩票没有启用的计划、不在销售状态或已有未开奖的期号时不做任何操作并返回 nil。
+// 幂等键由上一期 ID 生成，同一期开奖后重复调用只会开出一个新期号。
+func (s *IssueScheduleService) OpenNextIssue(ctx context.Context, lotteryID string) (*models.LotteryIssue, error) {
+	var schedule models.IssueSchedule
+	if err := s.db.WithContext(ctx).Where("lottery_id = ? AND enabled = ?", lotteryID, true).First(&schedule).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, utils.NewInternalError("Failed to load issue schedule", errors.Wrap(err, "database error"))
+	}
+
+	var lottery models.Lottery
+	if err := s.db.WithContext(ctx).Where("lottery_id = ?", lotteryID).First(&lottery).Error; err != nil {
+		return nil, utils.NewInternalError("Failed to load lottery", errors.Wrap(err, "database error"))
+	}
+	if lottery.Status != models.LotteryStatusActive {
+		return nil, nil
+	}
+
+	var openIssues int64
+	if err := s.db.WithContext(ctx).Model(&models.LotteryIssue{}).
+		Where("lottery_id = ? AND status IN ?", lotteryID, []string{models.IssueStatusPending, models.IssueStatusDrawing}).
+		Count(&openIssues).Error; err != nil {
+		return nil, utils.NewInternalError("Failed to check open issues", errors.Wrap(err, "database error"))
+	}
+	if openIssues > 0 {
+		return nil, nil
+	}
+
+	// 上一期（最近开奖的期号）决定幂等键
+	idempotencyKey := "schedule:" + schedule.ScheduleID + ":first"
+	var previous models.LotteryIssue
+	err := s.db.WithContext(ctx).
+		Where("lottery_id = ? AND status = ?", lotteryID, models.IssueStatusDrawn).
+		Order("draw_time DESC").
+		First(&previous).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, utils.NewInternalError("Failed to load previous issue", errors.Wrap(err, "database error"))
+	}
+	if err == nil {
+		idempotencyKey = "schedule:" + previous.IssueID
+	}
+
+	drawTime, saleEndTime, err := NextScheduledDraw(&schedule, time.Now())
+	if err != nil {
+		utils.Logger.Error("Failed to compute next draw time", "schedule_id", schedule.ScheduleID, "error", err)
+		return nil, utils.NewBadRequestError("Failed to compute next draw time", err)
+	}
+
+	utils.Logger.Info("Opening scheduled issue",
+		"lottery_id", lotteryID,
+		"schedule_id", schedule.ScheduleID,
+		"draw_time", drawTime)
+	issue, _, err := NewIssueCreateService(s.db).CreateIssue(ctx, CreateIssueParams{
+		LotteryID:      lotteryID,
+		SaleEndTime:    saleEndTime,
+		DrawTime:       drawTime,
+		Status:         models.IssueStatusPending,
+		IdempotencyKey: idempotencyKey,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.db.WithContext(ctx).Model(&models.IssueSchedule{}).
+		Where("schedule_id = ?", schedule.ScheduleID).
+		Updates(map[string]interface{}{"last_opened_issue_id": issue.IssueID, "updated_at": time.Now()}).Error; err != nil {
+		utils.Logger.Warn("Failed to record last opened issue", "schedule_id", schedule.ScheduleID, "error", err)
+	}
+	return issue, nil
+}
+
+// OpenDueIssues 为所有启用计划且当前没有未开奖期号的彩票开下一期
+func (s *IssueScheduleService) OpenDueIssues(ctx context.Context) (int, error) {
+	var schedules []models.IssueSchedule
+	if err := s.db.WithContext(ctx).Where("enabled = ?", true).Find(&schedules).Error; err != nil {
+		return 0, utils.NewInternalError("Failed to list issue schedules", errors.Wrap(err, "database error"))
+	}
+	opened := 0
+	for _, schedule := range schedules {
+		issue, err := s.OpenNextIssue(ctx, schedule.LotteryID)
+		if err != nil {
+			utils.Logger.Error("Failed to open scheduled issue", "lottery_id", schedule.LotteryID, "error", err)
+			continue
+		}
+		if issue != nil {
+			opened++
+		}
+	}
+	return opened, nil
+}
+
+// StartIssueScheduler 定期检查启用的计划，为没有未开奖期号的彩票开下一期
+//
+// 开奖完成后会立即尝试开下一期，定时检查用于首次开期以及开期失败后的重试。
+func StartIssueScheduler(ctx context.Context, db *gorm.DB) {
+	service := NewIssueScheduleService(db)
+	interval := time.Duration(config.AppConfig.IssueSchedulerInterval) * time.Second
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			if opened, err := service.OpenDueIssues(ctx); err != nil {
+				utils.Logger.Error("Issue scheduler run failed", "error", err)
+			} else if opened > 0 {
+				utils.Logger.Info("Issue scheduler opened issues", "count", opened)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
@@ -0,0 +1,300 @@
+package kyc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"backend/config"
+	"backend/models"
+	"backend/services/account"
+	"backend/services/notification"
+	"backend/utils"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Reasons a verification expires
+const (
+	ExpiryReasonDocument       = "identity document expired"
+	ExpiryReasonReverification = "periodic re-verification due"
+)
+
+// Actions taken when a verification expires
+const (
+	ExpiryActionDowngrade = "downgrade" // Clear IsVerified: the customer can neither log in nor buy
+	ExpiryActionRestrict  = "restrict"  // Keep IsVerified, only purchases are refused
+)
+
+// expiryBatchSize bounds the customers handled by each step of one run
+const expiryBatchSize = 500
+
+// staffRoles never expire, so that verifiers and administrators are not locked out
+var staffRoles = []string{"admin", "kyc_verifier", "lottery_admin"}
+
+// ReverificationIntervals are the periodic re-verification intervals per risk level
+type ReverificationIntervals struct {
+	Low    time.Duration
+	Medium time.Duration // Also used for submissions without a known risk level
+	High   time.Duration
+}
+
+// DefaultIntervals returns the intervals of the configuration
+func DefaultIntervals() ReverificationIntervals {
+	days := func(n, fallback int) time.Duration {
+		if n <= 0 {
+			n = fallback
+		}
+		return time.Duration(n) * 24 * time.Hour
+	}
+	return ReverificationIntervals{
+		Low:    days(config.AppConfig.KYCReverifyDaysLow, 730),
+		Medium: days(config.AppConfig.KYCReverifyDaysMedium, 365),
+		High:   days(config.AppConfig.KYCReverifyDaysHigh, 180),
+	}
+}
+
+// For returns the interval of a risk level
+func (i ReverificationIntervals) For(riskLevel string) time.Duration {
+	switch strings.ToLower(strings.TrimSpace(riskLevel)) {
+	case "low":
+		return i.Low
+	case "high":
+		return i.High
+	}
+	return i.Medium
+}
+
+// DocumentExpiresAt returns the moment a document stops being valid: it is valid through its expiry date
+func DocumentExpiresAt(expiryDate time.Time) time.Time {
+	y, m, d := expiryDate.Date()
+	return time.Date(y, m, d+1, 0, 0, 0, 0, time.UTC)
+}
+
+// ExpiresAt returns when a verification expires and why: the earlier of the document expiry
+// and the periodic re-verification of the risk level
+func ExpiresAt(verifiedAt time.Time, riskLevel string, documentExpiry *time.Time, intervals ReverificationIntervals) (time.Time, string) {
+	expiresAt := verifiedAt.Add(intervals.For(riskLevel))
+	reason := ExpiryReasonReverification
+	if documentExpiry != nil && !documentExpiry.IsZero() {
+		if docExpiresAt := DocumentExpiresAt(*documentExpiry); docExpiresAt.Before(expiresAt) {
+			expiresAt, reason = docExpiresAt, ExpiryReasonDocument
+		}
+	}
+	return expiresAt, reason
+}
+
+// CheckPurchaseAllowed refuses purchases of a customer whose verification has expired
+//
+// Buyers without a customer record are left to the existing checks.
+func CheckPurchaseAllowed(ctx context.Context, db *gorm.DB, address string, now time.Time) error {
+	var row struct {
+		KYCExpiresAt *time.Time
+		Status       string
+	}
+	err := db.WithContext(ctx).Table("customers").
+		Select("customers.kyc_expires_at, kyc_reviews.status").
+		Joins("LEFT JOIN kyc_reviews ON kyc_reviews.customer_address = customers.customer_address").
+		Where("LOWER(customers.customer_address) = LOWER(?)", address).
+		Limit(1).
+		Scan(&row).Error
+	if err != nil {
+		return utils.NewInternalError("Failed to check KYC status", err)
+	}
+	if row.Status == models.KYCReviewStatusExpired || (row.KYCExpiresAt != nil && !row.KYCExpiresAt.After(now)) {
+		return utils.NewBadRequestError("KYC verification has expired, please submit up-to-date details", nil)
+	}
+	return nil
+}
+
+// KYCExpiryService schedules, reminds and expires verifications
+type KYCExpiryService struct {
+	db        *gorm.DB
+	intervals ReverificationIntervals
+}
+
+// NewKYCExpiryService creates a KYCExpiryService with the configured intervals
+func NewKYCExpiryService(db *gorm.DB) *KYCExpiryService {
+	return &KYCExpiryService{db: db, intervals: DefaultIntervals()}
+}
+
+// ExpiryRunResult counts what one run did
+type ExpiryRunResult struct {
+	Scheduled int // Verified customers given an expiry date
+	Reminded  int
+	Expired   int
+}
+
+// expiryCandidate is a verified customer with the data its expiry is computed from
+type expiryCandidate struct {
+	CustomerAddress    string
+	VerificationTime   time.Time
+	KYCExpiresAt       *time.Time
+	RiskLevel          string
+	DocumentExpiryDate *time.Time
+}
+
+// candidates selects verified customers, staff excluded, with their KYC data
+func (s *KYCExpiryService) candidates(ctx context.Context) *gorm.DB {
+	return s.db.WithContext(ctx).Table("customers").
+		Select("customers.customer_address, customers.verification_time, customers.kyc_expires_at, kyc_data.risk_level, kyc_data.document_expiry_date").
+		Joins("JOIN kyc_reviews ON kyc_reviews.customer_address = customers.customer_address").
+		Joins("LEFT JOIN kyc_data ON kyc_data.customer_address = customers.customer_address").
+		Joins("LEFT JOIN roles ON roles.role_id = customers.role_id").
+		Where("kyc_reviews.status = ?", models.KYCReviewStatusApproved).
+		Where("COALESCE(roles.role_name, '') NOT IN ?", staffRoles).
+		Limit(expiryBatchSize)
+}
+
+// Run gives verifications approved before expiry tracking an expiry date, reminds customers whose
+// verification expires soon and expires the ones that are due
+func (s *KYCExpiryService) Run(ctx context.Context, now time.Time) (ExpiryRunResult, error) {
+	var result ExpiryRunResult
+
+	var unscheduled []expiryCandidate
+	if err := s.candidates(ctx).Where("customers.kyc_expires_at IS NULL").Scan(&unscheduled).Error; err != nil {
+		return result, utils.NewInternalError("Failed to fetch unscheduled verifications", err)
+	}
+	for _, c := range unscheduled {
+		verifiedAt := c.VerificationTime
+		if verifiedAt.Year() < 2000 {
+			// Seeded accounts have no verification time, start their interval now
+			verifiedAt = now
+		}
+		expiresAt, _ := ExpiresAt(verifiedAt, c.RiskLevel, c.DocumentExpiryDate, s.intervals)
+		if err := s.db.WithContext(ctx).Model(&models.Customer{}).
+			Where("customer_address = ?", c.CustomerAddress).
+			Update("kyc_expires_at", expiresAt).Error; err != nil {
+			return result, utils.NewInternalError("Failed to schedule KYC expiry", err)
+		}
+		result.Scheduled++
+	}
+
+	reminderDays := config.AppConfig.KYCExpiryReminderDays
+	if reminderDays > 0 {
+		var expiring []expiryCandidate
+		if err := s.candidates(ctx).
+			Where("customers.kyc_expires_at > ? AND customers.kyc_expires_at <= ?", now, now.AddDate(0, 0, reminderDays)).
+			Where("customers.kyc_reminded_at IS NULL").
+			Scan(&expiring).Error; err != nil {
+			return result, utils.NewInternalError("Failed to fetch expiring verifications", err)
+		}
+		for _, c := range expiring {
+			if err := s.db.WithContext(ctx).Model(&models.Customer{}).
+				Where("customer_address = ?", c.CustomerAddress).
+				Update("kyc_reminded_at", now).Error; err != nil {
+				return result, utils.NewInternalError("Failed to record KYC expiry reminder", err)
+			}
+			notification.NewNotificationService(s.db).NotifyAsync(c.CustomerAddress, notification.TemplateKYCExpiring, map[string]interface{}{
+				"ExpiresAt": c.KYCExpiresAt.UTC().Format("2006-01-02"),
+				"Reason":    s.reason(c),
+			})
+			result.Reminded++
+		}
+	}
+
+	var due []expiryCandidate
+	if err := s.candidates(ctx).Where("customers.kyc_expires_at <= ?", now).Scan(&due).Error; err != nil {
+		return result, utils.NewInternalError("Failed to fetch expired verifications", err)
+	}
+	for _, c := range due {
+		expired, err := s.expire(ctx, c, now)
+		if err != nil {
+			return result, err
+		}
+		if expired {
+			result.Expired++
+		}
+	}
+	return result, nil
+}
+
+// reason tells why a candidate's verification expires
+func (s *KYCExpiryService) reason(c expiryCandidate) string {
+	if c.DocumentExpiryDate != nil && c.KYCExpiresAt != nil && !DocumentExpiresAt(*c.DocumentExpiryDate).After(*c.KYCExpiresAt) {
+		return ExpiryReasonDocument
+	}
+	return ExpiryReasonReverification
+}
+
+// expire moves an approved review to EXPIRED and applies the configured expiry action,
+// the customer is notified after commit
+func (s *KYCExpiryService) expire(ctx context.Context, c expiryCandidate, now time.Time) (bool, error) {
+	reason := s.reason(c)
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var review models.KYCReview
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("customer_address = ?", c.CustomerAddress).
+			First(&review).Error; err != nil {
+			return err
+		}
+		historyStatus, err := Transition(&review, ActionExpire, "", "", now)
+		if err != nil {
+			return err
+		}
+		review.UpdatedAt = now
+		if err := tx.Save(&review).Error; err != nil {
+			return utils.NewInternalError("Failed to update KYC review", err)
+		}
+		if err := appendHistory(tx, review.CustomerAddress, historyStatus, "", reason, now); err != nil {
+			return err
+		}
+		if !strings.EqualFold(config.AppConfig.KYCExpiryAction, ExpiryActionRestrict) {
+			if err := tx.Model(&models.Customer{}).Where("customer_address = ?", c.CustomerAddress).
+				Updates(map[string]interface{}{"is_verified": false, "updated_at": now}).Error; err != nil {
+				return utils.NewInternalError("Failed to update customer", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		if IsStaleExpiry(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	utils.Logger.Info("KYC verification expired", "customer_address", c.CustomerAddress, "reason", reason)
+
+	account.InvalidateAccountSummary(c.CustomerAddress)
+	notification.NewNotificationService(s.db).NotifyAsync(c.CustomerAddress, notification.TemplateKYCExpired, map[string]interface{}{
+		"ExpiresAt": c.KYCExpiresAt.UTC().Format("2006-01-02"),
+		"Reason":    reason,
+	})
+	return true, nil
+}
+
+// IsStaleExpiry tells whether expiring a review failed because the review changed since it was selected:
+// it was resubmitted or decided again (Transition refuses with 400) or is gone. Such reviews are skipped
+// instead of failing the run.
+func IsStaleExpiry(err error) bool {
+	var appErr *utils.Error
+	return errors.Is(err, gorm.ErrRecordNotFound) || (errors.As(err, &appErr) && appErr.Code == http.StatusBadRequest)
+}
+
+// StartExpiryWorker periodically runs the KYC expiry checks until ctx is cancelled
+func StartExpiryWorker(ctx context.Context, db *gorm.DB) {
+	service := NewKYCExpiryService(db)
+	interval := time.Duration(config.AppConfig.KYCExpiryCheckInterval) * time.Second
+	if interval <= 0 {
+		interval = time.Hour
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			if result, err := service.Run(ctx, time.Now()); err != nil {
+				utils.Logger.Error("KYC expiry run failed", "error", err)
+			} else if result.Scheduled+result.Reminded+result.Expired > 0 {
+				utils.Logger.Info("KYC expiry run finished", "scheduled", result.Scheduled, "reminded", result.Reminded, "expired", result.Expired)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
@@ -0,0 +1,378 @@
+package kyc
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"backend/models"
+	"backend/services/account"
+	"backend/services/document"
+	"backend/services/notification"
+	"backend/utils"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// verifiedRoleName is the role a customer without one gets once verified
+const verifiedRoleName = "normal_user"
+
+// KYCReviewService runs the KYC review queue: assignment, information requests and (four-eyes) decisions
+type KYCReviewService struct {
+	db *gorm.DB
+}
+
+// NewKYCReviewService creates a KYCReviewService
+func NewKYCReviewService(db *gorm.DB) *KYCReviewService {
+	return &KYCReviewService{db: db}
+}
+
+// Submit puts a new KYC submission in the queue, inside the registration transaction
+//
+// The submission starts at DefaultRiskLevel whatever the customer sent, a verifier sets the actual level.
+func Submit(tx *gorm.DB, kyc models.KYCData) error {
+	now := time.Now()
+	review := models.KYCReview{
+		CustomerAddress: kyc.CustomerAddress,
+		Status:          models.KYCReviewStatusPending,
+		RiskLevel:       DefaultRiskLevel,
+		SubmittedAt:     now,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+	if err := tx.Create(&review).Error; err != nil {
+		return utils.NewInternalError("Failed to queue KYC review", err)
+	}
+	return appendHistory(tx, kyc.CustomerAddress, models.KYCVerifyStatusPending, "", "Submitted for review", now)
+}
+
+// ReviewFilter defines the filters of the review queue
+type ReviewFilter struct {
+	Status     string
+	AssignedTo string // A verifier address, or "none" for unassigned reviews
+	RiskLevel  string
+	Page       int
+	PageSize   int
+}
+
+// ReviewListResult is a page of the review queue
+type ReviewListResult struct {
+	Total    int64              `json:"total"`
+	Page     int                `json:"page"`
+	PageSize int                `json:"page_size"`
+	Reviews  []models.KYCReview `json:"reviews"`
+}
+
+// ReviewDetail is a review with the submitted data and its full history
+type ReviewDetail struct {
+	Review  models.KYCReview                `json:"review"`
+	KYCData models.KYCData                  `json:"kyc_data"`
+	History []models.KYCVerificationHistory `json:"history"`
+}
+
+// List returns the review queue, oldest submission first
+func (s *KYCReviewService) List(ctx context.Context, filter ReviewFilter) (*ReviewListResult, error) {
+	query := s.db.WithContext(ctx).Model(&models.KYCReview{})
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	switch filter.AssignedTo {
+	case "":
+	case "none":
+		query = query.Where("COALESCE(assigned_verifier, '') = ''")
+	default:
+		query = query.Where("LOWER(assigned_verifier) = LOWER(?)", filter.AssignedTo)
+	}
+	if filter.RiskLevel != "" {
+		query = query.Where("LOWER(risk_level) = LOWER(?)", filter.RiskLevel)
+	}
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, utils.NewInternalError("Failed to count KYC reviews", err)
+	}
+
+	page, pageSize := filter.Page, filter.PageSize
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = 20
+	}
+	if pageSize > 100 {
+		pageSize = 100
+	}
+	reviews := []models.KYCReview{}
+	if err := query.Order("submitted_at").Offset((page - 1) * pageSize).Limit(pageSize).Find(&reviews).Error; err != nil {
+		return nil, utils.NewInternalError("Failed to fetch KYC reviews", err)
+	}
+	return &ReviewListResult{Total: total, Page: page, PageSize: pageSize, Reviews: reviews}, nil
+}
+
+// Get returns a customer's review with the submitted KYC data and the verification history
+func (s *KYCReviewService) Get(ctx context.Context, address string) (*ReviewDetail, error) {
+	db := s.db.WithContext(ctx)
+	var detail ReviewDetail
+	if err := db.Where("LOWER(customer_address) = LOWER(?)", address).First(&detail.Review).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, utils.NewBadRequestError("Customer has no KYC submission", err)
+		}
+		return nil, utils.NewInternalError("Failed to fetch KYC review", err)
+	}
+	if err := db.Where("customer_address = ?", detail.Review.CustomerAddress).First(&detail.KYCData).Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, utils.NewInternalError("Failed to fetch KYC data", err)
+	}
+	if err := db.Where("customer_address = ?", detail.Review.CustomerAddress).Order("history_id").Find(&detail.History).Error; err != nil {
+		return nil, utils.NewInternalError("Failed to fetch KYC verification history", err)
+	}
+	return &detail, nil
+}
+
+// Assign gives a review to a verifier, who must hold a verifier role
+func (s *KYCReviewService) Assign(ctx context.Context, address, actor, verifier string) (*models.KYCReview, error) {
+	if verifier == "" {
+		verifier = actor
+	}
+	if !sameAddress(verifier, actor) {
+		ok, err := s.hasVerifierRole(ctx, verifier)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, utils.NewBadRequestError("Assignee is not a verifier", nil)
+		}
+	}
+	return s.apply(ctx, address, ActionAssign, actor, verifier, "Assigned to "+verifier, nil)
+}
+
+// SetRiskLevel records a verifier's risk assessment, which must be made before the review can be approved
+//
+// The level is also stored on the KYC data, where re-verification intervals are read from.
+func (s *KYCReviewService) SetRiskLevel(ctx context.Context, address, actor, level, comments string) (*models.KYCReview, error) {
+	if canonical, ok := ParseRiskLevel(level); ok {
+		level = canonical
+	}
+	if comments == "" {
+		comments = "Risk level set to " + level
+	}
+	return s.apply(ctx, address, ActionSetRiskLevel, actor, level, comments, func(tx *gorm.DB, review *models.KYCReview) error {
+		if err := tx.Model(&models.KYCData{}).Where("customer_address = ?", review.CustomerAddress).
+			Updates(map[string]interface{}{"risk_level": review.RiskLevel, "updated_at": review.UpdatedAt}).Error; err != nil {
+			return utils.NewInternalError("Failed to update KYC risk level", err)
+		}
+		return nil
+	})
+}
+
+// RequestInfo sends a review back to the customer for more information
+func (s *KYCReviewService) RequestInfo(ctx context.Context, address, actor, comments string) (*models.KYCReview, error) {
+	return s.apply(ctx, address, ActionRequestInfo, actor, "", comments, nil)
+}
+
+// Approve records a verifier's approval; High risk submissions need a second, different verifier
+func (s *KYCReviewService) Approve(ctx context.Context, address, actor, comments string) (*models.KYCReview, error) {
+	return s.apply(ctx, address, ActionApprove, actor, "", comments, nil)
+}
+
+// Reject closes a review as rejected
+func (s *KYCReviewService) Reject(ctx context.Context, address, actor, comments string) (*models.KYCReview, error) {
+	return s.apply(ctx, address, ActionReject, actor, "", comments, nil)
+}
+
+// ResubmitParams defines the KYC data a customer changes when resubmitting, empty fields keep their value
+type ResubmitParams struct {
+	Name               string
+	ResidentialAddress string
+	PhoneNumber        string
+	Email              string
+	DocumentType       string
+	DocumentNumber     string
+	FilePath           string
+	SourceOfFunds      string
+	Occupation         string
+	TaxID              string
+	DocumentExpiryDate *time.Time
+	Comments           string
+}
+
+// Resubmit updates the customer's KYC data and puts the review back in the queue
+func (s *KYCReviewService) Resubmit(ctx context.Context, address string, params ResubmitParams) (*models.KYCReview, error) {
+	comments := params.Comments
+	if comments == "" {
+		comments = "Resubmitted by the customer"
+	}
+	return s.apply(ctx, address, ActionResubmit, address, "", comments, func(tx *gorm.DB, review *models.KYCReview) error {
+		updates := map[string]interface{}{}
+		for column, value := range map[string]string{
+			"name":                params.Name,
+			"residential_address": params.ResidentialAddress,
+			"phone_number":        params.PhoneNumber,
+			"email":               params.Email,
+			"document_type":       params.DocumentType,
+			"document_number":     params.DocumentNumber,
+			"file_path":           params.FilePath,
+			"source_of_funds":     params.SourceOfFunds,
+			"occupation":          params.Occupation,
+			"tax_id":              params.TaxID,
+		} {
+			if value != "" {
+				updates[column] = value
+			}
+		}
+		if params.DocumentExpiryDate != nil {
+			updates["document_expiry_date"] = *params.DocumentExpiryDate
+		}
+		updates["submission_date"] = review.SubmittedAt
+		updates["updated_at"] = review.SubmittedAt
+		// A legacy path is only kept if it is the customer's own previous one
+		var current models.KYCData
+		if err := tx.Select("file_path").Where("customer_address = ?", review.CustomerAddress).First(&current).Error; err != nil {
+			return utils.NewInternalError("Failed to fetch KYC data", err)
+		}
+		if err := document.ClaimDocument(tx, params.FilePath, review.CustomerAddress, current.FilePath); err != nil {
+			return err
+		}
+		if err := tx.Model(&models.KYCData{}).Where("customer_address = ?", review.CustomerAddress).Updates(updates).Error; err != nil {
+			return utils.NewInternalError("Failed to update KYC data", err)
+		}
+		return nil
+	})
+}
+
+// apply runs an action on a locked review, appends it to the verification history and, once decided,
+// updates the customer; the customer is notified after commit
+func (s *KYCReviewService) apply(ctx context.Context, address, action, actor, target, comments string, change func(tx *gorm.DB, review *models.KYCReview) error) (*models.KYCReview, error) {
+	var review models.KYCReview
+	var historyStatus string
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("LOWER(customer_address) = LOWER(?)", address).
+			First(&review).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return utils.NewBadRequestError("Customer has no KYC submission", err)
+			}
+			return utils.NewInternalError("Failed to fetch KYC review", err)
+		}
+
+		now := time.Now()
+		status, err := Transition(&review, action, actor, target, now)
+		if err != nil {
+			return err
+		}
+		historyStatus = status
+		review.UpdatedAt = now
+		if change != nil {
+			if err := change(tx, &review); err != nil {
+				return err
+			}
+		}
+		if err := tx.Save(&review).Error; err != nil {
+			return utils.NewInternalError("Failed to update KYC review", err)
+		}
+
+		verifier := actor
+		if action == ActionResubmit {
+			verifier = ""
+		}
+		if err := appendHistory(tx, review.CustomerAddress, historyStatus, verifier, comments, now); err != nil {
+			return err
+		}
+		if historyStatus == models.KYCVerifyStatusApproved {
+			return markVerified(tx, review.CustomerAddress, actor, now)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	utils.Logger.Info("KYC review updated", "customer_address", review.CustomerAddress, "action", action, "actor", actor, "status", review.Status)
+
+	var template string
+	switch historyStatus {
+	case models.KYCVerifyStatusApproved:
+		template = notification.TemplateKYCApproved
+	case models.KYCVerifyStatusRejected:
+		template = notification.TemplateKYCRejected
+	case models.KYCVerifyStatusInfoRequested:
+		template = notification.TemplateKYCInfoNeeded
+	}
+	if template != "" {
+		account.InvalidateAccountSummary(review.CustomerAddress)
+		notification.NewNotificationService(s.db).NotifyAsync(review.CustomerAddress, template, map[string]interface{}{
+			"Comments": comments,
+		})
+	}
+	return &review, nil
+}
+
+// appendHistory appends a step of the review to the verification history
+func appendHistory(tx *gorm.DB, address, status, verifier, comments string, now time.Time) error {
+	entry := models.KYCVerificationHistory{
+		CustomerAddress:  address,
+		VerifyStatus:     status,
+		VerifierAddress:  verifier,
+		VerificationDate: now,
+		Comments:         comments,
+		CreatedAt:        now,
+		UpdatedAt:        now,
+	}
+	if err := tx.Create(&entry).Error; err != nil {
+		return utils.NewInternalError("Failed to record KYC verification history", err)
+	}
+	return nil
+}
+
+// markVerified flags the customer as verified until the next re-verification and gives a customer
+// without a role the normal user role
+func markVerified(tx *gorm.DB, address, verifier string, now time.Time) error {
+	var customer models.Customer
+	if err := tx.Where("customer_address = ?", address).First(&customer).Error; err != nil {
+		return utils.NewInternalError("Failed to fetch customer", err)
+	}
+	var kycData models.KYCData
+	if err := tx.Where("customer_address = ?", address).First(&kycData).Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return utils.NewInternalError("Failed to fetch KYC data", err)
+	}
+	if kycData.DocumentExpiryDate != nil && !DocumentExpiresAt(*kycData.DocumentExpiryDate).After(now) {
+		return utils.NewBadRequestError("The identity document has expired", nil)
+	}
+	expiresAt, _ := ExpiresAt(now, kycData.RiskLevel, kycData.DocumentExpiryDate, DefaultIntervals())
+	updates := map[string]interface{}{
+		"is_verified":       true,
+		"verifier_address":  verifier,
+		"verification_time": now,
+		"kyc_expires_at":    expiresAt,
+		"kyc_reminded_at":   nil,
+		"updated_at":        now,
+	}
+	// Verifiers and administrators keep their role
+	if customer.RoleID == 0 {
+		var role models.Role
+		if err := tx.Where("role_name = ?", verifiedRoleName).First(&role).Error; err != nil {
+			return utils.NewInternalError("Failed to find the "+verifiedRoleName+" role", err)
+		}
+		updates["role_id"] = role.RoleID
+		updates["assigned_date"] = now
+	}
+	if err := tx.Model(&models.Customer{}).Where("customer_address = ?", address).Updates(updates).Error; err != nil {
+		return utils.NewInternalError("Failed to update customer", err)
+	}
+	return nil
+}
+
+// hasVerifierRole reports whether a customer holds one of the verifier roles
+func (s *KYCReviewService) hasVerifierRole(ctx context.Context, address string) (bool, error) {
+	roles := make([]string, 0, len(VerifierRoles))
+	for role := range VerifierRoles {
+		roles = append(roles, role)
+	}
+	var count int64
+	if err := s.db.WithContext(ctx).Table("customers").
+		Joins("JOIN roles ON roles.role_id = customers.role_id").
+		Where("LOWER(customers.customer_address) = LOWER(?) AND roles.role_name IN ?", strings.TrimSpace(address), roles).
+		Count(&count).Error; err != nil {
+		return false, utils.NewInternalError("Failed to check verifier role", err)
+	}
+	return count > 0, nil
+}
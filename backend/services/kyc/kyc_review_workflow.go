@@ -0,0 +1,217 @@
+package kyc
+
+import (
+	"strings"
+	"time"
+
+	"backend/models"
+	"backend/utils"
+)
+
+// Review actions
+const (
+	ActionAssign       = "assign"
+	ActionSetRiskLevel = "set_risk_level"
+	ActionRequestInfo  = "request_info"
+	ActionApprove      = "approve"
+	ActionReject       = "reject"
+	ActionResubmit     = "resubmit"
+	ActionExpire       = "expire"
+)
+
+// Risk levels a verifier can assign
+const (
+	RiskLevelLow    = "Low"
+	RiskLevelMedium = "Medium"
+	RiskLevelHigh   = "High"
+)
+
+// DefaultRiskLevel is the risk level of a new submission until a verifier assesses it, the level
+// sent by the customer is never used
+const DefaultRiskLevel = RiskLevelHigh
+
+// VerifierRoles are the roles allowed to work the review queue
+var VerifierRoles = map[string]bool{
+	"admin":        true,
+	"kyc_verifier": true,
+}
+
+// IsVerifier reports whether a role may review KYC submissions
+func IsVerifier(role string) bool {
+	return VerifierRoles[role]
+}
+
+// RequiresSecondApproval reports whether a risk level needs two distinct verifiers to approve
+func RequiresSecondApproval(riskLevel string) bool {
+	return strings.EqualFold(strings.TrimSpace(riskLevel), "high")
+}
+
+// ParseRiskLevel returns the canonical form of a risk level, false if it is not one of Low, Medium or High
+func ParseRiskLevel(level string) (string, bool) {
+	for _, known := range []string{RiskLevelLow, RiskLevelMedium, RiskLevelHigh} {
+		if strings.EqualFold(strings.TrimSpace(level), known) {
+			return known, true
+		}
+	}
+	return "", false
+}
+
+// sameAddress compares wallet addresses case-insensitively
+func sameAddress(a, b string) bool {
+	return strings.EqualFold(a, b)
+}
+
+// Transition applies an action of actor to a review and returns the status appended to the verification history
+//
+// target is the verifier to assign for ActionAssign and the risk level for ActionSetRiskLevel. The review is changed in place only when the action is allowed.
+func Transition(review *models.KYCReview, action, actor, target string, now time.Time) (string, error) {
+	if action != ActionResubmit && sameAddress(actor, review.CustomerAddress) {
+		return "", utils.NewForbiddenError("Verifiers cannot review their own submission", nil)
+	}
+	next := *review
+
+	switch action {
+	case ActionAssign:
+		switch review.Status {
+		case models.KYCReviewStatusPending, models.KYCReviewStatusInReview, models.KYCReviewStatusInfoRequested:
+		case models.KYCReviewStatusAwaitingSecondApproval:
+			if sameAddress(target, review.FirstApprover) {
+				return "", utils.NewBadRequestError("The second approval must come from a different verifier", nil)
+			}
+		default:
+			return "", utils.NewBadRequestError("Review is already decided", nil)
+		}
+		if sameAddress(target, review.CustomerAddress) {
+			return "", utils.NewBadRequestError("Verifiers cannot review their own submission", nil)
+		}
+		next.AssignedVerifier = target
+		if review.Status == models.KYCReviewStatusPending {
+			next.Status = models.KYCReviewStatusInReview
+		}
+		*review = next
+		return models.KYCVerifyStatusAssigned, nil
+
+	case ActionSetRiskLevel:
+		if err := checkReviewer(review, actor); err != nil {
+			return "", err
+		}
+		// The first approval was given for the current level, changing it would change how many approvals are needed
+		if review.Status == models.KYCReviewStatusAwaitingSecondApproval {
+			return "", utils.NewBadRequestError("The risk level cannot change after the first approval", nil)
+		}
+		level, ok := ParseRiskLevel(target)
+		if !ok {
+			return "", utils.NewBadRequestError("Risk level must be Low, Medium or High", nil)
+		}
+		next.RiskLevel = level
+		next.RiskAssessedBy = actor
+		next.RiskAssessedAt = &now
+		*review = next
+		return models.KYCVerifyStatusRiskAssessed, nil
+
+	case ActionRequestInfo:
+		if err := checkReviewer(review, actor); err != nil {
+			return "", err
+		}
+		// The submission will change, so earlier approvals no longer count
+		next.Status = models.KYCReviewStatusInfoRequested
+		next.FirstApprover = ""
+		next.FirstApprovedAt = nil
+		*review = next
+		return models.KYCVerifyStatusInfoRequested, nil
+
+	case ActionApprove:
+		if err := checkReviewer(review, actor); err != nil {
+			return "", err
+		}
+		if review.Status == models.KYCReviewStatusAwaitingSecondApproval {
+			if sameAddress(actor, review.FirstApprover) {
+				return "", utils.NewForbiddenError("The second approval must come from a different verifier", nil)
+			}
+		} else {
+			if review.RiskAssessedBy == "" {
+				return "", utils.NewBadRequestError("A verifier must set the risk level before approval", nil)
+			}
+			if next.AssignedVerifier == "" {
+				next.AssignedVerifier = actor
+			}
+			if RequiresSecondApproval(review.RiskLevel) {
+				next.Status = models.KYCReviewStatusAwaitingSecondApproval
+				next.FirstApprover = actor
+				next.FirstApprovedAt = &now
+				// Hand the review back to the queue for a second verifier
+				next.AssignedVerifier = ""
+				*review = next
+				return models.KYCVerifyStatusFirstApproval, nil
+			}
+		}
+		next.Status = models.KYCReviewStatusApproved
+		next.DecidedAt = &now
+		*review = next
+		return models.KYCVerifyStatusApproved, nil
+
+	case ActionReject:
+		// A submission waiting for more information can be closed by any verifier
+		if review.Status != models.KYCReviewStatusInfoRequested {
+			if err := checkReviewer(review, actor); err != nil {
+				return "", err
+			}
+		}
+		next.Status = models.KYCReviewStatusRejected
+		next.DecidedAt = &now
+		*review = next
+		return models.KYCVerifyStatusRejected, nil
+
+	case ActionExpire:
+		if review.Status != models.KYCReviewStatusApproved {
+			return "", utils.NewBadRequestError("Only approved reviews can expire", nil)
+		}
+		next.Status = models.KYCReviewStatusExpired
+		next.DecidedAt = &now
+		*review = next
+		return models.KYCVerifyStatusExpired, nil
+
+	case ActionResubmit:
+		switch review.Status {
+		case models.KYCReviewStatusInfoRequested:
+			// Back to the verifier who asked
+			next.Status = models.KYCReviewStatusInReview
+			if next.AssignedVerifier == "" {
+				next.Status = models.KYCReviewStatusPending
+			}
+		case models.KYCReviewStatusRejected, models.KYCReviewStatusExpired:
+			// A new submission starts over
+			next.Status = models.KYCReviewStatusPending
+			next.AssignedVerifier = ""
+			next.DecidedAt = nil
+		default:
+			return "", utils.NewBadRequestError("KYC data can only be changed when more information is requested, after a rejection or once expired", nil)
+		}
+		// The new data needs a new risk assessment
+		next.FirstApprover = ""
+		next.FirstApprovedAt = nil
+		next.RiskAssessedBy = ""
+		next.RiskAssessedAt = nil
+		next.SubmittedAt = now
+		*review = next
+		return models.KYCVerifyStatusResubmitted, nil
+	}
+	return "", utils.NewBadRequestError("Unknown review action", nil)
+}
+
+// checkReviewer ensures a review is open for a decision and that actor may take it
+//
+// Unassigned reviews can be decided by any verifier; assigned ones only by the assignee.
+func checkReviewer(review *models.KYCReview, actor string) error {
+	switch review.Status {
+	case models.KYCReviewStatusPending, models.KYCReviewStatusInReview, models.KYCReviewStatusAwaitingSecondApproval:
+	case models.KYCReviewStatusInfoRequested:
+		return utils.NewBadRequestError("Review is waiting for the customer to provide more information", nil)
+	default:
+		return utils.NewBadRequestError("Review is already decided", nil)
+	}
+	if review.AssignedVerifier != "" && !sameAddress(review.AssignedVerifier, actor) {
+		return utils.NewForbiddenError("Review is assigned to another verifier", nil)
+	}
+	return nil
+}
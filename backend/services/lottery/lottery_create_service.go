@@ -9,6 +9,8 @@ import (
 	lotteryBlockchain "backend/blockchain/lottery"
 	"backend/config"
 	"backend/models"
+	"backend/services/issue"
+	"backend/services/outbox"
 	"backend/utils"
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
@@ -28,6 +30,10 @@ type CreateLotteryParams struct {
 	PrizeStructure         string
 	RegisteredAddr         string
 	RolloutContractAddress string
+	IssueNumberPattern     string
+	RolloverPolicy         string
+	AllowedCountries       []string // ISO 3166-1 alpha-2 codes the lottery can be bought from, empty for everywhere
+	BlockedCountries       []string // Codes the lottery cannot be bought from
 }
 
 // LotteryService encapsulates lottery creation business logic
@@ -78,6 +84,23 @@ func (s *LotteryCreateService) validateCreateLotteryParams(params CreateLotteryP
 		return utils.NewBadRequestError("Type ID must be between 1 and 36 characters", nil)
 	}
 
+	// Validate issue number pattern
+	if params.IssueNumberPattern != "" {
+		if err := issue.ValidateIssueNumberPattern(params.IssueNumberPattern); err != nil {
+			return utils.NewBadRequestError("Invalid issue number pattern", err)
+		}
+	}
+
+	// Validate rollover policy
+	if params.RolloverPolicy != "" && !IsValidRolloverPolicy(params.RolloverPolicy) {
+		return utils.NewBadRequestError("Invalid rollover policy", nil)
+	}
+
+	// Validate country restrictions
+	if _, _, err := ParseJurisdictions(params.AllowedCountries, params.BlockedCountries); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -109,6 +132,12 @@ func (s *LotteryCreateService) CreateLottery(ctx context.Context, params CreateL
 		return nil, common.Hash{}, utils.NewBadRequestError("Invalid ticket price format", nil)
 	}
 
+	rolloverPolicy := params.RolloverPolicy
+	if rolloverPolicy == "" {
+		rolloverPolicy = models.RolloverPolicyRollover
+	}
+	allowedCountries, blockedCountries, _ := ParseJurisdictions(params.AllowedCountries, params.BlockedCountries)
+
 	// Construct lottery record
 	lottery := models.Lottery{
 		LotteryID:              uuid.NewString(),
@@ -120,6 +149,11 @@ func (s *LotteryCreateService) CreateLottery(ctx context.Context, params CreateL
 		TicketPrice:            params.TicketPrice,
 		RegisteredAddr:         params.RegisteredAddr,
 		RolloutContractAddress: params.RolloutContractAddress,
+		IssueNumberPattern:     params.IssueNumberPattern,
+		Status:                 models.LotteryStatusActive,
+		RolloverPolicy:         rolloverPolicy,
+		AllowedCountries:       allowedCountries,
+		BlockedCountries:       blockedCountries,
 		CreatedAt:              time.Now(),
 		UpdatedAt:              time.Now(),
 	}
@@ -127,6 +161,13 @@ func (s *LotteryCreateService) CreateLottery(ctx context.Context, params CreateL
 	// Log creation attempt
 	utils.Logger.Info("Creating lottery", "lottery_id", lottery.LotteryID, "type_id", lottery.TypeID)
 
+	// Record the intent before deploying, so an interrupted request can be recovered from the transaction hash
+	outboxService := outbox.NewOutboxService(s.db)
+	intent, err := outboxService.CreateIntent(ctx, models.ChainIntentOpCreateLottery, lottery.LotteryID, lottery)
+	if err != nil {
+		return nil, common.Hash{}, err
+	}
+
 	// Execute blockchain transaction
 	data := []byte{} // Empty data, gas estimation handled by blockchain package
 	executeTx := func() (common.Hash, error) {
@@ -150,8 +191,9 @@ func (s *LotteryCreateService) CreateLottery(ctx context.Context, params CreateL
 			"owner", ownerAddr.Hex(),
 			"nonce", blockchain.Auth.Nonce,
 			"gas_limit", blockchain.Auth.GasLimit)
+		// Sign the deployment without sending it, so the transaction is saved on the intent before it can be mined
 		contractAddr, tx, _, err := lotteryBlockchain.DeployLotteryManager(
-			blockchain.Auth,
+			blockchain.SignOnlyAuth(),
 			blockchain.Client,
 			adminAddr,
 			ownerAddr,
@@ -163,20 +205,28 @@ func (s *LotteryCreateService) CreateLottery(ctx context.Context, params CreateL
 		)
 		if err != nil {
 			utils.Logger.Error("Failed to deploy LotteryManager contract", "error", err)
-			if tx == nil {
-				return common.Hash{}, utils.NewInternalError("Failed to deploy LotteryManager contract", err)
-			}
-			return tx.Hash(), utils.NewInternalError("Failed to deploy LotteryManager contract, transaction failed", err)
+			return common.Hash{}, utils.NewInternalError("Failed to deploy LotteryManager contract", err)
+		}
+		lottery.ContractAddress = contractAddr.Hex()
+		if err := outboxService.MarkSubmitted(ctx, intent.IntentID, tx, lottery); err != nil {
+			return common.Hash{}, blockchain.NonRetryable(err)
+		}
+		if err := blockchain.Client.SendTransaction(ctx, tx); err != nil {
+			// The node may have received it anyway, the recovery worker broadcasts the saved transaction or fails the intent
+			utils.Logger.Error("Failed to send contract deployment", "tx_hash", tx.Hash().Hex(), "error", err)
+			return tx.Hash(), blockchain.NonRetryable(utils.NewInternalError("Failed to deploy LotteryManager contract, transaction failed", err))
 		}
 
 		// Wait for transaction confirmation
 		receipt, err := bind.WaitMined(ctx, blockchain.Client, tx)
 		if err != nil {
+			// The deployment may still be mined, leave the intent to the recovery worker instead of deploying again
 			utils.Logger.Error("Failed to confirm contract deployment", "tx_hash", tx.Hash().Hex(), "error", err)
-			return tx.Hash(), utils.NewInternalError("Failed to confirm contract deployment", err)
+			return tx.Hash(), blockchain.NonRetryable(utils.NewInternalError("Failed to confirm contract deployment", err))
 		}
 		if receipt.Status != 1 {
 			utils.Logger.Error("Contract deployment transaction failed", "tx_hash", tx.Hash().Hex(), "status", receipt.Status)
+			outboxService.MarkFailed(ctx, intent.IntentID, errors.New("contract deployment transaction reverted"))
 			return tx.Hash(), utils.NewInternalError("Contract deployment transaction failed", nil)
 		}
 
@@ -184,11 +234,17 @@ func (s *LotteryCreateService) CreateLottery(ctx context.Context, params CreateL
 		blockchain.BlockchainMgr.UpdateGasHistory(receipt)
 		utils.Logger.Info("Transaction submitted successfully", "tx_hash", tx.Hash().Hex(), "gas_used", receipt.GasUsed)
 
-		// Update contract address and save to database
-		lottery.ContractAddress = contractAddr.Hex()
-		if err := s.db.WithContext(ctx).Create(&lottery).Error; err != nil {
+		// Save to database and complete the intent in one transaction
+		err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			if err := tx.Create(&lottery).Error; err != nil {
+				return err
+			}
+			return outboxService.Complete(tx, intent.IntentID)
+		})
+		if err != nil {
+			// The contract is deployed, the recovery worker saves the lottery from the stored intent
 			utils.Logger.Error("Failed to save lottery to database", "error", err)
-			return common.Hash{}, utils.NewInternalError("Failed to save lottery to database", err)
+			return tx.Hash(), blockchain.NonRetryable(utils.NewInternalError("Failed to save lottery to database", err))
 		}
 
 		utils.Logger.Info("Lottery created successfully",
@@ -199,6 +255,7 @@ func (s *LotteryCreateService) CreateLottery(ctx context.Context, params CreateL
 	// Execute blockchain transaction
 	txhash, err := blockchain.WithBlockchain(ctx, data, executeTx)
 	if err != nil {
+		outboxService.FailPending(ctx, intent.IntentID, err)
 		return nil, common.Hash{}, err
 	}
 	lottery.LotteryType = lotteryType
@@ -0,0 +1,230 @@
+package lottery
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	lotteryBlockchain "backend/blockchain/lottery"
+	"backend/models"
+	"backend/utils"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DrawNumberRange is the modulus SimpleRollout applies to each random word: number = word % 36 + 1
+const DrawNumberRange = 36
+
+// WinningNumbersFromWords maps VRF random words to winning numbers the same way SimpleRollout does
+func WinningNumbersFromWords(words []*big.Int, numberRange int64) []*big.Int {
+	numbers := make([]*big.Int, 0, len(words))
+	for _, word := range words {
+		number := new(big.Int).Mod(word, big.NewInt(numberRange))
+		numbers = append(numbers, number.Add(number, big.NewInt(1)))
+	}
+	return numbers
+}
+
+// ResultsTopic returns the topic of an indexed uint256[] holding the numbers, as emitted by DiceLanded
+func ResultsTopic(numbers []*big.Int) common.Hash {
+	data := make([]byte, 0, len(numbers)*32)
+	for _, number := range numbers {
+		data = append(data, common.LeftPadBytes(number.Bytes(), 32)...)
+	}
+	return crypto.Keccak256Hash(data)
+}
+
+// FormatNumbers joins numbers as comma separated decimals, the format of WinningNumbers
+func FormatNumbers(numbers []*big.Int) string {
+	parts := make([]string, 0, len(numbers))
+	for _, number := range numbers {
+		parts = append(parts, number.String())
+	}
+	return strings.Join(parts, ",")
+}
+
+// DrawProofVerification is the outcome of re-deriving a draw from its proof
+type DrawProofVerification struct {
+	Verified        bool   `json:"verified"`
+	ComputedNumbers string `json:"computed_numbers"` // Numbers derived from the random words
+	MatchesIssue    bool   `json:"matches_issue"`    // Derived numbers equal the issue's winning numbers
+	MatchesTopic    bool   `json:"matches_topic"`    // Derived numbers hash to the DiceLanded results topic
+	Reason          string `json:"reason,omitempty"`
+}
+
+// VerifyDrawProof re-derives the winning numbers from the proof's random words and checks them
+// against the issue's winning numbers and the results topic committed on chain
+func VerifyDrawProof(proof *models.DrawProof, issueWinningNumbers string) DrawProofVerification {
+	words := utils.ParseBetContent(proof.RandomWords)
+	if len(words) == 0 {
+		return DrawProofVerification{Reason: "random words were not recorded for this draw"}
+	}
+	numbers := WinningNumbersFromWords(words, proof.NumberRange)
+	result := DrawProofVerification{ComputedNumbers: FormatNumbers(numbers)}
+	result.MatchesIssue = result.ComputedNumbers == issueWinningNumbers
+	// Without the on-chain commitment the words cannot be tied to the draw, so the proof is not verified
+	result.MatchesTopic = proof.ResultsTopic != "" && common.HexToHash(proof.ResultsTopic) == ResultsTopic(numbers)
+	switch {
+	case !result.MatchesIssue:
+		result.Reason = "numbers derived from the random words differ from the issue's winning numbers"
+	case proof.ResultsTopic == "":
+		result.Reason = "results topic was not recorded"
+	case !result.MatchesTopic:
+		result.Reason = "numbers derived from the random words differ from the DiceLanded results"
+	default:
+		result.Verified = true
+	}
+	return result
+}
+
+// recordDrawProof collects the VRF request and fulfilment of a draw from the chain and stores it
+// alongside the issue, the issue's random seed is set to the VRF request ID
+func (s *LotteryDrawService) recordDrawProof(issueID string, lottery *models.Lottery, rolloutTxHash common.Hash) error {
+	ctx := context.Background()
+	var issue models.LotteryIssue
+	if err := s.db.Where("issue_id = ?", issueID).First(&issue).Error; err != nil {
+		return utils.NewServiceError("failed to find issue", err)
+	}
+
+	rolloutAddress := common.HexToAddress(lottery.RolloutContractAddress)
+	rolloutABI, err := lotteryBlockchain.SimpleRolloutMetaData.GetAbi()
+	if err != nil {
+		return utils.NewServiceError("failed to load SimpleRollout ABI", err)
+	}
+	coordinatorABI, err := lotteryBlockchain.VRFCoordinatorV2MetaData.GetAbi()
+	if err != nil {
+		return utils.NewServiceError("failed to load VRFCoordinatorV2 ABI", err)
+	}
+	rollout, err := lotteryBlockchain.NewSimpleRolloutFilterer(rolloutAddress, s.client)
+	if err != nil {
+		return utils.NewServiceError("failed to initialize Rollout contract", err)
+	}
+
+	proof := models.DrawProof{
+		IssueID:         issue.IssueID,
+		LotteryID:       issue.LotteryID,
+		RolloutContract: rolloutAddress.Hex(),
+		RequestTxHash:   rolloutTxHash.Hex(),
+		NumberRange:     DrawNumberRange,
+		WinningNumbers:  issue.WinningNumbers,
+	}
+
+	// The rolloutCall receipt holds the coordinator's RandomWordsRequested and the rollout's DiceRolled
+	receipt, err := s.client.TransactionReceipt(ctx, rolloutTxHash)
+	if err != nil {
+		return utils.NewServiceError("failed to fetch rolloutCall receipt", err)
+	}
+	proof.RequestBlockNumber = receipt.BlockNumber.Uint64()
+	proof.RequestBlockHash = receipt.BlockHash.Hex()
+	var requestID *big.Int
+	for _, log := range receipt.Logs {
+		if len(log.Topics) == 0 {
+			continue
+		}
+		switch {
+		case log.Address == rolloutAddress && log.Topics[0] == rolloutABI.Events["DiceRolled"].ID:
+			event, err := rollout.ParseDiceRolled(*log)
+			if err != nil {
+				return utils.NewServiceError("failed to parse DiceRolled event", err)
+			}
+			requestID = event.RequestId
+			proof.RolloutEpoch = event.Epoch.Int64()
+		case log.Topics[0] == coordinatorABI.Events["RandomWordsRequested"].ID:
+			coordinator, err := lotteryBlockchain.NewVRFCoordinatorV2Filterer(log.Address, s.client)
+			if err != nil {
+				return utils.NewServiceError("failed to initialize VRF coordinator", err)
+			}
+			if _, err := coordinator.ParseRandomWordsRequested(*log); err != nil {
+				return utils.NewServiceError("failed to parse RandomWordsRequested event", err)
+			}
+			proof.CoordinatorAddress = log.Address.Hex()
+		}
+	}
+	if requestID == nil {
+		return utils.NewServiceError("no DiceRolled event in rolloutCall receipt", nil)
+	}
+	proof.VRFRequestID = requestID.String()
+
+	// DiceLanded is emitted by the fulfilment transaction, its results are indexed so only their hash is on the log
+	logs, err := s.client.FilterLogs(ctx, ethereum.FilterQuery{
+		FromBlock: receipt.BlockNumber,
+		Addresses: []common.Address{rolloutAddress},
+		Topics:    [][]common.Hash{{rolloutABI.Events["DiceLanded"].ID}, {common.BigToHash(requestID)}},
+	})
+	if err != nil {
+		return utils.NewServiceError("failed to filter DiceLanded events", err)
+	}
+	if len(logs) > 0 {
+		landed := logs[0]
+		proof.FulfillTxHash = landed.TxHash.Hex()
+		proof.FulfillBlockNumber = landed.BlockNumber
+		proof.FulfillBlockHash = landed.BlockHash.Hex()
+		if len(landed.Topics) > 2 {
+			proof.ResultsTopic = landed.Topics[2].Hex()
+		}
+		words, err := s.fulfilledWords(ctx, landed.TxHash, requestID)
+		if err != nil {
+			utils.Logger.Warn("Random words not decodable from fulfilment transaction", "issue_id", issueID, "tx_hash", landed.TxHash.Hex(), "error", err)
+		} else {
+			proof.RandomWords = FormatNumbers(words)
+		}
+	} else {
+		utils.Logger.Warn("No DiceLanded event found for VRF request", "issue_id", issueID, "request_id", proof.VRFRequestID)
+	}
+
+	now := time.Now()
+	proof.CreatedAt = now
+	proof.UpdatedAt = now
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.OnConflict{UpdateAll: true}).Create(&proof).Error; err != nil {
+			return utils.NewServiceError("failed to save draw proof", err)
+		}
+		if err := tx.Model(&models.LotteryIssue{}).Where("issue_id = ?", issueID).
+			Updates(map[string]interface{}{"random_seed": proof.VRFRequestID, "updated_at": now}).Error; err != nil {
+			return utils.NewServiceError("failed to update issue random seed", err)
+		}
+		return nil
+	})
+}
+
+// fulfilledWords decodes the random words from a fulfilment sent through the coordinator's
+// CallFullfillRandomWords, coordinators that derive the words from a VRF proof are not decodable
+func (s *LotteryDrawService) fulfilledWords(ctx context.Context, txHash common.Hash, requestID *big.Int) ([]*big.Int, error) {
+	tx, _, err := s.client.TransactionByHash(ctx, txHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch fulfilment transaction: %v", err)
+	}
+	if len(tx.Data()) < 4 {
+		return nil, fmt.Errorf("fulfilment transaction has no call data")
+	}
+	coordinatorABI, err := lotteryBlockchain.VRFCoordinatorV2MetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	method, err := coordinatorABI.MethodById(tx.Data()[:4])
+	if err != nil {
+		return nil, err
+	}
+	if method.RawName != "CallFullfillRandomWords" {
+		return nil, fmt.Errorf("unexpected fulfilment method %s", method.RawName)
+	}
+	args, err := method.Inputs.Unpack(tx.Data()[4:])
+	if err != nil {
+		return nil, fmt.Errorf("failed to unpack fulfilment input: %v", err)
+	}
+	id, ok := args[0].(*big.Int)
+	if !ok || id.Cmp(requestID) != 0 {
+		return nil, fmt.Errorf("fulfilment is for request %v, expected %s", args[0], requestID)
+	}
+	words, ok := args[1].([]*big.Int)
+	if !ok {
+		return nil, fmt.Errorf("unexpected random words type %T", args[1])
+	}
+	return words, nil
+}
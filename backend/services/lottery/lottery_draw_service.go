@@ -4,6 +4,9 @@ import (
 	"backend/blockchain"
 	lotteryBlockchain "backend/blockchain/lottery"
 	"backend/models"
+	issueService "backend/services/issue"
+	"backend/services/webhook"
+	winnerService "backend/services/winner"
 	"backend/utils"
 	"context"
 	"fmt"
@@ -72,7 +75,7 @@ func (s *LotteryDrawService) DrawLotteryAsync(issueID string) error {
 // It fetches data, sets contract state, executes the draw, and processes results
 func (s *LotteryDrawService) executeLotteryDraw(issueID string) error {
 	// Fetch issue and lottery data
-	lottery, err := s.fetchLotteryData(issueID)
+	issue, lottery, err := s.fetchLotteryData(issueID)
 	if err != nil {
 		return err
 	}
@@ -85,6 +88,11 @@ func (s *LotteryDrawService) executeLotteryDraw(issueID string) error {
 	if err != nil {
 		return utils.NewServiceError("failed to connect to lottery contract", err)
 	}
+	// Resolve the epoch the issue's bets were recorded in
+	resultsEpoch, err := s.resolveResultsEpoch(issue, contract)
+	if err != nil {
+		return err
+	}
 	// Set contract state to Rollout if needed
 	if err := s.setContractState(contract, uint8(models.ContractStateRollout)); err != nil {
 		return err
@@ -94,12 +102,17 @@ func (s *LotteryDrawService) executeLotteryDraw(issueID string) error {
 	if err := s.db.Model(&models.LotteryIssue{}).Where("issue_id = ?", issueID).Update("status", models.IssueStatusDrawing).Error; err != nil {
 		return utils.NewServiceError("failed to update lottery issue status", err)
 	}
+	issue.Status = models.IssueStatusDrawing
+	publishIssueStatus(issue)
+	if err := webhook.EnqueueSalesClosed(s.db, issue); err != nil {
+		utils.Logger.Warn("Failed to enqueue sales closed webhook", "issue_id", issueID, "error", err)
+	}
 
 	// Subscribe to LotteryResults event before triggering rollout
 	resultsChan := make(chan []*big.Int)
 	errChan := make(chan error)
 	go func() {
-		results, err := s.subscribeToLotteryResults(contract)
+		results, err := s.subscribeToLotteryResults(contract, resultsEpoch)
 		if err != nil {
 			errChan <- utils.NewServiceError("failed to subscribe to LotteryResults event", err)
 			return
@@ -114,20 +127,61 @@ func (s *LotteryDrawService) executeLotteryDraw(issueID string) error {
 	}
 
 	// Wait for and process results
-	return s.waitAndProcessResults(issueID, contract, tx, resultsChan, errChan)
+	if err := s.waitAndProcessResults(issueID, contract, tx, resultsEpoch, resultsChan, errChan); err != nil {
+		return err
+	}
+
+	// Settle the payouts rolloutCallback made, failed transfers are left for an operator retry.
+	// Winners are only notified once their payout status is known.
+	if err := s.recordPayouts(issueID, contract, tx.Hash(), resultsEpoch); err != nil {
+		utils.Logger.Warn("Failed to record prize payouts, winners are not notified", "issue_id", issueID, "tx_hash", tx.Hash().Hex(), "error", err)
+	} else {
+		s.notifyPrizeWinners(issue)
+	}
+
+	// Record the VRF request and fulfilment so the draw can be re-verified, the draw itself is already final
+	if err := s.recordDrawProof(issueID, lottery, tx.Hash()); err != nil {
+		utils.Logger.Warn("Failed to record draw proof", "issue_id", issueID, "tx_hash", tx.Hash().Hex(), "error", err)
+	}
+
+	// Open the next issue if the lottery has a recurring schedule, the scheduler retries on failure
+	if next, err := issueService.NewIssueScheduleService(s.db).OpenNextIssue(context.Background(), issue.LotteryID); err != nil {
+		utils.Logger.Warn("Failed to open next scheduled issue", "lottery_id", issue.LotteryID, "error", err)
+	} else if next != nil {
+		utils.Logger.Info("Opened next scheduled issue", "lottery_id", issue.LotteryID, "issue_id", next.IssueID)
+		publishIssueStatus(next)
+	}
+	return nil
 }
 
 // fetchLotteryData retrieves lottery issue and associated lottery data
-func (s *LotteryDrawService) fetchLotteryData(issueID string) (*models.Lottery, error) {
+func (s *LotteryDrawService) fetchLotteryData(issueID string) (*models.LotteryIssue, *models.Lottery, error) {
 	var lottery models.Lottery
 	var issue models.LotteryIssue
 	if err := s.db.Where("issue_id = ?", issueID).First(&issue).Error; err != nil {
-		return nil, utils.NewServiceError("failed to fetch lottery issue data", err)
+		return nil, nil, utils.NewServiceError("failed to fetch lottery issue data", err)
 	}
 	if err := s.db.Where("lottery_id = ?", issue.LotteryID).First(&lottery).Error; err != nil {
-		return nil, utils.NewServiceError("failed to fetch lottery data", err)
+		return nil, nil, utils.NewServiceError("failed to fetch lottery data", err)
+	}
+	return &issue, &lottery, nil
+}
+
+// resolveResultsEpoch returns the epoch carried by the LotteryResults event of this issue's draw
+//
+// rolloutCallback moves the contract back to Ready, which increments the epoch, before emitting
+// LotteryResults, so the event carries the issue's epoch + 1. Issues created before epochs were
+// recorded fall back to the contract's current epoch, which must match a stored epoch otherwise.
+func (s *LotteryDrawService) resolveResultsEpoch(issue *models.LotteryIssue, contract *lotteryBlockchain.LotteryManager) (*big.Int, error) {
+	current, err := contract.Epoch(nil)
+	if err != nil {
+		return nil, utils.NewServiceError("failed to get contract epoch", err)
+	}
+	if issue.Epoch != nil && current.Cmp(big.NewInt(*issue.Epoch)) != 0 {
+		utils.Logger.Error("Issue epoch does not match contract epoch", "issue_id", issue.IssueID, "issue_epoch", *issue.Epoch, "contract_epoch", current)
+		return nil, utils.NewServiceError(fmt.Sprintf("issue epoch %d does not match contract epoch %s", *issue.Epoch, current), nil)
 	}
-	return &lottery, nil
+	return new(big.Int).Add(current, big.NewInt(1)), nil
 }
 
 // setContractState sets the lottery contract state to the target state if needed
@@ -207,7 +261,7 @@ func (s *LotteryDrawService) executeRollout(lottery *models.Lottery) (*types.Tra
 }
 
 // waitAndProcessResults waits for lottery results and processes them
-func (s *LotteryDrawService) waitAndProcessResults(issueID string, contract *lotteryBlockchain.LotteryManager, tx *types.Transaction, resultsChan chan []*big.Int, errChan chan error) error {
+func (s *LotteryDrawService) waitAndProcessResults(issueID string, contract *lotteryBlockchain.LotteryManager, tx *types.Transaction, resultsEpoch *big.Int, resultsChan chan []*big.Int, errChan chan error) error {
 	// Wait for results
 	select {
 	case results := <-resultsChan:
@@ -230,7 +284,7 @@ func (s *LotteryDrawService) waitAndProcessResults(issueID string, contract *lot
 	case err := <-errChan:
 		// On error, attempt to query historical logs as a fallback
 		utils.Logger.Warn("Subscription failed, attempting to query historical logs", "issue_id", issueID, "error", err)
-		results, err := s.queryHistoricalResults(contract, tx.Hash())
+		results, err := s.queryHistoricalResults(contract, tx.Hash(), resultsEpoch)
 		if err != nil {
 			return utils.NewServiceError("failed to recover results from historical logs", err)
 		}
@@ -243,7 +297,7 @@ func (s *LotteryDrawService) waitAndProcessResults(issueID string, contract *lot
 	case <-time.After(LotteryResultsTimeout):
 		// On timeout, attempt to query historical logs
 		utils.Logger.Warn("Timeout waiting for LotteryResults event, querying historical logs", "issue_id", issueID)
-		results, err := s.queryHistoricalResults(contract, tx.Hash())
+		results, err := s.queryHistoricalResults(contract, tx.Hash(), resultsEpoch)
 		if err != nil {
 			return utils.NewServiceError("failed to recover results from historical logs after timeout", err)
 		}
@@ -256,8 +310,8 @@ func (s *LotteryDrawService) waitAndProcessResults(issueID string, contract *lot
 	}
 }
 
-// subscribeToLotteryResults subscribes to the LotteryResults event and retries on failure
-func (s *LotteryDrawService) subscribeToLotteryResults(contract *lotteryBlockchain.LotteryManager) ([]*big.Int, error) {
+// subscribeToLotteryResults subscribes to the LotteryResults event of the given epoch and retries on failure
+func (s *LotteryDrawService) subscribeToLotteryResults(contract *lotteryBlockchain.LotteryManager, epoch *big.Int) ([]*big.Int, error) {
 	logs := make(chan *lotteryBlockchain.LotteryManagerLotteryResults)
 	opts := &bind.WatchOpts{Context: context.Background()}
 
@@ -273,40 +327,53 @@ func (s *LotteryDrawService) subscribeToLotteryResults(contract *lotteryBlockcha
 			continue
 		}
 
-		utils.Logger.Info("Successfully subscribed, waiting for event", "attempt", attempt)
-		select {
-		case event := <-logs:
-			sub.Unsubscribe()
-			if len(event.Results) != ExpectedResultCount {
-				return nil, fmt.Errorf("expected %d results, got %d", ExpectedResultCount, len(event.Results))
-			}
-			// Validate results
-			for i, result := range event.Results {
-				if result == nil || result.Cmp(big.NewInt(0)) <= 0 {
-					return nil, fmt.Errorf("invalid result at index %d: %v", i, result)
-				}
-			}
-			utils.Logger.Info("Received LotteryResults event", "results", event.Results, "epoch", event.Epoch, "timestamp", event.Timestamp)
-			return event.Results, nil
-		case err := <-sub.Err():
+		utils.Logger.Info("Successfully subscribed, waiting for event", "attempt", attempt, "epoch", epoch)
+		event, err := waitForEpochResults(logs, sub.Err(), epoch)
+		if err != nil {
 			sub.Unsubscribe()
 			utils.Logger.Warn("Subscription error, retrying", "attempt", attempt, "error", err)
 			if attempt == LotteryResultsRetries {
-				return nil, fmt.Errorf("subscription error: %v", err)
+				return nil, err
 			}
-		case <-time.After(LotteryResultsTimeout):
-			sub.Unsubscribe()
-			utils.Logger.Warn("Timeout waiting for LotteryResults event", "attempt", attempt)
-			if attempt == LotteryResultsRetries {
-				return nil, fmt.Errorf("timeout waiting for LotteryResults event after %d attempts", LotteryResultsRetries)
+			continue
+		}
+		sub.Unsubscribe()
+		if len(event.Results) != ExpectedResultCount {
+			return nil, fmt.Errorf("expected %d results, got %d", ExpectedResultCount, len(event.Results))
+		}
+		// Validate results
+		for i, result := range event.Results {
+			if result == nil || result.Cmp(big.NewInt(0)) <= 0 {
+				return nil, fmt.Errorf("invalid result at index %d: %v", i, result)
 			}
 		}
+		utils.Logger.Info("Received LotteryResults event", "results", event.Results, "epoch", event.Epoch, "timestamp", event.Timestamp)
+		return event.Results, nil
 	}
 	return nil, fmt.Errorf("failed to subscribe, exceeded retry attempts")
 }
 
+// waitForEpochResults waits for the LotteryResults event of the given epoch, events of other epochs are skipped
+func waitForEpochResults(logs chan *lotteryBlockchain.LotteryManagerLotteryResults, errs <-chan error, epoch *big.Int) (*lotteryBlockchain.LotteryManagerLotteryResults, error) {
+	timeout := time.After(LotteryResultsTimeout)
+	for {
+		select {
+		case event := <-logs:
+			if event.Epoch == nil || event.Epoch.Cmp(epoch) != 0 {
+				utils.Logger.Warn("Skipping LotteryResults event of another epoch", "expected_epoch", epoch, "epoch", event.Epoch)
+				continue
+			}
+			return event, nil
+		case err := <-errs:
+			return nil, fmt.Errorf("subscription error: %v", err)
+		case <-timeout:
+			return nil, fmt.Errorf("timeout waiting for LotteryResults event of epoch %s", epoch)
+		}
+	}
+}
+
 // queryHistoricalResults queries historical logs for LotteryResults events
-func (s *LotteryDrawService) queryHistoricalResults(contract *lotteryBlockchain.LotteryManager, txHash common.Hash) ([]*big.Int, error) {
+func (s *LotteryDrawService) queryHistoricalResults(contract *lotteryBlockchain.LotteryManager, txHash common.Hash, epoch *big.Int) ([]*big.Int, error) {
 	// Query logs from the block of the transaction
 	_, _, err := s.client.TransactionByHash(context.Background(), txHash)
 	if err != nil {
@@ -336,6 +403,10 @@ func (s *LotteryDrawService) queryHistoricalResults(contract *lotteryBlockchain.
 		if len(event.Results) != ExpectedResultCount {
 			continue
 		}
+		// Only the event of this issue's epoch belongs to the issue
+		if event.Epoch == nil || event.Epoch.Cmp(epoch) != 0 {
+			continue
+		}
 		// Validate results
 		for i, result := range event.Results {
 			if result == nil || result.Cmp(big.NewInt(0)) <= 0 {
@@ -346,55 +417,61 @@ func (s *LotteryDrawService) queryHistoricalResults(contract *lotteryBlockchain.
 		return event.Results, nil
 	}
 
-	return nil, fmt.Errorf("no valid LotteryResults event found in historical logs")
+	return nil, fmt.Errorf("no valid LotteryResults event of epoch %s found in historical logs", epoch)
 }
 
 // recordLotteryResults updates the issue and saves winners in a transaction
 func (s *LotteryDrawService) recordLotteryResults(issueID string, results []*big.Int, txHash common.Hash) error {
-	// Begin transaction
-	tx := s.db.Begin()
-	defer func() {
-		if r := recover(); r != nil || tx.Error != nil {
-			tx.Rollback()
+	var issue models.LotteryIssue
+	// The transaction is rolled back on any error or panic
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		// Fetch issue
+		if err := tx.Where("issue_id = ?", issueID).First(&issue).Error; err != nil {
+			utils.Logger.Error("Failed to find issue", "issue_id", issueID, "error", err)
+			return utils.NewServiceError("failed to find issue", err)
 		}
-	}()
 
-	// Fetch issue
-	var issue models.LotteryIssue
-	if err := tx.Where("issue_id = ?", issueID).First(&issue).Error; err != nil {
-		utils.Logger.Error("Failed to find issue", "issue_id", issueID, "error", err)
-		return utils.NewServiceError("failed to find issue", err)
-	}
+		// Update issue
+		issue.WinningNumbers = fmt.Sprintf("%d,%d,%d", results[0], results[1], results[2])
+		issue.DrawTxHash = txHash.Hex()
+		issue.Status = models.IssueStatusDrawn
+		issue.UpdatedAt = time.Now()
+		if err := tx.Save(&issue).Error; err != nil {
+			utils.Logger.Error("Failed to update issue", "issue_id", issueID, "error", err)
+			return utils.NewServiceError("failed to update issue", err)
+		}
+		utils.Logger.Info("Issue updated successfully", "issue_id", issueID, "winning_numbers", issue.WinningNumbers)
 
-	// Update issue
-	issue.WinningNumbers = fmt.Sprintf("%d,%d,%d", results[0], results[1], results[2])
-	issue.DrawTxHash = txHash.Hex()
-	issue.Status = models.IssueStatusDrawn
-	issue.UpdatedAt = time.Now()
-	if err := tx.Save(&issue).Error; err != nil {
-		utils.Logger.Error("Failed to update issue", "issue_id", issueID, "error", err)
-		return utils.NewServiceError("failed to update issue", err)
-	}
-	utils.Logger.Info("Issue updated successfully", "issue_id", issueID, "winning_numbers", issue.WinningNumbers)
+		// Fetch and save winners
+		winners, err := s.getWinnersFromChain(issueID, results)
+		if err != nil {
+			return utils.NewServiceError("failed to get winners from chain", err)
+		}
+		if err := winnerService.NewWinnerTaxService(s.db).WithholdWinners(tx, winners); err != nil {
+			return err
+		}
+		for _, winner := range winners {
+			if err := tx.Create(&winner).Error; err != nil {
+				utils.Logger.Error("Failed to save winner", "ticket_id", winner.TicketID, "error", err)
+				return utils.NewServiceError("failed to save winner", err)
+			}
+		}
+		utils.Logger.Info("Winners saved successfully", "issue_id", issueID, "winner_count", len(winners))
 
-	// Fetch and save winners
-	winners, err := s.getWinnersFromChain(issueID, results)
-	if err != nil {
-		return utils.NewServiceError("failed to get winners from chain", err)
-	}
-	for _, winner := range winners {
-		if err := tx.Create(&winner).Error; err != nil {
-			utils.Logger.Error("Failed to save winner", "ticket_id", winner.TicketID, "error", err)
-			return utils.NewServiceError("failed to save winner", err)
+		// No first prize winner, handle the pool according to the lottery's rollover policy
+		if len(winners) == 0 {
+			if err := s.recordRollover(tx, &issue, results); err != nil {
+				return err
+			}
 		}
-	}
-	utils.Logger.Info("Winners saved successfully", "issue_id", issueID, "winner_count", len(winners))
 
-	// Commit transaction
-	if err := tx.Commit().Error; err != nil {
-		utils.Logger.Error("Failed to commit transaction", "issue_id", issueID, "error", err)
-		return utils.NewServiceError("failed to commit transaction", err)
+		return enqueueDrawWebhooks(tx, &issue)
+	})
+	if err != nil {
+		utils.Logger.Error("Failed to record lottery results", "issue_id", issueID, "error", err)
+		return err
 	}
+	s.publishDrawResults(&issue)
 
 	return nil
 }
@@ -421,14 +498,15 @@ func (s *LotteryDrawService) getWinnersFromChain(issueID string, results []*big.
 				ticketNumbers[1].Cmp(results[1]) == 0 &&
 				ticketNumbers[2].Cmp(results[2]) == 0 {
 				winner := models.Winner{
-					WinnerID:    uuid.NewString(),
-					IssueID:     issueID,
-					TicketID:    ticket.TicketID,
-					Address:     ticket.BuyerAddress,
-					PrizeLevel:  "First Prize",
-					PrizeAmount: ticket.PurchaseAmount,
-					CreatedAt:   time.Now(),
-					UpdatedAt:   time.Now(),
+					WinnerID:     uuid.NewString(),
+					IssueID:      issueID,
+					TicketID:     ticket.TicketID,
+					Address:      ticket.BuyerAddress,
+					PrizeLevel:   PrizeLevelFirst,
+					PrizeAmount:  ticket.PurchaseAmount,
+					PayoutStatus: models.PayoutStatusPending,
+					CreatedAt:    time.Now(),
+					UpdatedAt:    time.Now(),
 				}
 				winners = append(winners, winner)
 			}
@@ -0,0 +1,105 @@
+package lottery
+
+import (
+	"backend/models"
+	"backend/services/account"
+	"backend/services/events"
+	"backend/services/notification"
+	"backend/services/webhook"
+	"backend/utils"
+
+	"gorm.io/gorm"
+)
+
+// publishIssueStatus publishes the current status of an issue on the event bus
+func publishIssueStatus(issue *models.LotteryIssue) {
+	events.Publish(events.Event{
+		Type:      events.TypeIssueStatus,
+		LotteryID: issue.LotteryID,
+		IssueID:   issue.IssueID,
+		Data: events.IssueStatusData{
+			IssueNumber:    issue.IssueNumber,
+			Status:         issue.Status,
+			PrizePool:      issue.PrizePool,
+			WinningNumbers: issue.WinningNumbers,
+			DrawTxHash:     issue.DrawTxHash,
+		},
+	})
+}
+
+// publishDrawResults announces a drawn issue and its winners, including the lower tier winners of a rollover
+//
+// Winners are notified separately by notifyPrizeWinners once their payouts are settled.
+func (s *LotteryDrawService) publishDrawResults(issue *models.LotteryIssue) {
+	publishIssueStatus(issue)
+
+	var winners []models.Winner
+	if err := s.db.Where("issue_id = ?", issue.IssueID).Order("created_at").Find(&winners).Error; err != nil {
+		utils.Logger.Warn("Failed to fetch winners to announce", "issue_id", issue.IssueID, "error", err)
+		return
+	}
+	for _, winner := range winners {
+		account.InvalidateAccountSummary(winner.Address)
+		events.Publish(events.Event{
+			Type:      events.TypeWinnerAnnounced,
+			LotteryID: issue.LotteryID,
+			IssueID:   issue.IssueID,
+			Data: events.WinnerAnnouncedData{
+				WinnerID:    winner.WinnerID,
+				TicketID:    winner.TicketID,
+				Address:     winner.Address,
+				PrizeLevel:  winner.PrizeLevel,
+				PrizeAmount: winner.PrizeAmount,
+			},
+		})
+	}
+}
+
+// notifyPrizeWinners sends the prize won notification to the winners of an issue
+//
+// It is called after recordPayouts has settled the payouts, so the notification carries the final
+// payout status and amounts rather than the PENDING status the winners are saved with.
+func (s *LotteryDrawService) notifyPrizeWinners(issue *models.LotteryIssue) {
+	var winners []models.Winner
+	if err := s.db.Where("issue_id = ?", issue.IssueID).Order("created_at").Find(&winners).Error; err != nil {
+		utils.Logger.Warn("Failed to fetch winners to notify", "issue_id", issue.IssueID, "error", err)
+		return
+	}
+	var lottery models.Lottery
+	if err := s.db.Select("ticket_name").Where("lottery_id = ?", issue.LotteryID).First(&lottery).Error; err != nil {
+		utils.Logger.Warn("Failed to fetch lottery of drawn issue", "issue_id", issue.IssueID, "error", err)
+	}
+	notifier := notification.NewNotificationService(s.db)
+	for _, winner := range winners {
+		account.InvalidateAccountSummary(winner.Address)
+		notifier.NotifyAsync(winner.Address, notification.TemplatePrizeWon, map[string]interface{}{
+			"TicketID":       winner.TicketID,
+			"TicketName":     lottery.TicketName,
+			"IssueNumber":    issue.IssueNumber,
+			"PrizeLevel":     winner.PrizeLevel,
+			"GrossAmount":    winner.GrossAmount,
+			"WithheldAmount": winner.WithheldAmount,
+			"NetAmount":      winner.NetAmount,
+			"TaxOwedAmount":  winner.TaxOwedAmount,
+			"PayoutStatus":   winner.PayoutStatus,
+		})
+	}
+}
+
+// enqueueDrawWebhooks records the issue.drawn and winner.recorded webhooks in the transaction that saves the results
+func enqueueDrawWebhooks(tx *gorm.DB, issue *models.LotteryIssue) error {
+	if err := webhook.Enqueue(tx, models.WebhookEventIssueDrawn, models.WebhookEventIssueDrawn+":"+issue.IssueID, issue.LotteryID, webhook.NewIssueData(issue)); err != nil {
+		return err
+	}
+	var winners []models.Winner
+	if err := tx.Where("issue_id = ?", issue.IssueID).Find(&winners).Error; err != nil {
+		return utils.NewServiceError("failed to fetch winners for webhooks", err)
+	}
+	for i := range winners {
+		eventKey := models.WebhookEventWinnerRecorded + ":" + winners[i].WinnerID
+		if err := webhook.Enqueue(tx, models.WebhookEventWinnerRecorded, eventKey, issue.LotteryID, webhook.NewWinnerData(issue.LotteryID, &winners[i])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package lottery
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"backend/models"
+	"backend/services/geo"
+	"backend/utils"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// JurisdictionService changes the countries a lottery can be bought from
+type JurisdictionService struct {
+	db *gorm.DB
+}
+
+// NewJurisdictionService creates a new JurisdictionService instance
+func NewJurisdictionService(db *gorm.DB) *JurisdictionService {
+	return &JurisdictionService{db: db}
+}
+
+// ParseJurisdictions validates the allowed and blocked country codes of a lottery and returns them as stored
+func ParseJurisdictions(allowed, blocked []string) (string, string, error) {
+	allowedCountries, err := geo.ParseCountries(strings.Join(allowed, ","))
+	if err != nil {
+		return "", "", utils.NewBadRequestError("Invalid allowed_countries", err)
+	}
+	blockedCountries, err := geo.ParseCountries(strings.Join(blocked, ","))
+	if err != nil {
+		return "", "", utils.NewBadRequestError("Invalid blocked_countries", err)
+	}
+	for _, country := range allowedCountries {
+		for _, other := range blockedCountries {
+			if country == other {
+				return "", "", utils.NewBadRequestError("Country "+country+" is both allowed and blocked", nil)
+			}
+		}
+	}
+	return geo.FormatCountries(allowedCountries), geo.FormatCountries(blockedCountries), nil
+}
+
+// SetJurisdictions replaces the countries a lottery can and cannot be bought from
+func (s *JurisdictionService) SetJurisdictions(ctx context.Context, lotteryID string, allowed, blocked []string) (*models.Lottery, error) {
+	allowedCountries, blockedCountries, err := ParseJurisdictions(allowed, blocked)
+	if err != nil {
+		return nil, err
+	}
+	var lottery models.Lottery
+	if err := s.db.WithContext(ctx).Where("lottery_id = ?", lotteryID).First(&lottery).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, utils.NewBadRequestError("Lottery not found", nil)
+		}
+		return nil, utils.NewInternalError("Failed to fetch lottery", errors.Wrap(err, "database error"))
+	}
+	if err := s.db.WithContext(ctx).Model(&lottery).
+		Updates(map[string]interface{}{
+			"allowed_countries": allowedCountries,
+			"blocked_countries": blockedCountries,
+			"updated_at":        time.Now(),
+		}).Error; err != nil {
+		return nil, utils.NewInternalError("Failed to update country restrictions", errors.Wrap(err, "database error"))
+	}
+	utils.Logger.Info("Lottery country restrictions updated", "lottery_id", lotteryID, "allowed", allowedCountries, "blocked", blockedCountries)
+	lottery.AllowedCountries = allowedCountries
+	lottery.BlockedCountries = blockedCountries
+	return &lottery, nil
+}
@@ -0,0 +1,216 @@
+package lottery
+
+import (
+	"context"
+	"time"
+
+	"backend/blockchain"
+	"backend/models"
+	"backend/utils"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// lifecycleTransitions maps each target status to the statuses it can be reached from
+var lifecycleTransitions = map[string][]string{
+	models.LotteryStatusPaused:     {models.LotteryStatusActive},
+	models.LotteryStatusActive:     {models.LotteryStatusPaused},
+	models.LotteryStatusTerminated: {models.LotteryStatusActive, models.LotteryStatusPaused},
+	models.LotteryStatusDestroyed:  {models.LotteryStatusActive, models.LotteryStatusPaused, models.LotteryStatusTerminated},
+}
+
+// LotteryLifecycleService encapsulates pausing, resuming, terminating and destroying lotteries
+type LotteryLifecycleService struct {
+	db *gorm.DB
+}
+
+// NewLotteryLifecycleService creates a new LotteryLifecycleService instance
+func NewLotteryLifecycleService(db *gorm.DB) *LotteryLifecycleService {
+	return &LotteryLifecycleService{db: db}
+}
+
+// PauseLottery stops ticket sales and issue creation for a lottery, the contract is left untouched
+func (s *LotteryLifecycleService) PauseLottery(ctx context.Context, lotteryID string) (*models.Lottery, error) {
+	return s.updateStatus(ctx, lotteryID, models.LotteryStatusPaused)
+}
+
+// ResumeLottery reopens ticket sales for a paused lottery
+func (s *LotteryLifecycleService) ResumeLottery(ctx context.Context, lotteryID string) (*models.Lottery, error) {
+	return s.updateStatus(ctx, lotteryID, models.LotteryStatusActive)
+}
+
+// TerminateLottery moves the contract from Ready to Terminal, which returns the pool to the owner
+//
+// Parameters:
+//   - ctx: Request context
+//   - lotteryID: ID of the lottery to terminate
+//
+// Returns:
+//   - *Lottery: The updated lottery record
+//   - common.Hash: Blockchain transaction hash
+//   - error: Invalid state or blockchain error
+func (s *LotteryLifecycleService) TerminateLottery(ctx context.Context, lotteryID string) (*models.Lottery, common.Hash, error) {
+	return s.retire(ctx, lotteryID, models.LotteryStatusTerminated, []uint8{uint8(models.ContractStateReady)},
+		func(opts *bind.TransactOpts, address string) (*types.Transaction, error) {
+			contract, err := blockchain.ConnectLotteryContract(address)
+			if err != nil {
+				return nil, err
+			}
+			return contract.TransState(opts, uint8(models.ContractStateTerminal))
+		})
+}
+
+// DestroyLottery destroys the contract, allowed when the contract is in Ready or Terminal state
+//
+// Parameters:
+//   - ctx: Request context
+//   - lotteryID: ID of the lottery to destroy
+//
+// Returns:
+//   - *Lottery: The updated lottery record
+//   - common.Hash: Blockchain transaction hash
+//   - error: Invalid state or blockchain error
+func (s *LotteryLifecycleService) DestroyLottery(ctx context.Context, lotteryID string) (*models.Lottery, common.Hash, error) {
+	return s.retire(ctx, lotteryID, models.LotteryStatusDestroyed, []uint8{uint8(models.ContractStateReady), uint8(models.ContractStateTerminal)},
+		func(opts *bind.TransactOpts, address string) (*types.Transaction, error) {
+			contract, err := blockchain.ConnectLotteryContract(address)
+			if err != nil {
+				return nil, err
+			}
+			return contract.Destroy(opts)
+		})
+}
+
+// CanChangeStatus reports whether a lottery in status from can be moved to status target
+func CanChangeStatus(from, target string) bool {
+	for _, allowed := range lifecycleTransitions[target] {
+		if from == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// loadLottery fetches a lottery and checks the transition to the target status is allowed
+func (s *LotteryLifecycleService) loadLottery(ctx context.Context, lotteryID, target string) (*models.Lottery, error) {
+	var lottery models.Lottery
+	if err := s.db.WithContext(ctx).Where("lottery_id = ?", lotteryID).First(&lottery).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.Logger.Warn("Lottery not found", "lottery_id", lotteryID)
+			return nil, utils.NewBadRequestError("Lottery not found", nil)
+		}
+		return nil, utils.NewInternalError("Failed to fetch lottery", errors.Wrap(err, "database error"))
+	}
+	if CanChangeStatus(lottery.Status, target) {
+		return &lottery, nil
+	}
+	utils.Logger.Warn("Invalid lottery status transition", "lottery_id", lotteryID, "status", lottery.Status, "target", target)
+	return nil, utils.NewBadRequestError("Cannot change lottery status from "+lottery.Status+" to "+target, nil)
+}
+
+// updateStatus changes the lottery status without a blockchain transaction
+func (s *LotteryLifecycleService) updateStatus(ctx context.Context, lotteryID, target string) (*models.Lottery, error) {
+	lottery, err := s.loadLottery(ctx, lotteryID, target)
+	if err != nil {
+		return nil, err
+	}
+	// Guard on the current status so concurrent transitions cannot both succeed
+	result := s.db.WithContext(ctx).Model(&models.Lottery{}).
+		Where("lottery_id = ? AND status = ?", lotteryID, lottery.Status).
+		Updates(map[string]interface{}{"status": target, "updated_at": time.Now()})
+	if result.Error != nil {
+		return nil, utils.NewInternalError("Failed to update lottery status", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, utils.NewBadRequestError("Lottery status changed concurrently, please retry", nil)
+	}
+	utils.Logger.Info("Lottery status updated", "lottery_id", lotteryID, "from", lottery.Status, "to", target)
+	lottery.Status = target
+	return lottery, nil
+}
+
+// retire sends the terminate or destroy transaction after checking the lottery and contract state
+func (s *LotteryLifecycleService) retire(ctx context.Context, lotteryID, target string, allowedStates []uint8,
+	send func(opts *bind.TransactOpts, address string) (*types.Transaction, error)) (*models.Lottery, common.Hash, error) {
+	lottery, err := s.loadLottery(ctx, lotteryID, target)
+	if err != nil {
+		return nil, common.Hash{}, err
+	}
+
+	// Issues still on sale or being drawn would lose their pool
+	var openIssues int64
+	if err := s.db.WithContext(ctx).Model(&models.LotteryIssue{}).
+		Where("lottery_id = ? AND status IN ?", lotteryID, []string{models.IssueStatusPending, models.IssueStatusDrawing}).
+		Count(&openIssues).Error; err != nil {
+		return nil, common.Hash{}, utils.NewInternalError("Failed to check open issues", err)
+	}
+	if openIssues > 0 {
+		utils.Logger.Warn("Lottery has open issues", "lottery_id", lotteryID, "open_issues", openIssues)
+		return nil, common.Hash{}, utils.NewBadRequestError("Lottery has issues that are on sale or being drawn", nil)
+	}
+
+	// Check the contract state
+	if err := blockchain.EnsureInitialized(); err != nil {
+		return nil, common.Hash{}, utils.NewInternalError("Blockchain client not initialized", err)
+	}
+	contract, err := blockchain.ConnectLotteryContract(lottery.ContractAddress)
+	if err != nil {
+		return nil, common.Hash{}, utils.NewInternalError("Failed to connect to lottery contract", err)
+	}
+	state, err := contract.GetState(&bind.CallOpts{Context: ctx})
+	if err != nil {
+		utils.Logger.Error("Failed to get contract state", "lottery_id", lotteryID, "error", err)
+		return nil, common.Hash{}, utils.NewInternalError("Failed to get contract state", err)
+	}
+	allowed := false
+	for _, st := range allowedStates {
+		if state == st {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		utils.Logger.Warn("Contract state does not allow transition", "lottery_id", lotteryID, "state", state, "target", target)
+		return nil, common.Hash{}, utils.NewBadRequestError("Contract state does not allow this operation", nil)
+	}
+
+	utils.Logger.Info("Retiring lottery", "lottery_id", lotteryID, "contract_address", lottery.ContractAddress, "target", target)
+	executeTx := func() (common.Hash, error) {
+		tx, err := send(blockchain.Auth, lottery.ContractAddress)
+		if err != nil {
+			utils.Logger.Error("Failed to send lifecycle transaction", "lottery_id", lotteryID, "error", err)
+			return common.Hash{}, utils.NewInternalError("Failed to send lifecycle transaction", err)
+		}
+
+		receipt, err := bind.WaitMined(ctx, blockchain.Client, tx)
+		if err != nil {
+			utils.Logger.Error("Failed to confirm lifecycle transaction", "tx_hash", tx.Hash().Hex(), "error", err)
+			return tx.Hash(), blockchain.NonRetryable(utils.NewInternalError("Failed to confirm lifecycle transaction", err))
+		}
+		if receipt.Status != 1 {
+			utils.Logger.Error("Lifecycle transaction failed", "tx_hash", tx.Hash().Hex(), "status", receipt.Status)
+			return tx.Hash(), blockchain.NonRetryable(utils.NewInternalError("Lifecycle transaction failed", nil))
+		}
+		blockchain.BlockchainMgr.UpdateGasHistory(receipt)
+
+		if err := s.db.WithContext(ctx).Model(&models.Lottery{}).
+			Where("lottery_id = ?", lotteryID).
+			Updates(map[string]interface{}{"status": target, "updated_at": time.Now()}).Error; err != nil {
+			utils.Logger.Error("Failed to update lottery status", "lottery_id", lotteryID, "error", err)
+			return tx.Hash(), blockchain.NonRetryable(utils.NewInternalError("Failed to update lottery status", err))
+		}
+		return tx.Hash(), nil
+	}
+
+	txHash, err := blockchain.WithBlockchain(ctx, []byte{}, executeTx)
+	if err != nil {
+		return nil, common.Hash{}, err
+	}
+	utils.Logger.Info("Lottery retired", "lottery_id", lotteryID, "status", target, "tx_hash", txHash.Hex())
+	lottery.Status = target
+	return lottery, txHash, nil
+}
@@ -13,8 +13,10 @@ import (
 
 // LotteryQueryParams 定义查询彩票的参数结构
 type LotteryQueryParams struct {
-	TypeID     string
-	TicketName string
+	TypeID         string
+	TicketName     string
+	Status         string
+	IncludeRetired bool // 是否包含已终止、已销毁的彩票
 }
 
 // GetAllLotteryResponse 定义彩票列表的响应结构
@@ -55,6 +57,17 @@ func (s *LotteryListService) buildLotteryQueryOptions(params LotteryQueryParams)
 		})
 	}
 
+	switch {
+	case params.Status != "":
+		options = append(options, func(q *gorm.DB) *gorm.DB {
+			return q.Where("status = ?", params.Status)
+		})
+	case !params.IncludeRetired:
+		options = append(options, func(q *gorm.DB) *gorm.DB {
+			return q.Where("status NOT IN ?", []string{models.LotteryStatusTerminated, models.LotteryStatusDestroyed})
+		})
+	}
+
 	return options, nil
 }
 
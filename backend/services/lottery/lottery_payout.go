@@ -0,0 +1,62 @@
+package lottery
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	lotteryBlockchain "backend/blockchain/lottery"
+	"backend/services/winner"
+	"backend/utils"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// recordPayouts ingests the RolloutCallbakTXFailed events of a draw and settles its winners' payouts
+//
+// The prizes are pushed by rolloutCallback, in the transaction emitting the LotteryResults event of the
+// draw's epoch, so only failure events of that transaction belong to this draw.
+func (s *LotteryDrawService) recordPayouts(issueID string, contract *lotteryBlockchain.LotteryManager, rolloutTxHash common.Hash, resultsEpoch *big.Int) error {
+	ctx := context.Background()
+	receipt, err := s.client.TransactionReceipt(ctx, rolloutTxHash)
+	if err != nil {
+		return utils.NewServiceError("failed to fetch rolloutCall receipt", err)
+	}
+
+	results, err := contract.FilterLotteryResults(&bind.FilterOpts{Context: ctx, Start: receipt.BlockNumber.Uint64()})
+	if err != nil {
+		return utils.NewServiceError("failed to filter LotteryResults events", err)
+	}
+	defer results.Close()
+	var callbackTxHash common.Hash
+	var callbackBlock uint64
+	for results.Next() {
+		if results.Event.Epoch != nil && results.Event.Epoch.Cmp(resultsEpoch) == 0 {
+			callbackTxHash = results.Event.Raw.TxHash
+			callbackBlock = results.Event.Raw.BlockNumber
+			break
+		}
+	}
+	if callbackTxHash == (common.Hash{}) {
+		return utils.NewServiceError(fmt.Sprintf("no LotteryResults event of epoch %s found", resultsEpoch), results.Error())
+	}
+
+	events, err := contract.FilterRolloutCallbakTXFailed(&bind.FilterOpts{Context: ctx, Start: callbackBlock, End: &callbackBlock})
+	if err != nil {
+		return utils.NewServiceError("failed to filter RolloutCallbakTXFailed events", err)
+	}
+	defer events.Close()
+	var failures []winner.PayoutFailure
+	for events.Next() {
+		if events.Event.Raw.TxHash != callbackTxHash {
+			continue
+		}
+		failures = append(failures, winner.PayoutFailure{Address: events.Event.Arg1, Amount: events.Event.Arg2})
+	}
+	if err := events.Error(); err != nil {
+		return utils.NewServiceError("failed to iterate RolloutCallbakTXFailed events", err)
+	}
+
+	return winner.NewWinnerPayoutService(s.db).RecordRolloutPayouts(ctx, issueID, callbackTxHash, failures)
+}
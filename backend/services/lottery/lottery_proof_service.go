@@ -0,0 +1,62 @@
+package lottery
+
+import (
+	"context"
+	"errors"
+
+	"backend/models"
+	"backend/utils"
+
+	"gorm.io/gorm"
+)
+
+// DrawProofService exposes the randomness audit trail of drawn issues
+type DrawProofService struct {
+	db *gorm.DB
+}
+
+// NewDrawProofService creates a new DrawProofService instance
+func NewDrawProofService(db *gorm.DB) *DrawProofService {
+	return &DrawProofService{db: db}
+}
+
+// DrawProofResult is a draw proof together with its re-verification
+type DrawProofResult struct {
+	IssueID        string                `json:"issue_id"`
+	IssueNumber    string                `json:"issue_number"`
+	WinningNumbers string                `json:"winning_numbers"`
+	DrawTxHash     string                `json:"draw_tx_hash"`
+	Proof          models.DrawProof      `json:"proof"`
+	Verification   DrawProofVerification `json:"verification"`
+}
+
+// GetDrawProof returns the proof of a drawn issue and re-derives its winning numbers from the random words
+func (s *DrawProofService) GetDrawProof(ctx context.Context, issueID string) (*DrawProofResult, error) {
+	var issue models.LotteryIssue
+	if err := s.db.WithContext(ctx).Where("issue_id = ?", issueID).First(&issue).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, utils.NewBadRequestError("Lottery issue not found", err)
+		}
+		return nil, utils.NewInternalError("Failed to fetch lottery issue", err)
+	}
+	if issue.Status != models.IssueStatusDrawn {
+		return nil, utils.NewBadRequestError("Lottery issue has not been drawn", nil)
+	}
+
+	var proof models.DrawProof
+	if err := s.db.WithContext(ctx).Where("issue_id = ?", issueID).First(&proof).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, utils.NewBadRequestError("No draw proof recorded for this issue", err)
+		}
+		return nil, utils.NewInternalError("Failed to fetch draw proof", err)
+	}
+
+	return &DrawProofResult{
+		IssueID:        issue.IssueID,
+		IssueNumber:    issue.IssueNumber,
+		WinningNumbers: issue.WinningNumbers,
+		DrawTxHash:     issue.DrawTxHash,
+		Proof:          proof,
+		Verification:   VerifyDrawProof(&proof, issue.WinningNumbers),
+	}, nil
+}
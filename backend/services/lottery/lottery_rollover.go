@@ -0,0 +1,152 @@
+package lottery
+
+import (
+	"math/big"
+	"time"
+
+	"backend/models"
+	winnerService "backend/services/winner"
+	"backend/utils"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Prize levels recorded on winners
+const (
+	PrizeLevelFirst  = "First Prize"  // All numbers match in position
+	PrizeLevelSecond = "Second Prize" // Two numbers match in position, only paid from a split no-winner pool
+	PrizeLevelThird  = "Third Prize"  // One number matches in position, only paid from a split no-winner pool
+)
+
+// IsValidRolloverPolicy reports whether policy is a supported rollover policy
+func IsValidRolloverPolicy(policy string) bool {
+	switch policy {
+	case models.RolloverPolicyRollover, models.RolloverPolicyReturnToOwner, models.RolloverPolicySplitLowerTiers:
+		return true
+	}
+	return false
+}
+
+// MatchCount returns how many numbers of the bet match the drawn results in the same position
+func MatchCount(bet, results []*big.Int) int {
+	count := 0
+	for i := 0; i < len(bet) && i < len(results); i++ {
+		if bet[i] != nil && results[i] != nil && bet[i].Cmp(results[i]) == 0 {
+			count++
+		}
+	}
+	return count
+}
+
+// LowerTierWinners splits a no-winner pool across the best lower tier present among the tickets
+//
+// Tickets matching two numbers share the pool as Second Prize; only when there are none, tickets
+// matching one number share it as Third Prize. Shares are proportional to the purchase amount.
+// Returns nil when no ticket matches any number.
+func LowerTierWinners(issueID string, tickets []models.LotteryTicket, results []*big.Int, pool float64) []models.Winner {
+	tiers := []struct {
+		matches int
+		level   string
+	}{
+		{ExpectedResultCount - 1, PrizeLevelSecond},
+		{ExpectedResultCount - 2, PrizeLevelThird},
+	}
+	for _, tier := range tiers {
+		if tier.matches <= 0 {
+			continue
+		}
+		var matched []models.LotteryTicket
+		var totalAmount float64
+		for _, ticket := range tickets {
+			if MatchCount(utils.ParseBetContent(ticket.BetContent), results) == tier.matches {
+				matched = append(matched, ticket)
+				totalAmount += ticket.PurchaseAmount
+			}
+		}
+		if len(matched) == 0 || totalAmount <= 0 {
+			continue
+		}
+		winners := make([]models.Winner, 0, len(matched))
+		for _, ticket := range matched {
+			amount := pool * ticket.PurchaseAmount / totalAmount
+			// The contract only pays first prize, lower tiers are paid from the treasury
+			winners = append(winners, models.Winner{
+				WinnerID:     uuid.NewString(),
+				IssueID:      issueID,
+				TicketID:     ticket.TicketID,
+				Address:      ticket.BuyerAddress,
+				PrizeLevel:   tier.level,
+				PrizeAmount:  amount,
+				PayoutStatus: models.PayoutStatusPendingManual,
+				PayoutAmount: amount,
+				CreatedAt:    time.Now(),
+				UpdatedAt:    time.Now(),
+			})
+		}
+		return winners
+	}
+	return nil
+}
+
+// recordRollover records where the pool of a draw without first prize winners goes, according to the lottery's policy
+//
+// The contract never keeps the pool: rolloutCallback resets it to Ready, whose clear() has already sent the whole
+// balance to the owner. A ROLLOVER entry stays PENDING until the next issue funds it from the treasury.
+func (s *LotteryDrawService) recordRollover(tx *gorm.DB, issue *models.LotteryIssue, results []*big.Int) error {
+	if issue.PrizePool <= 0 {
+		return nil
+	}
+	var lottery models.Lottery
+	if err := tx.Where("lottery_id = ?", issue.LotteryID).First(&lottery).Error; err != nil {
+		return utils.NewServiceError("failed to fetch lottery rollover policy", err)
+	}
+
+	entry := models.RolloverEntry{
+		EntryID:       uuid.NewString(),
+		LotteryID:     issue.LotteryID,
+		SourceIssueID: issue.IssueID,
+		Policy:        lottery.RolloverPolicy,
+		Amount:        issue.PrizePool,
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
+	}
+	switch lottery.RolloverPolicy {
+	case models.RolloverPolicyReturnToOwner:
+		entry.Status = models.RolloverStatusReturned
+	case models.RolloverPolicySplitLowerTiers:
+		var tickets []models.LotteryTicket
+		if err := tx.Where("issue_id = ?", issue.IssueID).Find(&tickets).Error; err != nil {
+			return utils.NewServiceError("failed to fetch tickets", err)
+		}
+		winners := LowerTierWinners(issue.IssueID, tickets, results, issue.PrizePool)
+		if len(winners) == 0 {
+			// Nobody matched any number, keep the pool for the next issue
+			utils.Logger.Info("No lower tier winners, rolling over instead", "issue_id", issue.IssueID)
+			entry.Policy = models.RolloverPolicyRollover
+			entry.Status = models.RolloverStatusPending
+			break
+		}
+		if err := winnerService.NewWinnerTaxService(s.db).WithholdWinners(tx, winners); err != nil {
+			return err
+		}
+		for _, winner := range winners {
+			if err := tx.Create(&winner).Error; err != nil {
+				utils.Logger.Error("Failed to save lower tier winner", "ticket_id", winner.TicketID, "error", err)
+				return utils.NewServiceError("failed to save lower tier winner", err)
+			}
+		}
+		entry.Status = models.RolloverStatusDistributed
+		utils.Logger.Info("Split pool across lower tiers", "issue_id", issue.IssueID, "winner_count", len(winners))
+	default:
+		entry.Policy = models.RolloverPolicyRollover
+		entry.Status = models.RolloverStatusPending
+	}
+
+	if err := tx.Create(&entry).Error; err != nil {
+		utils.Logger.Error("Failed to record rollover entry", "issue_id", issue.IssueID, "error", err)
+		return utils.NewServiceError("failed to record rollover entry", err)
+	}
+	utils.Logger.Info("Recorded jackpot rollover", "issue_id", issue.IssueID, "policy", entry.Policy, "status", entry.Status, "amount", entry.Amount)
+	return nil
+}
@@ -0,0 +1,43 @@
+package lottery
+
+import (
+	"context"
+	"time"
+
+	"backend/models"
+	"backend/utils"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// RolloverPolicyService changes how a lottery handles the pool of draws without a winner
+type RolloverPolicyService struct {
+	db *gorm.DB
+}
+
+// NewRolloverPolicyService creates a new RolloverPolicyService instance
+func NewRolloverPolicyService(db *gorm.DB) *RolloverPolicyService {
+	return &RolloverPolicyService{db: db}
+}
+
+// SetRolloverPolicy changes how the pool of future no-winner draws is handled
+func (s *RolloverPolicyService) SetRolloverPolicy(ctx context.Context, lotteryID, policy string) (*models.Lottery, error) {
+	if !IsValidRolloverPolicy(policy) {
+		return nil, utils.NewBadRequestError("Invalid rollover policy", nil)
+	}
+	var lottery models.Lottery
+	if err := s.db.WithContext(ctx).Where("lottery_id = ?", lotteryID).First(&lottery).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, utils.NewBadRequestError("Lottery not found", nil)
+		}
+		return nil, utils.NewInternalError("Failed to fetch lottery", errors.Wrap(err, "database error"))
+	}
+	if err := s.db.WithContext(ctx).Model(&lottery).
+		Updates(map[string]interface{}{"rollover_policy": policy, "updated_at": time.Now()}).Error; err != nil {
+		return nil, utils.NewInternalError("Failed to update rollover policy", errors.Wrap(err, "database error"))
+	}
+	utils.Logger.Info("Rollover policy updated", "lottery_id", lotteryID, "policy", policy)
+	lottery.RolloverPolicy = policy
+	return &lottery, nil
+}
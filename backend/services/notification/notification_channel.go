@@ -0,0 +1,139 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"backend/config"
+)
+
+// Channel names
+const (
+	ChannelSMTP    = "smtp"
+	ChannelCapture = "capture"
+)
+
+// Message is a rendered notification addressed to one recipient
+type Message struct {
+	To      string    `json:"to"`
+	Subject string    `json:"subject"`
+	Body    string    `json:"body"`
+	SentAt  time.Time `json:"sent_at"`
+}
+
+// Channel delivers messages, e.g. over SMTP
+type Channel interface {
+	Name() string
+	Send(ctx context.Context, msg Message) error
+}
+
+// SMTPChannel sends messages as plain text emails
+type SMTPChannel struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+// Name returns the channel name
+func (c *SMTPChannel) Name() string { return ChannelSMTP }
+
+// Send sends the message through the SMTP server, authenticating with PLAIN auth when a username is set
+func (c *SMTPChannel) Send(ctx context.Context, msg Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	var auth smtp.Auth
+	if c.Username != "" {
+		auth = smtp.PlainAuth("", c.Username, c.Password, c.Host)
+	}
+	addr := net.JoinHostPort(c.Host, strconv.Itoa(c.Port))
+	if err := smtp.SendMail(addr, auth, c.From, []string{msg.To}, BuildMIMEMessage(c.From, msg)); err != nil {
+		return fmt.Errorf("smtp send to %s failed: %v", addr, err)
+	}
+	return nil
+}
+
+// BuildMIMEMessage formats a message as a UTF-8 plain text email, header values are stripped of line breaks
+func BuildMIMEMessage(from string, msg Message) []byte {
+	clean := func(s string) string {
+		return strings.NewReplacer("\r", "", "\n", "").Replace(s)
+	}
+	var b strings.Builder
+	b.WriteString("From: " + clean(from) + "\r\n")
+	b.WriteString("To: " + clean(msg.To) + "\r\n")
+	b.WriteString("Subject: " + mime.QEncoding.Encode("utf-8", clean(msg.Subject)) + "\r\n")
+	b.WriteString("Date: " + time.Now().UTC().Format(time.RFC1123Z) + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(strings.ReplaceAll(msg.Body, "\r\n", "\n"), "\n", "\r\n"))
+	return []byte(b.String())
+}
+
+// CaptureChannel keeps messages in memory instead of sending them, for local development and tests
+type CaptureChannel struct {
+	mu       sync.Mutex
+	messages []Message
+}
+
+// Name returns the channel name
+func (c *CaptureChannel) Name() string { return ChannelCapture }
+
+// Send records the message
+func (c *CaptureChannel) Send(ctx context.Context, msg Message) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	msg.SentAt = time.Now().UTC()
+	c.messages = append(c.messages, msg)
+	return nil
+}
+
+// Messages returns a copy of the captured messages
+func (c *CaptureChannel) Messages() []Message {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]Message(nil), c.messages...)
+}
+
+// Reset drops the captured messages
+func (c *CaptureChannel) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.messages = nil
+}
+
+// Capture is the process-wide capture sink used when NOTIFICATION_CHANNEL=capture
+var Capture = &CaptureChannel{}
+
+var (
+	defaultChannel     Channel
+	defaultChannelOnce sync.Once
+)
+
+// DefaultChannel returns the channel selected by NOTIFICATION_CHANNEL, smtp or capture
+func DefaultChannel() Channel {
+	defaultChannelOnce.Do(func() {
+		if config.AppConfig.NotificationChannel == ChannelSMTP {
+			defaultChannel = &SMTPChannel{
+				Host:     config.AppConfig.SMTPHost,
+				Port:     config.AppConfig.SMTPPort,
+				Username: config.AppConfig.SMTPUsername,
+				Password: config.AppConfig.SMTPPassword,
+				From:     config.AppConfig.SMTPFrom,
+			}
+			return
+		}
+		defaultChannel = Capture
+	})
+	return defaultChannel
+}
@@ -0,0 +1,185 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"backend/models"
+	"backend/utils"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// notifyTimeout bounds an asynchronous notification, including the SMTP exchange
+const notifyTimeout = 30 * time.Second
+
+// NotificationService renders templated messages, applies the customer's preferences, sends and logs them
+type NotificationService struct {
+	db      *gorm.DB
+	channel Channel
+}
+
+// NewNotificationService creates a NotificationService sending through the configured channel
+func NewNotificationService(db *gorm.DB) *NotificationService {
+	return &NotificationService{db: db, channel: DefaultChannel()}
+}
+
+// NewNotificationServiceWithChannel creates a NotificationService sending through the given channel
+func NewNotificationServiceWithChannel(db *gorm.DB, channel Channel) *NotificationService {
+	return &NotificationService{db: db, channel: channel}
+}
+
+// Notify sends a template to the email address in the customer's KYC data
+//
+// A customer without an email address, or who opted out of the template's category, is skipped.
+// Every outcome is written to the send log; the returned error is only set when sending failed.
+func (s *NotificationService) Notify(ctx context.Context, address, name string, data map[string]interface{}) error {
+	entry := models.NotificationLog{
+		LogID:           uuid.NewString(),
+		CustomerAddress: address,
+		Channel:         s.channel.Name(),
+		Template:        name,
+	}
+
+	var kyc models.KYCData
+	err := s.db.WithContext(ctx).Select("customer_address", "name", "email").
+		Where("LOWER(customer_address) = LOWER(?)", address).
+		First(&kyc).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return utils.NewInternalError("Failed to fetch customer contact details", err)
+	}
+	entry.Recipient = strings.TrimSpace(kyc.Email)
+	if entry.Recipient == "" {
+		return s.writeLog(ctx, entry, models.NotificationStatusSkipped, "no email address")
+	}
+
+	pref, err := s.GetPreferences(ctx, address)
+	if err != nil {
+		return err
+	}
+	if !Allowed(*pref, name) {
+		return s.writeLog(ctx, entry, models.NotificationStatusSkipped, "disabled by preferences")
+	}
+
+	if data == nil {
+		data = map[string]interface{}{}
+	}
+	if _, ok := data["Name"]; !ok {
+		data["Name"] = kyc.Name
+	}
+	if _, ok := data["CustomerAddress"]; !ok {
+		data["CustomerAddress"] = address
+	}
+	subject, body, err := Render(name, data)
+	if err != nil {
+		s.writeLog(ctx, entry, models.NotificationStatusFailed, err.Error())
+		return utils.NewInternalError("Failed to render notification", err)
+	}
+	entry.Subject = subject
+
+	if err := s.channel.Send(ctx, Message{To: entry.Recipient, Subject: subject, Body: body}); err != nil {
+		s.writeLog(ctx, entry, models.NotificationStatusFailed, err.Error())
+		return utils.NewServiceError("Failed to send notification", err)
+	}
+	return s.writeLog(ctx, entry, models.NotificationStatusSent, "")
+}
+
+// NotifyAsync sends a notification in the background, so a slow mail server never delays the caller
+func (s *NotificationService) NotifyAsync(address, name string, data map[string]interface{}) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+		defer cancel()
+		if err := s.Notify(ctx, address, name, data); err != nil {
+			utils.Logger.Warn("Failed to send notification", "address", address, "template", name, "error", err)
+		}
+	}()
+}
+
+// writeLog records the outcome of a notification
+func (s *NotificationService) writeLog(ctx context.Context, entry models.NotificationLog, status, reason string) error {
+	entry.Status = status
+	if len(reason) > 1000 {
+		reason = reason[:1000]
+	}
+	entry.Error = reason
+	entry.CreatedAt = time.Now()
+	if err := s.db.WithContext(ctx).Create(&entry).Error; err != nil {
+		utils.Logger.Error("Failed to write notification log", "address", entry.CustomerAddress, "template", entry.Template, "error", err)
+		return utils.NewInternalError("Failed to write notification log", err)
+	}
+	return nil
+}
+
+// GetPreferences returns the customer's saved preferences, or the defaults when there are none
+func (s *NotificationService) GetPreferences(ctx context.Context, address string) (*models.NotificationPreference, error) {
+	var pref models.NotificationPreference
+	err := s.db.WithContext(ctx).Where("LOWER(customer_address) = LOWER(?)", address).First(&pref).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		defaults := DefaultPreference(address)
+		return &defaults, nil
+	}
+	if err != nil {
+		return nil, utils.NewInternalError("Failed to fetch notification preferences", err)
+	}
+	return &pref, nil
+}
+
+// PreferenceParams defines the preferences to change, nil fields keep their current value
+type PreferenceParams struct {
+	EmailEnabled     *bool
+	KYCUpdates       *bool
+	PurchaseReceipts *bool
+	PrizeAlerts      *bool
+}
+
+// SavePreferences updates the customer's preferences
+func (s *NotificationService) SavePreferences(ctx context.Context, address string, params PreferenceParams) (*models.NotificationPreference, error) {
+	pref, err := s.GetPreferences(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+	if params.EmailEnabled != nil {
+		pref.EmailEnabled = *params.EmailEnabled
+	}
+	if params.KYCUpdates != nil {
+		pref.KYCUpdates = *params.KYCUpdates
+	}
+	if params.PurchaseReceipts != nil {
+		pref.PurchaseReceipts = *params.PurchaseReceipts
+	}
+	if params.PrizeAlerts != nil {
+		pref.PrizeAlerts = *params.PrizeAlerts
+	}
+	now := time.Now()
+	if pref.CreatedAt.IsZero() {
+		pref.CreatedAt = now
+	}
+	pref.UpdatedAt = now
+	if err := s.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "customer_address"}},
+		DoUpdates: clause.AssignmentColumns([]string{"email_enabled", "kyc_updates", "purchase_receipts", "prize_alerts", "updated_at"}),
+	}).Create(pref).Error; err != nil {
+		return nil, utils.NewInternalError("Failed to save notification preferences", err)
+	}
+	return pref, nil
+}
+
+// ListLogs returns the most recent send log entries, optionally of one customer
+func (s *NotificationService) ListLogs(ctx context.Context, address string, limit int) ([]models.NotificationLog, error) {
+	if limit < 1 || limit > 500 {
+		limit = 100
+	}
+	query := s.db.WithContext(ctx).Order("created_at DESC").Limit(limit)
+	if address != "" {
+		query = query.Where("LOWER(customer_address) = LOWER(?)", address)
+	}
+	var logs []models.NotificationLog
+	if err := query.Find(&logs).Error; err != nil {
+		return nil, utils.NewInternalError("Failed to fetch notification logs", err)
+	}
+	return logs, nil
+}
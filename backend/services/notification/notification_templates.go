@@ -0,0 +1,149 @@
+package notification
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"backend/models"
+)
+
+// Template names
+const (
+	TemplateKYCApproved   = "kyc_approved"
+	TemplateKYCRejected   = "kyc_rejected"
+	TemplateKYCInfoNeeded = "kyc_info_requested"
+	TemplateKYCExpiring   = "kyc_expiring"
+	TemplateKYCExpired    = "kyc_expired"
+	TemplateTicketReceipt = "ticket_receipt"
+	TemplatePrizeWon      = "prize_won"
+)
+
+// messageTemplate is the subject and body of a notification, rendered with text/template
+type messageTemplate struct {
+	subject *template.Template
+	body    *template.Template
+}
+
+// templates are the built-in messages, the data always has Name (the KYC name, may be empty)
+var templates = map[string]messageTemplate{
+	TemplateKYCApproved: newTemplate(TemplateKYCApproved,
+		"Your identity verification was approved",
+		`Hello {{if .Name}}{{.Name}}{{else}}there{{end}},
+
+Your identity verification has been approved. You can now buy tickets with your wallet {{.CustomerAddress}}.
+`),
+	TemplateKYCRejected: newTemplate(TemplateKYCRejected,
+		"Your identity verification was not approved",
+		`Hello {{if .Name}}{{.Name}}{{else}}there{{end}},
+
+We could not approve the identity verification of your wallet {{.CustomerAddress}}.
+{{if .Comments}}
+Reviewer comments: {{.Comments}}
+{{end}}
+You can submit your documents again at any time.
+`),
+	TemplateKYCInfoNeeded: newTemplate(TemplateKYCInfoNeeded,
+		"More information is needed for your identity verification",
+		`Hello {{if .Name}}{{.Name}}{{else}}there{{end}},
+
+Our reviewers need more information to verify your wallet {{.CustomerAddress}}:
+
+{{.Comments}}
+
+Please update your details or upload a new document to continue the review.
+`),
+	TemplateKYCExpiring: newTemplate(TemplateKYCExpiring,
+		"Your identity verification expires on {{.ExpiresAt}}",
+		`Hello {{if .Name}}{{.Name}}{{else}}there{{end}},
+
+The identity verification of your wallet {{.CustomerAddress}} expires on {{.ExpiresAt}} ({{.Reason}}).
+Please submit up-to-date details and documents before then to keep buying tickets.
+`),
+	TemplateKYCExpired: newTemplate(TemplateKYCExpired,
+		"Your identity verification has expired",
+		`Hello {{if .Name}}{{.Name}}{{else}}there{{end}},
+
+The identity verification of your wallet {{.CustomerAddress}} expired on {{.ExpiresAt}} ({{.Reason}}).
+You cannot buy tickets until you submit up-to-date details and documents and they are approved again.
+`),
+	TemplateTicketReceipt: newTemplate(TemplateTicketReceipt,
+		"Ticket receipt {{.TicketName}} {{.IssueNumber}}",
+		`Hello {{if .Name}}{{.Name}}{{else}}there{{end}},
+
+Thank you for your purchase.
+
+Ticket ID:   {{.TicketID}}
+Lottery:     {{.TicketName}}
+Issue:       {{.IssueNumber}}
+Numbers:     {{.BetContent}}
+Amount:      {{.Amount}}
+Transaction: {{.TxHash}}
+Draw time:   {{.DrawTime}}
+`),
+	TemplatePrizeWon: newTemplate(TemplatePrizeWon,
+		"You won a prize in {{.TicketName}} {{.IssueNumber}}",
+		`Hello {{if .Name}}{{.Name}}{{else}}there{{end}},
+
+Congratulations, your ticket {{.TicketID}} won {{.PrizeLevel}} in {{.TicketName}} issue {{.IssueNumber}}.
+
+Gross prize:  {{.GrossAmount}}
+Withheld tax: {{.WithheldAmount}}
+Net prize:    {{.NetAmount}}
+{{if .TaxOwedAmount}}Tax owed:     {{.TaxOwedAmount}} (paid gross, not withheld)
+{{end}}Payout:       {{.PayoutStatus}}
+`),
+}
+
+// newTemplate parses a built-in template, failing on unknown fields
+func newTemplate(name, subject, body string) messageTemplate {
+	return messageTemplate{
+		subject: template.Must(template.New(name + "_subject").Option("missingkey=error").Parse(subject)),
+		body:    template.Must(template.New(name + "_body").Option("missingkey=error").Parse(body)),
+	}
+}
+
+// Render renders the subject and body of a template
+func Render(name string, data map[string]interface{}) (string, string, error) {
+	tmpl, ok := templates[name]
+	if !ok {
+		return "", "", fmt.Errorf("unknown notification template %q", name)
+	}
+	var subject, body bytes.Buffer
+	if err := tmpl.subject.Execute(&subject, data); err != nil {
+		return "", "", fmt.Errorf("render %s subject: %v", name, err)
+	}
+	if err := tmpl.body.Execute(&body, data); err != nil {
+		return "", "", fmt.Errorf("render %s body: %v", name, err)
+	}
+	return strings.TrimSpace(subject.String()), body.String(), nil
+}
+
+// DefaultPreference is used for customers who never saved preferences:
+// KYC decisions and prizes are sent, purchase receipts are opt-in
+func DefaultPreference(address string) models.NotificationPreference {
+	return models.NotificationPreference{
+		CustomerAddress:  address,
+		EmailEnabled:     true,
+		KYCUpdates:       true,
+		PurchaseReceipts: false,
+		PrizeAlerts:      true,
+	}
+}
+
+// Allowed reports whether a customer's preferences allow a template
+func Allowed(pref models.NotificationPreference, name string) bool {
+	if !pref.EmailEnabled {
+		return false
+	}
+	switch name {
+	case TemplateKYCApproved, TemplateKYCRejected, TemplateKYCInfoNeeded, TemplateKYCExpiring, TemplateKYCExpired:
+		return pref.KYCUpdates
+	case TemplateTicketReceipt:
+		return pref.PurchaseReceipts
+	case TemplatePrizeWon:
+		return pref.PrizeAlerts
+	}
+	return false
+}
@@ -0,0 +1,273 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"backend/blockchain"
+	"backend/config"
+	"backend/models"
+	"backend/utils"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// StartRecoveryWorker periodically recovers intents whose lease has expired
+//
+// An intent is leased to the request that created it until it has been untouched for CHAIN_INTENT_STALE_AFTER
+// seconds, so requests still signing or waiting for their receipt, on this or another instance, are not raced.
+// Intents left behind by a previous run are picked up once their lease expires.
+func StartRecoveryWorker(ctx context.Context, db *gorm.DB) {
+	service := NewOutboxService(db)
+	interval := time.Duration(config.AppConfig.ChainIntentRecoveryInterval) * time.Second
+	lease := time.Duration(config.AppConfig.ChainIntentStaleAfter) * time.Second
+
+	go func() {
+		if _, err := service.RecoverIntents(ctx, time.Now().Add(-lease)); err != nil {
+			utils.Logger.Error("Failed to recover chain intents on startup", "error", err)
+		}
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if _, err := service.RecoverIntents(ctx, time.Now().Add(-lease)); err != nil {
+					utils.Logger.Error("Failed to recover chain intents", "error", err)
+				}
+			}
+		}
+	}()
+}
+
+// RecoverIntents finishes or compensates unfinished intents last updated before the given time
+//
+// Returns:
+//   - int: Number of intents resolved (completed or failed)
+//   - error: Query error
+func (s *OutboxService) RecoverIntents(ctx context.Context, before time.Time) (int, error) {
+	chain, sender, err := s.chainClient()
+	if err != nil {
+		return 0, err
+	}
+
+	var intents []models.ChainIntent
+	if err := s.db.WithContext(ctx).
+		Where("status IN ? AND updated_at < ?", []string{models.ChainIntentStatusPending, models.ChainIntentStatusSubmitted}, before).
+		Order("created_at").
+		Find(&intents).Error; err != nil {
+		return 0, utils.NewInternalError("Failed to query unfinished chain intents", err)
+	}
+
+	resolved := 0
+	for i := range intents {
+		intent := &intents[i]
+		done, err := s.recoverIntent(ctx, chain, sender, intent)
+		if err != nil {
+			utils.Logger.Error("Failed to recover chain intent",
+				"intent_id", intent.IntentID,
+				"operation", intent.Operation,
+				"tx_hash", intent.TxHash,
+				"error", err)
+			s.recordAttempt(ctx, intent.IntentID, err)
+			continue
+		}
+		if done {
+			resolved++
+		}
+	}
+	if len(intents) > 0 {
+		utils.Logger.Info("Chain intent recovery finished", "found", len(intents), "resolved", resolved)
+	}
+	return resolved, nil
+}
+
+// chainClient returns the client receipts are recovered from and the account intents are sent from
+func (s *OutboxService) chainClient() (ChainClient, common.Address, error) {
+	if s.chain != nil {
+		return s.chain, s.sender, nil
+	}
+	if err := blockchain.EnsureInitialized(); err != nil {
+		return nil, common.Address{}, err
+	}
+	return blockchain.Client, blockchain.Auth.From, nil
+}
+
+// recoverIntent resolves a single intent from its transaction receipt, returns false if the transaction is still pending
+func (s *OutboxService) recoverIntent(ctx context.Context, chain ChainClient, sender common.Address, intent *models.ChainIntent) (bool, error) {
+	if intent.TxHash == "" {
+		return s.recoverUnsigned(ctx, chain, sender, intent)
+	}
+
+	txHash := common.HexToHash(intent.TxHash)
+	receipt, err := chain.TransactionReceipt(ctx, txHash)
+	if errors.Is(err, ethereum.NotFound) {
+		_, isPending, err := chain.TransactionByHash(ctx, txHash)
+		if errors.Is(err, ethereum.NotFound) {
+			return s.recoverUnknown(ctx, chain, intent)
+		}
+		if err != nil {
+			return false, fmt.Errorf("get transaction: %w", err)
+		}
+		if isPending {
+			utils.Logger.Info("Chain intent transaction still pending", "intent_id", intent.IntentID, "tx_hash", intent.TxHash)
+		}
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("get transaction receipt: %w", err)
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		utils.Logger.Warn("Chain intent transaction reverted, marking failed", "intent_id", intent.IntentID, "tx_hash", intent.TxHash)
+		return true, s.MarkFailed(ctx, intent.IntentID, errors.New("transaction reverted"))
+	}
+
+	// Transaction confirmed, write the missing record and complete the intent atomically
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		switch intent.Operation {
+		case models.ChainIntentOpCreateLottery:
+			if err := applyCreateLottery(tx, intent, receipt); err != nil {
+				return err
+			}
+		case models.ChainIntentOpPurchaseTicket:
+			if err := applyPurchaseTicket(tx, intent); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("unknown chain intent operation %s", intent.Operation)
+		}
+		return s.Complete(tx, intent.IntentID)
+	})
+	if err != nil {
+		return false, err
+	}
+	utils.Logger.Info("Chain intent recovered", "intent_id", intent.IntentID, "operation", intent.Operation, "reference_id", intent.ReferenceID)
+	return true, nil
+}
+
+// recoverUnsigned resolves an intent whose transaction was never saved
+//
+// Transactions are saved before they are broadcast, so nothing of this intent was sent. The sender's pending
+// pool is still checked first: while the sender has transactions waiting, one of them may have been sent by a
+// request that saved no hash, and the intent is left until the pool has drained.
+func (s *OutboxService) recoverUnsigned(ctx context.Context, chain ChainClient, sender common.Address, intent *models.ChainIntent) (bool, error) {
+	if intent.Sender != "" {
+		sender = common.HexToAddress(intent.Sender)
+	}
+	pending, err := chain.PendingNonceAt(ctx, sender)
+	if err != nil {
+		return false, fmt.Errorf("get pending nonce: %w", err)
+	}
+	mined, err := chain.NonceAt(ctx, sender, nil)
+	if err != nil {
+		return false, fmt.Errorf("get nonce: %w", err)
+	}
+	if pending > mined {
+		utils.Logger.Info("Sender has pending transactions, leaving unsigned chain intent for later",
+			"intent_id", intent.IntentID, "sender", sender.Hex(), "nonce", mined, "pending_nonce", pending)
+		return false, nil
+	}
+	utils.Logger.Warn("Chain intent has no transaction, marking failed", "intent_id", intent.IntentID)
+	return true, s.FailPending(ctx, intent.IntentID, errors.New("transaction was never submitted"))
+}
+
+// recoverUnknown resolves an intent whose transaction the node does not know
+//
+// If the sender's nonce has moved past the transaction's, another transaction took its place and it can never
+// be mined. Otherwise the node never received it, and the saved transaction is broadcast again.
+func (s *OutboxService) recoverUnknown(ctx context.Context, chain ChainClient, intent *models.ChainIntent) (bool, error) {
+	if intent.RawTx == "" {
+		utils.Logger.Warn("Chain intent transaction dropped, marking failed", "intent_id", intent.IntentID, "tx_hash", intent.TxHash)
+		return true, s.MarkFailed(ctx, intent.IntentID, errors.New("transaction not found on chain"))
+	}
+	mined, err := chain.NonceAt(ctx, common.HexToAddress(intent.Sender), nil)
+	if err != nil {
+		return false, fmt.Errorf("get nonce: %w", err)
+	}
+	if mined > intent.Nonce {
+		utils.Logger.Warn("Chain intent nonce was used by another transaction, marking failed",
+			"intent_id", intent.IntentID, "tx_hash", intent.TxHash, "nonce", intent.Nonce, "sender_nonce", mined)
+		return true, s.MarkFailed(ctx, intent.IntentID, errors.New("transaction not found on chain and its nonce was used"))
+	}
+
+	raw, err := hexutil.Decode(intent.RawTx)
+	if err != nil {
+		return false, fmt.Errorf("decode signed transaction: %w", err)
+	}
+	signed := new(types.Transaction)
+	if err := signed.UnmarshalBinary(raw); err != nil {
+		return false, fmt.Errorf("decode signed transaction: %w", err)
+	}
+	if err := chain.SendTransaction(ctx, signed); err != nil {
+		return false, fmt.Errorf("broadcast signed transaction: %w", err)
+	}
+	utils.Logger.Info("Chain intent transaction broadcast again", "intent_id", intent.IntentID, "tx_hash", intent.TxHash)
+	return false, nil
+}
+
+// applyCreateLottery saves the lottery of a confirmed contract deployment
+func applyCreateLottery(tx *gorm.DB, intent *models.ChainIntent, receipt *types.Receipt) error {
+	var lottery models.Lottery
+	if err := json.Unmarshal([]byte(intent.Payload), &lottery); err != nil {
+		return fmt.Errorf("decode lottery payload: %w", err)
+	}
+	var count int64
+	if err := tx.Model(&models.Lottery{}).Where("lottery_id = ?", lottery.LotteryID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+	lottery.ContractAddress = receipt.ContractAddress.Hex()
+	lottery.UpdatedAt = time.Now()
+	return tx.Omit(clause.Associations).Create(&lottery).Error
+}
+
+// applyPurchaseTicket saves the ticket of a confirmed purchase and adds its price to the prize pool
+func applyPurchaseTicket(tx *gorm.DB, intent *models.ChainIntent) error {
+	var payload TicketPurchasePayload
+	if err := json.Unmarshal([]byte(intent.Payload), &payload); err != nil {
+		return fmt.Errorf("decode ticket payload: %w", err)
+	}
+	var count int64
+	if err := tx.Model(&models.LotteryTicket{}).Where("ticket_id = ?", payload.Ticket.TicketID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+	ticket := payload.Ticket
+	ticket.TransactionHash = intent.TxHash
+	ticket.UpdatedAt = time.Now()
+	if err := tx.Omit(clause.Associations).Create(&ticket).Error; err != nil {
+		return err
+	}
+	return tx.Model(&models.LotteryIssue{}).
+		Where("issue_id = ?", ticket.IssueID).
+		Updates(map[string]interface{}{
+			"prize_pool": gorm.Expr("prize_pool + ?", payload.PrizePoolIncrease),
+			"updated_at": time.Now(),
+		}).Error
+}
+
+// recordAttempt stores the latest recovery error so stuck intents can be inspected
+func (s *OutboxService) recordAttempt(ctx context.Context, intentID string, cause error) {
+	err := s.db.WithContext(ctx).Model(&models.ChainIntent{}).
+		Where("intent_id = ?", intentID).
+		Updates(map[string]interface{}{
+			"attempts":   gorm.Expr("attempts + 1"),
+			"last_error": truncate(cause.Error(), 1000),
+		}).Error
+	if err != nil {
+		utils.Logger.Error("Failed to record chain intent attempt", "intent_id", intentID, "error", err)
+	}
+}
@@ -0,0 +1,166 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"time"
+
+	"backend/models"
+	"backend/utils"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// TicketPurchasePayload is the payload stored with PURCHASE_TICKET intents
+type TicketPurchasePayload struct {
+	Ticket            models.LotteryTicket `json:"ticket"`
+	PrizePoolIncrease float64              `json:"prize_pool_increase"`
+}
+
+// ChainClient is the part of the blockchain client the recovery worker uses
+type ChainClient interface {
+	ethereum.TransactionReader
+	ethereum.TransactionSender
+	NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error)
+	PendingNonceAt(ctx context.Context, account common.Address) (uint64, error)
+}
+
+// OutboxService records chain intents before blockchain calls and completes them after the database write
+type OutboxService struct {
+	db     *gorm.DB
+	chain  ChainClient    // Reads receipts during recovery, nil uses the global blockchain client
+	sender common.Address // Account intents are sent from, used for intents that have no signed transaction
+}
+
+// NewOutboxService creates a new OutboxService instance
+func NewOutboxService(db *gorm.DB) *OutboxService {
+	return &OutboxService{db: db}
+}
+
+// NewOutboxServiceWithChain creates an OutboxService that recovers intents of the given sender from the given chain client
+func NewOutboxServiceWithChain(db *gorm.DB, chain ChainClient, sender common.Address) *OutboxService {
+	return &OutboxService{db: db, chain: chain, sender: sender}
+}
+
+// CreateIntent records an operation before its blockchain transaction is sent
+//
+// Parameters:
+//   - ctx: Request context
+//   - operation: Intent operation, e.g. CREATE_LOTTERY
+//   - referenceID: ID of the business record the operation writes
+//   - payload: Record to write once the transaction is confirmed
+//
+// Returns:
+//   - *ChainIntent: The created intent
+//   - error: Database error
+func (s *OutboxService) CreateIntent(ctx context.Context, operation, referenceID string, payload interface{}) (*models.ChainIntent, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, utils.NewInternalError("Failed to encode chain intent payload", err)
+	}
+	intent := models.ChainIntent{
+		IntentID:    uuid.NewString(),
+		Operation:   operation,
+		ReferenceID: referenceID,
+		Status:      models.ChainIntentStatusPending,
+		Payload:     string(data),
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+	if err := s.db.WithContext(ctx).Create(&intent).Error; err != nil {
+		utils.Logger.Error("Failed to create chain intent", "operation", operation, "reference_id", referenceID, "error", err)
+		return nil, utils.NewInternalError("Failed to create chain intent", err)
+	}
+	return &intent, nil
+}
+
+// MarkSubmitted stores the signed transaction and the final payload before the transaction is broadcast
+//
+// The transaction must be signed without being sent. Once this returns, the recovery worker can find the
+// transaction by its hash, broadcast it again if the node never received it, or fail the intent once the
+// sender's nonce has moved past it.
+func (s *OutboxService) MarkSubmitted(ctx context.Context, intentID string, signed *types.Transaction, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return utils.NewInternalError("Failed to encode chain intent payload", err)
+	}
+	raw, err := signed.MarshalBinary()
+	if err != nil {
+		return utils.NewInternalError("Failed to encode signed transaction", err)
+	}
+	sender, err := types.Sender(types.LatestSignerForChainID(signed.ChainId()), signed)
+	if err != nil {
+		return utils.NewInternalError("Failed to recover transaction sender", err)
+	}
+	txHash := signed.Hash().Hex()
+	err = s.db.WithContext(ctx).Model(&models.ChainIntent{}).
+		Where("intent_id = ?", intentID).
+		Updates(map[string]interface{}{
+			"status":     models.ChainIntentStatusSubmitted,
+			"tx_hash":    txHash,
+			"raw_tx":     hexutil.Encode(raw),
+			"sender":     sender.Hex(),
+			"nonce":      signed.Nonce(),
+			"payload":    string(data),
+			"updated_at": time.Now(),
+		}).Error
+	if err != nil {
+		utils.Logger.Error("Failed to mark chain intent submitted", "intent_id", intentID, "tx_hash", txHash, "error", err)
+		return utils.NewInternalError("Failed to mark chain intent submitted", err)
+	}
+	return nil
+}
+
+// Complete marks an intent completed, tx must be the database transaction that wrote the business record
+func (s *OutboxService) Complete(tx *gorm.DB, intentID string) error {
+	return tx.Model(&models.ChainIntent{}).
+		Where("intent_id = ?", intentID).
+		Updates(map[string]interface{}{
+			"status":     models.ChainIntentStatusCompleted,
+			"last_error": "",
+			"updated_at": time.Now(),
+		}).Error
+}
+
+// MarkFailed marks an intent failed, used when its transaction reverted or was never mined
+func (s *OutboxService) MarkFailed(ctx context.Context, intentID string, cause error) error {
+	return s.fail(ctx, intentID, cause, models.ChainIntentStatusPending, models.ChainIntentStatusSubmitted)
+}
+
+// FailPending marks an intent failed only if no transaction was submitted for it
+func (s *OutboxService) FailPending(ctx context.Context, intentID string, cause error) error {
+	return s.fail(ctx, intentID, cause, models.ChainIntentStatusPending)
+}
+
+func (s *OutboxService) fail(ctx context.Context, intentID string, cause error, fromStatus ...string) error {
+	lastError := ""
+	if cause != nil {
+		lastError = truncate(cause.Error(), 1000)
+	}
+	err := s.db.WithContext(ctx).Model(&models.ChainIntent{}).
+		Where("intent_id = ? AND status IN ?", intentID, fromStatus).
+		Updates(map[string]interface{}{
+			"status":     models.ChainIntentStatusFailed,
+			"last_error": lastError,
+			"updated_at": time.Now(),
+		}).Error
+	if err != nil {
+		utils.Logger.Error("Failed to mark chain intent failed", "intent_id", intentID, "error", err)
+		return utils.NewInternalError("Failed to mark chain intent failed", err)
+	}
+	return nil
+}
+
+// truncate shortens s to at most n bytes
+func truncate(s string, n int) string {
+	if len(s) > n {
+		return s[:n]
+	}
+	return s
+}
@@ -0,0 +1,152 @@
+package ratelimit
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"backend/config"
+	"backend/utils"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Route policies
+const (
+	PolicyLogin    = "login"
+	PolicyRegister = "register"
+	PolicyPurchase = "purchase"
+)
+
+// Kinds of client identity a bucket is kept for
+const (
+	KeyIP      = "ip"
+	KeyWallet  = "wallet"
+	KeySubject = "sub"
+)
+
+// defaultRates apply when a policy is not configured or cannot be parsed
+var defaultRates = map[string]Rate{
+	PolicyLogin:    {Limit: 10, Period: time.Minute},
+	PolicyRegister: {Limit: 5, Period: time.Hour},
+	PolicyPurchase: {Limit: 20, Period: time.Minute},
+}
+
+// Identity is what a request is limited by; empty fields are not limited
+type Identity struct {
+	IP      string
+	Wallet  string
+	Subject string // Subject of the request's JWT
+}
+
+// keys returns the bucket keys of a policy for the identity
+func (id Identity) keys(policy string) []string {
+	var keys []string
+	add := func(kind, value string) {
+		if value = strings.TrimSpace(value); value != "" {
+			keys = append(keys, policy+":"+kind+":"+value)
+		}
+	}
+	add(KeyIP, id.IP)
+	add(KeyWallet, strings.ToLower(id.Wallet))
+	add(KeySubject, strings.ToLower(id.Subject))
+	return keys
+}
+
+// Limiter applies per-route rates to the buckets of a Store
+type Limiter struct {
+	store Store
+	rates map[string]Rate
+}
+
+// NewLimiter creates a Limiter; policies missing from rates use their default rate
+func NewLimiter(store Store, rates map[string]Rate) *Limiter {
+	merged := make(map[string]Rate, len(defaultRates))
+	for policy, rate := range defaultRates {
+		merged[policy] = rate
+	}
+	for policy, rate := range rates {
+		merged[policy] = rate
+	}
+	return &Limiter{store: store, rates: merged}
+}
+
+// Allow takes a token from every bucket of the identity under the policy
+//
+// The request is refused when any bucket is empty, and RetryAfter is the longest wait of the empty ones.
+// Store errors are returned with an allowed result, so callers can let requests through while the store is down.
+func (l *Limiter) Allow(ctx context.Context, policy string, id Identity, now time.Time) (Result, error) {
+	rate, ok := l.rates[policy]
+	if !ok {
+		return Result{Allowed: true}, nil
+	}
+	combined := Result{Allowed: true, Limit: rate.Limit, Remaining: rate.Limit}
+	for _, key := range id.keys(policy) {
+		res, err := l.store.Take(ctx, key, rate, now)
+		if err != nil {
+			return Result{Allowed: true, Limit: rate.Limit, Remaining: rate.Limit}, err
+		}
+		if res.Remaining < combined.Remaining {
+			combined.Remaining = res.Remaining
+		}
+		if !res.Allowed {
+			combined.Allowed = false
+			if res.RetryAfter > combined.RetryAfter {
+				combined.RetryAfter = res.RetryAfter
+			}
+		}
+	}
+	return combined, nil
+}
+
+var (
+	defaultLimiter     *Limiter
+	defaultLimiterOnce sync.Once
+)
+
+// DefaultLimiter returns the limiter of the configuration, created once
+//
+// RATE_LIMIT_BACKEND=redis shares buckets through REDIS_URL; otherwise, or when REDIS_URL cannot be parsed,
+// buckets are kept in memory.
+func DefaultLimiter() *Limiter {
+	defaultLimiterOnce.Do(func() {
+		defaultLimiter = NewLimiter(defaultStore(), configuredRates())
+	})
+	return defaultLimiter
+}
+
+// defaultStore creates the configured store
+func defaultStore() Store {
+	if !strings.EqualFold(config.AppConfig.RateLimitBackend, "redis") {
+		return NewMemoryStore()
+	}
+	options, err := redis.ParseURL(config.AppConfig.RedisURL)
+	if err != nil {
+		utils.Logger.Error("Invalid REDIS_URL, rate limits are kept in memory", "error", err)
+		return NewMemoryStore()
+	}
+	return NewRedisStore(redis.NewClient(options), "ratelimit:")
+}
+
+// configuredRates parses the configured route rates, keeping the default of a rate that cannot be parsed
+func configuredRates() map[string]Rate {
+	specs := map[string]string{
+		PolicyLogin:    config.AppConfig.RateLimitLogin,
+		PolicyRegister: config.AppConfig.RateLimitRegister,
+		PolicyPurchase: config.AppConfig.RateLimitPurchase,
+	}
+	rates := make(map[string]Rate, len(specs))
+	for policy, spec := range specs {
+		if spec == "" {
+			continue
+		}
+		rate, err := ParseRate(spec)
+		if err != nil {
+			utils.Logger.Error("Invalid rate limit, using the default", "policy", policy, "rate", spec, "default", defaultRates[policy].String(), "error", err)
+			continue
+		}
+		rates[policy] = rate
+	}
+	return rates
+}
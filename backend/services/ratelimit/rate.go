@@ -0,0 +1,91 @@
+package ratelimit
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Rate is a token bucket holding at most Limit tokens, refilled with Limit tokens every Period
+type Rate struct {
+	Limit  int
+	Period time.Duration
+}
+
+// ParseRate parses a rate written as "<limit>/<period>", e.g. "10/1m", "5/h" or "100/30s"
+func ParseRate(spec string) (Rate, error) {
+	limitPart, periodPart, ok := strings.Cut(strings.TrimSpace(spec), "/")
+	if !ok {
+		return Rate{}, fmt.Errorf("invalid rate %q, expected <limit>/<period>", spec)
+	}
+	limit, err := strconv.Atoi(strings.TrimSpace(limitPart))
+	if err != nil || limit <= 0 {
+		return Rate{}, fmt.Errorf("invalid rate limit %q", limitPart)
+	}
+	periodPart = strings.TrimSpace(periodPart)
+	if periodPart != "" && (periodPart[0] < '0' || periodPart[0] > '9') {
+		// A bare unit such as "m" means one of it
+		periodPart = "1" + periodPart
+	}
+	period, err := time.ParseDuration(periodPart)
+	if err != nil || period <= 0 {
+		return Rate{}, fmt.Errorf("invalid rate period %q", periodPart)
+	}
+	return Rate{Limit: limit, Period: period}, nil
+}
+
+// String formats the rate the way ParseRate reads it
+func (r Rate) String() string {
+	return fmt.Sprintf("%d/%s", r.Limit, r.Period)
+}
+
+// perSecond is the refill speed in tokens per second
+func (r Rate) perSecond() float64 {
+	return float64(r.Limit) / r.Period.Seconds()
+}
+
+// Bucket is the state of one token bucket; a zero Bucket is full
+type Bucket struct {
+	Tokens    float64
+	UpdatedAt time.Time
+}
+
+// Result is the outcome of taking a token
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int           // Whole tokens left after this request
+	RetryAfter time.Duration // How long until a token is available again, zero when allowed
+}
+
+// Take refills the bucket for the time elapsed since its last update and takes one token if there is one
+func Take(bucket Bucket, rate Rate, now time.Time) (Bucket, Result) {
+	tokens := float64(rate.Limit)
+	if !bucket.UpdatedAt.IsZero() {
+		tokens = bucket.Tokens
+		if elapsed := now.Sub(bucket.UpdatedAt); elapsed > 0 {
+			tokens = math.Min(float64(rate.Limit), tokens+elapsed.Seconds()*rate.perSecond())
+		}
+	}
+	allowed := tokens >= 1
+	if allowed {
+		tokens--
+	}
+	updatedAt := now
+	if bucket.UpdatedAt.After(now) {
+		// Never move the bucket back in time when clocks disagree
+		updatedAt = bucket.UpdatedAt
+	}
+	return Bucket{Tokens: tokens, UpdatedAt: updatedAt}, result(allowed, tokens, rate)
+}
+
+// result describes a bucket left with the given tokens
+func result(allowed bool, tokens float64, rate Rate) Result {
+	res := Result{Allowed: allowed, Limit: rate.Limit, Remaining: int(math.Floor(tokens))}
+	if !allowed {
+		res.RetryAfter = time.Duration((1 - tokens) / rate.perSecond() * float64(time.Second))
+	}
+	return res
+}
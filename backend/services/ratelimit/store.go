@@ -0,0 +1,125 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Store keeps token buckets; implementations must take tokens atomically
+type Store interface {
+	Take(ctx context.Context, key string, rate Rate, now time.Time) (Result, error)
+}
+
+// memorySweepInterval is how often MemoryStore drops buckets that have refilled completely
+const memorySweepInterval = time.Minute
+
+// memoryEntry is a bucket with the period it refills in
+type memoryEntry struct {
+	bucket Bucket
+	period time.Duration
+}
+
+// MemoryStore keeps buckets in process memory, limits are per server
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]memoryEntry
+	lastSweep time.Time
+}
+
+// NewMemoryStore creates an empty MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: make(map[string]memoryEntry)}
+}
+
+// Take takes a token from the bucket of key
+func (s *MemoryStore) Take(_ context.Context, key string, rate Rate, now time.Time) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if now.Sub(s.lastSweep) >= memorySweepInterval {
+		s.sweep(now)
+	}
+	bucket, res := Take(s.buckets[key].bucket, rate, now)
+	s.buckets[key] = memoryEntry{bucket: bucket, period: rate.Period}
+	return res, nil
+}
+
+// sweep drops buckets untouched for a whole period: they are full again, the same as a missing bucket
+func (s *MemoryStore) sweep(now time.Time) {
+	for key, entry := range s.buckets {
+		if now.Sub(entry.bucket.UpdatedAt) >= entry.period {
+			delete(s.buckets, key)
+		}
+	}
+	s.lastSweep = now
+}
+
+// takeScript is Take run inside Redis, so that servers sharing the store take tokens atomically
+//
+// KEYS[1] bucket key; ARGV: limit, tokens per millisecond, now in milliseconds, key TTL in milliseconds.
+// Returns {allowed, tokens}; tokens is a string since Redis truncates Lua numbers to integers.
+var takeScript = redis.NewScript(`
+local limit = tonumber(ARGV[1])
+local per_ms = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1])
+local ts = tonumber(state[2])
+if tokens == nil or ts == nil then
+	tokens = limit
+	ts = now
+end
+if now > ts then
+	tokens = math.min(limit, tokens + (now - ts) * per_ms)
+	ts = now
+end
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', tostring(ts))
+redis.call('PEXPIRE', KEYS[1], ARGV[4])
+return {allowed, tostring(tokens)}
+`)
+
+// RedisStore keeps buckets in Redis or any server speaking its protocol and Lua scripting,
+// so that limits are shared by every server using it
+type RedisStore struct {
+	client redis.Scripter
+	prefix string
+}
+
+// NewRedisStore creates a RedisStore on a client, cluster or ring; keys are prefixed with prefix
+func NewRedisStore(client redis.Scripter, prefix string) *RedisStore {
+	return &RedisStore{client: client, prefix: prefix}
+}
+
+// Take takes a token from the bucket of key
+func (s *RedisStore) Take(ctx context.Context, key string, rate Rate, now time.Time) (Result, error) {
+	perMillisecond := rate.perSecond() / 1000
+	values, err := takeScript.Run(ctx, s.client, []string{s.prefix + key},
+		rate.Limit,
+		strconv.FormatFloat(perMillisecond, 'g', -1, 64),
+		now.UnixMilli(),
+		rate.Period.Milliseconds(),
+	).Slice()
+	if err != nil {
+		return Result{}, fmt.Errorf("run rate limit script: %w", err)
+	}
+	if len(values) != 2 {
+		return Result{}, fmt.Errorf("unexpected rate limit script result %v", values)
+	}
+	allowed, _ := values[0].(int64)
+	tokensText, _ := values[1].(string)
+	tokens, err := strconv.ParseFloat(tokensText, 64)
+	if err != nil {
+		return Result{}, fmt.Errorf("parse rate limit tokens %q: %w", tokensText, err)
+	}
+	return result(allowed == 1, tokens, rate), nil
+}
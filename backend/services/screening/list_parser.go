@@ -0,0 +1,153 @@
+package screening
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ListEntry is an entry of a sanctions list file
+type ListEntry struct {
+	Ref           string     `json:"ref"`
+	Name          string     `json:"name"`
+	BirthDate     *time.Time `json:"-"`
+	WalletAddress string     `json:"wallet_address"`
+}
+
+// listColumns maps accepted column names to the ListEntry fields
+var listColumns = map[string]string{
+	"ref":            "ref",
+	"id":             "ref",
+	"name":           "name",
+	"full_name":      "name",
+	"birth_date":     "birth_date",
+	"dob":            "birth_date",
+	"wallet_address": "wallet_address",
+	"wallet":         "wallet_address",
+	"address":        "wallet_address",
+}
+
+// IsListFile reports whether a file is a sanctions list the importer reads
+func IsListFile(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv", ".json":
+		return true
+	}
+	return false
+}
+
+// ParseListFile parses a CSV or JSON sanctions list by its extension
+func ParseListFile(path string, r io.Reader) ([]ListEntry, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return ParseCSV(r)
+	case ".json":
+		return ParseJSON(r)
+	}
+	return nil, fmt.Errorf("unsupported list file %s", filepath.Base(path))
+}
+
+// ParseCSV parses a CSV list with a header row of name, birth_date, wallet_address and ref columns
+// (or their aliases); entries without a ref are numbered by line
+func ParseCSV(r io.Reader) ([]ListEntry, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("read header: %w", err)
+	}
+	columns := make(map[string]int)
+	for i, column := range header {
+		if field, ok := listColumns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(column, "\ufeff")))]; ok {
+			columns[field] = i
+		}
+	}
+	if _, ok := columns["name"]; !ok {
+		if _, ok := columns["wallet_address"]; !ok {
+			return nil, fmt.Errorf("header has neither a name nor a wallet_address column")
+		}
+	}
+
+	var entries []ListEntry
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		value := func(field string) string {
+			if i, ok := columns[field]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		entry, ok, err := newListEntry(value("ref"), value("name"), value("birth_date"), value("wallet_address"), line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if ok {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+// ParseJSON parses a JSON array of objects with name, birth_date, wallet_address and ref fields
+// (or their aliases); entries without a ref are numbered by position
+func ParseJSON(r io.Reader) ([]ListEntry, error) {
+	var records []map[string]interface{}
+	if err := json.NewDecoder(r).Decode(&records); err != nil {
+		return nil, fmt.Errorf("decode list: %w", err)
+	}
+	var entries []ListEntry
+	for i, record := range records {
+		fields := make(map[string]string)
+		for key, raw := range record {
+			field, ok := listColumns[strings.ToLower(key)]
+			if !ok {
+				continue
+			}
+			switch v := raw.(type) {
+			case string:
+				fields[field] = strings.TrimSpace(v)
+			case float64:
+				fields[field] = strconv.FormatFloat(v, 'f', -1, 64)
+			}
+		}
+		entry, ok, err := newListEntry(fields["ref"], fields["name"], fields["birth_date"], fields["wallet_address"], i+1)
+		if err != nil {
+			return nil, fmt.Errorf("entry %d: %w", i+1, err)
+		}
+		if ok {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+// newListEntry builds an entry, skipping rows with neither a name nor a wallet address
+func newListEntry(ref, name, birthDate, wallet string, position int) (ListEntry, bool, error) {
+	if name == "" && wallet == "" {
+		return ListEntry{}, false, nil
+	}
+	if ref == "" {
+		ref = strconv.Itoa(position)
+	}
+	entry := ListEntry{Ref: ref, Name: name, WalletAddress: strings.ToLower(wallet)}
+	if birthDate != "" {
+		date, err := time.Parse("2006-01-02", birthDate)
+		if err != nil {
+			return ListEntry{}, false, fmt.Errorf("invalid birth_date %q, want YYYY-MM-DD", birthDate)
+		}
+		entry.BirthDate = &date
+	}
+	return entry, true, nil
+}
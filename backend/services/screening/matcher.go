@@ -0,0 +1,115 @@
+package screening
+
+import (
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// DefaultMatchThreshold is the name similarity from which a name is considered a hit
+const DefaultMatchThreshold = 0.9
+
+// NormalizeName lowercases a name, strips diacritics and punctuation and sorts its words,
+// so that "Müller, Hans" and "hans muller" normalize alike
+func NormalizeName(name string) string {
+	var b strings.Builder
+	for _, r := range norm.NFD.String(name) {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+			// Combining marks left by the decomposition
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			b.WriteRune(unicode.ToLower(r))
+		default:
+			b.WriteRune(' ')
+		}
+	}
+	words := strings.Fields(b.String())
+	sort.Strings(words)
+	return strings.Join(words, " ")
+}
+
+// JaroWinkler returns the Jaro-Winkler similarity of two strings, from 0 (nothing in common) to 1 (equal)
+func JaroWinkler(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	if len(ra) == 0 && len(rb) == 0 {
+		return 1
+	}
+	if len(ra) == 0 || len(rb) == 0 {
+		return 0
+	}
+	window := max(len(ra), len(rb))/2 - 1
+	if window < 0 {
+		window = 0
+	}
+	matchedA := make([]bool, len(ra))
+	matchedB := make([]bool, len(rb))
+	matches := 0
+	for i := range ra {
+		lo, hi := max(0, i-window), min(len(rb), i+window+1)
+		for j := lo; j < hi; j++ {
+			if !matchedB[j] && ra[i] == rb[j] {
+				matchedA[i], matchedB[j] = true, true
+				matches++
+				break
+			}
+		}
+	}
+	if matches == 0 {
+		return 0
+	}
+	transpositions, j := 0, 0
+	for i := range ra {
+		if !matchedA[i] {
+			continue
+		}
+		for !matchedB[j] {
+			j++
+		}
+		if ra[i] != rb[j] {
+			transpositions++
+		}
+		j++
+	}
+	m := float64(matches)
+	jaro := (m/float64(len(ra)) + m/float64(len(rb)) + (m-float64(transpositions/2))/m) / 3
+
+	prefix := 0
+	for prefix < min(4, len(ra), len(rb)) && ra[prefix] == rb[prefix] {
+		prefix++
+	}
+	return jaro + float64(prefix)*0.1*(1-jaro)
+}
+
+// NameScore returns the similarity of two names
+func NameScore(a, b string) float64 {
+	return JaroWinkler(NormalizeName(a), NormalizeName(b))
+}
+
+// sameDate compares the calendar dates of two times
+func sameDate(a, b time.Time) bool {
+	ya, ma, da := a.Date()
+	yb, mb, db := b.Date()
+	return ya == yb && ma == mb && da == db
+}
+
+// MatchName reports whether a customer matches a list entry by name, and the name similarity
+//
+// normalizedEntry is the entry's normalized name. When both birth dates are known they must be equal,
+// which keeps common names from hitting.
+func MatchName(name string, birthDate *time.Time, normalizedEntry string, entryBirthDate *time.Time, threshold float64) (float64, bool) {
+	normalized := NormalizeName(name)
+	if normalized == "" || normalizedEntry == "" {
+		return 0, false
+	}
+	score := JaroWinkler(normalized, normalizedEntry)
+	if score < threshold {
+		return score, false
+	}
+	if birthDate != nil && !birthDate.IsZero() && entryBirthDate != nil && !sameDate(*birthDate, *entryBirthDate) {
+		return score, false
+	}
+	return score, true
+}
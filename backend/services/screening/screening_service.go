@@ -0,0 +1,451 @@
+package screening
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"backend/config"
+	"backend/models"
+	"backend/utils"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// rescreenBatchSize is the number of customers loaded at a time when re-screening
+const rescreenBatchSize = 500
+
+// Subject is a wallet, and the identity behind it when known, to screen
+type Subject struct {
+	Address   string
+	Name      string
+	BirthDate *time.Time
+}
+
+// nameCandidate is a list entry with a name, kept in memory for fuzzy matching
+type nameCandidate struct {
+	ListName       string
+	EntryRef       string
+	Name           string
+	NormalizedName string
+	BirthDate      *time.Time
+}
+
+// nameIndex caches the named entries until a list changes
+var nameIndex struct {
+	sync.Mutex
+	version    string
+	candidates []nameCandidate
+}
+
+// ScreeningService screens customers against the imported sanctions lists
+type ScreeningService struct {
+	db        *gorm.DB
+	threshold float64
+}
+
+// NewScreeningService creates a ScreeningService with the configured match threshold
+func NewScreeningService(db *gorm.DB) *ScreeningService {
+	threshold := config.AppConfig.SanctionsMatchThreshold
+	if threshold <= 0 || threshold > 1 {
+		threshold = DefaultMatchThreshold
+	}
+	return &ScreeningService{db: db, threshold: threshold}
+}
+
+// ImportDir imports every CSV and JSON list of a directory whose content changed since the last import,
+// and drops lists whose file is gone; it returns the names of the lists that changed
+func (s *ScreeningService) ImportDir(ctx context.Context, dir string) ([]string, error) {
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, utils.NewInternalError("Failed to read sanctions list directory", err)
+	}
+	var imported []models.SanctionsList
+	if err := s.db.WithContext(ctx).Find(&imported).Error; err != nil {
+		return nil, utils.NewInternalError("Failed to fetch sanctions lists", err)
+	}
+	checksums := make(map[string]string, len(imported))
+	for _, list := range imported {
+		checksums[list.ListName] = list.Checksum
+	}
+
+	var changed []string
+	present := make(map[string]bool)
+	for _, file := range files {
+		if file.IsDir() || !IsListFile(file.Name()) {
+			continue
+		}
+		path := filepath.Join(dir, file.Name())
+		name := strings.TrimSuffix(file.Name(), filepath.Ext(file.Name()))
+		present[name] = true
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return changed, utils.NewInternalError("Failed to read sanctions list "+file.Name(), err)
+		}
+		sum := sha256.Sum256(content)
+		checksum := hex.EncodeToString(sum[:])
+		if checksums[name] == checksum {
+			continue
+		}
+		entries, err := ParseListFile(path, strings.NewReader(string(content)))
+		if err != nil {
+			// Keep the previous version of a list that no longer parses
+			utils.Logger.Error("Failed to parse sanctions list", "file", path, "error", err)
+			continue
+		}
+		if err := s.replaceList(ctx, models.SanctionsList{
+			ListName:   name,
+			Source:     path,
+			Checksum:   checksum,
+			EntryCount: len(entries),
+			ImportedAt: time.Now(),
+		}, entries); err != nil {
+			return changed, err
+		}
+		utils.Logger.Info("Sanctions list imported", "list", name, "entries", len(entries))
+		changed = append(changed, name)
+	}
+	for _, list := range imported {
+		if present[list.ListName] {
+			continue
+		}
+		if err := s.db.WithContext(ctx).Where("list_name = ?", list.ListName).Delete(&models.SanctionsList{}).Error; err != nil {
+			return changed, utils.NewInternalError("Failed to remove sanctions list", err)
+		}
+		utils.Logger.Info("Sanctions list removed", "list", list.ListName)
+		changed = append(changed, list.ListName)
+	}
+	return changed, nil
+}
+
+// replaceList swaps the entries of a list in one transaction
+func (s *ScreeningService) replaceList(ctx context.Context, list models.SanctionsList, entries []ListEntry) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.OnConflict{UpdateAll: true}).Create(&list).Error; err != nil {
+			return utils.NewInternalError("Failed to save sanctions list", err)
+		}
+		if err := tx.Where("list_name = ?", list.ListName).Delete(&models.SanctionsEntry{}).Error; err != nil {
+			return utils.NewInternalError("Failed to clear sanctions list entries", err)
+		}
+		rows := make([]models.SanctionsEntry, 0, len(entries))
+		for _, entry := range entries {
+			rows = append(rows, models.SanctionsEntry{
+				ListName:       list.ListName,
+				EntryRef:       entry.Ref,
+				Name:           entry.Name,
+				NormalizedName: NormalizeName(entry.Name),
+				BirthDate:      entry.BirthDate,
+				WalletAddress:  entry.WalletAddress,
+			})
+		}
+		if len(rows) > 0 {
+			if err := tx.CreateInBatches(rows, 500).Error; err != nil {
+				return utils.NewInternalError("Failed to save sanctions list entries", err)
+			}
+		}
+		return nil
+	})
+}
+
+// nameCandidates returns the named entries of all lists, reloaded when a list changed
+func (s *ScreeningService) nameCandidates(ctx context.Context) ([]nameCandidate, error) {
+	var version struct {
+		Lists     int64
+		Entries   int64
+		Checksums string
+	}
+	if err := s.db.WithContext(ctx).Model(&models.SanctionsList{}).
+		Select("COUNT(*) AS lists, COALESCE(SUM(entry_count), 0) AS entries, COALESCE(STRING_AGG(checksum, ',' ORDER BY list_name), '') AS checksums").
+		Scan(&version).Error; err != nil {
+		return nil, utils.NewInternalError("Failed to fetch sanctions lists", err)
+	}
+	key := fmt.Sprintf("%d/%d/%s", version.Lists, version.Entries, version.Checksums)
+
+	nameIndex.Lock()
+	defer nameIndex.Unlock()
+	if nameIndex.version == key {
+		return nameIndex.candidates, nil
+	}
+	var candidates []nameCandidate
+	if err := s.db.WithContext(ctx).Model(&models.SanctionsEntry{}).
+		Select("list_name, entry_ref, name, normalized_name, birth_date").
+		Where("COALESCE(normalized_name, '') <> ''").
+		Scan(&candidates).Error; err != nil {
+		return nil, utils.NewInternalError("Failed to fetch sanctions list entries", err)
+	}
+	nameIndex.version, nameIndex.candidates = key, candidates
+	return candidates, nil
+}
+
+// Match returns the list entries a subject hits: an exact wallet address or a name (and birth date) match
+func (s *ScreeningService) Match(ctx context.Context, subject Subject) ([]models.ScreeningHit, error) {
+	var hits []models.ScreeningHit
+	if subject.Address != "" {
+		var entries []models.SanctionsEntry
+		if err := s.db.WithContext(ctx).
+			Where("wallet_address = ?", strings.ToLower(strings.TrimSpace(subject.Address))).
+			Find(&entries).Error; err != nil {
+			return nil, utils.NewInternalError("Failed to screen wallet address", err)
+		}
+		for _, entry := range entries {
+			hits = append(hits, models.ScreeningHit{
+				ListName:  entry.ListName,
+				EntryRef:  entry.EntryRef,
+				EntryName: entry.Name,
+				MatchType: models.ScreeningMatchWallet,
+				Score:     1,
+			})
+		}
+	}
+	if subject.Name != "" {
+		candidates, err := s.nameCandidates(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, candidate := range candidates {
+			score, ok := MatchName(subject.Name, subject.BirthDate, candidate.NormalizedName, candidate.BirthDate, s.threshold)
+			if !ok {
+				continue
+			}
+			hits = append(hits, models.ScreeningHit{
+				ListName:  candidate.ListName,
+				EntryRef:  candidate.EntryRef,
+				EntryName: candidate.Name,
+				MatchType: models.ScreeningMatchName,
+				Score:     score,
+			})
+		}
+	}
+	return hits, nil
+}
+
+// Screen matches a subject and records new hits as OPEN; hits already cleared stay cleared.
+// It returns an error when the subject has blocking hits
+func (s *ScreeningService) Screen(ctx context.Context, subject Subject) error {
+	hits, err := s.Match(ctx, subject)
+	if err != nil {
+		return err
+	}
+	if err := s.record(ctx, subject.Address, hits); err != nil {
+		return err
+	}
+	return s.checkHits(ctx, subject.Address)
+}
+
+// record saves hits of an address, skipping the entries it already has a hit for
+func (s *ScreeningService) record(ctx context.Context, address string, hits []models.ScreeningHit) error {
+	if len(hits) == 0 {
+		return nil
+	}
+	now := time.Now()
+	for i := range hits {
+		hits[i].CustomerAddress = address
+		hits[i].Status = models.ScreeningHitOpen
+		hits[i].CreatedAt = now
+		hits[i].UpdatedAt = now
+	}
+	result := s.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "customer_address"}, {Name: "list_name"}, {Name: "entry_ref"}, {Name: "match_type"}},
+		DoNothing: true,
+	}).Create(&hits)
+	if result.Error != nil {
+		return utils.NewInternalError("Failed to record screening hits", result.Error)
+	}
+	if result.RowsAffected > 0 {
+		utils.Logger.Warn("Sanctions screening hit", "customer_address", address, "hits", result.RowsAffected)
+	}
+	return nil
+}
+
+// checkHits returns a forbidden error when an address has open or confirmed hits
+func (s *ScreeningService) checkHits(ctx context.Context, address string) error {
+	var count int64
+	if err := s.db.WithContext(ctx).Model(&models.ScreeningHit{}).
+		Where("LOWER(customer_address) = LOWER(?) AND status IN ?", address, []string{models.ScreeningHitOpen, models.ScreeningHitConfirmed}).
+		Count(&count).Error; err != nil {
+		return utils.NewInternalError("Failed to check screening hits", err)
+	}
+	if count > 0 {
+		return utils.NewForbiddenError("Account is blocked by sanctions screening", nil)
+	}
+	return nil
+}
+
+// CheckAllowed refuses a wallet with blocking hits or whose address is on a list, used at login and purchase
+func (s *ScreeningService) CheckAllowed(ctx context.Context, address string) error {
+	return s.Screen(ctx, Subject{Address: address})
+}
+
+// RescreenAll screens every customer with KYC data again, after a list changed
+func (s *ScreeningService) RescreenAll(ctx context.Context) (int, error) {
+	screened := 0
+	lastAddress := ""
+	for {
+		var rows []struct {
+			CustomerAddress string
+			Name            string
+			BirthDate       *time.Time
+		}
+		if err := s.db.WithContext(ctx).Table("customers").
+			Select("customers.customer_address, kyc_data.name, kyc_data.birth_date").
+			Joins("LEFT JOIN kyc_data ON kyc_data.customer_address = customers.customer_address").
+			Where("customers.customer_address > ?", lastAddress).
+			Order("customers.customer_address").
+			Limit(rescreenBatchSize).
+			Scan(&rows).Error; err != nil {
+			return screened, utils.NewInternalError("Failed to fetch customers to screen", err)
+		}
+		for _, row := range rows {
+			if err := ctx.Err(); err != nil {
+				return screened, err
+			}
+			hits, err := s.Match(ctx, Subject{Address: row.CustomerAddress, Name: row.Name, BirthDate: row.BirthDate})
+			if err != nil {
+				return screened, err
+			}
+			if err := s.record(ctx, row.CustomerAddress, hits); err != nil {
+				return screened, err
+			}
+			screened++
+		}
+		if len(rows) < rescreenBatchSize {
+			return screened, nil
+		}
+		lastAddress = rows[len(rows)-1].CustomerAddress
+	}
+}
+
+// Refresh imports the changed lists of the configured directory and re-screens the customers when any changed
+func (s *ScreeningService) Refresh(ctx context.Context) ([]string, error) {
+	changed, err := s.ImportDir(ctx, config.AppConfig.SanctionsListDir)
+	if err != nil || len(changed) == 0 {
+		return changed, err
+	}
+	screened, err := s.RescreenAll(ctx)
+	if err != nil {
+		return changed, err
+	}
+	utils.Logger.Info("Customers re-screened after sanctions list update", "lists", changed, "customers", screened)
+	return changed, nil
+}
+
+// ListLists returns the imported sanctions lists
+func (s *ScreeningService) ListLists(ctx context.Context) ([]models.SanctionsList, error) {
+	lists := []models.SanctionsList{}
+	if err := s.db.WithContext(ctx).Order("list_name").Find(&lists).Error; err != nil {
+		return nil, utils.NewInternalError("Failed to fetch sanctions lists", err)
+	}
+	return lists, nil
+}
+
+// HitFilter defines the filters of the screening hit list
+type HitFilter struct {
+	Status          string
+	CustomerAddress string
+	Page            int
+	PageSize        int
+}
+
+// HitListResult is a page of screening hits
+type HitListResult struct {
+	Total    int64                 `json:"total"`
+	Page     int                   `json:"page"`
+	PageSize int                   `json:"page_size"`
+	Hits     []models.ScreeningHit `json:"hits"`
+}
+
+// ListHits returns screening hits, newest first
+func (s *ScreeningService) ListHits(ctx context.Context, filter HitFilter) (*HitListResult, error) {
+	query := s.db.WithContext(ctx).Model(&models.ScreeningHit{})
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.CustomerAddress != "" {
+		query = query.Where("LOWER(customer_address) = LOWER(?)", filter.CustomerAddress)
+	}
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, utils.NewInternalError("Failed to count screening hits", err)
+	}
+	page, pageSize := filter.Page, filter.PageSize
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = 20
+	}
+	if pageSize > 100 {
+		pageSize = 100
+	}
+	hits := []models.ScreeningHit{}
+	if err := query.Order("created_at DESC, hit_id DESC").Offset((page - 1) * pageSize).Limit(pageSize).Find(&hits).Error; err != nil {
+		return nil, utils.NewInternalError("Failed to fetch screening hits", err)
+	}
+	return &HitListResult{Total: total, Page: page, PageSize: pageSize, Hits: hits}, nil
+}
+
+// ResolveHit confirms a hit or clears it as a false positive
+func (s *ScreeningService) ResolveHit(ctx context.Context, hitID uint, status, reviewer, notes string) (*models.ScreeningHit, error) {
+	if status != models.ScreeningHitConfirmed && status != models.ScreeningHitCleared {
+		return nil, utils.NewBadRequestError("Hits can only be confirmed or cleared", nil)
+	}
+	var hit models.ScreeningHit
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&hit, hitID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return utils.NewBadRequestError("Screening hit not found", err)
+			}
+			return utils.NewInternalError("Failed to fetch screening hit", err)
+		}
+		now := time.Now()
+		hit.Status = status
+		hit.ReviewedBy = reviewer
+		hit.ReviewedAt = &now
+		hit.Notes = notes
+		hit.UpdatedAt = now
+		if err := tx.Save(&hit).Error; err != nil {
+			return utils.NewInternalError("Failed to update screening hit", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	utils.Logger.Info("Screening hit resolved", "hit_id", hit.HitID, "customer_address", hit.CustomerAddress, "status", status, "reviewer", reviewer)
+	return &hit, nil
+}
+
+// StartListWatcher imports the sanctions lists at startup and whenever their files change,
+// re-screening the customers after each change, until ctx is cancelled
+func StartListWatcher(ctx context.Context, db *gorm.DB) {
+	service := NewScreeningService(db)
+	interval := time.Duration(config.AppConfig.SanctionsRefreshInterval) * time.Second
+	if interval <= 0 {
+		interval = 5 * time.Minute
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			if _, err := service.Refresh(ctx); err != nil {
+				utils.Logger.Error("Sanctions list refresh failed", "error", err)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
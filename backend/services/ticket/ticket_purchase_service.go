@@ -9,6 +9,13 @@ import (
 	"backend/blockchain"
 	"backend/config"
 	"backend/models"
+	"backend/services/account"
+	"backend/services/events"
+	"backend/services/geo"
+	"backend/services/kyc"
+	"backend/services/notification"
+	"backend/services/outbox"
+	"backend/services/screening"
 	"backend/utils"
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
@@ -24,6 +31,7 @@ type PurchaseTicketParams struct {
 	BuyerAddress   string
 	PurchaseAmount uint64
 	BetContent     string
+	ClientIP       string // Checked against the lottery's country restrictions
 }
 
 // TicketService encapsulates ticket purchasing business logic
@@ -47,6 +55,18 @@ func (s *TicketPurchaseService) validatePurchaseTicketParams(ctx context.Context
 		return utils.NewBadRequestError("Invalid or inactive issue_id", nil)
 	}
 
+	// Buyers whose KYC verification expired must re-verify first
+	if err := kyc.CheckPurchaseAllowed(ctx, s.db, params.BuyerAddress, time.Now()); err != nil {
+		utils.Logger.Warn("Purchase refused by KYC check", "buyer_address", params.BuyerAddress, "error", err)
+		return err
+	}
+
+	// Sanctioned wallets and customers with unresolved screening hits cannot buy
+	if err := screening.NewScreeningService(s.db).CheckAllowed(ctx, params.BuyerAddress); err != nil {
+		utils.Logger.Warn("Purchase refused by sanctions screening", "buyer_address", params.BuyerAddress, "error", err)
+		return err
+	}
+
 	// Validate lottery exists
 	var lottery models.Lottery
 	if err := s.db.WithContext(ctx).
@@ -58,6 +78,10 @@ func (s *TicketPurchaseService) validatePurchaseTicketParams(ctx context.Context
 		}
 		return utils.NewInternalError("Failed to check lottery ID", errors.Wrap(err, "database error"))
 	}
+	if lottery.Status != models.LotteryStatusActive {
+		utils.Logger.Warn("Lottery is not on sale", "lottery_id", lottery.LotteryID, "status", lottery.Status)
+		return utils.NewBadRequestError("Lottery is not on sale", nil)
+	}
 
 	// Validate ticket supply
 	var totalTickets uint64
@@ -110,6 +134,19 @@ func (s *TicketPurchaseService) validatePurchaseTicketParams(ctx context.Context
 	return nil
 }
 
+// checkJurisdiction checks the client's country against the country restrictions of the issue's lottery,
+// the decision is kept on the ticket
+func (s *TicketPurchaseService) checkJurisdiction(ctx context.Context, params PurchaseTicketParams) (geo.Decision, error) {
+	var lottery models.Lottery
+	if err := s.db.WithContext(ctx).
+		Joins("JOIN lottery_issues ON lottery_issues.lottery_id = lotteries.lottery_id").
+		Where("lottery_issues.issue_id = ?", params.IssueID).
+		First(&lottery).Error; err != nil {
+		return geo.Decision{}, utils.NewInternalError("Failed to fetch lottery", err)
+	}
+	return geo.NewGeoService(s.db).CheckPurchase(lottery, params.ClientIP)
+}
+
 // PurchaseTicket purchases a lottery ticket
 //
 // Parameters:
@@ -125,6 +162,17 @@ func (s *TicketPurchaseService) PurchaseTicket(ctx context.Context, params Purch
 	if err := s.validatePurchaseTicketParams(ctx, params); err != nil {
 		return nil, common.Hash{}, err
 	}
+	geoDecision, err := s.checkJurisdiction(ctx, params)
+	if err != nil {
+		return nil, common.Hash{}, err
+	}
+
+	// Record the intent before buying, so an interrupted request can be recovered from the transaction hash
+	outboxService := outbox.NewOutboxService(s.db)
+	intent, err := outboxService.CreateIntent(ctx, models.ChainIntentOpPurchaseTicket, params.TicketID, params)
+	if err != nil {
+		return nil, common.Hash{}, err
+	}
 
 	// Execute blockchain transaction
 	data := []byte{}
@@ -158,14 +206,17 @@ func (s *TicketPurchaseService) PurchaseTicket(ctx context.Context, params Purch
 
 		// Construct ticket record
 		ticket = models.LotteryTicket{
-			TicketID:       params.TicketID,
-			IssueID:        params.IssueID,
-			BuyerAddress:   params.BuyerAddress,
-			PurchaseAmount: float64(params.PurchaseAmount), // Store as number of tickets
-			BetContent:     params.BetContent,
-			PurchaseTime:   time.Now(),
-			CreatedAt:      time.Now(),
-			UpdatedAt:      time.Now(),
+			TicketID:        params.TicketID,
+			IssueID:         params.IssueID,
+			BuyerAddress:    params.BuyerAddress,
+			PurchaseAmount:  float64(params.PurchaseAmount), // Store as number of tickets
+			BetContent:      params.BetContent,
+			PurchaseIP:      params.ClientIP,
+			PurchaseCountry: geoDecision.Country,
+			GeoDecision:     geoDecision.Result,
+			PurchaseTime:    time.Now(),
+			CreatedAt:       time.Now(),
+			UpdatedAt:       time.Now(),
 		}
 
 		// Log purchase attempt
@@ -190,9 +241,9 @@ func (s *TicketPurchaseService) PurchaseTicket(ctx context.Context, params Purch
 			return common.Hash{}, utils.NewInternalError("Failed to connect to LOTToken contract", err)
 		}
 
-		// Call Buy function on token contract
+		// Sign the Buy call without sending it, so the transaction is saved on the intent before it can be mined
 		tx, err := tokenContract.Buy(
-			blockchain.Auth,
+			blockchain.SignOnlyAuth(),
 			common.HexToAddress(lottery.ContractAddress),
 			amount,
 			targets,
@@ -202,26 +253,35 @@ func (s *TicketPurchaseService) PurchaseTicket(ctx context.Context, params Purch
 				"error", err,
 				"amount", amount.String(),
 				"total_price", totalPrice.String())
-			if tx != nil {
-				return tx.Hash(), utils.NewInternalError("Failed to buy ticket", err)
-			}
 			return common.Hash{}, utils.NewInternalError("Failed to buy ticket", err)
 		}
+		prizePoolIncrease := float64(totalPrice.Int64()) / 1e18 // Convert wei to ETH for prize pool
+		payload := outbox.TicketPurchasePayload{Ticket: ticket, PrizePoolIncrease: prizePoolIncrease}
+		if err := outboxService.MarkSubmitted(ctx, intent.IntentID, tx, payload); err != nil {
+			return common.Hash{}, blockchain.NonRetryable(err)
+		}
+		if err := blockchain.Client.SendTransaction(ctx, tx); err != nil {
+			// The node may have received it anyway, the recovery worker broadcasts the saved transaction or fails the intent
+			utils.Logger.Error("Failed to send ticket purchase", "tx_hash", tx.Hash().Hex(), "error", err)
+			return tx.Hash(), blockchain.NonRetryable(utils.NewInternalError("Failed to buy ticket", err))
+		}
 
 		// Wait for transaction confirmation
 		receipt, err := bind.WaitMined(ctx, blockchain.Client, tx)
 		if err != nil {
+			// The purchase may still be mined, leave the intent to the recovery worker instead of buying again
 			utils.Logger.Error("Transaction failed", "tx_hash", tx.Hash().Hex(), "error", err)
-			return tx.Hash(), utils.NewInternalError("Transaction failed", err)
+			return tx.Hash(), blockchain.NonRetryable(utils.NewInternalError("Transaction failed", err))
 		}
 		if receipt.Status != 1 {
 			utils.Logger.Error("Transaction failed", "tx_hash", tx.Hash().Hex(), "status", receipt.Status)
+			outboxService.MarkFailed(ctx, intent.IntentID, errors.New("purchase transaction reverted"))
 			return tx.Hash(), utils.NewInternalError("Transaction failed", nil)
 		}
 
 		// Update ticket and issue
 		ticket.TransactionHash = tx.Hash().Hex()
-		issue.PrizePool += float64(totalPrice.Int64()) / 1e18 // Convert wei to ETH for prize pool
+		issue.PrizePool += prizePoolIncrease
 
 		// Save to database within a transaction
 		err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
@@ -233,22 +293,44 @@ func (s *TicketPurchaseService) PurchaseTicket(ctx context.Context, params Purch
 				utils.Logger.Error("Failed to save ticket to database", "error", err)
 				return utils.NewInternalError("Failed to save ticket to database", err)
 			}
-			return nil
+			return outboxService.Complete(tx, intent.IntentID)
 		})
 		if err != nil {
-			return common.Hash{}, err
+			// The purchase is mined, the recovery worker saves the ticket from the stored intent
+			return tx.Hash(), blockchain.NonRetryable(err)
 		}
 
 		utils.Logger.Info("Ticket purchased successfully",
 			"ticket_id", ticket.TicketID,
 			"tx_hash", tx.Hash().Hex())
+		events.Publish(events.Event{
+			Type:      events.TypeTicketSold,
+			LotteryID: issue.LotteryID,
+			IssueID:   issue.IssueID,
+			Data: events.TicketSoldData{
+				TicketID:       ticket.TicketID,
+				PurchaseAmount: ticket.PurchaseAmount,
+				PrizePool:      issue.PrizePool,
+			},
+		})
+		notification.NewNotificationService(s.db).NotifyAsync(ticket.BuyerAddress, notification.TemplateTicketReceipt, map[string]interface{}{
+			"TicketID":    ticket.TicketID,
+			"TicketName":  lottery.TicketName,
+			"IssueNumber": issue.IssueNumber,
+			"BetContent":  ticket.BetContent,
+			"Amount":      ticket.PurchaseAmount,
+			"TxHash":      ticket.TransactionHash,
+			"DrawTime":    issue.DrawTime.UTC().Format(time.RFC1123),
+		})
 		return tx.Hash(), nil
 	}
 
 	txHash, err := blockchain.WithBlockchain(ctx, data, executeTx)
 	if err != nil {
+		outboxService.FailPending(ctx, intent.IntentID, err)
 		return nil, common.Hash{}, err
 	}
+	account.InvalidateAccountSummary(ticket.BuyerAddress)
 
 	return &ticket, txHash, nil
 }
@@ -4,9 +4,12 @@ package services
 import (
 	"backend/db"
 	"backend/models"
+	"backend/services/document"
+	"backend/services/kyc"
+	"backend/services/screening"
 	"backend/utils"
+	"context"
 	"errors"
-	"time"
 
 	"gorm.io/gorm"
 )
@@ -100,6 +103,15 @@ func GetCustomerByAddress(customerAddress string) (*models.Customer, error) {
 
 // CreateCustomer 创建用户，仅插入 customers 和 kyc_data 表
 func CreateCustomer(customer *models.Customer) error {
+	// 制裁名单筛查：钱包地址或姓名和出生日期命中时拒绝注册，命中记录在事务外保存，供合规人员处理
+	subject := screening.Subject{Address: customer.CustomerAddress, Name: customer.KYCData.Name}
+	if !customer.KYCData.BirthDate.IsZero() {
+		subject.BirthDate = &customer.KYCData.BirthDate
+	}
+	if err := screening.NewScreeningService(db.DB).Screen(context.Background(), subject); err != nil {
+		return err
+	}
+
 	// 使用事务确保数据一致性
 	tx := db.DB.Begin()
 	defer func() {
@@ -120,6 +132,16 @@ func CreateCustomer(customer *models.Customer) error {
 			tx.Rollback()
 			return err
 		}
+		// 认领上传的证件，证件键只能被一个用户使用
+		if err := document.ClaimDocument(tx, customer.KYCData.FilePath, customer.CustomerAddress, ""); err != nil {
+			tx.Rollback()
+			return err
+		}
+		// 进入 KYC 审核队列
+		if err := kyc.Submit(tx, customer.KYCData); err != nil {
+			tx.Rollback()
+			return err
+		}
 	}
 
 	// 不插入 KYCVerifications，留给验证流程处理
@@ -127,40 +149,20 @@ func CreateCustomer(customer *models.Customer) error {
 }
 
 // VerifyCustomer 验证用户 KYC 信息
-func VerifyCustomer(verification *models.KYCVerificationHistory) error {
-	// 插入验证记录（无论 Approved 还是 Rejected 都会插入）
-	if err := db.DB.Create(verification).Error; err != nil {
-		return err
-	}
-
-	// 查询用户
-	var customer models.Customer
-	if err := db.DB.Where("customer_address = ?", verification.CustomerAddress).First(&customer).Error; err != nil {
-		return err
+//
+// 兼容旧的单步审核接口：Approved 和 Rejected 分别作为审核人员的通过和拒绝进入审核流程，
+// 高风险用户第一次通过后需要另一名审核人员再次通过
+func VerifyCustomer(verification *models.KYCVerificationHistory) (*models.KYCReview, error) {
+	service := kyc.NewKYCReviewService(db.DB)
+	ctx := context.Background()
+	switch verification.VerifyStatus {
+	case models.KYCVerifyStatusApproved:
+		return service.Approve(ctx, verification.CustomerAddress, verification.VerifierAddress, verification.Comments)
+	case models.KYCVerifyStatusRejected:
+		return service.Reject(ctx, verification.CustomerAddress, verification.VerifierAddress, verification.Comments)
 	}
-	utils.Logger.Info("VerifyCustomer'customer from database , ", customer)
-
-	// 根据验证状态更新用户记录
-	if verification.VerifyStatus == "Approved" {
-		// 验证通过，更新 Customer 记录
-		updates := map[string]interface{}{
-			"is_verified":       true,
-			"verifier_address":  verification.VerifierAddress,
-			"verification_time": verification.VerificationDate,
-			"role_id":           2, // 分配 normal_user 角色
-			"assigned_date":     time.Now(),
-		}
-		// 明确指定更新条件
-		if err := db.DB.Model(&customer).Where("customer_address = ?", customer.CustomerAddress).Updates(updates).Error; err != nil {
-			return err
-		}
-	} else if verification.VerifyStatus != "Rejected" {
-		// 如果 verify_status 既不是 Approved 也不是 Rejected，返回错误
-		return errors.New("invalid verify_status, must be 'Approved' or 'Rejected'")
-	}
-
-	// Rejected 状态下无需更新 Customer 表，仅插入验证记录
-	return nil
+	// 如果 verify_status 既不是 Approved 也不是 Rejected，返回错误
+	return nil, utils.NewBadRequestError("invalid verify_status, must be 'Approved' or 'Rejected'", nil)
 }
 
 // GetRoleList 获取角色列表
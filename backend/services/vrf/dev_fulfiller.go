@@ -0,0 +1,195 @@
+package vrf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"backend/blockchain"
+	lotteryBlockchain "backend/blockchain/lottery"
+	"backend/config"
+	"backend/utils"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// DevFulfillerLookbackBlocks is how far back the fulfiller looks for requests on startup,
+// requests already fulfilled are skipped because fulfilling them again reverts
+const DevFulfillerLookbackBlocks = 1000
+
+// DeterministicWords derives the random words for a request from the seed and the request ID
+//
+// Word i is keccak256(seed || requestId || i) with requestId and i left padded to 32 bytes, so the same
+// seed replays the same draws and anyone knowing the seed can recompute them.
+func DeterministicWords(seed string, requestID *big.Int, numWords uint32) []*big.Int {
+	words := make([]*big.Int, 0, numWords)
+	for i := uint32(0); i < numWords; i++ {
+		hash := crypto.Keccak256(
+			[]byte(seed),
+			common.LeftPadBytes(requestID.Bytes(), 32),
+			common.LeftPadBytes(big.NewInt(int64(i)).Bytes(), 32),
+		)
+		words = append(words, new(big.Int).SetBytes(hash))
+	}
+	return words
+}
+
+// IsRevert reports whether a call failed because the EVM reverted, rather than because the node could not be reached
+//
+// Geth attaches the revert data to the error; other nodes only report it in the message.
+func IsRevert(err error) bool {
+	if err == nil {
+		return false
+	}
+	var dataErr rpc.DataError
+	if errors.As(err, &dataErr) {
+		return true
+	}
+	return strings.Contains(strings.ToLower(err.Error()), "revert")
+}
+
+// DevFulfiller stands in for the VRF oracle on development chains
+//
+// It polls the coordinator for RandomWordsRequested events and answers each request through
+// CallFullfillRandomWords with DeterministicWords, which completes the SimpleRollout draw.
+type DevFulfiller struct {
+	client      *ethclient.Client
+	address     common.Address
+	coordinator *lotteryBlockchain.VRFCoordinatorV2
+	seed        string
+	nextBlock   uint64
+}
+
+// NewDevFulfiller creates a new DevFulfiller for the coordinator at address
+func NewDevFulfiller(client *ethclient.Client, address common.Address, seed string) (*DevFulfiller, error) {
+	coordinator, err := lotteryBlockchain.NewVRFCoordinatorV2(address, client)
+	if err != nil {
+		return nil, utils.NewServiceError("failed to initialize VRF coordinator", err)
+	}
+	return &DevFulfiller{client: client, address: address, coordinator: coordinator, seed: seed}, nil
+}
+
+// StartDevFulfiller runs the fulfiller in the background when DEV_VRF_ENABLED is set
+//
+// Never enable it against a chain with a real VRF oracle: the words are predictable by design.
+func StartDevFulfiller(ctx context.Context, client *ethclient.Client) {
+	if !config.AppConfig.DevVRFEnabled {
+		return
+	}
+	if !common.IsHexAddress(config.AppConfig.DevVRFCoordinatorAddress) {
+		utils.Logger.Error("DEV_VRF_ENABLED is set but DEV_VRF_COORDINATOR_ADDRESS is not a valid address", "address", config.AppConfig.DevVRFCoordinatorAddress)
+		return
+	}
+	fulfiller, err := NewDevFulfiller(client, common.HexToAddress(config.AppConfig.DevVRFCoordinatorAddress), config.AppConfig.DevVRFSeed)
+	if err != nil {
+		utils.Logger.Error("Failed to start dev VRF fulfiller", "error", err)
+		return
+	}
+	utils.Logger.Warn("Dev VRF fulfiller enabled, random words are deterministic", "coordinator", fulfiller.address.Hex())
+
+	interval := time.Duration(config.AppConfig.DevVRFPollInterval) * time.Second
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			if fulfilled, err := fulfiller.FulfillPending(ctx); err != nil {
+				utils.Logger.Error("Dev VRF fulfiller run failed", "error", err)
+			} else if fulfilled > 0 {
+				utils.Logger.Info("Dev VRF fulfiller answered requests", "count", fulfilled)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// FulfillPending answers the RandomWordsRequested events emitted since the last run
+//
+// Returns:
+//   - int: Number of requests fulfilled
+//   - error: Query or fulfilment error, the block of a failed request is read again on the next run
+func (f *DevFulfiller) FulfillPending(ctx context.Context) (int, error) {
+	head, err := f.client.BlockNumber(ctx)
+	if err != nil {
+		return 0, utils.NewServiceError("failed to get latest block number", err)
+	}
+	if f.nextBlock == 0 && head > DevFulfillerLookbackBlocks {
+		f.nextBlock = head - DevFulfillerLookbackBlocks
+	}
+	if f.nextBlock > head {
+		return 0, nil
+	}
+
+	iterator, err := f.coordinator.FilterRandomWordsRequested(&bind.FilterOpts{Context: ctx, Start: f.nextBlock, End: &head})
+	if err != nil {
+		return 0, utils.NewServiceError("failed to filter RandomWordsRequested events", err)
+	}
+	defer iterator.Close()
+
+	fulfilled := 0
+	for iterator.Next() {
+		event := iterator.Event
+		if err := f.fulfill(ctx, event.RequestId, event.NumWords); err != nil {
+			// Leave the block unprocessed so the request is retried on the next run
+			f.nextBlock = event.Raw.BlockNumber
+			return fulfilled, utils.NewServiceError(fmt.Sprintf("failed to fulfil VRF request %s", event.RequestId), err)
+		}
+		fulfilled++
+	}
+	if err := iterator.Error(); err != nil {
+		return fulfilled, utils.NewServiceError("failed to iterate RandomWordsRequested events", err)
+	}
+	f.nextBlock = head + 1
+	return fulfilled, nil
+}
+
+// fulfill answers one request, requests the coordinator reverts are considered already fulfilled
+func (f *DevFulfiller) fulfill(ctx context.Context, requestID *big.Int, numWords uint32) error {
+	abi, err := lotteryBlockchain.VRFCoordinatorV2MetaData.GetAbi()
+	if err != nil {
+		return err
+	}
+	words := DeterministicWords(f.seed, requestID, numWords)
+	data, err := abi.Pack("CallFullfillRandomWords", requestID, words)
+	if err != nil {
+		return fmt.Errorf("failed to pack CallFullfillRandomWords: %v", err)
+	}
+	if _, err := f.client.CallContract(ctx, ethereum.CallMsg{From: blockchain.Auth.From, To: &f.address, Data: data}, nil); err != nil {
+		if !IsRevert(err) {
+			return fmt.Errorf("failed to simulate fulfilment: %w", err)
+		}
+		utils.Logger.Info("Skipping VRF request the coordinator rejects, likely already fulfilled", "request_id", requestID, "error", err)
+		return nil
+	}
+
+	txHash, err := blockchain.WithBlockchain(ctx, data, func() (common.Hash, error) {
+		tx, err := f.coordinator.CallFullfillRandomWords(blockchain.Auth, requestID, words)
+		if err != nil {
+			return common.Hash{}, err
+		}
+		receipt, err := bind.WaitMined(ctx, f.client, tx)
+		if err != nil {
+			return tx.Hash(), blockchain.NonRetryable(fmt.Errorf("failed to wait for fulfilment: %v", err))
+		}
+		if receipt.Status != 1 {
+			return tx.Hash(), blockchain.NonRetryable(fmt.Errorf("fulfilment reverted"))
+		}
+		return tx.Hash(), nil
+	})
+	if err != nil {
+		return err
+	}
+	utils.Logger.Info("Fulfilled VRF request", "request_id", requestID, "words", words, "tx_hash", txHash.Hex())
+	return nil
+}
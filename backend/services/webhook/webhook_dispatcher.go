@@ -0,0 +1,320 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"backend/config"
+	"backend/models"
+	"backend/utils"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// SignatureHeader carries the timestamp and HMAC-SHA256 signature of a delivery
+	SignatureHeader = "X-Lottery-Signature"
+
+	backoffBase     = 30 * time.Second
+	backoffMax      = 6 * time.Hour
+	dispatchBatch   = 50
+	dispatchLease   = 2 * time.Minute // A claimed delivery is retried after the lease if the worker dies
+	salesCloseLimit = 24 * time.Hour  // Issues whose sales closed longer ago are not announced
+)
+
+// SignPayload returns the signature header value of a body: t=<unix seconds>,v1=<hex HMAC-SHA256 of "<t>.<body>">
+func SignPayload(secret string, timestamp time.Time, body []byte) string {
+	t := strconv.FormatInt(timestamp.Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(t))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "t=" + t + ",v1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifySignature checks a signature header against a body, rejecting signatures older than tolerance
+func VerifySignature(secret, header string, body []byte, now time.Time, tolerance time.Duration) bool {
+	var t, v1 string
+	for _, part := range strings.Split(header, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "t":
+			t = value
+		case "v1":
+			v1 = value
+		}
+	}
+	seconds, err := strconv.ParseInt(t, 10, 64)
+	if err != nil || v1 == "" {
+		return false
+	}
+	timestamp := time.Unix(seconds, 0)
+	if tolerance > 0 && (now.Sub(timestamp) > tolerance || timestamp.Sub(now) > tolerance) {
+		return false
+	}
+	expected := SignPayload(secret, timestamp, body)
+	return hmac.Equal([]byte(expected), []byte("t="+t+",v1="+v1))
+}
+
+// BackoffDelay returns the delay before retrying after the given number of failed attempts,
+// doubling from 30 seconds up to 6 hours
+func BackoffDelay(attempts int) time.Duration {
+	if attempts < 1 {
+		return 0
+	}
+	delay := backoffBase
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= backoffMax {
+			return backoffMax
+		}
+	}
+	return delay
+}
+
+// Dispatcher posts pending deliveries to their subscribers
+type Dispatcher struct {
+	db          *gorm.DB
+	client      *http.Client
+	maxAttempts int
+}
+
+// NewDispatcher creates a dispatcher using the WEBHOOK_* configuration
+func NewDispatcher(db *gorm.DB) *Dispatcher {
+	return &Dispatcher{
+		db:          db,
+		client:      newDeliveryClient(time.Duration(config.AppConfig.WebhookTimeoutSeconds) * time.Second),
+		maxAttempts: config.AppConfig.WebhookMaxAttempts,
+	}
+}
+
+// EnqueueClosedSales enqueues issue.sales_closed for issues on sale whose sale end time has passed
+//
+// Deliveries are deduplicated by event key, so running it repeatedly is safe.
+func (d *Dispatcher) EnqueueClosedSales(ctx context.Context) error {
+	now := time.Now()
+	var issues []models.LotteryIssue
+	if err := d.db.WithContext(ctx).
+		Where("status = ? AND sale_end_time <= ? AND sale_end_time > ?", models.IssueStatusPending, now, now.Add(-salesCloseLimit)).
+		Find(&issues).Error; err != nil {
+		return utils.NewInternalError("Failed to fetch issues with closed sales", err)
+	}
+	for i := range issues {
+		if err := EnqueueSalesClosed(d.db.WithContext(ctx), &issues[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// EnqueueSalesClosed enqueues the issue.sales_closed event of an issue
+func EnqueueSalesClosed(tx *gorm.DB, issue *models.LotteryIssue) error {
+	return Enqueue(tx, models.WebhookEventIssueSalesClosed, models.WebhookEventIssueSalesClosed+":"+issue.IssueID, issue.LotteryID, NewIssueData(issue))
+}
+
+// DispatchDue claims the deliveries that are due and posts them, returns the number of deliveries attempted
+func (d *Dispatcher) DispatchDue(ctx context.Context) (int, error) {
+	var deliveries []models.WebhookDelivery
+	err := d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", models.WebhookDeliveryStatusPending, now).
+			Order("next_attempt_at").
+			Limit(dispatchBatch).
+			Find(&deliveries).Error; err != nil {
+			return err
+		}
+		if len(deliveries) == 0 {
+			return nil
+		}
+		ids := make([]string, len(deliveries))
+		for i, delivery := range deliveries {
+			ids[i] = delivery.DeliveryID
+		}
+		return tx.Model(&models.WebhookDelivery{}).
+			Where("delivery_id IN ?", ids).
+			Update("next_attempt_at", now.Add(dispatchLease)).Error
+	})
+	if err != nil {
+		return 0, utils.NewInternalError("Failed to claim webhook deliveries", err)
+	}
+
+	subscriptions := make(map[string]*models.WebhookSubscription)
+	for i := range deliveries {
+		delivery := &deliveries[i]
+		subscription, ok := subscriptions[delivery.SubscriptionID]
+		if !ok {
+			var sub models.WebhookSubscription
+			if err := d.db.WithContext(ctx).Where("subscription_id = ?", delivery.SubscriptionID).First(&sub).Error; err != nil {
+				utils.Logger.Error("Failed to fetch webhook subscription", "subscription_id", delivery.SubscriptionID, "error", err)
+				continue
+			}
+			subscription = &sub
+			subscriptions[delivery.SubscriptionID] = subscription
+		}
+		d.deliver(ctx, subscription, delivery)
+	}
+	return len(deliveries), nil
+}
+
+// deliver posts one delivery and records the attempt, scheduling a retry or dead-lettering it on failure
+func (d *Dispatcher) deliver(ctx context.Context, subscription *models.WebhookSubscription, delivery *models.WebhookDelivery) {
+	if !subscription.Active {
+		d.db.WithContext(ctx).Model(delivery).Updates(map[string]interface{}{
+			"status":     models.WebhookDeliveryStatusDead,
+			"last_error": "subscription deactivated",
+			"updated_at": time.Now(),
+		})
+		return
+	}
+
+	attempt := models.WebhookDeliveryAttempt{
+		AttemptID:  uuid.NewString(),
+		DeliveryID: delivery.DeliveryID,
+		Attempt:    delivery.Attempts + 1,
+	}
+	start := time.Now()
+	statusCode, err := d.post(ctx, subscription, delivery)
+	attempt.DurationMs = time.Since(start).Milliseconds()
+	attempt.StatusCode = statusCode
+	if err == nil && (statusCode < 200 || statusCode > 299) {
+		err = fmt.Errorf("unexpected status code %d", statusCode)
+	}
+	if err != nil {
+		attempt.Error = truncate(err.Error(), 1000)
+	}
+	attempt.CreatedAt = time.Now()
+
+	txErr := d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&attempt).Error; err != nil {
+			return err
+		}
+		now := time.Now()
+		updates := map[string]interface{}{
+			"attempts":         attempt.Attempt,
+			"last_status_code": statusCode,
+			"last_error":       attempt.Error,
+			"updated_at":       now,
+		}
+		switch {
+		case err == nil:
+			updates["status"] = models.WebhookDeliveryStatusDelivered
+			updates["delivered_at"] = now
+		case attempt.Attempt >= d.maxAttempts:
+			updates["status"] = models.WebhookDeliveryStatusDead
+			letter := models.WebhookDeadLetter{
+				DeliveryID:     delivery.DeliveryID,
+				SubscriptionID: delivery.SubscriptionID,
+				EventType:      delivery.EventType,
+				Payload:        delivery.Payload,
+				Attempts:       attempt.Attempt,
+				LastError:      attempt.Error,
+				CreatedAt:      now,
+			}
+			if err := tx.Clauses(clause.OnConflict{
+				Columns: []clause.Column{{Name: "delivery_id"}},
+				DoUpdates: clause.Assignments(map[string]interface{}{
+					"attempts":    letter.Attempts,
+					"last_error":  letter.LastError,
+					"replayed_at": nil,
+					"created_at":  now,
+				}),
+			}).Create(&letter).Error; err != nil {
+				return err
+			}
+		default:
+			updates["next_attempt_at"] = now.Add(BackoffDelay(attempt.Attempt))
+		}
+		return tx.Model(&models.WebhookDelivery{}).Where("delivery_id = ?", delivery.DeliveryID).Updates(updates).Error
+	})
+	if txErr != nil {
+		utils.Logger.Error("Failed to record webhook delivery attempt", "delivery_id", delivery.DeliveryID, "error", txErr)
+		return
+	}
+	if err != nil {
+		utils.Logger.Warn("Webhook delivery failed",
+			"delivery_id", delivery.DeliveryID,
+			"subscription_id", subscription.SubscriptionID,
+			"attempt", attempt.Attempt,
+			"error", err)
+	}
+}
+
+// post sends the signed payload of a delivery, returning the status code
+//
+// The response body is discarded: it is controlled by the subscriber and is never stored or shown.
+func (d *Dispatcher) post(ctx context.Context, subscription *models.WebhookSubscription, delivery *models.WebhookDelivery) (int, error) {
+	body := []byte(delivery.Payload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "lottery-webhooks/1.0")
+	req.Header.Set("X-Lottery-Event-ID", delivery.EventID)
+	req.Header.Set("X-Lottery-Event-Type", delivery.EventType)
+	req.Header.Set("X-Lottery-Delivery-ID", delivery.DeliveryID)
+	req.Header.Set(SignatureHeader, SignPayload(subscription.Secret, time.Now(), body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	return resp.StatusCode, nil
+}
+
+// truncate cuts s to at most n bytes
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n]
+}
+
+// StartDispatcher periodically announces closed sales and posts due deliveries
+//
+// Deliveries are claimed with SKIP LOCKED, so several processes can run the dispatcher.
+func StartDispatcher(ctx context.Context, db *gorm.DB) {
+	dispatcher := NewDispatcher(db)
+	interval := time.Duration(config.AppConfig.WebhookDispatchInterval) * time.Second
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			if err := dispatcher.EnqueueClosedSales(ctx); err != nil {
+				utils.Logger.Error("Failed to enqueue closed sales webhooks", "error", err)
+			}
+			for {
+				n, err := dispatcher.DispatchDue(ctx)
+				if err != nil {
+					utils.Logger.Error("Webhook dispatch failed", "error", err)
+				}
+				if err != nil || n < dispatchBatch {
+					break
+				}
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	utils.Logger.Info("Webhook dispatcher started", "interval", interval.String(), "max_attempts", dispatcher.maxAttempts)
+}
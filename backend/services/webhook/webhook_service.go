@@ -0,0 +1,388 @@
+package webhook
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"backend/models"
+	"backend/utils"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// EventTypes are the event types a subscription can subscribe to
+var EventTypes = []string{
+	models.WebhookEventIssueOpened,
+	models.WebhookEventIssueSalesClosed,
+	models.WebhookEventIssueDrawn,
+	models.WebhookEventWinnerRecorded,
+}
+
+// Envelope is the JSON body posted to subscribers
+type Envelope struct {
+	ID        string      `json:"id"`
+	Type      string      `json:"type"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+// IssueData is the data of issue.* events
+type IssueData struct {
+	IssueID        string    `json:"issue_id"`
+	LotteryID      string    `json:"lottery_id"`
+	IssueNumber    string    `json:"issue_number"`
+	Status         string    `json:"status"`
+	SaleEndTime    time.Time `json:"sale_end_time"`
+	DrawTime       time.Time `json:"draw_time"`
+	PrizePool      float64   `json:"prize_pool"`
+	WinningNumbers string    `json:"winning_numbers,omitempty"`
+	DrawTxHash     string    `json:"draw_tx_hash,omitempty"`
+}
+
+// WinnerData is the data of winner.recorded events
+type WinnerData struct {
+	WinnerID     string  `json:"winner_id"`
+	IssueID      string  `json:"issue_id"`
+	LotteryID    string  `json:"lottery_id"`
+	TicketID     string  `json:"ticket_id"`
+	Address      string  `json:"address"`
+	PrizeLevel   string  `json:"prize_level"`
+	GrossAmount  float64 `json:"gross_amount"`
+	NetAmount    float64 `json:"net_amount"`
+	PayoutStatus string  `json:"payout_status"`
+}
+
+// NewIssueData builds the event data of an issue
+func NewIssueData(issue *models.LotteryIssue) IssueData {
+	return IssueData{
+		IssueID:        issue.IssueID,
+		LotteryID:      issue.LotteryID,
+		IssueNumber:    issue.IssueNumber,
+		Status:         issue.Status,
+		SaleEndTime:    issue.SaleEndTime,
+		DrawTime:       issue.DrawTime,
+		PrizePool:      issue.PrizePool,
+		WinningNumbers: issue.WinningNumbers,
+		DrawTxHash:     issue.DrawTxHash,
+	}
+}
+
+// NewWinnerData builds the event data of a winner
+func NewWinnerData(lotteryID string, winner *models.Winner) WinnerData {
+	return WinnerData{
+		WinnerID:     winner.WinnerID,
+		IssueID:      winner.IssueID,
+		LotteryID:    lotteryID,
+		TicketID:     winner.TicketID,
+		Address:      winner.Address,
+		PrizeLevel:   winner.PrizeLevel,
+		GrossAmount:  winner.GrossAmount,
+		NetAmount:    winner.NetAmount,
+		PayoutStatus: winner.PayoutStatus,
+	}
+}
+
+// IsValidEventType reports whether the event type can be subscribed to
+func IsValidEventType(eventType string) bool {
+	for _, t := range EventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// SubscriptionMatches reports whether a subscription receives an event of a lottery
+func SubscriptionMatches(subscription *models.WebhookSubscription, eventType, lotteryID string) bool {
+	if !subscription.Active {
+		return false
+	}
+	if subscription.LotteryID != "" && subscription.LotteryID != lotteryID {
+		return false
+	}
+	for _, t := range strings.Split(subscription.EventTypes, ",") {
+		if strings.TrimSpace(t) == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// Enqueue records a delivery of an event for every matching subscription
+//
+// It is called inside the transaction that commits the business change, so an event is delivered if
+// and only if the change is committed. The event key deduplicates the event per subscription.
+func Enqueue(tx *gorm.DB, eventType, eventKey, lotteryID string, data interface{}) error {
+	var subscriptions []models.WebhookSubscription
+	if err := tx.Where("active = ?", true).Find(&subscriptions).Error; err != nil {
+		return utils.NewInternalError("Failed to fetch webhook subscriptions", err)
+	}
+
+	now := time.Now()
+	envelope := Envelope{ID: uuid.NewString(), Type: eventType, CreatedAt: now.UTC(), Data: data}
+	payload, err := json.Marshal(envelope)
+	if err != nil {
+		return utils.NewInternalError("Failed to encode webhook payload", err)
+	}
+
+	var deliveries []models.WebhookDelivery
+	for i := range subscriptions {
+		if !SubscriptionMatches(&subscriptions[i], eventType, lotteryID) {
+			continue
+		}
+		deliveries = append(deliveries, models.WebhookDelivery{
+			DeliveryID:     uuid.NewString(),
+			SubscriptionID: subscriptions[i].SubscriptionID,
+			EventID:        envelope.ID,
+			EventKey:       eventKey,
+			EventType:      eventType,
+			Payload:        string(payload),
+			Status:         models.WebhookDeliveryStatusPending,
+			NextAttemptAt:  now,
+			CreatedAt:      now,
+			UpdatedAt:      now,
+		})
+	}
+	if len(deliveries) == 0 {
+		return nil
+	}
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&deliveries).Error; err != nil {
+		return utils.NewInternalError("Failed to enqueue webhook deliveries", err)
+	}
+	return nil
+}
+
+// WebhookService manages webhook subscriptions, the delivery log and dead letters
+type WebhookService struct {
+	db *gorm.DB
+}
+
+// NewWebhookService creates a new WebhookService instance
+func NewWebhookService(db *gorm.DB) *WebhookService {
+	return &WebhookService{db: db}
+}
+
+// SubscriptionParams defines the parameters for creating a subscription
+type SubscriptionParams struct {
+	URL         string
+	EventTypes  []string
+	LotteryID   string
+	Description string
+}
+
+// CreatedSubscription is a new subscription with its signing secret, which is only returned once
+type CreatedSubscription struct {
+	models.WebhookSubscription
+	Secret string `json:"secret"`
+}
+
+// CreateSubscription registers a webhook endpoint and generates its signing secret
+//
+// Endpoints on loopback, private, link-local and metadata addresses are refused; the dispatcher checks again when it connects.
+func (s *WebhookService) CreateSubscription(ctx context.Context, params SubscriptionParams) (*CreatedSubscription, error) {
+	if err := ValidateEndpoint(ctx, params.URL); err != nil {
+		return nil, utils.NewBadRequestError("Invalid webhook URL", err)
+	}
+	if len(params.EventTypes) == 0 {
+		return nil, utils.NewBadRequestError("At least one event type is required", nil)
+	}
+	seen := make(map[string]bool)
+	types := make([]string, 0, len(params.EventTypes))
+	for _, eventType := range params.EventTypes {
+		eventType = strings.TrimSpace(eventType)
+		if !IsValidEventType(eventType) {
+			return nil, utils.NewBadRequestError("Unknown event type: "+eventType, nil)
+		}
+		if !seen[eventType] {
+			seen[eventType] = true
+			types = append(types, eventType)
+		}
+	}
+	if params.LotteryID != "" {
+		var count int64
+		if err := s.db.WithContext(ctx).Model(&models.Lottery{}).Where("lottery_id = ?", params.LotteryID).Count(&count).Error; err != nil {
+			return nil, utils.NewInternalError("Failed to check lottery", err)
+		}
+		if count == 0 {
+			return nil, utils.NewBadRequestError("Lottery not found", nil)
+		}
+	}
+
+	secret, err := newSecret()
+	if err != nil {
+		return nil, utils.NewInternalError("Failed to generate webhook secret", err)
+	}
+	now := time.Now()
+	subscription := models.WebhookSubscription{
+		SubscriptionID: uuid.NewString(),
+		URL:            params.URL,
+		Secret:         secret,
+		EventTypes:     strings.Join(types, ","),
+		LotteryID:      params.LotteryID,
+		Active:         true,
+		Description:    params.Description,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	if err := s.db.WithContext(ctx).Create(&subscription).Error; err != nil {
+		return nil, utils.NewInternalError("Failed to save webhook subscription", err)
+	}
+	utils.Logger.Info("Created webhook subscription", "subscription_id", subscription.SubscriptionID, "url", subscription.URL, "event_types", subscription.EventTypes)
+	return &CreatedSubscription{WebhookSubscription: subscription, Secret: secret}, nil
+}
+
+// ListSubscriptions returns all subscriptions, newest first
+func (s *WebhookService) ListSubscriptions(ctx context.Context) ([]models.WebhookSubscription, error) {
+	var subscriptions []models.WebhookSubscription
+	if err := s.db.WithContext(ctx).Order("created_at DESC").Find(&subscriptions).Error; err != nil {
+		return nil, utils.NewInternalError("Failed to fetch webhook subscriptions", err)
+	}
+	return subscriptions, nil
+}
+
+// DeactivateSubscription stops a subscription, its pending deliveries are abandoned
+func (s *WebhookService) DeactivateSubscription(ctx context.Context, subscriptionID string) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.WebhookSubscription{}).
+			Where("subscription_id = ? AND active = ?", subscriptionID, true).
+			Updates(map[string]interface{}{"active": false, "updated_at": time.Now()})
+		if result.Error != nil {
+			return utils.NewInternalError("Failed to deactivate webhook subscription", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return utils.NewBadRequestError("Active webhook subscription not found", gorm.ErrRecordNotFound)
+		}
+		if err := tx.Model(&models.WebhookDelivery{}).
+			Where("subscription_id = ? AND status = ?", subscriptionID, models.WebhookDeliveryStatusPending).
+			Updates(map[string]interface{}{
+				"status":     models.WebhookDeliveryStatusDead,
+				"last_error": "subscription deactivated",
+				"updated_at": time.Now(),
+			}).Error; err != nil {
+			return utils.NewInternalError("Failed to abandon pending deliveries", err)
+		}
+		return nil
+	})
+}
+
+// DeliveryLog is a delivery with its attempts
+type DeliveryLog struct {
+	models.WebhookDelivery
+	AttemptLog []models.WebhookDeliveryAttempt `json:"attempt_log"`
+}
+
+// DeliveryListResult is a page of a subscription's delivery log
+type DeliveryListResult struct {
+	Total      int64         `json:"total"`
+	Page       int           `json:"page"`
+	PageSize   int           `json:"page_size"`
+	Deliveries []DeliveryLog `json:"deliveries"`
+}
+
+// ListDeliveries returns the delivery log of a subscription, newest first, optionally filtered by status
+func (s *WebhookService) ListDeliveries(ctx context.Context, subscriptionID, status string, page, pageSize int) (*DeliveryListResult, error) {
+	query := s.db.WithContext(ctx).Model(&models.WebhookDelivery{}).Where("subscription_id = ?", subscriptionID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, utils.NewInternalError("Failed to count webhook deliveries", err)
+	}
+
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = 20
+	}
+	if pageSize > 100 {
+		pageSize = 100
+	}
+	var deliveries []models.WebhookDelivery
+	if err := query.Order("created_at DESC").Offset((page - 1) * pageSize).Limit(pageSize).Find(&deliveries).Error; err != nil {
+		return nil, utils.NewInternalError("Failed to fetch webhook deliveries", err)
+	}
+
+	logs := make([]DeliveryLog, len(deliveries))
+	ids := make([]string, len(deliveries))
+	index := make(map[string]int, len(deliveries))
+	for i, delivery := range deliveries {
+		logs[i] = DeliveryLog{WebhookDelivery: delivery, AttemptLog: []models.WebhookDeliveryAttempt{}}
+		ids[i] = delivery.DeliveryID
+		index[delivery.DeliveryID] = i
+	}
+	if len(ids) > 0 {
+		var attempts []models.WebhookDeliveryAttempt
+		if err := s.db.WithContext(ctx).Where("delivery_id IN ?", ids).Order("attempt").Find(&attempts).Error; err != nil {
+			return nil, utils.NewInternalError("Failed to fetch webhook delivery attempts", err)
+		}
+		for _, attempt := range attempts {
+			i := index[attempt.DeliveryID]
+			logs[i].AttemptLog = append(logs[i].AttemptLog, attempt)
+		}
+	}
+	return &DeliveryListResult{Total: total, Page: page, PageSize: pageSize, Deliveries: logs}, nil
+}
+
+// ListDeadLetters returns the dead letters that have not been replayed, oldest first
+func (s *WebhookService) ListDeadLetters(ctx context.Context) ([]models.WebhookDeadLetter, error) {
+	var letters []models.WebhookDeadLetter
+	if err := s.db.WithContext(ctx).Where("replayed_at IS NULL").Order("created_at").Find(&letters).Error; err != nil {
+		return nil, utils.NewInternalError("Failed to fetch webhook dead letters", err)
+	}
+	return letters, nil
+}
+
+// ReplayDeadLetter puts a dead delivery back in the queue with a fresh retry budget
+func (s *WebhookService) ReplayDeadLetter(ctx context.Context, deliveryID string) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var letter models.WebhookDeadLetter
+		if err := tx.Where("delivery_id = ? AND replayed_at IS NULL", deliveryID).First(&letter).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return utils.NewBadRequestError("Dead letter not found or already replayed", err)
+			}
+			return utils.NewInternalError("Failed to fetch dead letter", err)
+		}
+		var subscription models.WebhookSubscription
+		if err := tx.Where("subscription_id = ?", letter.SubscriptionID).First(&subscription).Error; err != nil {
+			return utils.NewInternalError("Failed to fetch webhook subscription", err)
+		}
+		if !subscription.Active {
+			return utils.NewBadRequestError("Webhook subscription is deactivated", nil)
+		}
+
+		now := time.Now()
+		if err := tx.Model(&models.WebhookDelivery{}).
+			Where("delivery_id = ? AND status = ?", deliveryID, models.WebhookDeliveryStatusDead).
+			Updates(map[string]interface{}{
+				"status":          models.WebhookDeliveryStatusPending,
+				"attempts":        0,
+				"next_attempt_at": now,
+				"updated_at":      now,
+			}).Error; err != nil {
+			return utils.NewInternalError("Failed to requeue webhook delivery", err)
+		}
+		if err := tx.Model(&letter).Update("replayed_at", now).Error; err != nil {
+			return utils.NewInternalError("Failed to mark dead letter replayed", err)
+		}
+		utils.Logger.Info("Replaying webhook dead letter", "delivery_id", deliveryID, "subscription_id", letter.SubscriptionID)
+		return nil
+	})
+}
+
+// newSecret generates a random signing secret
+func newSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,89 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// sharedAddressSpace is the carrier-grade NAT range (RFC 6598), which is not routable on the internet
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// IsBlockedIP reports whether webhooks must not be posted to an address: loopback, private,
+// link-local (which includes the 169.254.169.254 cloud metadata endpoint), unspecified and multicast addresses
+func IsBlockedIP(ip net.IP) bool {
+	return ip.IsLoopback() ||
+		ip.IsPrivate() ||
+		ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() ||
+		ip.IsMulticast() ||
+		ip.IsUnspecified() ||
+		sharedAddressSpace.Contains(ip)
+}
+
+// ValidateEndpoint checks that a webhook URL is an absolute http(s) URL whose host resolves only to public addresses
+func ValidateEndpoint(ctx context.Context, rawURL string) error {
+	endpoint, err := url.Parse(rawURL)
+	if err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Hostname() == "" {
+		return fmt.Errorf("webhook URL must be an absolute http(s) URL")
+	}
+	host := endpoint.Hostname()
+	if strings.EqualFold(host, "localhost") || strings.HasSuffix(strings.ToLower(host), ".localhost") {
+		return fmt.Errorf("webhook URL must not point to localhost")
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		if IsBlockedIP(ip) {
+			return fmt.Errorf("webhook URL must not point to a private or reserved address")
+		}
+		return nil
+	}
+
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return fmt.Errorf("failed to resolve webhook host: %w", err)
+	}
+	for _, addr := range addrs {
+		if IsBlockedIP(addr.IP) {
+			return fmt.Errorf("webhook host resolves to a private or reserved address")
+		}
+	}
+	return nil
+}
+
+// dialControl refuses connections to blocked addresses
+//
+// It runs after name resolution for every connection, including redirects, so a host that resolves
+// to a public address when the subscription is created cannot be rebound to an internal one later.
+func dialControl(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || IsBlockedIP(ip) {
+		return fmt.Errorf("webhook delivery to %s is not allowed", host)
+	}
+	return nil
+}
+
+// newDeliveryClient returns an HTTP client that only connects to public addresses
+//
+// Proxies from the environment are not used, because the check would then apply to the proxy instead of the subscriber.
+func newDeliveryClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{Timeout: timeout, Control: dialControl}
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			Proxy:               nil,
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: timeout,
+			MaxIdleConnsPerHost: 2,
+		},
+	}
+}
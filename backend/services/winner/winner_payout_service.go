@@ -0,0 +1,369 @@
+package winner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+	"time"
+
+	"backend/blockchain"
+	lotteryBlockchain "backend/blockchain/lottery"
+	"backend/config"
+	"backend/models"
+	"backend/utils"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"gorm.io/gorm"
+)
+
+// tokenUnit is the number of token base units per token, prize amounts are stored in tokens
+var tokenUnit = new(big.Float).SetFloat64(1e18)
+
+// PayoutFailure is a prize transfer rolloutCallback could not make, taken from a RolloutCallbakTXFailed event
+type PayoutFailure struct {
+	Address common.Address
+	Amount  *big.Int // Token base units
+}
+
+// WinnerPayoutService tracks and retries prize payouts
+type WinnerPayoutService struct {
+	db *gorm.DB
+}
+
+// NewWinnerPayoutService creates a new WinnerPayoutService instance
+func NewWinnerPayoutService(db *gorm.DB) *WinnerPayoutService {
+	return &WinnerPayoutService{db: db}
+}
+
+// ApplyRolloutPayouts settles the PENDING winners of a draw from the outcome of its rolloutCallback
+//
+// The contract pays every first prize in rolloutCallback and emits RolloutCallbakTXFailed for each
+// transfer that fails. Winners of an address with a failure become FAILED and owe their share of the
+// failed amount, split by prize amount when the address holds several winning tickets; all other
+// pending winners are PAID by the callback transaction. Winners in any other status are returned as is.
+func ApplyRolloutPayouts(winners []models.Winner, failures []PayoutFailure, callbackTxHash string, paidAt time.Time) []models.Winner {
+	failed := make(map[string]*big.Int)
+	for _, failure := range failures {
+		key := strings.ToLower(failure.Address.Hex())
+		if failed[key] == nil {
+			failed[key] = new(big.Int)
+		}
+		failed[key].Add(failed[key], failure.Amount)
+	}
+
+	// Total prize amount and count of pending winners per failed address, to split the failed amount
+	totals := make(map[string]float64)
+	counts := make(map[string]int)
+	for _, winner := range winners {
+		key := strings.ToLower(winner.Address)
+		if winner.PayoutStatus == models.PayoutStatusPending && failed[key] != nil {
+			totals[key] += winner.PrizeAmount
+			counts[key]++
+		}
+	}
+
+	settled := make([]models.Winner, len(winners))
+	for i, winner := range winners {
+		settled[i] = winner
+		if winner.PayoutStatus != models.PayoutStatusPending {
+			continue
+		}
+		key := strings.ToLower(winner.Address)
+		if amount, ok := failed[key]; ok {
+			share := 1 / float64(counts[key])
+			if totals[key] > 0 {
+				share = winner.PrizeAmount / totals[key]
+			}
+			settled[i].PayoutStatus = models.PayoutStatusFailed
+			// The treasury pays the failed share net, so its tax is now withheld instead of owed
+			failedShare := TokensFromUnits(amount) * share
+			withheld := failedShare * winner.TaxRate
+			settled[i].PayoutAmount = failedShare - withheld
+			settled[i].WithheldAmount = winner.WithheldAmount + withheld
+			settled[i].TaxOwedAmount = math.Max(0, winner.TaxOwedAmount-withheld)
+			settled[i].NetAmount = winner.GrossAmount - settled[i].WithheldAmount
+			settled[i].PayoutError = "prize transfer failed in rolloutCallback"
+			continue
+		}
+		settled[i].PayoutStatus = models.PayoutStatusPaid
+		settled[i].ClaimTxHash = callbackTxHash
+		settled[i].PaidAt = &paidAt
+	}
+	return settled
+}
+
+// TokensFromUnits converts token base units to tokens
+func TokensFromUnits(units *big.Int) float64 {
+	tokens, _ := new(big.Float).Quo(new(big.Float).SetInt(units), tokenUnit).Float64()
+	return tokens
+}
+
+// UnitsFromTokens converts tokens to token base units, truncating below one unit
+func UnitsFromTokens(tokens float64) *big.Int {
+	units, _ := new(big.Float).Mul(big.NewFloat(tokens), tokenUnit).Int(nil)
+	return units
+}
+
+// RecordRolloutPayouts settles the pending winners of an issue from its rolloutCallback transaction
+func (s *WinnerPayoutService) RecordRolloutPayouts(ctx context.Context, issueID string, callbackTxHash common.Hash, failures []PayoutFailure) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var winners []models.Winner
+		if err := tx.Where("issue_id = ? AND payout_status = ?", issueID, models.PayoutStatusPending).Find(&winners).Error; err != nil {
+			return utils.NewInternalError("Failed to fetch pending winners", err)
+		}
+		now := time.Now()
+		for _, winner := range ApplyRolloutPayouts(winners, failures, callbackTxHash.Hex(), now) {
+			winner.UpdatedAt = now
+			if err := tx.Model(&models.Winner{}).Where("winner_id = ?", winner.WinnerID).Updates(map[string]interface{}{
+				"payout_status":   winner.PayoutStatus,
+				"payout_amount":   winner.PayoutAmount,
+				"payout_error":    winner.PayoutError,
+				"claim_tx_hash":   winner.ClaimTxHash,
+				"paid_at":         winner.PaidAt,
+				"withheld_amount": winner.WithheldAmount,
+				"net_amount":      winner.NetAmount,
+				"tax_owed_amount": winner.TaxOwedAmount,
+				"updated_at":      winner.UpdatedAt,
+			}).Error; err != nil {
+				return utils.NewInternalError("Failed to update winner payout", err)
+			}
+			if winner.PayoutStatus == models.PayoutStatusFailed {
+				utils.Logger.Warn("Prize transfer failed in rolloutCallback",
+					"issue_id", issueID,
+					"winner_id", winner.WinnerID,
+					"address", winner.Address,
+					"amount", winner.PayoutAmount)
+			}
+		}
+		return nil
+	})
+}
+
+// RetryPayout pays a FAILED or PENDING_MANUAL prize from the treasury, the admin account's token balance
+//
+// A SUBMITTED payout is first resolved from its transaction: a mined transfer marks it PAID, a reverted or
+// dropped one is sent again and a pending one is refused, so a prize is never transferred twice.
+//
+// Returns:
+//   - *models.Winner: The winner after the retry
+//   - common.Hash: The transfer transaction hash, empty when no transfer was sent
+//   - error: Validation, chain or database error
+func (s *WinnerPayoutService) RetryPayout(ctx context.Context, winnerID string) (*models.Winner, common.Hash, error) {
+	if err := blockchain.EnsureInitialized(); err != nil {
+		return nil, common.Hash{}, err
+	}
+
+	var winner models.Winner
+	if err := s.db.WithContext(ctx).Where("winner_id = ?", winnerID).First(&winner).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, common.Hash{}, utils.NewBadRequestError("Winner not found", err)
+		}
+		return nil, common.Hash{}, utils.NewInternalError("Failed to fetch winner", err)
+	}
+
+	switch winner.PayoutStatus {
+	case models.PayoutStatusFailed, models.PayoutStatusPendingManual:
+	case models.PayoutStatusSubmitted:
+		paid, err := s.resolveSubmitted(ctx, &winner)
+		if err != nil || paid {
+			return &winner, common.Hash{}, err
+		}
+	case models.PayoutStatusPaid:
+		return nil, common.Hash{}, utils.NewBadRequestError("Prize is already paid", nil)
+	default:
+		return nil, common.Hash{}, utils.NewBadRequestError(fmt.Sprintf("Payout is %s and cannot be retried yet", winner.PayoutStatus), nil)
+	}
+
+	amount := UnitsFromTokens(winner.PayoutAmount)
+	if amount.Sign() <= 0 {
+		return nil, common.Hash{}, utils.NewBadRequestError("Winner has no payout amount to retry", nil)
+	}
+	if !common.IsHexAddress(winner.Address) {
+		return nil, common.Hash{}, utils.NewBadRequestError("Winner address is not a valid address", nil)
+	}
+	to := common.HexToAddress(winner.Address)
+
+	token, err := blockchain.ConnectTokenContract(config.AppConfig.TokenContractAddress)
+	if err != nil {
+		return nil, common.Hash{}, utils.NewInternalError("Failed to connect to LOTToken contract", err)
+	}
+	balance, err := token.BalanceOf(&bind.CallOpts{Context: ctx}, blockchain.Auth.From)
+	if err != nil {
+		return nil, common.Hash{}, utils.NewInternalError("Failed to get treasury balance", err)
+	}
+	if balance.Cmp(amount) < 0 {
+		return nil, common.Hash{}, utils.NewBadRequestError(fmt.Sprintf("Treasury balance %s is lower than the payout %s", balance, amount), nil)
+	}
+
+	// Claim the payout so concurrent retries cannot both transfer
+	previous := winner.PayoutStatus
+	result := s.db.WithContext(ctx).Model(&models.Winner{}).
+		Where("winner_id = ? AND payout_status = ?", winner.WinnerID, previous).
+		Updates(map[string]interface{}{
+			"payout_status":   models.PayoutStatusSubmitted,
+			"payout_attempts": gorm.Expr("payout_attempts + 1"),
+			"claim_tx_hash":   "",
+			"updated_at":      time.Now(),
+		})
+	if result.Error != nil {
+		return nil, common.Hash{}, utils.NewInternalError("Failed to update winner payout", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, common.Hash{}, utils.NewBadRequestError("Payout is already being retried", nil)
+	}
+
+	abi, err := lotteryBlockchain.LOTTokenMetaData.GetAbi()
+	if err != nil {
+		return nil, common.Hash{}, utils.NewInternalError("Failed to load LOTToken ABI", err)
+	}
+	data, err := abi.Pack("transfer", to, amount)
+	if err != nil {
+		return nil, common.Hash{}, utils.NewInternalError("Failed to pack transfer", err)
+	}
+
+	txHash, err := blockchain.WithBlockchain(ctx, data, func() (common.Hash, error) {
+		tx, err := token.Transfer(blockchain.Auth, to, amount)
+		if err != nil {
+			return common.Hash{}, err
+		}
+		// Keep the hash before waiting, a later retry resolves the payout from it instead of paying again
+		if err := s.db.WithContext(ctx).Model(&models.Winner{}).Where("winner_id = ?", winner.WinnerID).
+			Update("claim_tx_hash", tx.Hash().Hex()).Error; err != nil {
+			return tx.Hash(), blockchain.NonRetryable(utils.NewInternalError("Failed to record payout transaction", err))
+		}
+		receipt, err := bind.WaitMined(ctx, blockchain.Client, tx)
+		if err != nil {
+			return tx.Hash(), blockchain.NonRetryable(utils.NewInternalError("Failed to confirm payout transaction", err))
+		}
+		if receipt.Status != 1 {
+			return tx.Hash(), blockchain.NonRetryable(utils.NewInternalError("Payout transaction reverted", nil))
+		}
+		return tx.Hash(), nil
+	})
+	if err != nil {
+		updates := map[string]interface{}{"payout_error": truncate(err.Error(), 255), "updated_at": time.Now()}
+		if txHash == (common.Hash{}) {
+			// Nothing was sent, the payout can be retried right away
+			updates["payout_status"] = previous
+		}
+		s.db.WithContext(ctx).Model(&models.Winner{}).Where("winner_id = ?", winner.WinnerID).Updates(updates)
+		utils.Logger.Error("Failed to retry payout", "winner_id", winner.WinnerID, "tx_hash", txHash.Hex(), "error", err)
+		return nil, txHash, err
+	}
+
+	if err := s.markPaid(ctx, &winner, txHash); err != nil {
+		return nil, txHash, err
+	}
+	utils.Logger.Info("Retried payout from treasury", "winner_id", winner.WinnerID, "address", winner.Address, "amount", amount.String(), "tx_hash", txHash.Hex())
+	return &winner, txHash, nil
+}
+
+// resolveSubmitted settles a SUBMITTED payout from its transaction
+//
+// Returns true when the transfer was mined and the winner is now PAID. A reverted or dropped transfer
+// moves the winner back to FAILED so it can be sent again.
+func (s *WinnerPayoutService) resolveSubmitted(ctx context.Context, winner *models.Winner) (bool, error) {
+	if winner.ClaimTxHash != "" {
+		txHash := common.HexToHash(winner.ClaimTxHash)
+		receipt, err := blockchain.Client.TransactionReceipt(ctx, txHash)
+		switch {
+		case err == nil && receipt.Status == 1:
+			return true, s.markPaid(ctx, winner, txHash)
+		case err == nil:
+			winner.PayoutError = "payout transaction reverted"
+		case errors.Is(err, ethereum.NotFound):
+			if _, pending, err := blockchain.Client.TransactionByHash(ctx, txHash); err == nil && pending {
+				return false, utils.NewBadRequestError(fmt.Sprintf("Payout transaction %s is not mined yet", winner.ClaimTxHash), nil)
+			} else if err != nil && !errors.Is(err, ethereum.NotFound) {
+				return false, utils.NewInternalError("Failed to fetch payout transaction", err)
+			}
+			winner.PayoutError = "payout transaction dropped"
+		default:
+			return false, utils.NewInternalError("Failed to fetch payout receipt", err)
+		}
+	}
+
+	result := s.db.WithContext(ctx).Model(&models.Winner{}).
+		Where("winner_id = ? AND payout_status = ?", winner.WinnerID, models.PayoutStatusSubmitted).
+		Updates(map[string]interface{}{"payout_status": models.PayoutStatusFailed, "payout_error": winner.PayoutError, "updated_at": time.Now()})
+	if result.Error != nil {
+		return false, utils.NewInternalError("Failed to update winner payout", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return false, utils.NewBadRequestError("Payout is already being retried", nil)
+	}
+	winner.PayoutStatus = models.PayoutStatusFailed
+	return false, nil
+}
+
+// markPaid records a mined payout transfer on the winner
+func (s *WinnerPayoutService) markPaid(ctx context.Context, winner *models.Winner, txHash common.Hash) error {
+	now := time.Now()
+	winner.PayoutStatus = models.PayoutStatusPaid
+	winner.ClaimTxHash = txHash.Hex()
+	winner.PayoutError = ""
+	winner.PaidAt = &now
+	winner.UpdatedAt = now
+	if err := s.db.WithContext(ctx).Model(&models.Winner{}).Where("winner_id = ?", winner.WinnerID).Updates(map[string]interface{}{
+		"payout_status": winner.PayoutStatus,
+		"claim_tx_hash": winner.ClaimTxHash,
+		"payout_error":  winner.PayoutError,
+		"paid_at":       winner.PaidAt,
+		"updated_at":    winner.UpdatedAt,
+	}).Error; err != nil {
+		utils.Logger.Error("Failed to mark payout as paid", "winner_id", winner.WinnerID, "tx_hash", txHash.Hex(), "error", err)
+		return utils.NewInternalError("Failed to mark payout as paid", err)
+	}
+	return nil
+}
+
+// GetWinningsByAddress lists the prizes won by an address with their payout status
+func (s *WinnerPayoutService) GetWinningsByAddress(ctx context.Context, address, payoutStatus string, page, pageSize int) (*WinnerListResult, error) {
+	query := s.db.WithContext(ctx).Model(&models.Winner{}).Where("LOWER(address) = LOWER(?)", address)
+	if payoutStatus != "" {
+		query = query.Where("payout_status = ?", payoutStatus)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		utils.Logger.Error("Failed to count winnings", "address", address, "error", err)
+		return nil, utils.NewInternalError("Failed to count winnings", err)
+	}
+
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = 20
+	}
+	if pageSize > 100 {
+		pageSize = 100
+	}
+	var winners []models.Winner
+	if err := query.
+		Preload("LotteryIssue").
+		Preload("LotteryIssue.Lottery").
+		Preload("LotteryTicket").
+		Order("created_at desc").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&winners).Error; err != nil {
+		utils.Logger.Error("Failed to fetch winnings", "address", address, "error", err)
+		return nil, utils.NewInternalError("Failed to fetch winnings", err)
+	}
+
+	return &WinnerListResult{Total: total, Page: page, PageSize: pageSize, Winners: winners}, nil
+}
+
+// truncate shortens s to at most n bytes
+func truncate(s string, n int) string {
+	if len(s) > n {
+		return s[:n]
+	}
+	return s
+}
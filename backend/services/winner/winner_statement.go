@@ -0,0 +1,199 @@
+package winner
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"backend/models"
+	"backend/utils"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+// StatementLine is one prize on a winnings statement
+type StatementLine struct {
+	Date         time.Time `json:"date"`
+	IssueID      string    `json:"issue_id"`
+	IssueNumber  string    `json:"issue_number"`
+	TicketName   string    `json:"ticket_name"`
+	PrizeLevel   string    `json:"prize_level"`
+	Jurisdiction string    `json:"jurisdiction"`
+	Gross        float64   `json:"gross"`
+	Withheld     float64   `json:"withheld"`
+	Net          float64   `json:"net"`
+	TaxOwed      float64   `json:"tax_owed"` // Tax on a prize paid gross, not withheld
+	PayoutStatus string    `json:"payout_status"`
+	ClaimTxHash  string    `json:"claim_tx_hash"`
+}
+
+// WinningsStatement is the annual winnings statement of a customer
+type WinningsStatement struct {
+	CustomerAddress string          `json:"customer_address"`
+	Name            string          `json:"name"`
+	Nationality     string          `json:"nationality"`
+	TaxID           string          `json:"tax_id"`
+	Year            int             `json:"year"`
+	Lines           []StatementLine `json:"lines"`
+	TotalGross      float64         `json:"total_gross"`
+	TotalWithheld   float64         `json:"total_withheld"`
+	TotalNet        float64         `json:"total_net"`
+	TotalTaxOwed    float64         `json:"total_tax_owed"`
+	GeneratedAt     time.Time       `json:"generated_at"`
+}
+
+// BuildStatement collects the prizes a customer won in a calendar year (UTC)
+func (s *WinnerTaxService) BuildStatement(ctx context.Context, address string, year int) (*WinningsStatement, error) {
+	if year < 2000 || year > 9999 {
+		return nil, utils.NewBadRequestError("Invalid statement year", nil)
+	}
+	kyc, err := s.customerTaxProfile(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+	var winners []models.Winner
+	if err := s.db.WithContext(ctx).
+		Preload("LotteryIssue").
+		Preload("LotteryIssue.Lottery").
+		Where("LOWER(address) = LOWER(?) AND created_at >= ? AND created_at < ?", address, start, start.AddDate(1, 0, 0)).
+		Order("created_at").
+		Find(&winners).Error; err != nil {
+		utils.Logger.Error("Failed to fetch winnings for statement", "address", address, "year", year, "error", err)
+		return nil, utils.NewInternalError("Failed to fetch winnings", err)
+	}
+
+	statement := &WinningsStatement{
+		CustomerAddress: address,
+		Name:            kyc.Name,
+		Nationality:     kyc.Nationality,
+		TaxID:           kyc.TaxID,
+		Year:            year,
+		Lines:           make([]StatementLine, 0, len(winners)),
+		GeneratedAt:     time.Now().UTC(),
+	}
+	for _, winner := range winners {
+		line := StatementLine{
+			Date:         winner.CreatedAt.UTC(),
+			IssueID:      winner.IssueID,
+			IssueNumber:  winner.LotteryIssue.IssueNumber,
+			TicketName:   winner.LotteryIssue.Lottery.TicketName,
+			PrizeLevel:   winner.PrizeLevel,
+			Jurisdiction: winner.TaxJurisdiction,
+			Gross:        winner.GrossAmount,
+			Withheld:     winner.WithheldAmount,
+			Net:          winner.NetAmount,
+			TaxOwed:      winner.TaxOwedAmount,
+			PayoutStatus: winner.PayoutStatus,
+			ClaimTxHash:  winner.ClaimTxHash,
+		}
+		statement.Lines = append(statement.Lines, line)
+		statement.TotalGross += line.Gross
+		statement.TotalWithheld += line.Withheld
+		statement.TotalNet += line.Net
+		statement.TotalTaxOwed += line.TaxOwed
+	}
+	return statement, nil
+}
+
+// formatAmount formats a token amount with a fixed precision
+func formatAmount(amount float64) string {
+	return strconv.FormatFloat(amount, 'f', 6, 64)
+}
+
+// WriteStatementCSV writes the statement as CSV, one row per prize followed by a total row
+func WriteStatementCSV(w io.Writer, statement *WinningsStatement) error {
+	writer := csv.NewWriter(w)
+	rows := [][]string{
+		{"date", "issue_id", "issue_number", "ticket_name", "prize_level", "jurisdiction", "gross", "withheld", "net", "tax_owed", "payout_status", "claim_tx_hash"},
+	}
+	for _, line := range statement.Lines {
+		rows = append(rows, []string{
+			line.Date.Format("2006-01-02"),
+			line.IssueID,
+			line.IssueNumber,
+			line.TicketName,
+			line.PrizeLevel,
+			line.Jurisdiction,
+			formatAmount(line.Gross),
+			formatAmount(line.Withheld),
+			formatAmount(line.Net),
+			formatAmount(line.TaxOwed),
+			line.PayoutStatus,
+			line.ClaimTxHash,
+		})
+	}
+	rows = append(rows, []string{"TOTAL", "", "", "", "", "", formatAmount(statement.TotalGross), formatAmount(statement.TotalWithheld), formatAmount(statement.TotalNet), formatAmount(statement.TotalTaxOwed), "", ""})
+	if err := writer.WriteAll(rows); err != nil {
+		return fmt.Errorf("failed to write statement CSV: %v", err)
+	}
+	return nil
+}
+
+// WriteStatementPDF writes the statement as a one table PDF document
+func WriteStatementPDF(w io.Writer, statement *WinningsStatement) error {
+	pdf := gofpdf.New("L", "mm", "A4", "")
+	pdf.SetTitle(fmt.Sprintf("Winnings statement %d", statement.Year), false)
+	pdf.AddPage()
+
+	pdf.SetFont("Helvetica", "B", 14)
+	pdf.CellFormat(0, 10, fmt.Sprintf("Winnings statement %d", statement.Year), "", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 10)
+	for _, field := range [][2]string{
+		{"Customer address", statement.CustomerAddress},
+		{"Name", statement.Name},
+		{"Nationality", statement.Nationality},
+		{"Tax ID", statement.TaxID},
+		{"Generated at", statement.GeneratedAt.Format(time.RFC3339)},
+	} {
+		pdf.CellFormat(40, 6, field[0], "", 0, "L", false, 0, "")
+		pdf.CellFormat(0, 6, field[1], "", 1, "L", false, 0, "")
+	}
+	pdf.Ln(4)
+
+	widths := []float64{22, 36, 30, 38, 24, 24, 26, 26, 26, 25}
+	headers := []string{"Date", "Issue", "Lottery", "Prize level", "Jurisdiction", "Status", "Gross", "Withheld", "Net", "Tax owed"}
+	pdf.SetFont("Helvetica", "B", 9)
+	for i, header := range headers {
+		pdf.CellFormat(widths[i], 7, header, "1", 0, "C", false, 0, "")
+	}
+	pdf.Ln(-1)
+	pdf.SetFont("Helvetica", "", 9)
+	for _, line := range statement.Lines {
+		cells := []string{
+			line.Date.Format("2006-01-02"),
+			line.IssueNumber,
+			line.TicketName,
+			line.PrizeLevel,
+			line.Jurisdiction,
+			line.PayoutStatus,
+			formatAmount(line.Gross),
+			formatAmount(line.Withheld),
+			formatAmount(line.Net),
+			formatAmount(line.TaxOwed),
+		}
+		for i, cell := range cells {
+			align := "L"
+			if i >= 6 {
+				align = "R"
+			}
+			pdf.CellFormat(widths[i], 6, cell, "1", 0, align, false, 0, "")
+		}
+		pdf.Ln(-1)
+	}
+	pdf.SetFont("Helvetica", "B", 9)
+	pdf.CellFormat(widths[0]+widths[1]+widths[2]+widths[3]+widths[4]+widths[5], 7, "Total", "1", 0, "L", false, 0, "")
+	pdf.CellFormat(widths[6], 7, formatAmount(statement.TotalGross), "1", 0, "R", false, 0, "")
+	pdf.CellFormat(widths[7], 7, formatAmount(statement.TotalWithheld), "1", 0, "R", false, 0, "")
+	pdf.CellFormat(widths[8], 7, formatAmount(statement.TotalNet), "1", 0, "R", false, 0, "")
+	pdf.CellFormat(widths[9], 7, formatAmount(statement.TotalTaxOwed), "1", 1, "R", false, 0, "")
+
+	if err := pdf.Output(w); err != nil {
+		return fmt.Errorf("failed to write statement PDF: %v", err)
+	}
+	return nil
+}
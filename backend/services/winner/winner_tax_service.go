@@ -0,0 +1,184 @@
+package winner
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"backend/models"
+	"backend/utils"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DefaultTaxJurisdiction is the rule applied to winners whose nationality has no rule of its own
+const DefaultTaxJurisdiction = "DEFAULT"
+
+// WinnerTaxService manages withholding rules and applies them to winners
+type WinnerTaxService struct {
+	db *gorm.DB
+}
+
+// NewWinnerTaxService creates a new WinnerTaxService instance
+func NewWinnerTaxService(db *gorm.DB) *WinnerTaxService {
+	return &WinnerTaxService{db: db}
+}
+
+// TaxRuleParams defines the parameters for saving a withholding rule
+type TaxRuleParams struct {
+	Jurisdiction string
+	Threshold    float64
+	Rate         float64
+	Description  string
+}
+
+// MatchWithholdingRule returns the rule of the nationality's jurisdiction, falling back to the DEFAULT rule
+func MatchWithholdingRule(rules []models.TaxWithholdingRule, nationality string) *models.TaxWithholdingRule {
+	var fallback *models.TaxWithholdingRule
+	for i := range rules {
+		if nationality != "" && strings.EqualFold(rules[i].Jurisdiction, strings.TrimSpace(nationality)) {
+			return &rules[i]
+		}
+		if strings.EqualFold(rules[i].Jurisdiction, DefaultTaxJurisdiction) {
+			fallback = &rules[i]
+		}
+	}
+	return fallback
+}
+
+// ApplyWithholding sets the gross, withheld, net and owed amounts of a winner from its prize and the matched rule
+//
+// The whole gross prize is taxed at the rule's rate once it reaches the threshold. Tax is only withheld from
+// prizes the treasury pays, which are reduced to the net amount; prizes the contract pushes are paid gross,
+// their tax is recorded as owed by the winner instead.
+func ApplyWithholding(winner *models.Winner, rule *models.TaxWithholdingRule) {
+	winner.GrossAmount = winner.PrizeAmount
+	winner.WithheldAmount = 0
+	winner.TaxOwedAmount = 0
+	winner.TaxRate = 0
+	winner.TaxJurisdiction = ""
+	tax := 0.0
+	if rule != nil {
+		winner.TaxJurisdiction = rule.Jurisdiction
+		if rule.Rate > 0 && winner.GrossAmount >= rule.Threshold {
+			winner.TaxRate = rule.Rate
+			tax = winner.GrossAmount * rule.Rate
+		}
+	}
+	if winner.PayoutStatus == models.PayoutStatusPendingManual {
+		winner.WithheldAmount = tax
+	} else {
+		winner.TaxOwedAmount = tax
+	}
+	winner.NetAmount = winner.GrossAmount - winner.WithheldAmount
+	if winner.PayoutStatus == models.PayoutStatusPendingManual {
+		winner.PayoutAmount = winner.NetAmount
+	}
+}
+
+// WithholdWinners applies the withholding rules to winners before they are saved, using each winner's KYC nationality
+func (s *WinnerTaxService) WithholdWinners(tx *gorm.DB, winners []models.Winner) error {
+	if len(winners) == 0 {
+		return nil
+	}
+	var rules []models.TaxWithholdingRule
+	if err := tx.Find(&rules).Error; err != nil {
+		return utils.NewInternalError("Failed to fetch withholding rules", err)
+	}
+
+	addresses := make([]string, 0, len(winners))
+	for _, winner := range winners {
+		addresses = append(addresses, strings.ToLower(winner.Address))
+	}
+	var kycs []models.KYCData
+	if err := tx.Select("customer_address", "nationality").
+		Where("LOWER(customer_address) IN ?", addresses).
+		Find(&kycs).Error; err != nil {
+		return utils.NewInternalError("Failed to fetch winner nationalities", err)
+	}
+	nationalities := make(map[string]string, len(kycs))
+	for _, kyc := range kycs {
+		nationalities[strings.ToLower(kyc.CustomerAddress)] = kyc.Nationality
+	}
+
+	for i := range winners {
+		ApplyWithholding(&winners[i], MatchWithholdingRule(rules, nationalities[strings.ToLower(winners[i].Address)]))
+	}
+	return nil
+}
+
+// SaveRule creates or replaces the withholding rule of a jurisdiction
+func (s *WinnerTaxService) SaveRule(ctx context.Context, params TaxRuleParams) (*models.TaxWithholdingRule, error) {
+	jurisdiction := strings.TrimSpace(params.Jurisdiction)
+	if jurisdiction == "" {
+		return nil, utils.NewBadRequestError("Jurisdiction is required", nil)
+	}
+	if strings.EqualFold(jurisdiction, DefaultTaxJurisdiction) {
+		jurisdiction = DefaultTaxJurisdiction
+	}
+	if params.Rate < 0 || params.Rate > 1 {
+		return nil, utils.NewBadRequestError("Rate must be between 0 and 1", nil)
+	}
+	if params.Threshold < 0 {
+		return nil, utils.NewBadRequestError("Threshold must not be negative", nil)
+	}
+
+	now := time.Now()
+	rule := models.TaxWithholdingRule{
+		RuleID:       uuid.NewString(),
+		Jurisdiction: jurisdiction,
+		Threshold:    params.Threshold,
+		Rate:         params.Rate,
+		Description:  params.Description,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	if err := s.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "jurisdiction"}},
+		DoUpdates: clause.AssignmentColumns([]string{"threshold", "rate", "description", "updated_at"}),
+	}).Create(&rule).Error; err != nil {
+		return nil, utils.NewInternalError("Failed to save withholding rule", err)
+	}
+	if err := s.db.WithContext(ctx).Where("jurisdiction = ?", jurisdiction).First(&rule).Error; err != nil {
+		return nil, utils.NewInternalError("Failed to fetch withholding rule", err)
+	}
+	utils.Logger.Info("Saved withholding rule", "jurisdiction", rule.Jurisdiction, "threshold", rule.Threshold, "rate", rule.Rate)
+	return &rule, nil
+}
+
+// ListRules returns all withholding rules ordered by jurisdiction
+func (s *WinnerTaxService) ListRules(ctx context.Context) ([]models.TaxWithholdingRule, error) {
+	var rules []models.TaxWithholdingRule
+	if err := s.db.WithContext(ctx).Order("jurisdiction").Find(&rules).Error; err != nil {
+		return nil, utils.NewInternalError("Failed to fetch withholding rules", err)
+	}
+	return rules, nil
+}
+
+// DeleteRule removes a withholding rule, winners already recorded keep their amounts
+func (s *WinnerTaxService) DeleteRule(ctx context.Context, ruleID string) error {
+	result := s.db.WithContext(ctx).Where("rule_id = ?", ruleID).Delete(&models.TaxWithholdingRule{})
+	if result.Error != nil {
+		return utils.NewInternalError("Failed to delete withholding rule", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return utils.NewBadRequestError("Withholding rule not found", gorm.ErrRecordNotFound)
+	}
+	return nil
+}
+
+// customerTaxProfile returns the KYC data of a customer, empty when the customer has none
+func (s *WinnerTaxService) customerTaxProfile(ctx context.Context, address string) (*models.KYCData, error) {
+	var kyc models.KYCData
+	err := s.db.WithContext(ctx).Where("LOWER(customer_address) = LOWER(?)", address).First(&kyc).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &models.KYCData{CustomerAddress: address}, nil
+	}
+	if err != nil {
+		return nil, utils.NewInternalError("Failed to fetch customer KYC data", err)
+	}
+	return &kyc, nil
+}
@@ -0,0 +1,137 @@
+// tests/access_list_test.go
+package tests
+
+import (
+	"backend/middleware"
+	"backend/models"
+	"backend/services/accesslist"
+	"backend/utils"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccessList(t *testing.T) {
+	now := time.Now()
+	past := now.Add(-time.Hour)
+
+	t.Run("NormalizeValue", func(t *testing.T) {
+		value, ok := accesslist.NormalizeValue(models.AccessListKindIP, "10.1.2.3")
+		assert.True(t, ok)
+		assert.Equal(t, "10.1.2.3/32", value)
+		value, ok = accesslist.NormalizeValue(models.AccessListKindIP, "10.1.2.3/16")
+		assert.True(t, ok)
+		assert.Equal(t, "10.1.0.0/16", value)
+		value, ok = accesslist.NormalizeValue(models.AccessListKindIP, "2001:db8::1")
+		assert.True(t, ok)
+		assert.Equal(t, "2001:db8::1/128", value)
+		_, ok = accesslist.NormalizeValue(models.AccessListKindIP, "10.1.2")
+		assert.False(t, ok)
+
+		value, ok = accesslist.NormalizeValue(models.AccessListKindWallet, " 0xAbCdEf0000000000000000000000000000000001 ")
+		assert.True(t, ok)
+		assert.Equal(t, "0xabcdef0000000000000000000000000000000001", value)
+		_, ok = accesslist.NormalizeValue(models.AccessListKindWallet, "0x123")
+		assert.False(t, ok)
+		_, ok = accesslist.NormalizeValue("EMAIL", "a@b.c")
+		assert.False(t, ok)
+	})
+
+	t.Run("DenyList", func(t *testing.T) {
+		entries := []models.AccessListEntry{
+			{EntryID: 1, ListType: models.AccessListDeny, Kind: models.AccessListKindIP, Value: "10.0.0.0/8"},
+			{EntryID: 2, ListType: models.AccessListDeny, Kind: models.AccessListKindWallet, Value: "0xabc"},
+			{EntryID: 3, ListType: models.AccessListDeny, Kind: models.AccessListKindIP, Value: "192.168.1.1/32", ExpiresAt: &past},
+		}
+		decision := accesslist.Evaluate(entries, models.AccessListKindIP, "10.20.30.40", now)
+		assert.False(t, decision.Allowed)
+		assert.Equal(t, uint(1), decision.EntryID)
+		// IPv4-mapped IPv6 addresses match IPv4 entries
+		assert.False(t, accesslist.Evaluate(entries, models.AccessListKindIP, "::ffff:10.0.0.1", now).Allowed)
+		assert.True(t, accesslist.Evaluate(entries, models.AccessListKindIP, "11.0.0.1", now).Allowed)
+		// Expired entries no longer apply
+		assert.True(t, accesslist.Evaluate(entries, models.AccessListKindIP, "192.168.1.1", now).Allowed)
+
+		assert.False(t, accesslist.Evaluate(entries, models.AccessListKindWallet, "0xABC", now).Allowed)
+		assert.True(t, accesslist.Evaluate(entries, models.AccessListKindWallet, "0xdef", now).Allowed)
+		assert.True(t, accesslist.Evaluate(entries, models.AccessListKindWallet, "", now).Allowed)
+	})
+
+	t.Run("AllowListWins", func(t *testing.T) {
+		entries := []models.AccessListEntry{
+			{EntryID: 1, ListType: models.AccessListDeny, Kind: models.AccessListKindIP, Value: "10.0.0.0/8"},
+			{EntryID: 2, ListType: models.AccessListAllow, Kind: models.AccessListKindIP, Value: "10.1.0.0/16"},
+		}
+		decision := accesslist.Evaluate(entries, models.AccessListKindIP, "10.1.2.3", now)
+		assert.True(t, decision.Allowed)
+		assert.Equal(t, uint(2), decision.EntryID)
+		assert.False(t, accesslist.Evaluate(entries, models.AccessListKindIP, "10.2.0.1", now).Allowed)
+
+		// With an allow list in place, anything not on it is refused
+		decision = accesslist.Evaluate(entries, models.AccessListKindIP, "8.8.8.8", now)
+		assert.False(t, decision.Allowed)
+		assert.Equal(t, "not on the allow list", decision.Reason)
+		// Allow lists apply per kind
+		assert.True(t, accesslist.Evaluate(entries, models.AccessListKindWallet, "0xabc", now).Allowed)
+	})
+
+	t.Run("MiddlewareRefusesWith403", func(t *testing.T) {
+		utils.InitCache()
+		defer utils.Cache.Flush()
+		// Served from the cache of AccessListService.entries, so no database is needed
+		utils.Cache.Set("access_list_entries", []models.AccessListEntry{
+			{EntryID: 1, ListType: models.AccessListDeny, Kind: models.AccessListKindIP, Value: "192.0.2.0/24"},
+			{EntryID: 2, ListType: models.AccessListDeny, Kind: models.AccessListKindWallet, Value: "0xdead000000000000000000000000000000000001"},
+		}, time.Minute)
+
+		gin.SetMode(gin.TestMode)
+		r := gin.New()
+		r.POST("/login", middleware.AccessListMiddleware(), func(c *gin.Context) { c.Status(http.StatusOK) })
+		send := func(remoteAddr, body string) int {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(body))
+			req.RemoteAddr = remoteAddr
+			req.Header.Set("Content-Type", "application/json")
+			r.ServeHTTP(w, req)
+			return w.Code
+		}
+
+		assert.Equal(t, http.StatusForbidden, send("192.0.2.10:4000", `{}`))
+		assert.Equal(t, http.StatusForbidden, send("198.51.100.1:4000", `{"wallet_address":"0xDEAD000000000000000000000000000000000001"}`))
+		assert.Equal(t, http.StatusOK, send("198.51.100.1:4000", `{"wallet_address":"0xbeef000000000000000000000000000000000001"}`))
+		// A denied wallet in any field is refused, even after an allowed one
+		assert.Equal(t, http.StatusForbidden, send("198.51.100.1:4000",
+			`{"wallet_address":"0xbeef000000000000000000000000000000000001","buyer_address":"0xdead000000000000000000000000000000000001"}`))
+		assert.Equal(t, http.StatusForbidden, send("198.51.100.1:4000",
+			`{"customer_address":"0xdead000000000000000000000000000000000001","wallet_address":"0xbeef000000000000000000000000000000000001"}`))
+	})
+
+	t.Run("MiddlewareChecksAuthenticatedAndBodyWallets", func(t *testing.T) {
+		utils.InitCache()
+		defer utils.Cache.Flush()
+		utils.Cache.Set("access_list_entries", []models.AccessListEntry{
+			{EntryID: 1, ListType: models.AccessListDeny, Kind: models.AccessListKindWallet, Value: "0xdead000000000000000000000000000000000001"},
+		}, time.Minute)
+
+		gin.SetMode(gin.TestMode)
+		r := gin.New()
+		authenticated := func(c *gin.Context) { c.Set("customer_address", "0xbeef000000000000000000000000000000000001") }
+		r.POST("/buy", authenticated, middleware.AccessListMiddleware(), func(c *gin.Context) { c.Status(http.StatusOK) })
+		send := func(body string) int {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/buy", strings.NewReader(body))
+			req.RemoteAddr = "198.51.100.1:4000"
+			req.Header.Set("Content-Type", "application/json")
+			r.ServeHTTP(w, req)
+			return w.Code
+		}
+
+		assert.Equal(t, http.StatusOK, send(`{"buyer_address":"0xbeef000000000000000000000000000000000001"}`))
+		assert.Equal(t, http.StatusForbidden, send(`{"buyer_address":"0xdead000000000000000000000000000000000001"}`))
+	})
+}
@@ -0,0 +1,69 @@
+// tests/account_summary_test.go
+package tests
+
+import (
+	"backend/models"
+	"backend/services/account"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeriveKYCStatus(t *testing.T) {
+	assert.Equal(t, account.KYCStatusNotSubmitted, account.DeriveKYCStatus(false, false, ""))
+	assert.Equal(t, account.KYCStatusPending, account.DeriveKYCStatus(true, false, ""))
+	assert.Equal(t, account.KYCStatusApproved, account.DeriveKYCStatus(true, true, "Approved"))
+	assert.Equal(t, account.KYCStatusRejected, account.DeriveKYCStatus(true, false, "Rejected"))
+	// A customer approved after an earlier rejection is approved
+	assert.Equal(t, account.KYCStatusApproved, account.DeriveKYCStatus(true, true, "Rejected"))
+	// An expired verification is expired whether or not the verified flag was cleared
+	assert.Equal(t, account.KYCStatusExpired, account.DeriveKYCStatus(true, true, "Expired"))
+	assert.Equal(t, account.KYCStatusExpired, account.DeriveKYCStatus(true, false, "Expired"))
+}
+
+func TestAccountSummaryAggregates(t *testing.T) {
+	suite := SetupTestDB()
+	defer suite.TearDown()
+	require.NoError(t, suite.DB.AutoMigrate(&models.LotteryType{}, &models.Lottery{}, &models.LotteryIssue{}, &models.LotteryTicket{}, &models.Winner{}))
+	require.NoError(t, suite.DB.Create(&models.LotteryType{TypeID: "type-summary", TypeName: "Summary"}).Error)
+	require.NoError(t, suite.DB.Create(&models.Lottery{
+		LotteryID: "lottery-summary", TypeID: "type-summary", TicketName: "Summary", TicketPrice: 2.5, TicketSupply: 100,
+		BettingRules: "-", PrizeStructure: "-", RegisteredAddr: "0x1", RolloutContractAddress: "0x2", ContractAddress: "0x3",
+	}).Error)
+	for id, status := range map[string]string{"issue-summary-open": models.IssueStatusPending, "issue-summary-drawn": models.IssueStatusDrawn} {
+		require.NoError(t, suite.DB.Create(&models.LotteryIssue{
+			IssueID: id, LotteryID: "lottery-summary", IssueNumber: id, Status: status,
+			SaleEndTime: time.Now().Add(time.Hour), DrawTime: time.Now().Add(2 * time.Hour),
+		}).Error)
+	}
+	buyer := "0xSummaryBuyer"
+	for id, ticket := range map[string]struct {
+		issue  string
+		amount float64
+	}{
+		"ticket-summary-1": {"issue-summary-open", 3},
+		"ticket-summary-2": {"issue-summary-open", 1},
+		"ticket-summary-3": {"issue-summary-drawn", 2},
+	} {
+		require.NoError(t, suite.DB.Create(&models.LotteryTicket{
+			TicketID: id, IssueID: ticket.issue, BuyerAddress: buyer, PurchaseAmount: ticket.amount,
+			BetContent: "1,2,3", PurchaseTime: time.Now(),
+		}).Error)
+	}
+
+	account.InvalidateAccountSummary(buyer)
+	summary, err := account.NewAccountSummaryService(suite.DB).GetSummary(context.Background(), buyer)
+	require.NoError(t, err)
+
+	// purchase_amount is a number of tickets, the amounts are priced at 2.5 per ticket
+	assert.Equal(t, int64(3), summary.TicketCount)
+	assert.InDelta(t, 15, summary.TotalSpent, 1e-9)
+	assert.InDelta(t, -15, summary.NetPosition, 1e-9)
+	require.Len(t, summary.OpenTickets, 1)
+	assert.Equal(t, "issue-summary-open", summary.OpenTickets[0].IssueID)
+	assert.Equal(t, int64(2), summary.OpenTickets[0].TicketCount)
+	assert.InDelta(t, 10, summary.OpenTickets[0].TotalAmount, 1e-9)
+}
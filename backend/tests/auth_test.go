@@ -34,9 +34,18 @@ func TestAuthService(t *testing.T) {
 	})
 
 	t.Run("RefreshToken", func(t *testing.T) {
-		newToken, err := services.RefreshToken("test@example.com", "lottery_admin")
+		login, err := services.Login("0xTestAddress123", "127.0.0.1")
 		assert.NoError(t, err)
-		assert.NotEmpty(t, newToken)
+		tokens, err := services.RefreshToken(login.Tokens.RefreshToken, "127.0.0.1")
+		assert.NoError(t, err)
+		assert.NotEmpty(t, tokens.AccessToken)
+		assert.NotEqual(t, login.Tokens.RefreshToken, tokens.RefreshToken)
+
+		// A rotated refresh token cannot be used again, and reusing it revokes the session
+		_, err = services.RefreshToken(login.Tokens.RefreshToken, "127.0.0.1")
+		assert.Error(t, err)
+		_, err = services.RefreshToken(tokens.RefreshToken, "127.0.0.1")
+		assert.Error(t, err)
 	})
 }
 
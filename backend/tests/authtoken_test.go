@@ -0,0 +1,132 @@
+// tests/authtoken_test.go
+package tests
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	"backend/config"
+	"backend/services/authtoken"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func pemPrivateKey(t *testing.T, key interface{}) []byte {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+}
+
+func TestAuthTokens(t *testing.T) {
+	config.AppConfig.JWTIssuer = "lottery-test"
+	config.AppConfig.JWTAccessTTL = 900
+	now := time.Now()
+
+	_, edKey1, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	_, edKey2, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	t.Run("AccessToken", func(t *testing.T) {
+		keys, err := authtoken.NewHMACKeySet("secret")
+		require.NoError(t, err)
+		service := authtoken.NewTokenServiceWithKeys(nil, keys)
+
+		signed, issued, err := service.SignAccessToken("0xabc", "admin", "session-1", now)
+		require.NoError(t, err)
+		claims, err := service.ParseAccessToken(signed, now)
+		require.NoError(t, err)
+		assert.Equal(t, "0xabc", claims.CustomerAddress)
+		assert.Equal(t, "0xabc", claims.Subject)
+		assert.Equal(t, "admin", claims.Role)
+		assert.Equal(t, "session-1", claims.SessionID)
+		assert.Equal(t, issued.ID, claims.ID)
+		assert.NotEmpty(t, claims.ID)
+
+		_, err = service.ParseAccessToken(signed, now.Add(16*time.Minute))
+		assert.Error(t, err, "access tokens are short-lived")
+
+		other, err := authtoken.NewHMACKeySet("other secret")
+		require.NoError(t, err)
+		_, err = authtoken.NewTokenServiceWithKeys(nil, other).ParseAccessToken(signed, now)
+		assert.Error(t, err)
+
+		// Tokens of the old format have no jti or session and are refused
+		legacy, err := keys.Sign(jwt.MapClaims{"customer_address": "0xabc", "role": "admin", "iss": "lottery-test", "exp": now.Add(time.Hour).Unix()})
+		require.NoError(t, err)
+		_, err = service.ParseAccessToken(legacy, now)
+		assert.Error(t, err)
+
+		_, err = authtoken.NewHMACKeySet("")
+		assert.Error(t, err)
+	})
+
+	t.Run("KeyRotation", func(t *testing.T) {
+		oldKeys, err := authtoken.NewKeySet(authtoken.AlgorithmEdDSA, [][]byte{pemPrivateKey(t, edKey1)})
+		require.NoError(t, err)
+		signed, _, err := authtoken.NewTokenServiceWithKeys(nil, oldKeys).SignAccessToken("0xabc", "normal_user", "session-1", now)
+		require.NoError(t, err)
+
+		// The new key signs, the old one still verifies the tokens it signed
+		rotated, err := authtoken.NewKeySet(authtoken.AlgorithmEdDSA, [][]byte{pemPrivateKey(t, edKey2), pemPrivateKey(t, edKey1)})
+		require.NoError(t, err)
+		_, err = authtoken.NewTokenServiceWithKeys(nil, rotated).ParseAccessToken(signed, now)
+		assert.NoError(t, err)
+
+		jwks := rotated.JWKS()
+		require.Len(t, jwks.Keys, 2)
+		assert.Equal(t, oldKeys.JWKS().Keys[0], jwks.Keys[1])
+		assert.Equal(t, "OKP", jwks.Keys[0].Kty)
+		assert.Equal(t, "EdDSA", jwks.Keys[0].Alg)
+		assert.NotEqual(t, jwks.Keys[0].Kid, jwks.Keys[1].Kid)
+
+		// Once the old key is dropped its tokens are refused
+		dropped, err := authtoken.NewKeySet(authtoken.AlgorithmEdDSA, [][]byte{pemPrivateKey(t, edKey2)})
+		require.NoError(t, err)
+		_, err = authtoken.NewTokenServiceWithKeys(nil, dropped).ParseAccessToken(signed, now)
+		assert.Error(t, err)
+	})
+
+	t.Run("RS256", func(t *testing.T) {
+		keys, err := authtoken.NewKeySet(authtoken.AlgorithmRS256, [][]byte{pemPrivateKey(t, rsaKey)})
+		require.NoError(t, err)
+		service := authtoken.NewTokenServiceWithKeys(nil, keys)
+		signed, _, err := service.SignAccessToken("0xabc", "normal_user", "session-1", now)
+		require.NoError(t, err)
+		_, err = service.ParseAccessToken(signed, now)
+		assert.NoError(t, err)
+
+		jwks := keys.JWKS()
+		require.Len(t, jwks.Keys, 1)
+		assert.Equal(t, "RSA", jwks.Keys[0].Kty)
+		assert.Equal(t, "AQAB", jwks.Keys[0].E)
+
+		// A token cannot switch to HS256 using the public key as the secret
+		forged, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+			"customer_address": "0xabc", "sid": "session-1", "jti": "x", "iss": "lottery-test", "exp": now.Add(time.Hour).Unix(),
+		}).SignedString(x509.MarshalPKCS1PublicKey(&rsaKey.PublicKey))
+		require.NoError(t, err)
+		_, err = service.ParseAccessToken(forged, now)
+		assert.Error(t, err)
+
+		_, err = authtoken.NewKeySet(authtoken.AlgorithmRS256, [][]byte{pemPrivateKey(t, edKey1)})
+		assert.Error(t, err, "an Ed25519 key cannot sign RS256")
+		_, err = authtoken.NewKeySet(authtoken.AlgorithmRS256, nil)
+		assert.Error(t, err)
+	})
+
+	t.Run("HashRefreshToken", func(t *testing.T) {
+		assert.Equal(t, authtoken.HashRefreshToken("token"), authtoken.HashRefreshToken("token"))
+		assert.NotEqual(t, authtoken.HashRefreshToken("token"), authtoken.HashRefreshToken("token2"))
+		assert.Len(t, authtoken.HashRefreshToken("token"), 64)
+	})
+}
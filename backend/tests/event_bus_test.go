@@ -0,0 +1,56 @@
+// tests/event_bus_test.go
+package tests
+
+import (
+	"backend/services/events"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventBus(t *testing.T) {
+	t.Run("FilterAndDeliver", func(t *testing.T) {
+		bus := events.NewBus(4, 10)
+		stream, unsubscribe := bus.Subscribe(events.Filter{IssueID: "issue-1", Types: map[string]bool{events.TypeTicketSold: true}}, 0)
+		defer unsubscribe()
+
+		bus.Publish(events.Event{Type: events.TypeTicketSold, IssueID: "issue-2"})
+		bus.Publish(events.Event{Type: events.TypeIssueStatus, IssueID: "issue-1"})
+		published := bus.Publish(events.Event{Type: events.TypeTicketSold, IssueID: "issue-1"})
+
+		event := <-stream
+		assert.Equal(t, published.ID, event.ID)
+		assert.Equal(t, uint64(3), event.ID)
+		assert.False(t, event.Timestamp.IsZero())
+		assert.Len(t, stream, 0)
+	})
+
+	t.Run("ReplayAfterLastEventID", func(t *testing.T) {
+		bus := events.NewBus(4, 3)
+		for i := 0; i < 5; i++ {
+			bus.Publish(events.Event{Type: events.TypeIssueStatus})
+		}
+		stream, unsubscribe := bus.Subscribe(events.Filter{}, 1)
+		defer unsubscribe()
+		// Only the last 3 events are kept
+		var ids []uint64
+		for len(stream) > 0 {
+			ids = append(ids, (<-stream).ID)
+		}
+		assert.Equal(t, []uint64{3, 4, 5}, ids)
+	})
+
+	t.Run("SlowSubscriberDropped", func(t *testing.T) {
+		bus := events.NewBus(1, 0)
+		stream, unsubscribe := bus.Subscribe(events.Filter{}, 0)
+		bus.Publish(events.Event{Type: events.TypeTicketSold})
+		bus.Publish(events.Event{Type: events.TypeTicketSold})
+		assert.Equal(t, 0, bus.SubscriberCount())
+
+		<-stream
+		_, ok := <-stream
+		assert.False(t, ok)
+		// Unsubscribing a dropped subscriber is a no-op
+		unsubscribe()
+	})
+}
@@ -0,0 +1,108 @@
+// tests/geo_test.go
+package tests
+
+import (
+	"backend/models"
+	"backend/services/geo"
+	"backend/services/lottery"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeLocator resolves countries from a fixed table
+type fakeLocator map[string]string
+
+func (l fakeLocator) Country(ip net.IP) (string, error) {
+	if ip.String() == "203.0.113.99" {
+		return "", errors.New("corrupt record")
+	}
+	return l[ip.String()], nil
+}
+
+func TestGeoRestrictions(t *testing.T) {
+	t.Run("ParseCountries", func(t *testing.T) {
+		countries, err := geo.ParseCountries(" us,GB, us ,,de")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"DE", "GB", "US"}, countries)
+		assert.Equal(t, "DE,GB,US", geo.FormatCountries(countries))
+
+		_, err = geo.ParseCountries("USA")
+		assert.Error(t, err)
+		_, err = geo.ParseCountries("U1")
+		assert.Error(t, err)
+	})
+
+	t.Run("ParseJurisdictions", func(t *testing.T) {
+		allowed, blocked, err := lottery.ParseJurisdictions([]string{"gb", "de"}, []string{"us"})
+		require.NoError(t, err)
+		assert.Equal(t, "DE,GB", allowed)
+		assert.Equal(t, "US", blocked)
+
+		_, _, err = lottery.ParseJurisdictions([]string{"GB"}, []string{"gb"})
+		assert.Error(t, err, "a country cannot be both allowed and blocked")
+	})
+
+	t.Run("Decide", func(t *testing.T) {
+		open := models.Lottery{}
+		blockList := models.Lottery{BlockedCountries: "CN,US"}
+		allowList := models.Lottery{AllowedCountries: "DE,GB"}
+
+		assert.Equal(t, models.GeoDecisionAllowed, geo.Decide(open, "US", true, false).Result)
+		assert.False(t, geo.Decide(blockList, "us", true, false).Allowed)
+		assert.True(t, geo.Decide(blockList, "GB", true, false).Allowed)
+		assert.True(t, geo.Decide(allowList, "GB", true, false).Allowed)
+
+		decision := geo.Decide(allowList, "FR", true, false)
+		assert.False(t, decision.Allowed)
+		assert.Equal(t, models.GeoDecisionBlocked, decision.Result)
+		assert.Equal(t, "FR", decision.Country)
+
+		// Unknown countries pass unless configured otherwise, and only restricted lotteries block them
+		assert.Equal(t, models.GeoDecisionUnknown, geo.Decide(allowList, "", true, false).Result)
+		assert.False(t, geo.Decide(allowList, "", true, true).Allowed)
+		assert.True(t, geo.Decide(open, "", true, true).Allowed)
+
+		// Without a GeoIP database restricted lotteries are refused and the others pass
+		decision = geo.Decide(allowList, "", false, false)
+		assert.False(t, decision.Allowed)
+		assert.Equal(t, models.GeoDecisionUnchecked, decision.Result)
+		assert.False(t, geo.Decide(blockList, "", false, false).Allowed)
+		decision = geo.Decide(open, "", false, false)
+		assert.True(t, decision.Allowed)
+		assert.Equal(t, models.GeoDecisionUnchecked, decision.Result)
+	})
+
+	t.Run("CheckPurchase", func(t *testing.T) {
+		service := geo.NewGeoServiceWithLocator(nil, fakeLocator{"198.51.100.7": "GB", "192.0.2.1": "US"})
+		target := models.Lottery{LotteryID: "lottery-1", AllowedCountries: "GB"}
+
+		decision, err := service.CheckPurchase(target, "198.51.100.7")
+		require.NoError(t, err)
+		assert.Equal(t, "GB", decision.Country)
+		assert.Equal(t, models.GeoDecisionAllowed, decision.Result)
+
+		decision, err = service.CheckPurchase(target, "192.0.2.1")
+		assert.Error(t, err)
+		assert.Equal(t, models.GeoDecisionBlocked, decision.Result)
+
+		// Lookup failures and unparsable addresses count as an unknown country
+		decision, err = service.CheckPurchase(target, "203.0.113.99")
+		require.NoError(t, err)
+		assert.Equal(t, models.GeoDecisionUnknown, decision.Result)
+		decision, err = service.CheckPurchase(target, "not-an-ip")
+		require.NoError(t, err)
+		assert.Equal(t, models.GeoDecisionUnknown, decision.Result)
+
+		// Without a GeoIP database a restricted lottery cannot be bought, an unrestricted one can
+		unchecked, err := geo.NewGeoServiceWithLocator(nil, nil).CheckPurchase(target, "192.0.2.1")
+		assert.Error(t, err)
+		assert.Equal(t, models.GeoDecisionUnchecked, unchecked.Result)
+		unchecked, err = geo.NewGeoServiceWithLocator(nil, nil).CheckPurchase(models.Lottery{LotteryID: "lottery-2"}, "192.0.2.1")
+		require.NoError(t, err)
+		assert.Equal(t, models.GeoDecisionUnchecked, unchecked.Result)
+	})
+}
@@ -0,0 +1,34 @@
+// tests/issue_create_test.go
+package tests
+
+import (
+	"backend/models"
+	"backend/services/issue"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlanIssueStart(t *testing.T) {
+	ready := uint8(models.ContractStateReady)
+	distribute := uint8(models.ContractStateDistribute)
+	rollout := uint8(models.ContractStateRollout)
+
+	t.Run("ReadyStartsNewEpoch", func(t *testing.T) {
+		assert.Equal(t, issue.IssueStartDistribute, issue.PlanIssueStart(ready, false))
+	})
+
+	t.Run("DistributeWithoutIssueIsAdopted", func(t *testing.T) {
+		// The Distribute transaction of an earlier attempt was mined but its issue was never saved
+		assert.Equal(t, issue.IssueStartAdopt, issue.PlanIssueStart(distribute, false))
+	})
+
+	t.Run("DistributeWithIssueIsRefused", func(t *testing.T) {
+		assert.Equal(t, issue.IssueStartRefuse, issue.PlanIssueStart(distribute, true))
+	})
+
+	t.Run("RolloutIsRefused", func(t *testing.T) {
+		assert.Equal(t, issue.IssueStartRefuse, issue.PlanIssueStart(rollout, false))
+		assert.Equal(t, issue.IssueStartRefuse, issue.PlanIssueStart(rollout, true))
+	})
+}
@@ -0,0 +1,37 @@
+// tests/issue_number_test.go
+package tests
+
+import (
+	"backend/services/issue"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIssueNumberPattern(t *testing.T) {
+	drawTime := time.Date(2025, 4, 25, 20, 30, 0, 0, time.UTC)
+
+	t.Run("Validate", func(t *testing.T) {
+		assert.NoError(t, issue.ValidateIssueNumberPattern("{yyyyMMdd}-{seq}"))
+		assert.NoError(t, issue.ValidateIssueNumberPattern("SSQ{yyyy}{seq:4}"))
+		assert.NoError(t, issue.ValidateIssueNumberPattern("{yyyy-MM-dd}/{seq}"))
+		assert.Error(t, issue.ValidateIssueNumberPattern(""))
+		assert.Error(t, issue.ValidateIssueNumberPattern("{yyyyMMdd}"))
+		assert.Error(t, issue.ValidateIssueNumberPattern("{seq}-{seq}"))
+		assert.Error(t, issue.ValidateIssueNumberPattern("{week}-{seq}"))
+	})
+
+	t.Run("Format", func(t *testing.T) {
+		assert.Equal(t, "20250425-001", issue.FormatIssueNumber("{yyyyMMdd}-{seq}", drawTime, 1))
+		assert.Equal(t, "SSQ20250012", issue.FormatIssueNumber("SSQ{yyyy}{seq:4}", drawTime, 12))
+		assert.Equal(t, "2025-04-25/1234", issue.FormatIssueNumber("{yyyy-MM-dd}/{seq}", drawTime, 1234))
+		assert.Equal(t, "250425-2030-07", issue.FormatIssueNumber("{yyMMdd}-{HHmm}-{seq:2}", drawTime, 7))
+	})
+
+	t.Run("PeriodKey", func(t *testing.T) {
+		assert.Equal(t, "20250425-", issue.IssuePeriodKey("{yyyyMMdd}-{seq}", drawTime))
+		assert.Equal(t, "SSQ2025", issue.IssuePeriodKey("SSQ{yyyy}{seq:4}", drawTime))
+		assert.Equal(t, "", issue.IssuePeriodKey("{seq:6}", drawTime))
+	})
+}
@@ -0,0 +1,53 @@
+// tests/issue_schedule_test.go
+package tests
+
+import (
+	"backend/models"
+	"backend/services/issue"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNextScheduledDraw(t *testing.T) {
+	// 2025-04-25 是周五，UTC 10:00 即北京时间 18:00
+	now := time.Date(2025, 4, 25, 10, 0, 0, 0, time.UTC)
+
+	t.Run("DailyInTimeZone", func(t *testing.T) {
+		schedule := &models.IssueSchedule{Frequency: models.ScheduleFrequencyDaily, DrawAt: "20:00", TimeZone: "Asia/Shanghai", SaleCloseMinutes: 30}
+		drawTime, saleEnd, err := issue.NextScheduledDraw(schedule, now)
+		assert.NoError(t, err)
+		assert.True(t, drawTime.Equal(time.Date(2025, 4, 25, 12, 0, 0, 0, time.UTC)))
+		assert.True(t, saleEnd.Equal(time.Date(2025, 4, 25, 11, 30, 0, 0, time.UTC)))
+	})
+
+	t.Run("SkipsDrawWhoseSaleAlreadyClosed", func(t *testing.T) {
+		schedule := &models.IssueSchedule{Frequency: models.ScheduleFrequencyDaily, DrawAt: "18:20", TimeZone: "Asia/Shanghai", SaleCloseMinutes: 30}
+		drawTime, _, err := issue.NextScheduledDraw(schedule, now)
+		assert.NoError(t, err)
+		assert.True(t, drawTime.Equal(time.Date(2025, 4, 26, 10, 20, 0, 0, time.UTC)))
+	})
+
+	t.Run("Weekly", func(t *testing.T) {
+		schedule := &models.IssueSchedule{Frequency: models.ScheduleFrequencyWeekly, DrawAt: "21:15", Weekday: 2, TimeZone: "UTC"}
+		drawTime, _, err := issue.NextScheduledDraw(schedule, now)
+		assert.NoError(t, err)
+		assert.True(t, drawTime.Equal(time.Date(2025, 4, 29, 21, 15, 0, 0, time.UTC)))
+	})
+
+	t.Run("Cron", func(t *testing.T) {
+		schedule := &models.IssueSchedule{Frequency: models.ScheduleFrequencyCron, CronExpr: "0 */6 * * *", TimeZone: "UTC"}
+		drawTime, _, err := issue.NextScheduledDraw(schedule, now)
+		assert.NoError(t, err)
+		assert.True(t, drawTime.Equal(time.Date(2025, 4, 25, 12, 0, 0, 0, time.UTC)))
+	})
+
+	t.Run("Validate", func(t *testing.T) {
+		assert.Error(t, issue.ValidateIssueSchedule(&models.IssueSchedule{Frequency: models.ScheduleFrequencyDaily, DrawAt: "25:00", TimeZone: "UTC"}))
+		assert.Error(t, issue.ValidateIssueSchedule(&models.IssueSchedule{Frequency: models.ScheduleFrequencyDaily, DrawAt: "20:00", TimeZone: "Mars/Base"}))
+		assert.Error(t, issue.ValidateIssueSchedule(&models.IssueSchedule{Frequency: models.ScheduleFrequencyCron, CronExpr: "not a cron", TimeZone: "UTC"}))
+		assert.Error(t, issue.ValidateIssueSchedule(&models.IssueSchedule{Frequency: "HOURLY", TimeZone: "UTC"}))
+		assert.NoError(t, issue.ValidateIssueSchedule(&models.IssueSchedule{Frequency: models.ScheduleFrequencyWeekly, DrawAt: "08:05", Weekday: 6, TimeZone: "Europe/Berlin"}))
+	})
+}
@@ -0,0 +1,144 @@
+// tests/kyc_document_test.go
+package tests
+
+import (
+	"backend/models"
+	"backend/services/document"
+	"backend/utils"
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testDocumentKey = "kyc/0b6f5b7e-0c1a-4f0e-9a4e-2d1c3b4a5f60"
+
+func TestKYCDocumentStore(t *testing.T) {
+	hexKey := strings.Repeat("ab", 32)
+
+	t.Run("EncryptedRoundTrip", func(t *testing.T) {
+		dir := t.TempDir()
+		store, err := document.NewLocalStore(dir, t.TempDir(), hexKey)
+		require.NoError(t, err)
+
+		data := []byte("\x89PNG passport scan")
+		require.NoError(t, store.Put(context.Background(), testDocumentKey, "image/png", bytes.NewReader(data), int64(len(data))))
+
+		raw, err := os.ReadFile(filepath.Join(dir, "0b6f5b7e-0c1a-4f0e-9a4e-2d1c3b4a5f60.enc"))
+		require.NoError(t, err)
+		assert.NotContains(t, string(raw), "passport scan")
+
+		read, err := store.Get(testDocumentKey)
+		require.NoError(t, err)
+		assert.Equal(t, data, read)
+	})
+
+	t.Run("RejectsBadKeys", func(t *testing.T) {
+		_, err := document.NewLocalStore(t.TempDir(), t.TempDir(), "short")
+		assert.Error(t, err)
+
+		store, err := document.NewLocalStore(t.TempDir(), t.TempDir(), hexKey)
+		require.NoError(t, err)
+		assert.Error(t, store.Put(context.Background(), "kyc/../../etc/passwd", "image/png", strings.NewReader("x"), 1))
+		_, err = store.Get("kyc/0b6f5b7e-0c1a-4f0e-9a4e-2d1c3b4a5f61")
+		assert.ErrorIs(t, err, document.ErrNotFound)
+	})
+
+	t.Run("SignedURL", func(t *testing.T) {
+		store, err := document.NewLocalStore(t.TempDir(), t.TempDir(), hexKey)
+		require.NoError(t, err)
+
+		signed, err := store.SignedURL(context.Background(), testDocumentKey, "0xOwner", 5*time.Minute)
+		require.NoError(t, err)
+		parsed, err := url.Parse(signed)
+		require.NoError(t, err)
+		assert.Equal(t, document.ContentPath, parsed.Path)
+		q := parsed.Query()
+
+		now := time.Now()
+		assert.True(t, store.VerifyURL(q.Get("key"), q.Get("accessor"), q.Get("expires"), q.Get("sig"), now))
+		assert.False(t, store.VerifyURL(q.Get("key"), "0xOther", q.Get("expires"), q.Get("sig"), now))
+		assert.False(t, store.VerifyURL("kyc/0b6f5b7e-0c1a-4f0e-9a4e-2d1c3b4a5f61", q.Get("accessor"), q.Get("expires"), q.Get("sig"), now))
+		assert.False(t, store.VerifyURL(q.Get("key"), q.Get("accessor"), q.Get("expires"), q.Get("sig"), now.Add(6*time.Minute)))
+	})
+
+	t.Run("LegacyKeys", func(t *testing.T) {
+		assert.Equal(t, "uploads/1_id.png", document.LegacyKey("/uploads/1_id.png", ""))
+		assert.Equal(t, "photos/1_id.png", document.LegacyKey("https://kyc-bucket.s3.eu-west-1.amazonaws.com/photos/1_id.png", "kyc-bucket"))
+		assert.Equal(t, "photos/1_id.png", document.LegacyKey("http://minio:9000/kyc-bucket/photos/1_id.png", "kyc-bucket"))
+		assert.Equal(t, testDocumentKey, document.LegacyKey(testDocumentKey, "kyc-bucket"))
+		assert.True(t, document.IsDocumentKey(testDocumentKey))
+		assert.False(t, document.IsDocumentKey("uploads/1_id.png"))
+	})
+}
+
+func TestKYCDocumentOwnership(t *testing.T) {
+	suite := SetupTestDB()
+	defer suite.TearDown()
+	require.NoError(t, suite.DB.AutoMigrate(&models.KYCDocument{}, &models.DocumentAccessLog{}))
+
+	const alice = "0xTestAddress123"
+	const bob = "0x00000000000000000000000000000000000000b0"
+	const aliceLegacyPath = "/uploads/1_alice.png"
+	var existing models.Customer
+	require.NoError(t, suite.DB.Where("customer_address = ?", alice).First(&existing).Error)
+	require.NoError(t, suite.DB.Create(&models.Customer{
+		CustomerAddress: bob, RoleID: existing.RoleID, RegistrationTime: time.Now(), AssignedDate: time.Now(),
+		KYCData: models.KYCData{CustomerAddress: bob, Name: "Bob"},
+	}).Error)
+	require.NoError(t, suite.DB.Create(&models.KYCDocument{
+		DocumentKey: testDocumentKey, Storage: "local", ContentType: "image/png", Size: 1, SHA256: "-", OwnerAddress: alice,
+	}).Error)
+
+	isStatus := func(err error, code int) bool {
+		var appErr *utils.Error
+		return errors.As(err, &appErr) && appErr.Code == code
+	}
+
+	t.Run("ClaimRejectsAnotherCustomersDocument", func(t *testing.T) {
+		assert.True(t, isStatus(document.ClaimDocument(suite.DB, testDocumentKey, bob, ""), http.StatusBadRequest))
+		assert.NoError(t, document.ClaimDocument(suite.DB, testDocumentKey, alice, ""))
+	})
+
+	t.Run("ClaimAcceptsOnlyOwnLegacyPath", func(t *testing.T) {
+		assert.True(t, isStatus(document.ClaimDocument(suite.DB, aliceLegacyPath, bob, ""), http.StatusBadRequest))
+		assert.True(t, isStatus(document.ClaimDocument(suite.DB, aliceLegacyPath, bob, "/uploads/2_bob.png"), http.StatusBadRequest))
+		assert.NoError(t, document.ClaimDocument(suite.DB, aliceLegacyPath, alice, aliceLegacyPath))
+	})
+
+	store, err := document.NewLocalStore(t.TempDir(), t.TempDir(), strings.Repeat("ab", 32))
+	require.NoError(t, err)
+	service := document.NewDocumentServiceWithStore(suite.DB, store, nil)
+	ctx := context.Background()
+
+	t.Run("IssueURLRejectsAnotherCustomersDocument", func(t *testing.T) {
+		// Bob's KYC data points at Alice's document, e.g. written before claims were enforced
+		require.NoError(t, suite.DB.Model(&models.KYCData{}).Where("customer_address = ?", bob).Update("file_path", testDocumentKey).Error)
+		_, err := service.IssueURL(ctx, bob, document.Accessor{Address: bob})
+		assert.True(t, isStatus(err, http.StatusForbidden))
+
+		require.NoError(t, suite.DB.Model(&models.KYCData{}).Where("customer_address = ?", alice).Update("file_path", testDocumentKey).Error)
+		signed, err := service.IssueURL(ctx, alice, document.Accessor{Address: alice})
+		require.NoError(t, err)
+		assert.Equal(t, testDocumentKey, signed.DocumentKey)
+	})
+
+	t.Run("IssueURLRejectsSharedLegacyPath", func(t *testing.T) {
+		require.NoError(t, suite.DB.Model(&models.KYCData{}).Where("customer_address IN ?", []string{alice, bob}).Update("file_path", aliceLegacyPath).Error)
+		_, err := service.IssueURL(ctx, bob, document.Accessor{Address: bob})
+		assert.True(t, isStatus(err, http.StatusForbidden))
+
+		require.NoError(t, suite.DB.Model(&models.KYCData{}).Where("customer_address = ?", bob).Update("file_path", "").Error)
+		_, err = service.IssueURL(ctx, alice, document.Accessor{Address: alice})
+		assert.NoError(t, err)
+	})
+}
@@ -0,0 +1,85 @@
+// tests/kyc_expiry_test.go
+package tests
+
+import (
+	"backend/models"
+	"backend/services/kyc"
+	"backend/utils"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestKYCExpiry(t *testing.T) {
+	intervals := kyc.ReverificationIntervals{Low: 730 * 24 * time.Hour, Medium: 365 * 24 * time.Hour, High: 180 * 24 * time.Hour}
+	verifiedAt := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	t.Run("IntervalPerRiskLevel", func(t *testing.T) {
+		expiresAt, reason := kyc.ExpiresAt(verifiedAt, "High", nil, intervals)
+		assert.Equal(t, verifiedAt.Add(180*24*time.Hour), expiresAt)
+		assert.Equal(t, kyc.ExpiryReasonReverification, reason)
+
+		expiresAt, _ = kyc.ExpiresAt(verifiedAt, "low", nil, intervals)
+		assert.Equal(t, verifiedAt.Add(730*24*time.Hour), expiresAt)
+
+		// Unknown risk levels use the medium interval
+		expiresAt, _ = kyc.ExpiresAt(verifiedAt, "", nil, intervals)
+		assert.Equal(t, verifiedAt.Add(365*24*time.Hour), expiresAt)
+	})
+
+	t.Run("DocumentExpiresFirst", func(t *testing.T) {
+		documentExpiry := time.Date(2025, 6, 30, 0, 0, 0, 0, time.UTC)
+		expiresAt, reason := kyc.ExpiresAt(verifiedAt, "Low", &documentExpiry, intervals)
+		// The document is valid through its expiry date
+		assert.Equal(t, time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC), expiresAt)
+		assert.Equal(t, kyc.ExpiryReasonDocument, reason)
+
+		documentExpiry = time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+		expiresAt, reason = kyc.ExpiresAt(verifiedAt, "Low", &documentExpiry, intervals)
+		assert.Equal(t, verifiedAt.Add(730*24*time.Hour), expiresAt)
+		assert.Equal(t, kyc.ExpiryReasonReverification, reason)
+	})
+
+	t.Run("ExpireTransition", func(t *testing.T) {
+		now := time.Now()
+		review := &models.KYCReview{CustomerAddress: "0xCustomer", Status: models.KYCReviewStatusInReview, AssignedVerifier: "0xAlice"}
+		_, err := kyc.Transition(review, kyc.ActionExpire, "", "", now)
+		assert.Error(t, err, "only approved reviews expire")
+		assert.Equal(t, models.KYCReviewStatusInReview, review.Status)
+
+		review.Status = models.KYCReviewStatusApproved
+		status, err := kyc.Transition(review, kyc.ActionExpire, "", "", now)
+		require.NoError(t, err)
+		assert.Equal(t, models.KYCVerifyStatusExpired, status)
+		assert.Equal(t, models.KYCReviewStatusExpired, review.Status)
+
+		_, err = kyc.Transition(review, kyc.ActionApprove, "0xAlice", "", now)
+		assert.Error(t, err, "an expired review needs a resubmission")
+
+		status, err = kyc.Transition(review, kyc.ActionResubmit, "0xCustomer", "", now)
+		require.NoError(t, err)
+		assert.Equal(t, models.KYCVerifyStatusResubmitted, status)
+		assert.Equal(t, models.KYCReviewStatusPending, review.Status)
+		assert.Empty(t, review.AssignedVerifier)
+		assert.Nil(t, review.DecidedAt)
+	})
+
+	t.Run("StaleReviewSkipped", func(t *testing.T) {
+		// A review resubmitted after the run selected it is no longer approved, Transition refuses
+		// with 400 and the run skips it
+		review := &models.KYCReview{CustomerAddress: "0xCustomer", Status: models.KYCReviewStatusPending}
+		_, err := kyc.Transition(review, kyc.ActionExpire, "", "", time.Now())
+		require.Error(t, err)
+		assert.True(t, kyc.IsStaleExpiry(err))
+		assert.True(t, kyc.IsStaleExpiry(fmt.Errorf("lock review: %w", gorm.ErrRecordNotFound)))
+
+		// Anything else still fails the run
+		assert.False(t, kyc.IsStaleExpiry(utils.NewInternalError("Failed to update KYC review", errors.New("connection reset"))))
+		assert.False(t, kyc.IsStaleExpiry(errors.New("connection reset")))
+	})
+}
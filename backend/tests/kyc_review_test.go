@@ -0,0 +1,140 @@
+// tests/kyc_review_test.go
+package tests
+
+import (
+	"backend/models"
+	"backend/services/kyc"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKYCReviewWorkflow(t *testing.T) {
+	now := time.Now()
+	// newReview returns a submission whose risk level a verifier has already assessed
+	newReview := func(risk string) *models.KYCReview {
+		return &models.KYCReview{CustomerAddress: "0xCustomer", Status: models.KYCReviewStatusPending, RiskLevel: risk, RiskAssessedBy: "0xCarol"}
+	}
+
+	t.Run("RiskLevelSetBeforeApproval", func(t *testing.T) {
+		review := &models.KYCReview{CustomerAddress: "0xCustomer", Status: models.KYCReviewStatusPending, RiskLevel: kyc.DefaultRiskLevel}
+		assert.Equal(t, "High", review.RiskLevel, "new submissions need review by two verifiers")
+		_, err := kyc.Transition(review, kyc.ActionApprove, "0xAlice", "", now)
+		assert.Error(t, err, "risk level not assessed")
+		assert.Equal(t, models.KYCReviewStatusPending, review.Status)
+
+		_, err = kyc.Transition(review, kyc.ActionSetRiskLevel, "0xAlice", "Critical", now)
+		assert.Error(t, err, "unknown risk level")
+		_, err = kyc.Transition(review, kyc.ActionSetRiskLevel, "0xCustomer", "low", now)
+		assert.Error(t, err, "customers cannot assess themselves")
+
+		status, err := kyc.Transition(review, kyc.ActionSetRiskLevel, "0xAlice", "low", now)
+		require.NoError(t, err)
+		assert.Equal(t, models.KYCVerifyStatusRiskAssessed, status)
+		assert.Equal(t, "Low", review.RiskLevel)
+		assert.Equal(t, "0xAlice", review.RiskAssessedBy)
+
+		status, err = kyc.Transition(review, kyc.ActionApprove, "0xAlice", "", now)
+		require.NoError(t, err)
+		assert.Equal(t, models.KYCVerifyStatusApproved, status)
+	})
+
+	t.Run("RiskLevelFixedAfterFirstApproval", func(t *testing.T) {
+		review := newReview("High")
+		_, err := kyc.Transition(review, kyc.ActionApprove, "0xAlice", "", now)
+		require.NoError(t, err)
+		_, err = kyc.Transition(review, kyc.ActionSetRiskLevel, "0xBob", "Low", now)
+		assert.Error(t, err, "lowering the level would skip the second approval")
+		assert.Equal(t, "High", review.RiskLevel)
+	})
+
+	t.Run("LowRiskSingleApproval", func(t *testing.T) {
+		review := newReview("Low")
+		status, err := kyc.Transition(review, kyc.ActionAssign, "0xAlice", "0xAlice", now)
+		require.NoError(t, err)
+		assert.Equal(t, models.KYCVerifyStatusAssigned, status)
+		assert.Equal(t, models.KYCReviewStatusInReview, review.Status)
+
+		_, err = kyc.Transition(review, kyc.ActionApprove, "0xBob", "", now)
+		assert.Error(t, err, "assigned to another verifier")
+		assert.Equal(t, models.KYCReviewStatusInReview, review.Status)
+
+		status, err = kyc.Transition(review, kyc.ActionApprove, "0xalice", "", now)
+		require.NoError(t, err)
+		assert.Equal(t, models.KYCVerifyStatusApproved, status)
+		assert.Equal(t, models.KYCReviewStatusApproved, review.Status)
+		assert.NotNil(t, review.DecidedAt)
+
+		_, err = kyc.Transition(review, kyc.ActionReject, "0xAlice", "", now)
+		assert.Error(t, err, "already decided")
+	})
+
+	t.Run("HighRiskFourEyes", func(t *testing.T) {
+		review := newReview("high")
+		status, err := kyc.Transition(review, kyc.ActionApprove, "0xAlice", "", now)
+		require.NoError(t, err)
+		assert.Equal(t, models.KYCVerifyStatusFirstApproval, status)
+		assert.Equal(t, models.KYCReviewStatusAwaitingSecondApproval, review.Status)
+		assert.Equal(t, "0xAlice", review.FirstApprover)
+		assert.Empty(t, review.AssignedVerifier)
+
+		_, err = kyc.Transition(review, kyc.ActionApprove, "0xALICE", "", now)
+		assert.Error(t, err, "same verifier twice")
+		_, err = kyc.Transition(review, kyc.ActionAssign, "0xAlice", "0xAlice", now)
+		assert.Error(t, err, "second approval assigned to the first approver")
+
+		status, err = kyc.Transition(review, kyc.ActionApprove, "0xBob", "", now)
+		require.NoError(t, err)
+		assert.Equal(t, models.KYCVerifyStatusApproved, status)
+		assert.Equal(t, models.KYCReviewStatusApproved, review.Status)
+	})
+
+	t.Run("InfoRequestedAndResubmitted", func(t *testing.T) {
+		review := newReview("High")
+		_, err := kyc.Transition(review, kyc.ActionApprove, "0xAlice", "", now)
+		require.NoError(t, err)
+		_, err = kyc.Transition(review, kyc.ActionAssign, "0xBob", "0xBob", now)
+		require.NoError(t, err)
+
+		status, err := kyc.Transition(review, kyc.ActionRequestInfo, "0xBob", "", now)
+		require.NoError(t, err)
+		assert.Equal(t, models.KYCVerifyStatusInfoRequested, status)
+		assert.Equal(t, models.KYCReviewStatusInfoRequested, review.Status)
+		assert.Empty(t, review.FirstApprover, "approvals reset when the submission changes")
+
+		_, err = kyc.Transition(review, kyc.ActionApprove, "0xBob", "", now)
+		assert.Error(t, err, "waiting for the customer")
+
+		status, err = kyc.Transition(review, kyc.ActionResubmit, "0xCustomer", "", now)
+		require.NoError(t, err)
+		assert.Equal(t, models.KYCVerifyStatusResubmitted, status)
+		assert.Equal(t, models.KYCReviewStatusInReview, review.Status)
+		assert.Equal(t, "0xBob", review.AssignedVerifier)
+		assert.Empty(t, review.RiskAssessedBy, "the new data needs a new risk assessment")
+		_, err = kyc.Transition(review, kyc.ActionApprove, "0xBob", "", now)
+		assert.Error(t, err, "risk level not assessed again")
+	})
+
+	t.Run("RejectionAndOwnSubmission", func(t *testing.T) {
+		review := newReview("Low")
+		_, err := kyc.Transition(review, kyc.ActionApprove, "0xcustomer", "", now)
+		assert.Error(t, err, "verifiers cannot approve themselves")
+
+		_, err = kyc.Transition(review, kyc.ActionResubmit, "0xCustomer", "", now)
+		assert.Error(t, err, "nothing to resubmit while pending")
+
+		status, err := kyc.Transition(review, kyc.ActionReject, "0xAlice", "", now)
+		require.NoError(t, err)
+		assert.Equal(t, models.KYCVerifyStatusRejected, status)
+
+		_, err = kyc.Transition(review, kyc.ActionResubmit, "0xCustomer", "", now)
+		require.NoError(t, err)
+		assert.Equal(t, models.KYCReviewStatusPending, review.Status)
+		assert.Nil(t, review.DecidedAt)
+	})
+
+	assert.True(t, kyc.IsVerifier("kyc_verifier"))
+	assert.False(t, kyc.IsVerifier("normal_user"))
+}
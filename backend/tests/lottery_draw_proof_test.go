@@ -0,0 +1,77 @@
+// tests/lottery_draw_proof_test.go
+package tests
+
+import (
+	"backend/models"
+	"backend/services/lottery"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifyDrawProof(t *testing.T) {
+	huge, _ := new(big.Int).SetString("78541660797044910968829902406342334108369226379826116161446442989268089806461", 10)
+	words := []*big.Int{big.NewInt(100), big.NewInt(35), huge}
+
+	t.Run("WinningNumbersFromWords", func(t *testing.T) {
+		numbers := lottery.WinningNumbersFromWords(words, lottery.DrawNumberRange)
+		expected := new(big.Int).Mod(huge, big.NewInt(36))
+		expected.Add(expected, big.NewInt(1))
+		assert.Equal(t, "29,36,"+expected.String(), lottery.FormatNumbers(numbers))
+		for _, number := range numbers {
+			assert.True(t, number.Sign() > 0 && number.Cmp(big.NewInt(36)) <= 0)
+		}
+	})
+
+	numbers := lottery.WinningNumbersFromWords(words, lottery.DrawNumberRange)
+	proof := func() *models.DrawProof {
+		return &models.DrawProof{
+			RandomWords:  lottery.FormatNumbers(words),
+			NumberRange:  lottery.DrawNumberRange,
+			ResultsTopic: lottery.ResultsTopic(numbers).Hex(),
+		}
+	}
+
+	t.Run("Verified", func(t *testing.T) {
+		result := lottery.VerifyDrawProof(proof(), lottery.FormatNumbers(numbers))
+		assert.True(t, result.Verified)
+		assert.True(t, result.MatchesIssue)
+		assert.True(t, result.MatchesTopic)
+	})
+
+	t.Run("IssueNumbersTampered", func(t *testing.T) {
+		result := lottery.VerifyDrawProof(proof(), "1,2,3")
+		assert.False(t, result.Verified)
+		assert.False(t, result.MatchesIssue)
+		assert.NotEmpty(t, result.Reason)
+	})
+
+	t.Run("TopicMismatch", func(t *testing.T) {
+		p := proof()
+		p.ResultsTopic = common.Hash{1}.Hex()
+		result := lottery.VerifyDrawProof(p, lottery.FormatNumbers(numbers))
+		assert.False(t, result.Verified)
+		assert.True(t, result.MatchesIssue)
+		assert.False(t, result.MatchesTopic)
+	})
+
+	t.Run("TopicMissing", func(t *testing.T) {
+		p := proof()
+		p.ResultsTopic = ""
+		result := lottery.VerifyDrawProof(p, lottery.FormatNumbers(numbers))
+		assert.False(t, result.Verified)
+		assert.True(t, result.MatchesIssue)
+		assert.False(t, result.MatchesTopic)
+		assert.Equal(t, "results topic was not recorded", result.Reason)
+	})
+
+	t.Run("WordsMissing", func(t *testing.T) {
+		p := proof()
+		p.RandomWords = ""
+		result := lottery.VerifyDrawProof(p, lottery.FormatNumbers(numbers))
+		assert.False(t, result.Verified)
+		assert.NotEmpty(t, result.Reason)
+	})
+}
@@ -0,0 +1,116 @@
+// tests/lottery_lifecycle_test.go
+package tests
+
+import (
+	"backend/models"
+	"backend/services/lottery"
+	"backend/utils"
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLotteryStatusTransitions(t *testing.T) {
+	cases := []struct {
+		from, target string
+		allowed      bool
+	}{
+		{models.LotteryStatusActive, models.LotteryStatusPaused, true},
+		{models.LotteryStatusPaused, models.LotteryStatusActive, true},
+		{models.LotteryStatusActive, models.LotteryStatusActive, false},
+		{models.LotteryStatusPaused, models.LotteryStatusPaused, false},
+		{models.LotteryStatusActive, models.LotteryStatusTerminated, true},
+		{models.LotteryStatusPaused, models.LotteryStatusTerminated, true},
+		{models.LotteryStatusTerminated, models.LotteryStatusActive, false},
+		{models.LotteryStatusTerminated, models.LotteryStatusDestroyed, true},
+		{models.LotteryStatusDestroyed, models.LotteryStatusDestroyed, false},
+		{models.LotteryStatusDestroyed, models.LotteryStatusActive, false},
+	}
+	for _, tc := range cases {
+		assert.Equal(t, tc.allowed, lottery.CanChangeStatus(tc.from, tc.target), "%s -> %s", tc.from, tc.target)
+	}
+}
+
+func TestLotteryLifecycleService(t *testing.T) {
+	suite := SetupTestDB()
+	defer suite.TearDown()
+	require.NoError(t, suite.DB.AutoMigrate(&models.LotteryType{}, &models.Lottery{}, &models.LotteryIssue{}))
+	require.NoError(t, suite.DB.Create(&models.LotteryType{TypeID: "type-lifecycle", TypeName: "Lifecycle"}).Error)
+
+	create := func(id, status string) {
+		require.NoError(t, suite.DB.Create(&models.Lottery{
+			LotteryID: id, TypeID: "type-lifecycle", TicketName: id, TicketPrice: 1, TicketSupply: 10,
+			BettingRules: "-", PrizeStructure: "-", RegisteredAddr: "0x1", RolloutContractAddress: "0x2", ContractAddress: "0x3",
+			Status: status,
+		}).Error)
+	}
+	isBadRequest := func(err error) bool {
+		var appErr *utils.Error
+		return errors.As(err, &appErr) && appErr.Code == http.StatusBadRequest
+	}
+	ctx := context.Background()
+	service := lottery.NewLotteryLifecycleService(suite.DB)
+
+	t.Run("PauseAndResume", func(t *testing.T) {
+		create("lottery-pause", models.LotteryStatusActive)
+		paused, err := service.PauseLottery(ctx, "lottery-pause")
+		require.NoError(t, err)
+		assert.Equal(t, models.LotteryStatusPaused, paused.Status)
+
+		_, err = service.PauseLottery(ctx, "lottery-pause")
+		assert.True(t, isBadRequest(err), "pausing a paused lottery is refused")
+
+		resumed, err := service.ResumeLottery(ctx, "lottery-pause")
+		require.NoError(t, err)
+		assert.Equal(t, models.LotteryStatusActive, resumed.Status)
+	})
+
+	t.Run("RetiredLotteriesCannotResume", func(t *testing.T) {
+		create("lottery-terminated", models.LotteryStatusTerminated)
+		_, err := service.ResumeLottery(ctx, "lottery-terminated")
+		assert.True(t, isBadRequest(err))
+		_, err = service.PauseLottery(ctx, "lottery-terminated")
+		assert.True(t, isBadRequest(err))
+
+		create("lottery-destroyed", models.LotteryStatusDestroyed)
+		_, _, err = service.DestroyLottery(ctx, "lottery-destroyed")
+		assert.True(t, isBadRequest(err), "a destroyed lottery cannot be destroyed again")
+	})
+
+	t.Run("TerminateRefusedWithOpenIssues", func(t *testing.T) {
+		create("lottery-open", models.LotteryStatusActive)
+		require.NoError(t, suite.DB.Create(&models.LotteryIssue{
+			IssueID: "issue-open", LotteryID: "lottery-open", IssueNumber: "1",
+			SaleEndTime: time.Now().Add(time.Hour), DrawTime: time.Now().Add(2 * time.Hour), Status: models.IssueStatusPending,
+		}).Error)
+		_, _, err := service.TerminateLottery(ctx, "lottery-open")
+		assert.True(t, isBadRequest(err))
+
+		var stored models.Lottery
+		require.NoError(t, suite.DB.Where("lottery_id = ?", "lottery-open").First(&stored).Error)
+		assert.Equal(t, models.LotteryStatusActive, stored.Status)
+	})
+
+	t.Run("UnknownLottery", func(t *testing.T) {
+		_, err := service.PauseLottery(ctx, "lottery-missing")
+		assert.True(t, isBadRequest(err))
+	})
+
+	t.Run("ListHidesRetiredLotteries", func(t *testing.T) {
+		list := lottery.NewLotteryListService(suite.DB)
+		result, err := list.GetAllLotteries(ctx, lottery.LotteryQueryParams{TypeID: "type-lifecycle"})
+		require.NoError(t, err)
+		for _, l := range result.Lotteries {
+			assert.NotContains(t, []string{models.LotteryStatusTerminated, models.LotteryStatusDestroyed}, l.Status)
+		}
+
+		all, err := list.GetAllLotteries(ctx, lottery.LotteryQueryParams{TypeID: "type-lifecycle", IncludeRetired: true})
+		require.NoError(t, err)
+		assert.Equal(t, result.Total+2, all.Total)
+	})
+}
@@ -0,0 +1,71 @@
+// tests/lottery_rollover_test.go
+package tests
+
+import (
+	"backend/models"
+	"backend/services/issue"
+	"backend/services/lottery"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLowerTierWinners(t *testing.T) {
+	results := []*big.Int{big.NewInt(5), big.NewInt(12), big.NewInt(30)}
+	ticket := func(id, bet string, amount float64) models.LotteryTicket {
+		return models.LotteryTicket{TicketID: id, BuyerAddress: "0x" + id, BetContent: bet, PurchaseAmount: amount}
+	}
+
+	t.Run("MatchCount", func(t *testing.T) {
+		assert.Equal(t, 3, lottery.MatchCount([]*big.Int{big.NewInt(5), big.NewInt(12), big.NewInt(30)}, results))
+		assert.Equal(t, 1, lottery.MatchCount([]*big.Int{big.NewInt(12), big.NewInt(12), big.NewInt(5)}, results))
+		assert.Equal(t, 0, lottery.MatchCount([]*big.Int{big.NewInt(30), big.NewInt(5), big.NewInt(12)}, results))
+	})
+
+	t.Run("SecondTierSharesProportionally", func(t *testing.T) {
+		tickets := []models.LotteryTicket{
+			ticket("a", "5,12,1", 1),
+			ticket("b", "5,2,30", 3),
+			ticket("c", "5,1,1", 10),
+		}
+		winners := lottery.LowerTierWinners("issue-1", tickets, results, 100)
+		assert.Len(t, winners, 2)
+		assert.Equal(t, lottery.PrizeLevelSecond, winners[0].PrizeLevel)
+		assert.InDelta(t, 25, winners[0].PrizeAmount, 1e-9)
+		assert.InDelta(t, 75, winners[1].PrizeAmount, 1e-9)
+	})
+
+	t.Run("FallsBackToThirdTier", func(t *testing.T) {
+		tickets := []models.LotteryTicket{ticket("a", "1,12,1", 2), ticket("b", "1,1,1", 2)}
+		winners := lottery.LowerTierWinners("issue-1", tickets, results, 50)
+		assert.Len(t, winners, 1)
+		assert.Equal(t, lottery.PrizeLevelThird, winners[0].PrizeLevel)
+		assert.InDelta(t, 50, winners[0].PrizeAmount, 1e-9)
+	})
+
+	t.Run("NoMatches", func(t *testing.T) {
+		assert.Empty(t, lottery.LowerTierWinners("issue-1", []models.LotteryTicket{ticket("a", "1,1,1", 1)}, results, 50))
+	})
+
+	t.Run("ValidPolicies", func(t *testing.T) {
+		assert.True(t, lottery.IsValidRolloverPolicy(models.RolloverPolicySplitLowerTiers))
+		assert.False(t, lottery.IsValidRolloverPolicy("KEEP"))
+	})
+}
+
+func TestRolloverShortfall(t *testing.T) {
+	t.Run("EmptyContractNeedsWholeCarryOver", func(t *testing.T) {
+		assert.Equal(t, big.NewInt(500), issue.RolloverShortfall(big.NewInt(500), big.NewInt(0)))
+	})
+
+	t.Run("RetryOnlySendsTheRest", func(t *testing.T) {
+		// An earlier attempt already funded part or all of the carry over
+		assert.Equal(t, big.NewInt(200), issue.RolloverShortfall(big.NewInt(500), big.NewInt(300)))
+		assert.Equal(t, 0, issue.RolloverShortfall(big.NewInt(500), big.NewInt(500)).Sign())
+	})
+
+	t.Run("NeverNegative", func(t *testing.T) {
+		assert.Equal(t, 0, issue.RolloverShortfall(big.NewInt(500), big.NewInt(800)).Sign())
+	})
+}
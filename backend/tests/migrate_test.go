@@ -0,0 +1,62 @@
+// tests/migrate_test.go
+package tests
+
+import (
+	"backend/db"
+	"backend/models"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm/schema"
+)
+
+func TestLoadMigrations(t *testing.T) {
+	migrations, err := db.LoadMigrations()
+	require.NoError(t, err)
+	require.NotEmpty(t, migrations)
+
+	t.Run("OrderedAndComplete", func(t *testing.T) {
+		for i, m := range migrations {
+			assert.NotEmpty(t, strings.TrimSpace(m.UpSQL), "migration %d has empty up script", m.Version)
+			assert.NotEmpty(t, strings.TrimSpace(m.DownSQL), "migration %d has empty down script", m.Version)
+			if i > 0 {
+				assert.Greater(t, m.Version, migrations[i-1].Version)
+			}
+		}
+	})
+
+	t.Run("CoversAllModels", func(t *testing.T) {
+		var upSQL strings.Builder
+		for _, m := range migrations {
+			upSQL.WriteString(m.UpSQL)
+		}
+		all := upSQL.String()
+
+		tables := []interface{}{
+			&models.Role{}, &models.RoleMenu{}, &models.Customer{}, &models.KYCData{},
+			&models.KYCVerificationHistory{}, &models.LotteryType{}, &models.Lottery{},
+			&models.LotteryIssue{}, &models.LotteryTicket{}, &models.Winner{},
+			&models.LotteryIssueSequence{},
+			&models.IdempotencyKey{}, &models.ChainIntent{}, &models.IssueSchedule{},
+			&models.RolloverEntry{},
+			&models.DrawProof{},
+			&models.TaxWithholdingRule{},
+			&models.WebhookSubscription{}, &models.WebhookDelivery{},
+			&models.WebhookDeliveryAttempt{}, &models.WebhookDeadLetter{},
+			&models.NotificationPreference{}, &models.NotificationLog{},
+			&models.KYCDocument{}, &models.DocumentAccessLog{},
+			&models.KYCReview{},
+			&models.SanctionsList{}, &models.SanctionsEntry{}, &models.ScreeningHit{},
+			&models.AccessListEntry{}, &models.AccessListAudit{},
+			&models.AuthSession{}, &models.RefreshToken{},
+		}
+		for _, model := range tables {
+			s, err := schema.Parse(model, &sync.Map{}, schema.NamingStrategy{})
+			require.NoError(t, err)
+			assert.Contains(t, all, "CREATE TABLE IF NOT EXISTS "+s.Table+" (", "no migration creates table %s", s.Table)
+		}
+	})
+}
@@ -0,0 +1,63 @@
+// tests/notification_test.go
+package tests
+
+import (
+	"backend/services/notification"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotificationTemplates(t *testing.T) {
+	t.Run("RenderPrizeWon", func(t *testing.T) {
+		subject, body, err := notification.Render(notification.TemplatePrizeWon, map[string]interface{}{
+			"Name": "Alice", "CustomerAddress": "0xabc", "TicketID": "t-1", "TicketName": "Daily 3",
+			"IssueNumber": "20260101-1", "PrizeLevel": "First Prize", "GrossAmount": 100.0,
+			"WithheldAmount": 24.0, "NetAmount": 76.0, "TaxOwedAmount": 0.0, "PayoutStatus": "PAID",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "You won a prize in Daily 3 20260101-1", subject)
+		assert.Contains(t, body, "Hello Alice")
+		assert.Contains(t, body, "Net prize:    76")
+	})
+
+	t.Run("MissingFieldFails", func(t *testing.T) {
+		_, _, err := notification.Render(notification.TemplateTicketReceipt, map[string]interface{}{"Name": ""})
+		assert.Error(t, err)
+		_, _, err = notification.Render("unknown", nil)
+		assert.Error(t, err)
+	})
+
+	t.Run("Preferences", func(t *testing.T) {
+		pref := notification.DefaultPreference("0xabc")
+		assert.True(t, notification.Allowed(pref, notification.TemplateKYCRejected))
+		assert.True(t, notification.Allowed(pref, notification.TemplatePrizeWon))
+		assert.False(t, notification.Allowed(pref, notification.TemplateTicketReceipt))
+		pref.PurchaseReceipts = true
+		assert.True(t, notification.Allowed(pref, notification.TemplateTicketReceipt))
+		pref.EmailEnabled = false
+		assert.False(t, notification.Allowed(pref, notification.TemplatePrizeWon))
+	})
+
+	t.Run("MIMEHeadersCannotBeInjected", func(t *testing.T) {
+		raw := string(notification.BuildMIMEMessage("from@example.com", notification.Message{
+			To: "to@example.com\r\nBcc: evil@example.com", Subject: "Hi", Body: "line1\nline2",
+		}))
+		assert.NotContains(t, raw, "\r\nBcc:")
+		assert.True(t, strings.HasSuffix(raw, "line1\r\nline2"))
+	})
+
+	t.Run("CaptureChannel", func(t *testing.T) {
+		capture := &notification.CaptureChannel{}
+		require.NoError(t, capture.Send(context.Background(), notification.Message{To: "a@example.com", Subject: "s"}))
+		messages := capture.Messages()
+		require.Len(t, messages, 1)
+		assert.Equal(t, "a@example.com", messages[0].To)
+		assert.False(t, messages[0].SentAt.IsZero())
+		capture.Reset()
+		assert.Empty(t, capture.Messages())
+	})
+}
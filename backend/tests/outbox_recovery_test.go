@@ -0,0 +1,246 @@
+// tests/outbox_recovery_test.go
+package tests
+
+import (
+	"backend/models"
+	"backend/services/outbox"
+	"context"
+	"encoding/json"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeChain serves receipts of mined transactions and reports the rest as pending or unknown
+type fakeChain struct {
+	receipts      map[common.Hash]*types.Receipt
+	pending       map[common.Hash]bool
+	nonces        map[common.Address]uint64 // Nonce of the latest block per sender
+	pendingNonces map[common.Address]uint64 // Nonce including the pending pool per sender
+	sent          []common.Hash
+}
+
+func (f *fakeChain) SendTransaction(_ context.Context, tx *types.Transaction) error {
+	f.sent = append(f.sent, tx.Hash())
+	return nil
+}
+
+func (f *fakeChain) NonceAt(_ context.Context, account common.Address, _ *big.Int) (uint64, error) {
+	return f.nonces[account], nil
+}
+
+func (f *fakeChain) PendingNonceAt(_ context.Context, account common.Address) (uint64, error) {
+	if nonce, ok := f.pendingNonces[account]; ok {
+		return nonce, nil
+	}
+	return f.nonces[account], nil
+}
+
+func (f *fakeChain) TransactionByHash(_ context.Context, hash common.Hash) (*types.Transaction, bool, error) {
+	if f.pending[hash] {
+		return types.NewTx(&types.LegacyTx{}), true, nil
+	}
+	return nil, false, ethereum.NotFound
+}
+
+func (f *fakeChain) TransactionReceipt(_ context.Context, hash common.Hash) (*types.Receipt, error) {
+	if receipt, ok := f.receipts[hash]; ok {
+		return receipt, nil
+	}
+	return nil, ethereum.NotFound
+}
+
+func TestOutboxRecovery(t *testing.T) {
+	suite := SetupTestDB()
+	defer suite.TearDown()
+	require.NoError(t, suite.DB.AutoMigrate(&models.ChainIntent{}, &models.LotteryType{}, &models.Lottery{}, &models.LotteryIssue{}, &models.LotteryTicket{}))
+	require.NoError(t, suite.DB.Create(&models.LotteryType{TypeID: "type-outbox", TypeName: "Outbox"}).Error)
+	require.NoError(t, suite.DB.Create(&models.Lottery{
+		LotteryID: "lottery-outbox", TypeID: "type-outbox", TicketName: "Outbox", TicketPrice: 2, TicketSupply: 100,
+		BettingRules: "-", PrizeStructure: "-", RegisteredAddr: "0x1", RolloutContractAddress: "0x2", ContractAddress: "0x3",
+	}).Error)
+	require.NoError(t, suite.DB.Create(&models.LotteryIssue{
+		IssueID: "issue-outbox", LotteryID: "lottery-outbox", IssueNumber: "1",
+		SaleEndTime: time.Now().Add(time.Hour), DrawTime: time.Now().Add(2 * time.Hour), Status: models.IssueStatusPending, PrizePool: 10,
+	}).Error)
+
+	minedHash := common.HexToHash("0x01")
+	revertedHash := common.HexToHash("0x02")
+	pendingHash := common.HexToHash("0x03")
+	droppedHash := common.HexToHash("0x04")
+	ticketHash := common.HexToHash("0x05")
+	contract := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	idleSender := common.HexToAddress("0x00000000000000000000000000000000000000b1")
+	busySender := common.HexToAddress("0x00000000000000000000000000000000000000b2")
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	signer := crypto.PubkeyToAddress(key.PublicKey)
+	chain := &fakeChain{
+		receipts: map[common.Hash]*types.Receipt{
+			minedHash:    {Status: types.ReceiptStatusSuccessful, ContractAddress: contract},
+			revertedHash: {Status: types.ReceiptStatusFailed},
+			ticketHash:   {Status: types.ReceiptStatusSuccessful},
+		},
+		pending:       map[common.Hash]bool{pendingHash: true},
+		nonces:        map[common.Address]uint64{idleSender: 3, busySender: 3, signer: 5},
+		pendingNonces: map[common.Address]uint64{busySender: 4},
+	}
+
+	intent := func(id, operation, reference, txHash string, payload interface{}) {
+		data, err := json.Marshal(payload)
+		require.NoError(t, err)
+		status := models.ChainIntentStatusSubmitted
+		if txHash == "" {
+			status = models.ChainIntentStatusPending
+		}
+		require.NoError(t, suite.DB.Create(&models.ChainIntent{
+			IntentID: id, Operation: operation, ReferenceID: reference, Status: status, Payload: string(data), TxHash: txHash,
+			CreatedAt: time.Now().Add(-time.Hour), UpdatedAt: time.Now().Add(-time.Hour),
+		}).Error)
+	}
+	newLottery := func(id string) models.Lottery {
+		return models.Lottery{LotteryID: id, TypeID: "type-outbox", TicketName: id, TicketPrice: 1, TicketSupply: 10,
+			BettingRules: "-", PrizeStructure: "-", RegisteredAddr: "0x1", RolloutContractAddress: "0x2", Status: models.LotteryStatusActive}
+	}
+	intent("mined", models.ChainIntentOpCreateLottery, "lottery-mined", minedHash.Hex(), newLottery("lottery-mined"))
+	intent("reverted", models.ChainIntentOpCreateLottery, "lottery-reverted", revertedHash.Hex(), newLottery("lottery-reverted"))
+	intent("pending", models.ChainIntentOpCreateLottery, "lottery-pending", pendingHash.Hex(), newLottery("lottery-pending"))
+	intent("dropped", models.ChainIntentOpCreateLottery, "lottery-dropped", droppedHash.Hex(), newLottery("lottery-dropped"))
+	intent("unsent", models.ChainIntentOpCreateLottery, "lottery-unsent", "", newLottery("lottery-unsent"))
+	intent("ticket", models.ChainIntentOpPurchaseTicket, "ticket-outbox", ticketHash.Hex(), outbox.TicketPurchasePayload{
+		Ticket: models.LotteryTicket{TicketID: "ticket-outbox", IssueID: "issue-outbox", BuyerAddress: "0xTestAddress123",
+			PurchaseTime: time.Now(), BetContent: "1,2,3", PurchaseAmount: 2},
+		PrizePoolIncrease: 2,
+	})
+
+	service := outbox.NewOutboxServiceWithChain(suite.DB, chain, idleSender)
+	resolved, err := service.RecoverIntents(context.Background(), time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, 5, resolved)
+
+	status := func(id string) string {
+		var stored models.ChainIntent
+		require.NoError(t, suite.DB.Where("intent_id = ?", id).First(&stored).Error)
+		return stored.Status
+	}
+	lotteryExists := func(id string) bool {
+		var count int64
+		require.NoError(t, suite.DB.Model(&models.Lottery{}).Where("lottery_id = ?", id).Count(&count).Error)
+		return count > 0
+	}
+
+	t.Run("MinedDeploymentWritesLottery", func(t *testing.T) {
+		assert.Equal(t, models.ChainIntentStatusCompleted, status("mined"))
+		var lottery models.Lottery
+		require.NoError(t, suite.DB.Where("lottery_id = ?", "lottery-mined").First(&lottery).Error)
+		assert.Equal(t, contract.Hex(), lottery.ContractAddress)
+	})
+
+	// signedIntent saves a transaction signed with the given nonce on a new intent, as a request does before sending it
+	signedIntent := func(id string, nonce uint64) common.Hash {
+		created, err := service.CreateIntent(context.Background(), models.ChainIntentOpCreateLottery, "lottery-"+id, newLottery("lottery-"+id))
+		require.NoError(t, err)
+		tx, err := types.SignTx(types.NewTx(&types.LegacyTx{Nonce: nonce, Gas: 21000, GasPrice: big.NewInt(1)}),
+			types.LatestSignerForChainID(big.NewInt(1)), key)
+		require.NoError(t, err)
+		require.NoError(t, service.MarkSubmitted(context.Background(), created.IntentID, tx, newLottery("lottery-"+id)))
+		require.NoError(t, suite.DB.Model(&models.ChainIntent{}).Where("intent_id = ?", created.IntentID).
+			Update("updated_at", time.Now().Add(-time.Hour)).Error)
+		return tx.Hash()
+	}
+	intentStatus := func(reference string) models.ChainIntent {
+		var stored models.ChainIntent
+		require.NoError(t, suite.DB.Where("reference_id = ?", reference).First(&stored).Error)
+		return stored
+	}
+
+	t.Run("RevertedDroppedAndUnsentAreFailed", func(t *testing.T) {
+		// The intent without a transaction is failed only because its sender has nothing in the pending pool
+		for _, id := range []string{"reverted", "dropped", "unsent"} {
+			assert.Equal(t, models.ChainIntentStatusFailed, status(id), id)
+			assert.False(t, lotteryExists("lottery-"+id), id)
+		}
+	})
+
+	t.Run("PendingTransactionIsLeftForLater", func(t *testing.T) {
+		assert.Equal(t, models.ChainIntentStatusSubmitted, status("pending"))
+		assert.False(t, lotteryExists("lottery-pending"))
+	})
+
+	t.Run("MarkSubmittedSavesSignedTransaction", func(t *testing.T) {
+		hash := signedIntent("signed", 7)
+		stored := intentStatus("lottery-signed")
+		assert.Equal(t, models.ChainIntentStatusSubmitted, stored.Status)
+		assert.Equal(t, hash.Hex(), stored.TxHash)
+		assert.NotEmpty(t, stored.RawTx)
+		assert.Equal(t, signer.Hex(), stored.Sender)
+		assert.Equal(t, uint64(7), stored.Nonce)
+	})
+
+	t.Run("UnknownTransactionIsBroadcastAgain", func(t *testing.T) {
+		// The request saved the transaction but the node never received it, and its nonce is still free
+		hash := signedIntent("unbroadcast", 5)
+		_, err := service.RecoverIntents(context.Background(), time.Now())
+		require.NoError(t, err)
+		assert.Contains(t, chain.sent, hash)
+		assert.Equal(t, models.ChainIntentStatusSubmitted, intentStatus("lottery-unbroadcast").Status)
+	})
+
+	t.Run("UnknownTransactionWithUsedNonceIsFailed", func(t *testing.T) {
+		hash := signedIntent("replaced", 4)
+		_, err := service.RecoverIntents(context.Background(), time.Now())
+		require.NoError(t, err)
+		assert.NotContains(t, chain.sent, hash)
+		assert.Equal(t, models.ChainIntentStatusFailed, intentStatus("lottery-replaced").Status)
+		assert.False(t, lotteryExists("lottery-replaced"))
+	})
+
+	t.Run("UnsentIsLeftWhileSenderHasPendingTransactions", func(t *testing.T) {
+		intent("unsent-busy", models.ChainIntentOpCreateLottery, "lottery-unsent-busy", "", newLottery("lottery-unsent-busy"))
+		busy := outbox.NewOutboxServiceWithChain(suite.DB, chain, busySender)
+		_, err := busy.RecoverIntents(context.Background(), time.Now())
+		require.NoError(t, err)
+		assert.Equal(t, models.ChainIntentStatusPending, status("unsent-busy"))
+	})
+
+	t.Run("LeasedIntentIsNotTouched", func(t *testing.T) {
+		// An intent updated within the lease still belongs to the request that created it
+		require.NoError(t, suite.DB.Create(&models.ChainIntent{
+			IntentID: "leased", Operation: models.ChainIntentOpCreateLottery, ReferenceID: "lottery-leased",
+			Status: models.ChainIntentStatusPending, Payload: "{}", CreatedAt: time.Now(), UpdatedAt: time.Now(),
+		}).Error)
+		_, err := service.RecoverIntents(context.Background(), time.Now().Add(-10*time.Minute))
+		require.NoError(t, err)
+		assert.Equal(t, models.ChainIntentStatusPending, status("leased"))
+	})
+
+	t.Run("MinedPurchaseWritesTicketAndPool", func(t *testing.T) {
+		assert.Equal(t, models.ChainIntentStatusCompleted, status("ticket"))
+		var ticket models.LotteryTicket
+		require.NoError(t, suite.DB.Where("ticket_id = ?", "ticket-outbox").First(&ticket).Error)
+		assert.Equal(t, ticketHash.Hex(), ticket.TransactionHash)
+		var issue models.LotteryIssue
+		require.NoError(t, suite.DB.Where("issue_id = ?", "issue-outbox").First(&issue).Error)
+		assert.InDelta(t, 12, issue.PrizePool, 1e-9)
+	})
+
+	t.Run("RecoveryIsIdempotent", func(t *testing.T) {
+		// A record written by the request itself is not written twice when its intent is recovered
+		intent("ticket-again", models.ChainIntentOpPurchaseTicket, "ticket-outbox", ticketHash.Hex(), outbox.TicketPurchasePayload{
+			Ticket: models.LotteryTicket{TicketID: "ticket-outbox", IssueID: "issue-outbox"}, PrizePoolIncrease: 2,
+		})
+		_, err := service.RecoverIntents(context.Background(), time.Now())
+		require.NoError(t, err)
+		assert.Equal(t, models.ChainIntentStatusCompleted, status("ticket-again"))
+		var issue models.LotteryIssue
+		require.NoError(t, suite.DB.Where("issue_id = ?", "issue-outbox").First(&issue).Error)
+		assert.InDelta(t, 12, issue.PrizePool, 1e-9)
+	})
+}
@@ -0,0 +1,94 @@
+// tests/ratelimit_test.go
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"backend/services/ratelimit"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimit(t *testing.T) {
+	t.Run("ParseRate", func(t *testing.T) {
+		rate, err := ratelimit.ParseRate("10/1m")
+		require.NoError(t, err)
+		assert.Equal(t, ratelimit.Rate{Limit: 10, Period: time.Minute}, rate)
+
+		rate, err = ratelimit.ParseRate(" 5 / h ")
+		require.NoError(t, err)
+		assert.Equal(t, ratelimit.Rate{Limit: 5, Period: time.Hour}, rate)
+
+		for _, spec := range []string{"", "10", "0/1m", "-1/1m", "10/", "10/0s", "ten/1m", "10/fortnight"} {
+			_, err := ratelimit.ParseRate(spec)
+			assert.Error(t, err, spec)
+		}
+	})
+
+	t.Run("Take", func(t *testing.T) {
+		rate := ratelimit.Rate{Limit: 3, Period: 30 * time.Second} // One token every 10 seconds
+		now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+		var bucket ratelimit.Bucket
+		var res ratelimit.Result
+		for i := 2; i >= 0; i-- {
+			bucket, res = ratelimit.Take(bucket, rate, now)
+			assert.True(t, res.Allowed)
+			assert.Equal(t, i, res.Remaining)
+		}
+		bucket, res = ratelimit.Take(bucket, rate, now.Add(4*time.Second))
+		assert.False(t, res.Allowed)
+		assert.Equal(t, 6*time.Second, res.RetryAfter.Round(time.Millisecond))
+
+		// A refused request does not consume the refill
+		bucket, res = ratelimit.Take(bucket, rate, now.Add(10*time.Second))
+		assert.True(t, res.Allowed)
+		assert.Equal(t, 0, res.Remaining)
+
+		// The bucket never holds more than the limit
+		_, res = ratelimit.Take(bucket, rate, now.Add(time.Hour))
+		assert.True(t, res.Allowed)
+		assert.Equal(t, 2, res.Remaining)
+	})
+
+	t.Run("Limiter", func(t *testing.T) {
+		ctx := context.Background()
+		now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+		limiter := ratelimit.NewLimiter(ratelimit.NewMemoryStore(), map[string]ratelimit.Rate{
+			ratelimit.PolicyLogin: {Limit: 2, Period: time.Minute},
+		})
+		wallet := "0xAbC0000000000000000000000000000000000001"
+
+		for i := 0; i < 2; i++ {
+			res, err := limiter.Allow(ctx, ratelimit.PolicyLogin, ratelimit.Identity{IP: "198.51.100.1", Wallet: wallet}, now)
+			require.NoError(t, err)
+			assert.True(t, res.Allowed)
+		}
+
+		// The wallet is limited from another IP, whatever the case of its address
+		res, err := limiter.Allow(ctx, ratelimit.PolicyLogin, ratelimit.Identity{IP: "198.51.100.2", Wallet: "0xabc0000000000000000000000000000000000001"}, now)
+		require.NoError(t, err)
+		assert.False(t, res.Allowed)
+		assert.Equal(t, 30*time.Second, res.RetryAfter.Round(time.Millisecond))
+		assert.Equal(t, 0, res.Remaining)
+
+		// The first IP is limited with another wallet
+		res, err = limiter.Allow(ctx, ratelimit.PolicyLogin, ratelimit.Identity{IP: "198.51.100.1", Subject: "0xdef"}, now)
+		require.NoError(t, err)
+		assert.False(t, res.Allowed)
+
+		// Policies keep separate buckets, and unconfigured policies use their default
+		res, err = limiter.Allow(ctx, ratelimit.PolicyPurchase, ratelimit.Identity{IP: "198.51.100.1", Wallet: wallet}, now)
+		require.NoError(t, err)
+		assert.True(t, res.Allowed)
+		assert.Equal(t, 20, res.Limit)
+
+		// Unknown policies are not limited
+		res, err = limiter.Allow(ctx, "unknown", ratelimit.Identity{IP: "198.51.100.1"}, now)
+		require.NoError(t, err)
+		assert.True(t, res.Allowed)
+	})
+}
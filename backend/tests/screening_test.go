@@ -0,0 +1,78 @@
+// tests/screening_test.go
+package tests
+
+import (
+	"backend/services/screening"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSanctionsScreening(t *testing.T) {
+	t.Run("NormalizeName", func(t *testing.T) {
+		assert.Equal(t, "hans muller", screening.NormalizeName("Müller, Hans"))
+		assert.Equal(t, "hans muller", screening.NormalizeName("  HANS   muller "))
+		assert.Equal(t, "", screening.NormalizeName(" -,. "))
+	})
+
+	t.Run("JaroWinkler", func(t *testing.T) {
+		assert.Equal(t, 1.0, screening.JaroWinkler("martha", "martha"))
+		assert.InDelta(t, 0.961, screening.JaroWinkler("martha", "marhta"), 0.001)
+		assert.InDelta(t, 0.813, screening.JaroWinkler("dixon", "dicksonx"), 0.001)
+		assert.Equal(t, 0.0, screening.JaroWinkler("abc", ""))
+	})
+
+	t.Run("MatchName", func(t *testing.T) {
+		entry := screening.NormalizeName("Ivan Petrov")
+		birth := time.Date(1970, 5, 1, 0, 0, 0, 0, time.UTC)
+		other := time.Date(1985, 1, 1, 0, 0, 0, 0, time.UTC)
+
+		// Word order, case and a typo still hit
+		score, ok := screening.MatchName("petrov, IVAN", nil, entry, &birth, 0.9)
+		assert.True(t, ok)
+		assert.Equal(t, 1.0, score)
+		_, ok = screening.MatchName("Ivan Petrow", &birth, entry, &birth, 0.9)
+		assert.True(t, ok)
+
+		// A different birth date rules a name match out
+		_, ok = screening.MatchName("Ivan Petrov", &other, entry, &birth, 0.9)
+		assert.False(t, ok)
+		_, ok = screening.MatchName("Maria Garcia", nil, entry, nil, 0.9)
+		assert.False(t, ok)
+		_, ok = screening.MatchName("", nil, entry, nil, 0.9)
+		assert.False(t, ok)
+	})
+
+	t.Run("ParseCSV", func(t *testing.T) {
+		entries, err := screening.ParseCSV(strings.NewReader("ID,Full_Name,DOB,Wallet\nA1,Ivan Petrov,1970-05-01,\n,,,0xABCDEF\n,,,\n"))
+		require.NoError(t, err)
+		require.Len(t, entries, 2)
+		assert.Equal(t, "A1", entries[0].Ref)
+		assert.Equal(t, "Ivan Petrov", entries[0].Name)
+		require.NotNil(t, entries[0].BirthDate)
+		assert.Equal(t, 1970, entries[0].BirthDate.Year())
+		// Entries without a ref are numbered by line, wallets are lowercased
+		assert.Equal(t, "3", entries[1].Ref)
+		assert.Equal(t, "0xabcdef", entries[1].WalletAddress)
+
+		_, err = screening.ParseCSV(strings.NewReader("name,birth_date\nIvan,01/05/1970\n"))
+		assert.Error(t, err)
+		_, err = screening.ParseCSV(strings.NewReader("country\nRU\n"))
+		assert.Error(t, err)
+	})
+
+	t.Run("ParseJSON", func(t *testing.T) {
+		entries, err := screening.ParseJSON(strings.NewReader(`[{"id": 7, "name": "Ivan Petrov", "birth_date": "1970-05-01"}, {"wallet_address": "0xABC"}, {"country": "RU"}]`))
+		require.NoError(t, err)
+		require.Len(t, entries, 2)
+		assert.Equal(t, "7", entries[0].Ref)
+		assert.Equal(t, "2", entries[1].Ref)
+		assert.Equal(t, "0xabc", entries[1].WalletAddress)
+
+		_, err = screening.ParseJSON(strings.NewReader(`{"name": "Ivan"}`))
+		assert.Error(t, err)
+	})
+}
@@ -82,14 +82,28 @@ func SetupTestDB() *TestSuite {
 	db.InitDB()
 
 	// 清理数据库
+	db.DB.Exec("DROP TABLE IF EXISTS refresh_tokens CASCADE;")
+	db.DB.Exec("DROP TABLE IF EXISTS auth_sessions CASCADE;")
+	db.DB.Exec("DROP TABLE IF EXISTS screening_hits CASCADE;")
+	db.DB.Exec("DROP TABLE IF EXISTS sanctions_entries CASCADE;")
+	db.DB.Exec("DROP TABLE IF EXISTS sanctions_lists CASCADE;")
+	db.DB.Exec("DROP TABLE IF EXISTS kyc_reviews CASCADE;")
+	db.DB.Exec("DROP TABLE IF EXISTS kyc_documents CASCADE;")
 	db.DB.Exec("DROP TABLE IF EXISTS kyc_verification_history CASCADE;")
 	db.DB.Exec("DROP TABLE IF EXISTS kyc_data CASCADE;")
 	db.DB.Exec("DROP TABLE IF EXISTS customers CASCADE;")
 	db.DB.Exec("DROP TABLE IF EXISTS role_menus CASCADE;")
 	db.DB.Exec("DROP TABLE IF EXISTS roles CASCADE;")
 
-	// 自动迁移
-	db.DB.AutoMigrate(&models.Role{}, &models.RoleMenu{}, &models.Customer{}, &models.KYCData{}, &models.KYCVerificationHistory{})
+	// 自动迁移：注册会筛查制裁名单、认领证件并进入 KYC 审核队列，登录会创建会话和刷新令牌
+	if err := db.DB.AutoMigrate(
+		&models.Role{}, &models.RoleMenu{}, &models.Customer{}, &models.KYCData{}, &models.KYCVerificationHistory{},
+		&models.KYCDocument{}, &models.KYCReview{},
+		&models.SanctionsList{}, &models.SanctionsEntry{}, &models.ScreeningHit{},
+		&models.AuthSession{}, &models.RefreshToken{},
+	); err != nil {
+		log.Fatalf("Failed to migrate test database: %v", err)
+	}
 
 	// 插入初始数据
 	role := models.Role{
@@ -124,6 +138,13 @@ func SetupTestDB() *TestSuite {
 
 func (suite *TestSuite) TearDown() {
 	// 清理数据库
+	suite.DB.Exec("DROP TABLE IF EXISTS refresh_tokens CASCADE;")
+	suite.DB.Exec("DROP TABLE IF EXISTS auth_sessions CASCADE;")
+	suite.DB.Exec("DROP TABLE IF EXISTS screening_hits CASCADE;")
+	suite.DB.Exec("DROP TABLE IF EXISTS sanctions_entries CASCADE;")
+	suite.DB.Exec("DROP TABLE IF EXISTS sanctions_lists CASCADE;")
+	suite.DB.Exec("DROP TABLE IF EXISTS kyc_reviews CASCADE;")
+	suite.DB.Exec("DROP TABLE IF EXISTS kyc_documents CASCADE;")
 	suite.DB.Exec("DROP TABLE IF EXISTS kyc_verification_history CASCADE;")
 	suite.DB.Exec("DROP TABLE IF EXISTS kyc_data CASCADE;")
 	suite.DB.Exec("DROP TABLE IF EXISTS customers CASCADE;")
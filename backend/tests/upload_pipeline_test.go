@@ -0,0 +1,188 @@
+// tests/upload_pipeline_test.go
+package tests
+
+import (
+	"backend/config"
+	"backend/services/document"
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const eicar = `X5O!P%@AP[4\PZX54(P^)7CC)7}$EICAR-STANDARD-ANTIVIRUS-TEST-FILE!$H+H*`
+
+// jpegWithOrientation encodes a w x h JPEG carrying an EXIF orientation tag
+func jpegWithOrientation(t *testing.T, w, h int, orientation uint16) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	img.Set(0, 0, color.RGBA{R: 255, A: 255})
+	var buf bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buf, img, nil))
+
+	tiff := []byte("MM\x00\x2a\x00\x00\x00\x08")
+	tiff = append(tiff, 0, 1)                         // One IFD entry
+	tiff = append(tiff, 0x01, 0x12, 0, 3, 0, 0, 0, 1) // Orientation, SHORT, count 1
+	tiff = binary.BigEndian.AppendUint16(tiff, orientation)
+	tiff = append(tiff, 0, 0, 0, 0, 0, 0, 0, 0) // Value padding, no next IFD
+	segment := append([]byte("Exif\x00\x00"), tiff...)
+	app1 := []byte{0xFF, 0xE1}
+	app1 = binary.BigEndian.AppendUint16(app1, uint16(len(segment)+2))
+	app1 = append(app1, segment...)
+
+	encoded := buf.Bytes()
+	return append(append(append([]byte{}, encoded[:2]...), app1...), encoded[2:]...)
+}
+
+func TestUploadPipeline(t *testing.T) {
+	pipeline := &document.Pipeline{Scanner: document.StubScanner{}, MaxBytes: document.MaxUploadBytes}
+	ctx := context.Background()
+
+	t.Run("DetectType", func(t *testing.T) {
+		assert.Equal(t, document.TypeJPEG, document.DetectType([]byte{0xFF, 0xD8, 0xFF, 0xE0}))
+		assert.Equal(t, document.TypePNG, document.DetectType([]byte("\x89PNG\r\n\x1a\n....")))
+		assert.Equal(t, document.TypePDF, document.DetectType([]byte("%PDF-1.7")))
+		assert.Equal(t, "", document.DetectType([]byte("GIF89a")))
+		assert.Equal(t, "", document.DetectType([]byte("MZ\x90\x00")))
+	})
+
+	t.Run("JPEGStripsEXIFAndAppliesOrientation", func(t *testing.T) {
+		data := jpegWithOrientation(t, 4, 2, 6)
+		assert.Equal(t, 6, document.JPEGOrientation(data))
+
+		processed, err := pipeline.Process(ctx, bytes.NewReader(data))
+		require.NoError(t, err)
+		defer processed.Close()
+		assert.Equal(t, document.TypeJPEG, processed.ContentType)
+
+		out, err := io.ReadAll(processed.File)
+		require.NoError(t, err)
+		assert.Equal(t, int64(len(out)), processed.Size)
+		assert.NotContains(t, string(out), "Exif")
+		assert.Equal(t, 1, document.JPEGOrientation(out))
+		cfg, err := jpeg.DecodeConfig(bytes.NewReader(out))
+		require.NoError(t, err)
+		assert.Equal(t, 2, cfg.Width)
+		assert.Equal(t, 4, cfg.Height)
+	})
+
+	t.Run("PNGDropsAppendedData", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 3, 3))))
+		buf.WriteString("<?php system($_GET['c']); ?>")
+
+		processed, err := pipeline.Process(ctx, &buf)
+		require.NoError(t, err)
+		defer processed.Close()
+		out, err := io.ReadAll(processed.File)
+		require.NoError(t, err)
+		assert.NotContains(t, string(out), "<?php")
+		assert.Len(t, processed.SHA256, 64)
+	})
+
+	t.Run("Rejections", func(t *testing.T) {
+		_, err := pipeline.Process(ctx, strings.NewReader("MZ\x90\x00 not a document"))
+		assert.Error(t, err, "unsupported type")
+
+		_, err = pipeline.Process(ctx, strings.NewReader("\x89PNG\r\n\x1a\ntruncated"))
+		assert.Error(t, err, "undecodable image")
+
+		_, err = pipeline.Process(ctx, strings.NewReader("%PDF-1.4\n"+eicar))
+		assert.Error(t, err, "malware")
+
+		small := &document.Pipeline{Scanner: document.StubScanner{}, MaxBytes: 8}
+		_, err = small.Process(ctx, strings.NewReader("%PDF-1.4 too large"))
+		assert.Error(t, err, "too large")
+
+		processed, err := pipeline.Process(ctx, strings.NewReader("%PDF-1.4\n%%EOF"))
+		require.NoError(t, err)
+		assert.Equal(t, document.TypePDF, processed.ContentType)
+		processed.Close()
+	})
+}
+
+func TestClamdScanner(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			reader := bufio.NewReader(conn)
+			command, _ := reader.ReadString(0)
+			var received []byte
+			for command == "zINSTREAM\x00" {
+				size := make([]byte, 4)
+				if _, err := io.ReadFull(reader, size); err != nil {
+					break
+				}
+				n := binary.BigEndian.Uint32(size)
+				if n == 0 {
+					break
+				}
+				chunk := make([]byte, n)
+				io.ReadFull(reader, chunk)
+				received = append(received, chunk...)
+			}
+			if bytes.Contains(received, []byte(eicar)) {
+				conn.Write([]byte("stream: Eicar-Signature FOUND\x00"))
+			} else {
+				conn.Write([]byte("stream: OK\x00"))
+			}
+			conn.Close()
+		}
+	}()
+
+	scanner := &document.ClamdScanner{Address: listener.Addr().String()}
+	result, err := scanner.Scan(context.Background(), strings.NewReader(strings.Repeat("clean ", 20000)))
+	require.NoError(t, err)
+	assert.True(t, result.Clean)
+
+	result, err = scanner.Scan(context.Background(), strings.NewReader("%PDF-1.4\n"+eicar))
+	require.NoError(t, err)
+	assert.False(t, result.Clean)
+	assert.Equal(t, "Eicar-Signature", result.Signature)
+
+	_, err = document.ParseClamdReply("INSTREAM size limit exceeded. ERROR")
+	assert.Error(t, err)
+
+	_, err = (&document.ClamdScanner{Address: "127.0.0.1:1"}).Scan(context.Background(), strings.NewReader("x"))
+	assert.Error(t, err)
+}
+
+func TestDefaultScanner(t *testing.T) {
+	previous := config.AppConfig.UploadScanner
+	defer func() { config.AppConfig.UploadScanner = previous }()
+
+	config.AppConfig.UploadScanner = document.ScannerClamd
+	scanner, err := document.DefaultScanner()
+	require.NoError(t, err)
+	assert.Equal(t, document.ScannerClamd, scanner.Name())
+
+	config.AppConfig.UploadScanner = document.ScannerStub
+	scanner, err = document.DefaultScanner()
+	require.NoError(t, err)
+	assert.Equal(t, document.ScannerStub, scanner.Name())
+
+	for _, value := range []string{"", "none", "Clamd"} {
+		config.AppConfig.UploadScanner = value
+		_, err = document.DefaultScanner()
+		assert.Error(t, err, value)
+		_, err = document.NewPipeline()
+		assert.Error(t, err, value)
+	}
+}
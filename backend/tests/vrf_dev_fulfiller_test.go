@@ -0,0 +1,52 @@
+// tests/vrf_dev_fulfiller_test.go
+package tests
+
+import (
+	"backend/services/lottery"
+	"backend/services/vrf"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeterministicWords(t *testing.T) {
+	requestID := big.NewInt(42)
+
+	words := vrf.DeterministicWords("lottery-dev", requestID, 3)
+	assert.Len(t, words, 3)
+	assert.Equal(t, words, vrf.DeterministicWords("lottery-dev", requestID, 3))
+	assert.NotEqual(t, words[0], words[1])
+
+	// Another request or another seed yields other words
+	assert.NotEqual(t, words, vrf.DeterministicWords("lottery-dev", big.NewInt(43), 3))
+	assert.NotEqual(t, words, vrf.DeterministicWords("other-seed", requestID, 3))
+
+	// The words map to valid winning numbers
+	for _, number := range lottery.WinningNumbersFromWords(words, lottery.DrawNumberRange) {
+		assert.True(t, number.Sign() > 0 && number.Cmp(big.NewInt(lottery.DrawNumberRange)) <= 0)
+	}
+}
+
+// revertError mimics the error geth returns for a reverted call
+type revertError struct{}
+
+func (revertError) Error() string          { return "execution reverted: request not found" }
+func (revertError) ErrorCode() int         { return 3 }
+func (revertError) ErrorData() interface{} { return "0x08c379a0" }
+
+func TestIsRevert(t *testing.T) {
+	assert.True(t, vrf.IsRevert(revertError{}))
+	assert.True(t, vrf.IsRevert(fmt.Errorf("call: %w", revertError{})))
+	assert.True(t, vrf.IsRevert(errors.New("VM Exception while processing transaction: revert")))
+
+	// Transport failures must not be mistaken for an already fulfilled request
+	assert.False(t, vrf.IsRevert(nil))
+	assert.False(t, vrf.IsRevert(context.DeadlineExceeded))
+	assert.False(t, vrf.IsRevert(&net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}))
+	assert.False(t, vrf.IsRevert(errors.New("429 Too Many Requests")))
+}
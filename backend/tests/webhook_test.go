@@ -0,0 +1,66 @@
+// tests/webhook_test.go
+package tests
+
+import (
+	"backend/models"
+	"backend/services/webhook"
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookSigning(t *testing.T) {
+	body := []byte(`{"id":"evt","type":"issue.drawn"}`)
+	now := time.Unix(1700000000, 0)
+	header := webhook.SignPayload("whsec_test", now, body)
+	assert.Contains(t, header, "t=1700000000,v1=")
+
+	assert.True(t, webhook.VerifySignature("whsec_test", header, body, now.Add(time.Minute), 5*time.Minute))
+	assert.False(t, webhook.VerifySignature("whsec_other", header, body, now, 5*time.Minute))
+	assert.False(t, webhook.VerifySignature("whsec_test", header, []byte(`{}`), now, 5*time.Minute))
+	assert.False(t, webhook.VerifySignature("whsec_test", header, body, now.Add(10*time.Minute), 5*time.Minute))
+	assert.False(t, webhook.VerifySignature("whsec_test", "v1=deadbeef", body, now, 0))
+}
+
+func TestWebhookBackoffAndMatching(t *testing.T) {
+	assert.Equal(t, 30*time.Second, webhook.BackoffDelay(1))
+	assert.Equal(t, time.Minute, webhook.BackoffDelay(2))
+	assert.Equal(t, 4*time.Minute, webhook.BackoffDelay(4))
+	assert.Equal(t, 6*time.Hour, webhook.BackoffDelay(20))
+
+	subscription := &models.WebhookSubscription{Active: true, EventTypes: "issue.drawn,winner.recorded", LotteryID: "lottery-1"}
+	assert.True(t, webhook.SubscriptionMatches(subscription, models.WebhookEventIssueDrawn, "lottery-1"))
+	assert.False(t, webhook.SubscriptionMatches(subscription, models.WebhookEventIssueOpened, "lottery-1"))
+	assert.False(t, webhook.SubscriptionMatches(subscription, models.WebhookEventIssueDrawn, "lottery-2"))
+	subscription.LotteryID = ""
+	assert.True(t, webhook.SubscriptionMatches(subscription, models.WebhookEventWinnerRecorded, "lottery-2"))
+	subscription.Active = false
+	assert.False(t, webhook.SubscriptionMatches(subscription, models.WebhookEventWinnerRecorded, "lottery-2"))
+}
+
+func TestWebhookEndpointValidation(t *testing.T) {
+	for _, ip := range []string{"127.0.0.1", "10.1.2.3", "172.16.0.1", "192.168.1.1", "169.254.169.254", "100.64.0.1", "0.0.0.0", "::1", "fd00:ec2::254", "fe80::1", "::ffff:127.0.0.1"} {
+		assert.True(t, webhook.IsBlockedIP(net.ParseIP(ip)), ip)
+	}
+	for _, ip := range []string{"8.8.8.8", "1.1.1.1", "2606:4700:4700::1111"} {
+		assert.False(t, webhook.IsBlockedIP(net.ParseIP(ip)), ip)
+	}
+
+	ctx := context.Background()
+	assert.NoError(t, webhook.ValidateEndpoint(ctx, "https://8.8.8.8/hooks"))
+	for _, endpoint := range []string{
+		"ftp://8.8.8.8/hooks",
+		"/relative",
+		"http://localhost:8080/hooks",
+		"http://api.localhost/hooks",
+		"http://127.0.0.1/hooks",
+		"http://169.254.169.254/latest/meta-data/",
+		"http://[::1]:9000/hooks",
+		"http://10.0.0.5/hooks",
+	} {
+		assert.Error(t, webhook.ValidateEndpoint(ctx, endpoint), endpoint)
+	}
+}
@@ -0,0 +1,71 @@
+// tests/winner_payout_test.go
+package tests
+
+import (
+	"backend/models"
+	"backend/services/winner"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyRolloutPayouts(t *testing.T) {
+	paidAt := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	alice := "0x00000000000000000000000000000000000000aa"
+	bob := "0x00000000000000000000000000000000000000bb"
+	pending := func(id, address string, amount float64) models.Winner {
+		return models.Winner{WinnerID: id, Address: address, PrizeAmount: amount, PayoutStatus: models.PayoutStatusPending}
+	}
+
+	t.Run("AllPaid", func(t *testing.T) {
+		settled := winner.ApplyRolloutPayouts([]models.Winner{pending("w1", alice, 1)}, nil, "0xcallback", paidAt)
+		assert.Equal(t, models.PayoutStatusPaid, settled[0].PayoutStatus)
+		assert.Equal(t, "0xcallback", settled[0].ClaimTxHash)
+		assert.Equal(t, paidAt, *settled[0].PaidAt)
+	})
+
+	t.Run("FailedTransferSplitByPrize", func(t *testing.T) {
+		winners := []models.Winner{
+			pending("w1", alice, 1),
+			pending("w2", "0x00000000000000000000000000000000000000AA", 3),
+			pending("w3", bob, 2),
+		}
+		amount, _ := new(big.Int).SetString("8000000000000000000", 10)
+		failures := []winner.PayoutFailure{{Address: common.HexToAddress(alice), Amount: amount}}
+
+		settled := winner.ApplyRolloutPayouts(winners, failures, "0xcallback", paidAt)
+		assert.Equal(t, models.PayoutStatusFailed, settled[0].PayoutStatus)
+		assert.InDelta(t, 2, settled[0].PayoutAmount, 1e-9)
+		assert.Equal(t, models.PayoutStatusFailed, settled[1].PayoutStatus)
+		assert.InDelta(t, 6, settled[1].PayoutAmount, 1e-9)
+		assert.Empty(t, settled[1].ClaimTxHash)
+		assert.Equal(t, models.PayoutStatusPaid, settled[2].PayoutStatus)
+	})
+
+	t.Run("FailedTransferIsWithheldByTreasury", func(t *testing.T) {
+		// The contract would have paid gross; the treasury pays the failed prize net, so the tax becomes withheld
+		w := models.Winner{WinnerID: "w1", Address: alice, PrizeAmount: 10, GrossAmount: 10, NetAmount: 10,
+			TaxRate: 0.2, TaxOwedAmount: 2, PayoutStatus: models.PayoutStatusPending}
+		amount, _ := new(big.Int).SetString("10000000000000000000", 10)
+		settled := winner.ApplyRolloutPayouts([]models.Winner{w}, []winner.PayoutFailure{{Address: common.HexToAddress(alice), Amount: amount}}, "0xcallback", paidAt)
+		assert.Equal(t, models.PayoutStatusFailed, settled[0].PayoutStatus)
+		assert.InDelta(t, 8, settled[0].PayoutAmount, 1e-9)
+		assert.InDelta(t, 2, settled[0].WithheldAmount, 1e-9)
+		assert.InDelta(t, 8, settled[0].NetAmount, 1e-9)
+		assert.Zero(t, settled[0].TaxOwedAmount)
+	})
+
+	t.Run("OnlyPendingWinnersSettled", func(t *testing.T) {
+		manual := models.Winner{WinnerID: "w1", Address: alice, PrizeAmount: 5, PayoutStatus: models.PayoutStatusPendingManual, PayoutAmount: 5}
+		settled := winner.ApplyRolloutPayouts([]models.Winner{manual}, nil, "0xcallback", paidAt)
+		assert.Equal(t, manual, settled[0])
+	})
+
+	t.Run("TokenUnits", func(t *testing.T) {
+		assert.Equal(t, "1500000000000000000", winner.UnitsFromTokens(1.5).String())
+		assert.InDelta(t, 1.5, winner.TokensFromUnits(winner.UnitsFromTokens(1.5)), 1e-12)
+	})
+}
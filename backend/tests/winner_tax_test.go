@@ -0,0 +1,96 @@
+// tests/winner_tax_test.go
+package tests
+
+import (
+	"backend/models"
+	"backend/services/winner"
+	"bytes"
+	"encoding/csv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWinnerWithholding(t *testing.T) {
+	rules := []models.TaxWithholdingRule{
+		{Jurisdiction: "US", Threshold: 5000, Rate: 0.24},
+		{Jurisdiction: winner.DefaultTaxJurisdiction, Threshold: 10000, Rate: 0.1},
+	}
+
+	t.Run("MatchWithholdingRule", func(t *testing.T) {
+		assert.Equal(t, "US", winner.MatchWithholdingRule(rules, "us").Jurisdiction)
+		assert.Equal(t, winner.DefaultTaxJurisdiction, winner.MatchWithholdingRule(rules, "CN").Jurisdiction)
+		assert.Equal(t, winner.DefaultTaxJurisdiction, winner.MatchWithholdingRule(rules, "").Jurisdiction)
+		assert.Nil(t, winner.MatchWithholdingRule(rules[:1], "CN"))
+	})
+
+	t.Run("AboveThreshold", func(t *testing.T) {
+		w := models.Winner{PrizeAmount: 6000, PayoutStatus: models.PayoutStatusPendingManual, PayoutAmount: 6000}
+		winner.ApplyWithholding(&w, &rules[0])
+		assert.InDelta(t, 6000, w.GrossAmount, 1e-9)
+		assert.InDelta(t, 1440, w.WithheldAmount, 1e-9)
+		assert.InDelta(t, 4560, w.NetAmount, 1e-9)
+		assert.InDelta(t, 4560, w.PayoutAmount, 1e-9)
+		assert.Zero(t, w.TaxOwedAmount)
+		assert.Equal(t, "US", w.TaxJurisdiction)
+	})
+
+	t.Run("ContractPaidPrizeOwesTax", func(t *testing.T) {
+		// The contract pays first prize gross, nothing is withheld
+		w := models.Winner{PrizeAmount: 6000, PayoutStatus: models.PayoutStatusPending}
+		winner.ApplyWithholding(&w, &rules[0])
+		assert.Zero(t, w.WithheldAmount)
+		assert.InDelta(t, 1440, w.TaxOwedAmount, 1e-9)
+		assert.InDelta(t, 6000, w.NetAmount, 1e-9)
+		assert.InDelta(t, 0.24, w.TaxRate, 1e-9)
+		assert.Zero(t, w.PayoutAmount)
+	})
+
+	t.Run("BelowThreshold", func(t *testing.T) {
+		w := models.Winner{PrizeAmount: 100, PayoutStatus: models.PayoutStatusPending}
+		winner.ApplyWithholding(&w, &rules[0])
+		assert.Zero(t, w.WithheldAmount)
+		assert.Zero(t, w.TaxRate)
+		assert.InDelta(t, 100, w.NetAmount, 1e-9)
+		assert.Zero(t, w.PayoutAmount)
+	})
+
+	t.Run("NoRule", func(t *testing.T) {
+		w := models.Winner{PrizeAmount: 100}
+		winner.ApplyWithholding(&w, nil)
+		assert.InDelta(t, 100, w.NetAmount, 1e-9)
+		assert.Empty(t, w.TaxJurisdiction)
+	})
+
+	statement := &winner.WinningsStatement{
+		CustomerAddress: "0x00000000000000000000000000000000000000aa",
+		Year:            2025,
+		Lines: []winner.StatementLine{
+			{Date: time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC), IssueNumber: "20250301-1", Gross: 6000, Withheld: 1440, Net: 4560, PayoutStatus: models.PayoutStatusPaid},
+			{Date: time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC), IssueNumber: "20250401-1", Gross: 1000, Net: 1000, TaxOwed: 240, PayoutStatus: models.PayoutStatusPaid},
+		},
+		TotalGross:    7000,
+		TotalWithheld: 1440,
+		TotalNet:      5560,
+		TotalTaxOwed:  240,
+		GeneratedAt:   time.Now(),
+	}
+
+	t.Run("StatementCSV", func(t *testing.T) {
+		var buf bytes.Buffer
+		assert.NoError(t, winner.WriteStatementCSV(&buf, statement))
+		rows, err := csv.NewReader(&buf).ReadAll()
+		assert.NoError(t, err)
+		assert.Len(t, rows, 4)
+		assert.Equal(t, "2025-03-01", rows[1][0])
+		assert.Equal(t, []string{"1000.000000", "0.000000", "1000.000000", "240.000000"}, rows[2][6:10])
+		assert.Equal(t, []string{"TOTAL", "7000.000000", "1440.000000", "5560.000000", "240.000000"}, []string{rows[3][0], rows[3][6], rows[3][7], rows[3][8], rows[3][9]})
+	})
+
+	t.Run("StatementPDF", func(t *testing.T) {
+		var buf bytes.Buffer
+		assert.NoError(t, winner.WriteStatementPDF(&buf, statement))
+		assert.True(t, bytes.HasPrefix(buf.Bytes(), []byte("%PDF-")))
+	})
+}
@@ -14,6 +14,8 @@ const (
 	ErrCodeInternalServer   = 1004
 	ErrCodeForbidden        = 1005
 	ErrCodeBadRequest       = 1006
+	ErrCodeConflict         = 1007
+	ErrCodeTooManyRequests  = 1008
 )
 
 func ErrorResponse(code int, message string, data interface{}) Response {
@@ -56,3 +58,7 @@ func NewBadRequestError(message string, err error) *Error {
 func NewInternalError(message string, err error) *Error {
 	return &Error{Code: http.StatusInternalServerError, Message: message, Err: err}
 }
+
+func NewForbiddenError(message string, err error) *Error {
+	return &Error{Code: http.StatusForbidden, Message: message, Err: err}
+}
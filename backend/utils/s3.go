@@ -3,7 +3,6 @@ package utils
 
 import (
 	"backend/config"
-	"bytes"
 	"context"
 	"fmt"
 	"io"
@@ -67,23 +66,17 @@ func UploadFileToS3(file *multipart.FileHeader, directory string) (string, error
 	}
 	defer src.Close()
 
-	// 读取文件内容
-	buffer := make([]byte, file.Size)
-	_, err = src.Read(buffer)
-	if err != nil {
-		return "", err
-	}
-
 	// 生成唯一的文件名
 	filename := fmt.Sprintf("%d_%s", time.Now().UnixNano(), file.Filename)
 	key := filepath.Join(directory, filename)
 
 	// 上传到 S3
 	_, err = S3Client.PutObject(context.TODO(), &s3.PutObjectInput{
-		Bucket:      aws.String(config.AppConfig.BucketName),
-		Key:         aws.String(key),
-		Body:        bytes.NewReader(buffer),
-		ContentType: aws.String(getContentType(file.Filename)),
+		Bucket:        aws.String(config.AppConfig.BucketName),
+		Key:           aws.String(key),
+		Body:          src, // 直接流式上传，单次 Read 可能只读到部分内容
+		ContentLength: aws.Int64(file.Size),
+		ContentType:   aws.String(getContentType(file.Filename)),
 	})
 
 	if err != nil {